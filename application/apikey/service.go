@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=APIKeyService.go
+type Service interface {
+	Create(ctx context.Context, request *model.CreateAPIKeyRequest) (*model.APIKeyResponse, error)
+	GetAll(ctx context.Context) ([]*model.APIKeyResponse, error)
+	Revoke(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, key string) (*model.APIKeyEntity, error)
+}
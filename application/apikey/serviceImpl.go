@@ -0,0 +1,173 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+// A key looks like osk_1a2b3c4d_<secret>. The part before the second
+// underscore is the prefix, stored in clear to find the key and to show
+// which key is which; only the hash of the whole key is stored.
+const (
+	keyPrefix     = "osk_"
+	keyIDSize     = 4
+	keySecretSize = 32
+
+	// touchInterval limits how often last_used_at is written for a busy key.
+	touchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey is returned for an unknown, revoked or expired key.
+var ErrInvalidAPIKey = model.NewUnauthorizedError("invalid_api_key", "invalid or expired api key")
+
+type apiKeySvcImpl struct {
+	repos *repository.Repositories
+}
+
+func NewAPIKeyService(repos *repository.Repositories) Service {
+	return &apiKeySvcImpl{repos: repos}
+}
+
+func (a *apiKeySvcImpl) Create(ctx context.Context, request *model.CreateAPIKeyRequest) (*model.APIKeyResponse, error) {
+	ctx, span := tracing.StartService(ctx, "apikey", "Create")
+	defer span.End()
+
+	scopes := make([]string, 0, len(request.Scopes))
+	for _, scope := range request.Scopes {
+		if !scope.IsValid() {
+			return nil, model.NewValidationError("invalid_scope", fmt.Sprintf("invalid api key scope: %s", scope))
+		}
+
+		scopes = append(scopes, string(scope))
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, model.NewValidationError("invalid_expires_at", "expires_at must be in the future")
+	}
+
+	key, prefix, err := generateKey()
+	if err != nil {
+		return nil, fmt.Errorf("error generating api key: %w", err)
+	}
+
+	apiKey := &model.APIKeyEntity{
+		Name:      request.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(key),
+		Scopes:    strings.Join(scopes, ","),
+		CreatedBy: request.CreatedBy,
+		ExpiresAt: request.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := a.repos.APIKey.Create(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("error creating api key: %w", err)
+	}
+
+	response := apiKey.ToResponse()
+	response.Key = key
+	return response, nil
+}
+
+func (a *apiKeySvcImpl) GetAll(ctx context.Context) ([]*model.APIKeyResponse, error) {
+	ctx, span := tracing.StartService(ctx, "apikey", "GetAll")
+	defer span.End()
+
+	apiKeys, err := a.repos.APIKey.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api keys: %w", err)
+	}
+
+	apiKeysResponse := make([]*model.APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		apiKeysResponse[i] = apiKey.ToResponse()
+	}
+
+	return apiKeysResponse, nil
+}
+
+func (a *apiKeySvcImpl) Revoke(ctx context.Context, id int) error {
+	ctx, span := tracing.StartService(ctx, "apikey", "Revoke")
+	defer span.End()
+
+	if err := a.repos.APIKey.Revoke(ctx, id); err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("api_key_not_found", "api key not found or already revoked")
+		}
+
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+
+	return nil
+}
+
+func (a *apiKeySvcImpl) Authenticate(ctx context.Context, key string) (*model.APIKeyEntity, error) {
+	ctx, span := tracing.StartService(ctx, "apikey", "Authenticate")
+	defer span.End()
+
+	prefix, ok := parsePrefix(key)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := a.repos.APIKey.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidAPIKey
+		}
+
+		return nil, fmt.Errorf("error getting api key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(key)), []byte(apiKey.KeyHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// a failed write of the last use must not refuse the request
+	if err := a.repos.APIKey.Touch(ctx, apiKey.ID, now, touchInterval); err != nil {
+		slog.ErrorContext(ctx, "error recording use of api key", "prefix", apiKey.Prefix, "error", err)
+	}
+
+	return apiKey, nil
+}
+
+func generateKey() (string, string, error) {
+	id := make([]byte, keyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret, err := utils.GenerateToken(keySecretSize)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix := keyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, nil
+}
+
+func parsePrefix(key string) (string, bool) {
+	prefixSize := len(keyPrefix) + keyIDSize*2
+	if !strings.HasPrefix(key, keyPrefix) || len(key) <= prefixSize+1 || key[prefixSize] != '_' {
+		return "", false
+	}
+
+	return key[:prefixSize], true
+}
@@ -0,0 +1,225 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	apikeyEnum "github.com/zakiyalmaya/online-store/constant/apikey"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	mockAPIKeyRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/apikey"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+var (
+	mockAPIKeyRepository *mockAPIKeyRepo.MockRepository
+	apiKeySvc            Service
+)
+
+func Setup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyRepository = mockAPIKeyRepo.NewMockRepository(ctrl)
+	apiKeySvc = NewAPIKeyService(&repository.Repositories{
+		APIKey: mockAPIKeyRepository,
+	})
+}
+
+func TestCreate(t *testing.T) {
+	Setup(t)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name    string
+		request *model.CreateAPIKeyRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when create then return the key once",
+			request: &model.CreateAPIKeyRequest{Name: "warehouse", Scopes: []apikeyEnum.Scope{apikeyEnum.ScopeCatalogRead, apikeyEnum.ScopeOrdersWrite}, ExpiresAt: &future, CreatedBy: 1},
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, apiKey *model.APIKeyEntity) error {
+					if apiKey.Scopes != "catalog:read,orders:write" || apiKey.CreatedBy != 1 || len(apiKey.Prefix) != 12 {
+						t.Errorf("Create() unexpected entity %+v", apiKey)
+					}
+					apiKey.ID = 1
+					return nil
+				}).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given invalid scope when create then return error",
+			request: &model.CreateAPIKeyRequest{Name: "warehouse", Scopes: []apikeyEnum.Scope{"catalog:delete"}},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name:    "Given expiry in the past when create then return error",
+			request: &model.CreateAPIKeyRequest{Name: "warehouse", Scopes: []apikeyEnum.Scope{apikeyEnum.ScopeCatalogRead}, ExpiresAt: &past},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name:    "Given error when create then return error",
+			request: &model.CreateAPIKeyRequest{Name: "warehouse", Scopes: []apikeyEnum.Scope{apikeyEnum.ScopeCatalogRead}},
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			got, err := apiKeySvc.Create(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && !strings.HasPrefix(got.Key, got.Prefix+"_") {
+				t.Errorf("Create() key %s does not start with prefix %s", got.Key, got.Prefix)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given active key when revoke then return success",
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().Revoke(gomock.Any(), 1).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given unknown or revoked key when revoke then return error",
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().Revoke(gomock.Any(), 1).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := apiKeySvc.Revoke(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Revoke() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	Setup(t)
+
+	key := "osk_1a2b3c4d_secret"
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	entity := func() *model.APIKeyEntity {
+		return &model.APIKeyEntity{ID: 1, Prefix: "osk_1a2b3c4d", KeyHash: utils.HashToken(key), Scopes: "catalog:read", ExpiresAt: &future}
+	}
+
+	testCases := []struct {
+		name    string
+		key     string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given valid key when authenticate then return the key and record its use",
+			key:  key,
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().GetByPrefix(gomock.Any(), "osk_1a2b3c4d").Return(entity(), nil).Times(1)
+				mockAPIKeyRepository.EXPECT().Touch(gomock.Any(), 1, gomock.Any(), touchInterval).Return(nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given touch error when authenticate then still return the key",
+			key:  key,
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().GetByPrefix(gomock.Any(), "osk_1a2b3c4d").Return(entity(), nil).Times(1)
+				mockAPIKeyRepository.EXPECT().Touch(gomock.Any(), 1, gomock.Any(), touchInterval).Return(errors.New("error")).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Given malformed key when authenticate then return invalid api key",
+			key:     "not-a-key",
+			mock:    func() {},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "Given unknown prefix when authenticate then return invalid api key",
+			key:  key,
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().GetByPrefix(gomock.Any(), "osk_1a2b3c4d").Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "Given wrong secret when authenticate then return invalid api key",
+			key:  "osk_1a2b3c4d_other",
+			mock: func() {
+				mockAPIKeyRepository.EXPECT().GetByPrefix(gomock.Any(), "osk_1a2b3c4d").Return(entity(), nil).Times(1)
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "Given revoked key when authenticate then return invalid api key",
+			key:  key,
+			mock: func() {
+				apiKey := entity()
+				apiKey.RevokedAt = &past
+				mockAPIKeyRepository.EXPECT().GetByPrefix(gomock.Any(), "osk_1a2b3c4d").Return(apiKey, nil).Times(1)
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "Given expired key when authenticate then return invalid api key",
+			key:  key,
+			mock: func() {
+				apiKey := entity()
+				apiKey.ExpiresAt = &past
+				mockAPIKeyRepository.EXPECT().GetByPrefix(gomock.Any(), "osk_1a2b3c4d").Return(apiKey, nil).Times(1)
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			got, err := apiKeySvc.Authenticate(context.Background(), tc.key)
+			if err != tc.wantErr {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && got.ID != 1 {
+				t.Errorf("Authenticate() = %v, want key 1", got)
+			}
+		})
+	}
+}
@@ -1,12 +1,21 @@
 package application
 
 import (
+	"github.com/zakiyalmaya/online-store/application/apikey"
 	"github.com/zakiyalmaya/online-store/application/cart"
 	"github.com/zakiyalmaya/online-store/application/category"
 	"github.com/zakiyalmaya/online-store/application/customer"
+	"github.com/zakiyalmaya/online-store/application/health"
 	"github.com/zakiyalmaya/online-store/application/product"
+	"github.com/zakiyalmaya/online-store/application/ratelimit"
+	"github.com/zakiyalmaya/online-store/application/review"
 	"github.com/zakiyalmaya/online-store/application/transaction"
+	"github.com/zakiyalmaya/online-store/application/wishlist"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/mailer"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/storage"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
 )
 
 type Application struct {
@@ -15,14 +24,24 @@ type Application struct {
 	ProductSvc     product.Service
 	CartSvc        cart.Service
 	TransactionSvc transaction.Service
+	WishlistSvc    wishlist.Service
+	ReviewSvc      review.Service
+	APIKeySvc      apikey.Service
+	HealthSvc      health.Service
+	RateLimitSvc   ratelimit.Service
 }
 
-func NewApplication(repos *repository.Repositories) *Application {
+func NewApplication(repos *repository.Repositories, store storage.Storage, tokens token.Manager, mail mailer.Mailer, cfg *config.Config) *Application {
 	return &Application{
 		CategorySvc:    category.NewCategoryService(repos),
-		CustomerSvc:    customer.NewCustomerService(repos),
-		ProductSvc:     product.NewProductService(repos),
+		CustomerSvc:    customer.NewCustomerService(repos, tokens, mail, cfg),
+		ProductSvc:     product.NewProductService(repos, store, cfg.Media),
 		CartSvc:        cart.NewCartService(repos),
-		TransactionSvc: transaction.NewTransactionService(repos),
+		TransactionSvc: transaction.NewTransactionService(repos, cfg.Account),
+		WishlistSvc:    wishlist.NewWishlistService(repos, cart.NewCartService),
+		ReviewSvc:      review.NewReviewService(repos),
+		APIKeySvc:      apikey.NewAPIKeyService(repos),
+		HealthSvc:      health.NewHealthService(repos),
+		RateLimitSvc:   ratelimit.NewRateLimitService(repos, cfg.RateLimit),
 	}
 }
@@ -1,12 +1,17 @@
 package cart
 
 import (
+	"context"
+
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=CartService.go
 type Service interface {
-	Create(request *model.CreateCartRequest) (*model.CartResponse, error)
-	GetByParams(request *model.GetCartRequest) ([]*model.CartResponse, error)
-	Delete(request *model.DeleteCartRequest) error
+	Create(ctx context.Context, request *model.CreateCartRequest) (*model.CartResponse, error)
+	GetByParams(ctx context.Context, request *model.GetCartRequest) ([]*model.CartResponse, error)
+	Delete(ctx context.Context, request *model.DeleteCartRequest) error
+	CreateGuest(ctx context.Context, request *model.GuestCartRequest) (*model.GuestCartResponse, error)
+	GetGuest(ctx context.Context, token string) (*model.GuestCartResponse, error)
+	DeleteGuest(ctx context.Context, request *model.DeleteGuestCartRequest) error
 }
\ No newline at end of file
@@ -1,13 +1,16 @@
 package cart
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"sync"
 
 	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
 )
 
 type cartSvcImpl struct {
@@ -18,9 +21,12 @@ func NewCartService(repos *repository.Repositories) Service {
 	return &cartSvcImpl{repos: repos}
 }
 
-func (c *cartSvcImpl) Create(request *model.CreateCartRequest) (*model.CartResponse, error) {
+func (c *cartSvcImpl) Create(ctx context.Context, request *model.CreateCartRequest) (*model.CartResponse, error) {
+	ctx, span := tracing.StartService(ctx, "cart", "Create")
+	defer span.End()
+
 	// check product existence
-	if err := c.checkProductExist(request); err != nil {
+	if err := c.checkProductExist(ctx, request.Items); err != nil {
 		return nil, err
 	}
 
@@ -28,35 +34,37 @@ func (c *cartSvcImpl) Create(request *model.CreateCartRequest) (*model.CartRespo
 	// if cart not exist, create new cart
 	// if cart exist, upsert product to cart
 	activeCartStatus := int(cartEnum.CartStatusActive)
-	cart, err := c.repos.Cart.GetByParams(&model.GetCartRequest{
+	cart, err := c.repos.Cart.GetByParams(ctx, &model.GetCartRequest{
 		CustomerID: request.CustomerID,
 		Status:     &activeCartStatus,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("error getting active cart")
+		return nil, fmt.Errorf("error getting active cart: %w", err)
 	}
 
 	if len(cart) == 0 {
 		cartEntity := request.ToEntity()
-		newCart, err := c.repos.Cart.Create(cartEntity)
+		newCart, err := c.repos.Cart.Create(ctx, cartEntity)
 		if err != nil {
-			return nil, fmt.Errorf("error creating cart")
+			return nil, fmt.Errorf("error creating cart: %w", err)
 		}
+		metrics.CartCreated(metrics.CartTypeCustomer)
 		cart = append(cart, newCart)
 	} else {
 		cartItems := make([]*model.CartItemEntity, len(request.Items))
 		for i, item := range request.Items {
 			cartItems[i] = &model.CartItemEntity{
-				CartID:    cart[0].ID,
-				ProductID: item.ProductID,
-				Quantity:  item.Quantity,
-				Price:     item.Price,
+				CartID:           cart[0].ID,
+				ProductID:        item.ProductID,
+				ProductVariantID: item.ProductVariantID,
+				Quantity:         item.Quantity,
+				Price:            item.Price,
 			}
 		}
 
-		cart[0], err = c.repos.Cart.Upsert(cart[0].ID, cartItems)
+		cart[0], err = c.repos.Cart.Upsert(ctx, cart[0].ID, cartItems)
 		if err != nil {
-			return nil, fmt.Errorf("error upserting cart")
+			return nil, fmt.Errorf("error upserting cart: %w", err)
 		}
 	}
 
@@ -64,44 +72,64 @@ func (c *cartSvcImpl) Create(request *model.CreateCartRequest) (*model.CartRespo
 	return cart[0].ToResponse(), nil
 }
 
-func (c *cartSvcImpl) checkProductExist(cart *model.CreateCartRequest) error {
-	var wg sync.WaitGroup
-	errorCh := make(chan error, len(cart.Items))
-
-	for _, item := range cart.Items {
-		wg.Add(1)
+// checkProductExist checks the items one after the other, the repositories may
+// be those of a unit of work and a transaction runs one query at a time.
+func (c *cartSvcImpl) checkProductExist(ctx context.Context, items []*model.CreateCartItemRequest) error {
+	for _, item := range items {
+		if err := c.checkItemExist(ctx, item.ProductID, item.ProductVariantID); err != nil {
+			return err
+		}
+	}
 
-		go func(productID int) {
-			defer wg.Done()
+	return nil
+}
 
-			_, err := c.repos.Product.GetByID(productID)
-			if err != nil {
-				if err == sql.ErrNoRows {
-					errorCh <- fmt.Errorf("product not found: %d", productID)
-					return
-				}
-				errorCh <- fmt.Errorf("error getting product by id: %d, %v", productID, err)
-				return
+// checkItemExist makes sure the variant belongs to the product, and that
+// a product sold in variants is not added without picking one.
+func (c *cartSvcImpl) checkItemExist(ctx context.Context, productID, variantID int) error {
+	if variantID != 0 {
+		variant, err := c.repos.Product.GetVariantByID(ctx, variantID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return model.NewNotFoundError("product_variant_not_found", fmt.Sprintf("product variant not found: %d", variantID))
 			}
-		}(item.ProductID)
-	}
+			return fmt.Errorf("error getting product variant by id: %d: %w", variantID, err)
+		}
 
-	wg.Wait()
-	close(errorCh)
+		if variant.ProductID != productID {
+			return model.NewValidationError("product_variant_mismatch", fmt.Sprintf("product variant %d does not belong to product %d", variantID, productID))
+		}
 
-	for err := range errorCh {
-		if err != nil {
-			return err
+		return nil
+	}
+
+	_, err := c.repos.Product.GetByID(ctx, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("product_not_found", fmt.Sprintf("product not found: %d", productID))
 		}
+		return fmt.Errorf("error getting product by id: %d: %w", productID, err)
+	}
+
+	variants, err := c.repos.Product.GetVariants(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("error getting product variants: %d: %w", productID, err)
+	}
+
+	if len(variants) > 0 {
+		return model.NewValidationError("product_variant_required", fmt.Sprintf("product variant is required: %d", productID))
 	}
 
 	return nil
 }
 
-func (c *cartSvcImpl) GetByParams(request *model.GetCartRequest) ([]*model.CartResponse, error) {
-	carts, err := c.repos.Cart.GetByParams(request)
+func (c *cartSvcImpl) GetByParams(ctx context.Context, request *model.GetCartRequest) ([]*model.CartResponse, error) {
+	ctx, span := tracing.StartService(ctx, "cart", "GetByParams")
+	defer span.End()
+
+	carts, err := c.repos.Cart.GetByParams(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("error getting cart by params")
+		return nil, fmt.Errorf("error getting cart by params: %w", err)
 	}
 
 	cartsResponse := make([]*model.CartResponse, len(carts))
@@ -112,21 +140,134 @@ func (c *cartSvcImpl) GetByParams(request *model.GetCartRequest) ([]*model.CartR
 	return cartsResponse, nil
 }
 
-func (c *cartSvcImpl) Delete(request *model.DeleteCartRequest) error {
+func (c *cartSvcImpl) Delete(ctx context.Context, request *model.DeleteCartRequest) error {
+	ctx, span := tracing.StartService(ctx, "cart", "Delete")
+	defer span.End()
+
 	// check cart item existence
-	cartItem, err := c.repos.Cart.GetItemByID(request.CartItemID)
+	cartItem, err := c.repos.Cart.GetItemByID(ctx, request.CartItemID)
 	if err != nil {
-		return fmt.Errorf("error getting cart item by id")
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("cart_item_not_found", "cart item not found")
+		}
+		return fmt.Errorf("error getting cart item by id: %w", err)
 	}
 
 	// delete the product from the cart if the cart status is active
-	if err := c.repos.Cart.Delete(&model.DeleteCartRequest{
+	if err := c.repos.Cart.Delete(ctx, &model.DeleteCartRequest{
 		ID:         cartItem.CartID,
 		CartItemID: cartItem.ID,
 		CustomerID: request.CustomerID,
 		Status:     cartEnum.CartStatusActive,
 	}); err != nil {
-		return fmt.Errorf("error deleting cart item")
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("cart_item_not_found", "cart item not found in an active cart")
+		}
+		return fmt.Errorf("error deleting cart item: %w", err)
+	}
+
+	return nil
+}
+
+func (c *cartSvcImpl) CreateGuest(ctx context.Context, request *model.GuestCartRequest) (*model.GuestCartResponse, error) {
+	ctx, span := tracing.StartService(ctx, "cart", "CreateGuest")
+	defer span.End()
+
+	// check product existence
+	if err := c.checkProductExist(ctx, request.Items); err != nil {
+		return nil, err
+	}
+
+	// keep using the given token while its cart is alive,
+	// otherwise start a new guest cart
+	token := request.Token
+	if token != "" {
+		exists, err := c.repos.GuestCart.Exists(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("error getting guest cart: %w", err)
+		}
+
+		if !exists {
+			token = ""
+		}
+	}
+
+	created := token == ""
+	if created {
+		token = utils.GenerateUUID()
+	}
+
+	cartItems := make([]*model.CartItemEntity, len(request.Items))
+	for i, item := range request.Items {
+		cartItems[i] = &model.CartItemEntity{
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+		}
+	}
+
+	if err := c.repos.GuestCart.Upsert(ctx, token, cartItems); err != nil {
+		return nil, fmt.Errorf("error upserting guest cart: %w", err)
+	}
+
+	if created {
+		metrics.CartCreated(metrics.CartTypeGuest)
+	}
+
+	return c.GetGuest(ctx, token)
+}
+
+func (c *cartSvcImpl) GetGuest(ctx context.Context, token string) (*model.GuestCartResponse, error) {
+	ctx, span := tracing.StartService(ctx, "cart", "GetGuest")
+	defer span.End()
+
+	items, err := c.repos.GuestCart.GetItems(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("error getting guest cart: %w", err)
+	}
+
+	itemsResponse := make([]*model.CartItemResponse, len(items))
+	for i, item := range items {
+		product, err := c.repos.Product.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting product by id: %d: %w", item.ProductID, err)
+		}
+
+		itemsResponse[i] = &model.CartItemResponse{
+			ProductID:   item.ProductID,
+			ProductName: product.Name,
+			Quantity:    item.Quantity,
+			Price:       product.Price.InexactFloat64(),
+		}
+
+		if item.ProductVariantID != 0 {
+			variant, err := c.repos.Product.GetVariantByID(ctx, item.ProductVariantID)
+			if err != nil {
+				return nil, fmt.Errorf("error getting product variant by id: %d: %w", item.ProductVariantID, err)
+			}
+
+			variantResponse := variant.ToResponse(product.Price)
+			itemsResponse[i].ProductVariantID = variant.ID
+			itemsResponse[i].SKU = variantResponse.SKU
+			itemsResponse[i].Price = variantResponse.Price
+		}
+	}
+
+	return &model.GuestCartResponse{
+		Token: token,
+		Items: itemsResponse,
+	}, nil
+}
+
+func (c *cartSvcImpl) DeleteGuest(ctx context.Context, request *model.DeleteGuestCartRequest) error {
+	ctx, span := tracing.StartService(ctx, "cart", "DeleteGuest")
+	defer span.End()
+
+	if err := c.repos.GuestCart.DeleteItem(ctx, request.Token, request.ProductID, request.ProductVariantID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("cart_item_not_found", "cart item not found in the guest cart")
+		}
+		return fmt.Errorf("error deleting guest cart item: %w", err)
 	}
 
 	return nil
@@ -1,6 +1,8 @@
 package cart
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
@@ -9,14 +11,16 @@ import (
 	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
 	mockCartRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/cart"
+	mockGuestCartRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/guestcart"
 	mockProductRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/product"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 var (
-	mockCartRepository    *mockCartRepo.MockRepository
-	mockProductRepository *mockProductRepo.MockRepository
-	cartSvc               Service
+	mockCartRepository      *mockCartRepo.MockRepository
+	mockGuestCartRepository *mockGuestCartRepo.MockRepository
+	mockProductRepository   *mockProductRepo.MockRepository
+	cartSvc                 Service
 )
 
 func Setup(t *testing.T) {
@@ -24,9 +28,12 @@ func Setup(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockCartRepository = mockCartRepo.NewMockRepository(ctrl)
+	mockGuestCartRepository = mockGuestCartRepo.NewMockRepository(ctrl)
+	mockProductRepository = mockProductRepo.NewMockRepository(ctrl)
 	cartSvc = NewCartService(&repository.Repositories{
-		Cart:    mockCartRepository,
-		Product: mockProductRepository,
+		Cart:      mockCartRepository,
+		GuestCart: mockGuestCartRepository,
+		Product:   mockProductRepository,
 	})
 }
 
@@ -49,7 +56,7 @@ func TestGetByParams(t *testing.T) {
 			name:    "Given valid request when get cart by params then return success",
 			request: request,
 			mock: func() {
-				mockCartRepository.EXPECT().GetByParams(request).Return([]*model.CartEntity{
+				mockCartRepository.EXPECT().GetByParams(gomock.Any(), request).Return([]*model.CartEntity{
 					{
 						ID:         1,
 						CustomerID: 1,
@@ -70,7 +77,7 @@ func TestGetByParams(t *testing.T) {
 			name:    "Given error when get cart by params then return error",
 			request: request,
 			mock: func() {
-				mockCartRepository.EXPECT().GetByParams(request).Return(nil, errors.New("error")).Times(1)
+				mockCartRepository.EXPECT().GetByParams(gomock.Any(), request).Return(nil, errors.New("error")).Times(1)
 			},
 			wantErr: true,
 		},
@@ -79,7 +86,7 @@ func TestGetByParams(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			_, err := cartSvc.GetByParams(tc.request)
+			_, err := cartSvc.GetByParams(context.Background(), tc.request)
 			if err != nil && !tc.wantErr {
 				t.Errorf("GetByParams() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -108,12 +115,12 @@ func TestDelete(t *testing.T) {
 			name:    "Given valid request when delete cart then return success",
 			request: request,
 			mock: func() {
-				mockCartRepository.EXPECT().GetItemByID(request.CartItemID).Return(&model.CartItemEntity{
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), request.CartItemID).Return(&model.CartItemEntity{
 					ID:     1,
 					CartID: 1,
 				}, nil).Times(1)
 
-				mockCartRepository.EXPECT().Delete(request).Return(nil).Times(1)
+				mockCartRepository.EXPECT().Delete(gomock.Any(), request).Return(nil).Times(1)
 			},
 			wantErr: false,
 		},
@@ -121,12 +128,12 @@ func TestDelete(t *testing.T) {
 			name:    "Given error when delete cart then return error",
 			request: request,
 			mock: func() {
-				mockCartRepository.EXPECT().GetItemByID(request.CartItemID).Return(&model.CartItemEntity{
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), request.CartItemID).Return(&model.CartItemEntity{
 					ID:     1,
 					CartID: 1,
 				}, nil).Times(1)
 
-				mockCartRepository.EXPECT().Delete(request).Return(errors.New("error")).Times(1)
+				mockCartRepository.EXPECT().Delete(gomock.Any(), request).Return(errors.New("error")).Times(1)
 			},
 			wantErr: true,
 		},
@@ -134,7 +141,7 @@ func TestDelete(t *testing.T) {
 			name:    "Given error when get cart item by id then return error",
 			request: request,
 			mock: func() {
-				mockCartRepository.EXPECT().GetItemByID(request.CartItemID).Return(nil, errors.New("error")).Times(1)
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), request.CartItemID).Return(nil, errors.New("error")).Times(1)
 			},
 			wantErr: true,
 		},
@@ -143,7 +150,7 @@ func TestDelete(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			err := cartSvc.Delete(tc.request)
+			err := cartSvc.Delete(context.Background(), tc.request)
 			if err != nil && !tc.wantErr {
 				t.Errorf("Delete() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -151,3 +158,207 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateGuest(t *testing.T) {
+	Setup(t)
+
+	request := &model.GuestCartRequest{
+		Token: "token",
+		Items: []*model.CreateCartItemRequest{
+			{
+				ProductID: 1,
+				Quantity:  1,
+			},
+		},
+	}
+
+	cartItems := []*model.CartItemEntity{
+		{
+			ProductID: 1,
+			Quantity:  1,
+		},
+	}
+
+	product := &model.ProductEntity{
+		ID:    1,
+		Name:  "T-Shirt",
+		Price: decimal.NewFromFloat(10000),
+	}
+
+	variantRequest := &model.GuestCartRequest{
+		Token: "token",
+		Items: []*model.CreateCartItemRequest{
+			{
+				ProductID:        1,
+				ProductVariantID: 2,
+				Quantity:         1,
+			},
+		},
+	}
+
+	variantCartItems := []*model.CartItemEntity{
+		{
+			ProductID:        1,
+			ProductVariantID: 2,
+			Quantity:         1,
+		},
+	}
+
+	variant := &model.ProductVariantEntity{
+		ID:        2,
+		ProductID: 1,
+		SKU:       "TSHIRT-M",
+		Price:     decimal.NewNullDecimal(decimal.NewFromFloat(12000)),
+	}
+
+	testCases := []struct {
+		name    string
+		request *model.GuestCartRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given existing guest cart when create guest cart then return success",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Exists(gomock.Any(), "token").Return(true, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Upsert(gomock.Any(), "token", cartItems).Return(nil).Times(1)
+				mockGuestCartRepository.EXPECT().GetItems(gomock.Any(), "token").Return(cartItems, nil).Times(1)
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given expired guest cart when create guest cart then return new token",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Exists(gomock.Any(), "token").Return(false, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Upsert(gomock.Any(), gomock.Not("token"), cartItems).Return(nil).Times(1)
+				mockGuestCartRepository.EXPECT().GetItems(gomock.Any(), gomock.Not("token")).Return(cartItems, nil).Times(1)
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given variant when create guest cart then return success",
+			request: variantRequest,
+			mock: func() {
+				mockProductRepository.EXPECT().GetVariantByID(gomock.Any(), 2).Return(variant, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Exists(gomock.Any(), "token").Return(true, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Upsert(gomock.Any(), "token", variantCartItems).Return(nil).Times(1)
+				mockGuestCartRepository.EXPECT().GetItems(gomock.Any(), "token").Return(variantCartItems, nil).Times(1)
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariantByID(gomock.Any(), 2).Return(variant, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given variant of another product when create guest cart then return error",
+			request: variantRequest,
+			mock: func() {
+				mockProductRepository.EXPECT().GetVariantByID(gomock.Any(), 2).Return(&model.ProductVariantEntity{ID: 2, ProductID: 3}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given product with variants and no variant when create guest cart then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{variant}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given product not found when create guest cart then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error upsert guest cart when create guest cart then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(product, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Exists(gomock.Any(), "token").Return(true, nil).Times(1)
+				mockGuestCartRepository.EXPECT().Upsert(gomock.Any(), "token", cartItems).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := cartSvc.CreateGuest(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateGuest() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeleteGuest(t *testing.T) {
+	Setup(t)
+
+	request := &model.DeleteGuestCartRequest{
+		Token:     "token",
+		ProductID: 1,
+	}
+
+	testCases := []struct {
+		name     string
+		request  *model.DeleteGuestCartRequest
+		mock     func()
+		wantErr  bool
+		wantKind model.ErrorKind
+	}{
+		{
+			name:    "Given valid request when delete guest cart item then return success",
+			request: request,
+			mock: func() {
+				mockGuestCartRepository.EXPECT().DeleteItem(gomock.Any(), request.Token, request.ProductID, request.ProductVariantID).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given item not in guest cart when delete guest cart item then return not found",
+			request: request,
+			mock: func() {
+				mockGuestCartRepository.EXPECT().DeleteItem(gomock.Any(), request.Token, request.ProductID, request.ProductVariantID).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindNotFound,
+		},
+		{
+			name:    "Given error when delete guest cart item then return error",
+			request: request,
+			mock: func() {
+				mockGuestCartRepository.EXPECT().DeleteItem(gomock.Any(), request.Token, request.ProductID, request.ProductVariantID).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := cartSvc.DeleteGuest(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DeleteGuest() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var domainErr *model.Error
+			if tc.wantKind != 0 && (!errors.As(err, &domainErr) || domainErr.Kind != tc.wantKind) {
+				t.Errorf("DeleteGuest() error = %v, want kind %v", err, tc.wantKind)
+			}
+		})
+	}
+}
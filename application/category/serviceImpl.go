@@ -1,9 +1,11 @@
 package category
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
@@ -15,19 +17,25 @@ func NewCategoryService(repos *repository.Repositories) Service {
 	return &categorySvcImpl{repos: repos}
 }
 
-func (c *categorySvcImpl) Create(name string) error {
-	err := c.repos.Category.Create(&model.CategoryEntity{Name: name})
+func (c *categorySvcImpl) Create(ctx context.Context, name string) error {
+	ctx, span := tracing.StartService(ctx, "category", "Create")
+	defer span.End()
+
+	err := c.repos.Category.Create(ctx, &model.CategoryEntity{Name: name})
 	if err != nil {
-		return fmt.Errorf("error creating category")
+		return fmt.Errorf("error creating category: %w", err)
 	}
 
 	return nil
 }
 
-func (c *categorySvcImpl) GetAll() ([]*model.CategoryEntity, error) {
-	categories, err := c.repos.Category.GetAll()
+func (c *categorySvcImpl) GetAll(ctx context.Context) ([]*model.CategoryEntity, error) {
+	ctx, span := tracing.StartService(ctx, "category", "GetAll")
+	defer span.End()
+
+	categories, err := c.repos.Category.GetAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error getting all categories")
+		return nil, fmt.Errorf("error getting all categories: %w", err)
 	}
 	
 	return categories, nil
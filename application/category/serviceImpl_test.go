@@ -1,6 +1,7 @@
 package category
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -38,7 +39,7 @@ func TestCreate(t *testing.T) {
 			name:     "Given valid request when create category then return success",
 			categoryName: "Fashion",
 			mock: func() {
-				mockCaregoryRepository.EXPECT().Create(&model.CategoryEntity{Name: "Fashion"}).Return(nil)
+				mockCaregoryRepository.EXPECT().Create(gomock.Any(), &model.CategoryEntity{Name: "Fashion"}).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -46,7 +47,7 @@ func TestCreate(t *testing.T) {
 			name:     "Given error when create category then return error",
 			categoryName: "Fashion",
 			mock: func() {
-				mockCaregoryRepository.EXPECT().Create(&model.CategoryEntity{Name: "Fashion"}).Return(errors.New("error"))
+				mockCaregoryRepository.EXPECT().Create(gomock.Any(), &model.CategoryEntity{Name: "Fashion"}).Return(errors.New("error"))
 			},
 			wantErr: true,
 		},
@@ -55,7 +56,7 @@ func TestCreate(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			err := categorySvc.Create(tc.categoryName)
+			err := categorySvc.Create(context.Background(), tc.categoryName)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -74,14 +75,14 @@ func TestGetAll(t *testing.T) {
 		{
 			name: "Given valid request when get all category then return success",
 			mock: func() {
-				mockCaregoryRepository.EXPECT().GetAll().Return([]*model.CategoryEntity{{Name: "Fashion"}}, nil)
+				mockCaregoryRepository.EXPECT().GetAll(gomock.Any()).Return([]*model.CategoryEntity{{Name: "Fashion"}}, nil)
 			},
 			wantErr: false,
 		},
 		{
 			name: "Given error when get all category then return error",
 			mock: func() {
-				mockCaregoryRepository.EXPECT().GetAll().Return(nil, errors.New("error"))
+				mockCaregoryRepository.EXPECT().GetAll(gomock.Any()).Return(nil, errors.New("error"))
 			},
 			wantErr: true,
 		},
@@ -90,7 +91,7 @@ func TestGetAll(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			_, err := categorySvc.GetAll()
+			_, err := categorySvc.GetAll(context.Background())
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetAll() error = %v, wantErr %v", err, tc.wantErr)
 			}
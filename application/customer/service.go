@@ -1,10 +1,30 @@
 package customer
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=CustomerService.go
 type Service interface {
-	Register(request *model.CustomerRequest) error
-	Login(request *model.AuthRequest) (*model.AuthResponse, error)
-	Logout(username string) error
+	Register(ctx context.Context, request *model.CustomerRequest) error
+	Login(ctx context.Context, request *model.AuthRequest) (*model.AuthResponse, error)
+	LoginTwoFactor(ctx context.Context, request *model.TwoFactorLoginRequest) (*model.AuthResponse, error)
+	Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.AuthResponse, error)
+	Logout(ctx context.Context, request *model.SessionRequest) error
+	GetSessions(ctx context.Context, request *model.SessionRequest) ([]*model.SessionResponse, error)
+	RevokeSession(ctx context.Context, request *model.SessionRequest) error
+	GetProfile(ctx context.Context, customerID int) (*model.CustomerResponse, error)
+	UpdateProfile(ctx context.Context, request *model.UpdateCustomerRequest) (*model.CustomerResponse, error)
+	ChangePassword(ctx context.Context, request *model.ChangePasswordRequest) error
+	Delete(ctx context.Context, request *model.DeleteCustomerRequest) error
+	SendVerificationEmail(ctx context.Context, customerID int) error
+	VerifyEmail(ctx context.Context, request *model.VerifyEmailRequest) error
+	ForgotPassword(ctx context.Context, request *model.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error
+	EnrollTwoFactor(ctx context.Context, customerID int) (*model.TwoFactorEnrollmentResponse, error)
+	ConfirmTwoFactor(ctx context.Context, request *model.ConfirmTwoFactorRequest) (*model.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, request *model.DisableTwoFactorRequest) error
+	Wait()
 }
\ No newline at end of file
@@ -2,94 +2,1161 @@ package customer
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/base32"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
-	"github.com/dgrijalva/jwt-go"
-	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/config"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	customerEnum "github.com/zakiyalmaya/online-store/constant/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/mailer"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	customerRepo "github.com/zakiyalmaya/online-store/infrastructure/repository/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// refreshTokenSize is the number of random bytes in a refresh token.
+	refreshTokenSize = 32
+
+	// accountTokenSize is the number of random bytes in a mailed verification or reset token.
+	accountTokenSize = 32
+
+	// challengeTokenSize is the number of random bytes in a two-factor login challenge token.
+	challengeTokenSize = 32
+
+	// recoveryCodeSize is the number of base32 characters in a recovery code,
+	// 50 random bits shown as two groups of 5.
+	recoveryCodeSize = 10
+
+	// dummyPasswordHash is compared against for unknown usernames, so a login takes
+	// as long whether the username exists or not. Nothing hashes to it.
+	dummyPasswordHash = "$2a$10$0/.c2hTQxSe6QyhsyKL3K.W2p7sAfWBl4mrXlK7zUMgxGOMBBWNGC"
+
+	// deletedCustomerName replaces the name of a deleted account in its reviews and transactions.
+	deletedCustomerName = "Deleted Customer"
+)
+
+var (
+	// ErrInvalidCredentials is the error of every failed login, it does not tell
+	// whether the username exists.
+	ErrInvalidCredentials = model.NewUnauthorizedError("invalid_credentials", "invalid username or password")
+
+	// ErrInvalidTwoFactorCode is returned for a wrong, reused or expired code of the second login step.
+	ErrInvalidTwoFactorCode = model.NewUnauthorizedError("invalid_two_factor_code", "invalid two-factor code")
+
+	// ErrInvalidChallenge is returned when the challenge token expired or was already answered.
+	ErrInvalidChallenge = model.NewUnauthorizedError("invalid_challenge", "invalid or expired challenge token")
+
+	// ErrInvalidRefreshToken is returned for an unknown, expired or revoked refresh token,
+	// the client has to log in again.
+	ErrInvalidRefreshToken = model.NewUnauthorizedError("invalid_refresh_token", "invalid refresh token")
+
+	// ErrWrongPassword is returned when the current password confirming a change is wrong.
+	ErrWrongPassword = model.NewValidationError("wrong_password", "wrong password")
+
+	// ErrInvalidToken is returned for an unknown, used or expired email verification or password reset token.
+	ErrInvalidToken = model.NewValidationError("invalid_token", "invalid or expired token")
+
+	ErrCustomerNotFound    = model.NewNotFoundError("customer_not_found", "customer not found")
+	ErrSessionNotFound     = model.NewNotFoundError("session_not_found", "session not found")
+	ErrTwoFactorEnabled    = model.NewConflictError("two_factor_enabled", "two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled = model.NewUnprocessableError("two_factor_not_enabled", "two-factor authentication not enabled")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
 type customerSvcImpl struct {
-	repos *repository.Repositories
+	repos     *repository.Repositories
+	tokens    token.Manager
+	mailer    mailer.Mailer
+	auth      config.AuthConfig
+	account   config.AccountConfig
+	password  config.PasswordConfig
+	login     config.LoginConfig
+	twoFactor config.TwoFactorConfig
+	// resets are the password reset emails being sent in the background
+	resets sync.WaitGroup
 }
 
-func NewCustomerService(repos *repository.Repositories) Service {
-	return &customerSvcImpl{repos: repos}
+func NewCustomerService(repos *repository.Repositories, tokens token.Manager, mail mailer.Mailer, cfg *config.Config) Service {
+	return &customerSvcImpl{
+		repos:     repos,
+		tokens:    tokens,
+		mailer:    mail,
+		auth:      cfg.Auth,
+		account:   cfg.Account,
+		password:  cfg.Password,
+		login:     cfg.Login,
+		twoFactor: cfg.TwoFactor,
+	}
 }
 
-func (c *customerSvcImpl) Register(request *model.CustomerRequest) error {
+func (c *customerSvcImpl) Register(ctx context.Context, request *model.CustomerRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "Register")
+	defer span.End()
+
+	if err := c.checkPassword("password", request.Password); err != nil {
+		return err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("error hashing password")
+		return fmt.Errorf("error hashing password: %w", err)
 	}
 
-	if err := c.repos.Customer.Create(&model.CustomerEntity{
+	customer := &model.CustomerEntity{
 		Name:        request.Name,
 		Username:    request.Username,
 		Password:    string(hashedPassword),
 		Email:       request.Email,
 		Address:     request.Address,
 		PhoneNumber: request.PhoneNumber,
+	}
+	if err := c.repos.Customer.Create(ctx, customer); err != nil {
+		if conflict := conflictError(err); conflict != nil {
+			return conflict
+		}
+
+		return fmt.Errorf("error creating customer: %w", err)
+	}
+
+	// the account is created either way, the customer can ask for another email
+	if customer.Email != "" {
+		if err := c.sendVerificationEmail(ctx, customer); err != nil {
+			slog.ErrorContext(ctx, "failed to send verification email", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// Login refuses a username or an IP address locked out after too many failures
+// before checking the password, and slows down every failure. An account with
+// two-factor authentication gets a challenge to answer with LoginTwoFactor instead of the tokens.
+func (c *customerSvcImpl) Login(ctx context.Context, request *model.AuthRequest) (*model.AuthResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "Login")
+	defer span.End()
+
+	userSubject := loginUserSubject(request.Username)
+	ipSubject := loginIPSubject(request.IPAddress)
+
+	if err := c.checkLoginLock(ctx, userSubject, ipSubject); err != nil {
+		return nil, err
+	}
+
+	response, err := c.repos.Customer.GetByUsername(ctx, request.Username)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error getting customer by username: %w", err)
+	}
+
+	passwordHash := dummyPasswordHash
+	if response != nil {
+		passwordHash = response.Password
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(request.Password)); err != nil || response == nil {
+		c.loginFailed(ctx, userSubject, ipSubject)
+		return nil, ErrInvalidCredentials
+	}
+
+	// the failures are kept until the code is right too, otherwise knowing the
+	// password would allow guessing codes forever
+	if response.TwoFactorEnabled {
+		return c.loginChallenge(ctx, response, request.CartToken)
+	}
+
+	return c.startSession(ctx, response, userSubject, request.Device, request.IPAddress, request.CartToken)
+}
+
+// LoginTwoFactor is the second step of the login of an account with two-factor
+// authentication, a wrong code counts as a failed login.
+func (c *customerSvcImpl) LoginTwoFactor(ctx context.Context, request *model.TwoFactorLoginRequest) (*model.AuthResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "LoginTwoFactor")
+	defer span.End()
+
+	challengeID := utils.HashToken(request.ChallengeToken)
+	challenge, err := c.repos.LoginChallenge.Get(ctx, challengeID)
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrInvalidChallenge
+		}
+
+		return nil, fmt.Errorf("error getting login challenge: %w", err)
+	}
+
+	userSubject := loginUserSubject(challenge.Username)
+	ipSubject := loginIPSubject(request.IPAddress)
+
+	if err := c.checkLoginLock(ctx, userSubject, ipSubject); err != nil {
+		return nil, err
+	}
+
+	customer, err := c.repos.Customer.GetByID(ctx, challenge.CustomerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidChallenge
+		}
+
+		return nil, fmt.Errorf("error getting customer: %w", err)
+	}
+
+	// disabled since the password was checked
+	if !customer.TwoFactorEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	valid, err := c.checkTwoFactorCode(ctx, customer, request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !valid {
+		c.loginFailed(ctx, userSubject, ipSubject)
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	deleted, err := c.repos.LoginChallenge.Delete(ctx, challengeID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting login challenge: %w", err)
+	}
+
+	if !deleted {
+		return nil, ErrInvalidChallenge
+	}
+
+	return c.startSession(ctx, customer, userSubject, request.Device, request.IPAddress, challenge.CartToken)
+}
+
+// loginChallenge stores the login that got the password right, only the hash
+// of the returned challenge token is kept.
+func (c *customerSvcImpl) loginChallenge(ctx context.Context, customer *model.CustomerEntity, cartToken string) (*model.AuthResponse, error) {
+	token, err := utils.GenerateToken(challengeTokenSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge token: %w", err)
+	}
+
+	if err := c.repos.LoginChallenge.Create(ctx, &model.LoginChallengeEntity{
+		ID:         utils.HashToken(token),
+		CustomerID: customer.ID,
+		Username:   customer.Username,
+		CartToken:  cartToken,
+		CreatedAt:  time.Now(),
 	}); err != nil {
-		return fmt.Errorf("error creating customer")
+		return nil, fmt.Errorf("error creating login challenge: %w", err)
+	}
+
+	return &model.AuthResponse{
+		Username:          customer.Username,
+		Name:              customer.Name,
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+	}, nil
+}
+
+// startSession ends a successful login with a new session for the device.
+func (c *customerSvcImpl) startSession(ctx context.Context, customer *model.CustomerEntity, userSubject, device, ipAddress, cartToken string) (*model.AuthResponse, error) {
+	// the IP keeps its failures, one good account must not cover for guessing others
+	if err := c.repos.LoginAttempt.Reset(ctx, userSubject); err != nil {
+		slog.ErrorContext(ctx, "failed to reset login failures", "error", err)
+	}
+
+	now := time.Now()
+	session := &model.SessionEntity{
+		ID:         utils.GenerateUUID(),
+		CustomerID: customer.ID,
+		Username:   customer.Username,
+		Device:     device,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+	}
+
+	refreshToken, err := c.renewSession(ctx, session, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.repos.Session.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	// a failed merge should not block the login, the guest cart is kept until it expires
+	if cartToken != "" {
+		if err := c.mergeGuestCart(ctx, cartToken, customer.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to merge guest cart", "error", err)
+		}
+	}
+
+	return c.authResponse(ctx, customer, session.ID, refreshToken)
+}
+
+func loginUserSubject(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func loginIPSubject(ip string) string {
+	if ip == "" {
+		return ""
+	}
+
+	return "ip:" + ip
+}
+
+// checkLoginLock returns the longest lock of the subjects.
+func (c *customerSvcImpl) checkLoginLock(ctx context.Context, subjects ...string) error {
+	var retryAfter time.Duration
+	for _, subject := range subjects {
+		if subject == "" {
+			continue
+		}
+
+		locked, err := c.repos.LoginAttempt.LockedFor(ctx, subject)
+		if err != nil {
+			return fmt.Errorf("error checking login attempts: %w", err)
+		}
+
+		retryAfter = max(retryAfter, locked)
+	}
+
+	if retryAfter > 0 {
+		return &model.TooManyAttemptsError{RetryAfter: retryAfter}
 	}
 
 	return nil
 }
 
-func (c *customerSvcImpl) Login(request *model.AuthRequest) (*model.AuthResponse, error) {
-	response, err := c.repos.Customer.GetByUsername(request.Username)
+// loginFailed counts the failure against the username and the IP address, locks
+// out the one that failed too often, and waits longer the more the username failed,
+// until ctx is done. Counting is best effort, a Redis error does not turn into another response.
+func (c *customerSvcImpl) loginFailed(ctx context.Context, userSubject, ipSubject string) {
+	failures := c.recordLoginFailure(ctx, userSubject, c.login.MaxUserFailures)
+	if ipSubject != "" {
+		c.recordLoginFailure(ctx, ipSubject, c.login.MaxIPFailures)
+	}
+
+	// a client that gave up is not waited for
+	select {
+	case <-time.After(c.loginDelay(failures)):
+	case <-ctx.Done():
+	}
+}
+
+func (c *customerSvcImpl) recordLoginFailure(ctx context.Context, subject string, maxFailures int) int {
+	failures, err := c.repos.LoginAttempt.RecordFailure(ctx, subject)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record login failure", "error", err)
+		return 0
+	}
+
+	if failures >= maxFailures {
+		if err := c.repos.LoginAttempt.Lock(ctx, subject, c.login.LockoutDuration); err != nil {
+			slog.ErrorContext(ctx, "failed to lock login", "error", err)
+			return failures
+		}
+
+		slog.WarnContext(ctx, "login locked out", "subject", subject, "failures", failures, "duration", c.login.LockoutDuration)
+	}
+
+	return failures
+}
+
+// loginDelay doubles from the base delay with every failure, up to the max delay.
+func (c *customerSvcImpl) loginDelay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	delay := c.login.BaseDelay
+	for i := 1; i < failures && delay < c.login.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, c.login.MaxDelay)
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh token,
+// the presented one can not be used again.
+func (c *customerSvcImpl) Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.AuthResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "Refresh")
+	defer span.End()
+
+	sessionID, _, found := strings.Cut(request.RefreshToken, ".")
+	if !found {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := c.repos.Session.Get(ctx, sessionID)
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrInvalidRefreshToken
+		}
+
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+
+	refreshTokenHash := utils.HashToken(request.RefreshToken)
+	if session.RefreshTokenHash != refreshTokenHash {
+		return nil, c.revokeReusedSession(ctx, session)
+	}
+
+	// the claims are read again so a revoked admin does not keep the role until the session ends
+	customer, err := c.repos.Customer.GetByUsername(ctx, session.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrInvalidRefreshToken
 		}
 
-		return nil, fmt.Errorf("error getting customer by username")
+		return nil, fmt.Errorf("error getting customer by username: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(response.Password), []byte(request.Password)); err != nil {
-		return nil, fmt.Errorf("wrong password")
+	refreshToken, err := c.renewSession(ctx, session, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	duration := constant.SessionExpire
-	expirationTime := time.Now().Add(duration)
-	claims := &model.AuthClaims{
-		UserID:   response.ID,
-		Username: response.Username,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
+	if request.Device != "" {
+		session.Device = request.Device
+	}
+	session.IPAddress = request.IPAddress
+
+	rotated, err := c.repos.Session.Rotate(ctx, session, refreshTokenHash)
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrInvalidRefreshToken
+		}
+
+		return nil, fmt.Errorf("error rotating session: %w", err)
+	}
+
+	// another request rotated the same token first
+	if !rotated {
+		return nil, c.revokeReusedSession(ctx, session)
+	}
+
+	return c.authResponse(ctx, customer, session.ID, refreshToken)
+}
+
+// revokeReusedSession ends a session whose rotated refresh token was presented
+// again. Either the legitimate client or an attacker holds a stolen copy and we
+// can not tell which, so both are logged out.
+func (c *customerSvcImpl) revokeReusedSession(ctx context.Context, session *model.SessionEntity) error {
+	slog.WarnContext(ctx, "refresh token reused, revoking session", "session_id", session.ID)
+	if err := c.repos.Session.Delete(ctx, session.CustomerID, session.ID); err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(constant.SecretKey))
+	return model.NewUnauthorizedError("refresh_token_reused", "refresh token already used, session revoked")
+}
+
+// renewSession gives the session a new refresh token, "<session_id>.<secret>",
+// and extends it by the session ttl. Only the hash of the token is stored.
+func (c *customerSvcImpl) renewSession(ctx context.Context, session *model.SessionEntity, now time.Time) (string, error) {
+	secret, err := utils.GenerateToken(refreshTokenSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create token")
+		return "", fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	refreshToken := session.ID + "." + secret
+	session.RefreshTokenHash = utils.HashToken(refreshToken)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(c.auth.SessionTTL)
+
+	return refreshToken, nil
+}
+
+func (c *customerSvcImpl) authResponse(ctx context.Context, customer *model.CustomerEntity, sessionID, refreshToken string) (*model.AuthResponse, error) {
+	claims := &model.AuthClaims{
+		UserID:    customer.ID,
+		Username:  customer.Username,
+		IsAdmin:   customer.IsAdmin,
+		SessionID: sessionID,
 	}
 
-	err = c.repos.RedCl.Set(context.Background(), constant.JWTPrefix+response.Username, tokenString, duration).Err()
+	tokenString, err := c.tokens.Issue(claims)
 	if err != nil {
-		log.Println("Failed to store token in Redis:", err.Error())
-		return nil, fmt.Errorf("failed to store token in Redis")
+		return nil, fmt.Errorf("failed to create token: %w", err)
 	}
 
 	return &model.AuthResponse{
-		Username: response.Username, 
-		Name: response.Name, 
-		Token: tokenString,
+		Username:     customer.Username,
+		Name:         customer.Name,
+		Token:        tokenString,
+		ExpiresIn:    int(c.auth.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (c *customerSvcImpl) Logout(username string) error {
-	if err := c.repos.RedCl.Del(context.Background(), constant.JWTPrefix+username).Err(); err != nil {
-		log.Println("Failed to delete token from Redis:", err.Error())
-		return fmt.Errorf("failed to delete token from Redis")
+// mergeGuestCart moves the guest cart items into the customer's active cart,
+// summing the quantity of products that are already in the cart.
+func (c *customerSvcImpl) mergeGuestCart(ctx context.Context, token string, customerID int) error {
+	items, err := c.repos.GuestCart.GetItems(ctx, token)
+	if err != nil {
+		return fmt.Errorf("error getting guest cart: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	activeCartStatus := int(cartEnum.CartStatusActive)
+	carts, err := c.repos.Cart.GetByParams(ctx, &model.GetCartRequest{
+		CustomerID: customerID,
+		Status:     &activeCartStatus,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting active cart: %w", err)
+	}
+
+	if len(carts) == 0 {
+		if _, err := c.repos.Cart.Create(ctx, &model.CartEntity{
+			CustomerID: customerID,
+			Status:     cartEnum.CartStatusActive,
+			Items:      items,
+		}); err != nil {
+			return fmt.Errorf("error creating cart: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			item.CartID = carts[0].ID
+		}
+
+		if _, err := c.repos.Cart.Upsert(ctx, carts[0].ID, items); err != nil {
+			return fmt.Errorf("error upserting cart: %w", err)
+		}
+	}
+
+	if err := c.repos.GuestCart.Delete(ctx, token); err != nil {
+		return fmt.Errorf("error deleting guest cart: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (c *customerSvcImpl) Logout(ctx context.Context, request *model.SessionRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "Logout")
+	defer span.End()
+
+	if err := c.repos.Session.Delete(ctx, request.CustomerID, request.SessionID); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+
+	return nil
+}
+
+func (c *customerSvcImpl) GetSessions(ctx context.Context, request *model.SessionRequest) ([]*model.SessionResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "GetSessions")
+	defer span.End()
+
+	sessions, err := c.repos.Session.GetByCustomerID(ctx, request.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions: %w", err)
+	}
+
+	responses := make([]*model.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = session.ToResponse(request.SessionID)
+	}
+
+	return responses, nil
+}
+
+// RevokeSession logs out one of the customer's devices, its access token stops
+// working right away and its refresh token can not be used anymore.
+func (c *customerSvcImpl) RevokeSession(ctx context.Context, request *model.SessionRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "RevokeSession")
+	defer span.End()
+
+	session, err := c.repos.Session.Get(ctx, request.SessionID)
+	if err != nil {
+		if err == redis.Nil {
+			return ErrSessionNotFound
+		}
+
+		return fmt.Errorf("error getting session: %w", err)
+	}
+
+	if session.CustomerID != request.CustomerID {
+		return ErrSessionNotFound
+	}
+
+	if err := c.repos.Session.Delete(ctx, session.CustomerID, session.ID); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+
+	return nil
+}
+
+func (c *customerSvcImpl) GetProfile(ctx context.Context, customerID int) (*model.CustomerResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "GetProfile")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return customer.ToResponse(), nil
+}
+
+// UpdateProfile marks a changed email as unverified and mails a verification link to it.
+func (c *customerSvcImpl) UpdateProfile(ctx context.Context, request *model.UpdateCustomerRequest) (*model.CustomerResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "UpdateProfile")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.repos.Customer.Update(ctx, &model.CustomerEntity{
+		ID:          request.CustomerID,
+		Name:        request.Name,
+		Email:       request.Email,
+		PhoneNumber: request.PhoneNumber,
+		Address:     request.Address,
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCustomerNotFound
+		}
+
+		if conflict := conflictError(err); conflict != nil {
+			return nil, conflict
+		}
+
+		return nil, fmt.Errorf("error updating customer: %w", err)
+	}
+
+	response, err := c.GetProfile(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Email != "" && !strings.EqualFold(request.Email, customer.Email) {
+		customer.Email = request.Email
+		if err := c.sendVerificationEmail(ctx, customer); err != nil {
+			slog.ErrorContext(ctx, "failed to send verification email", "error", err)
+		}
+	}
+
+	return response, nil
+}
+
+// ChangePassword logs the customer out of every device, including the one
+// changing the password, since a stolen password may be the reason for the change.
+func (c *customerSvcImpl) ChangePassword(ctx context.Context, request *model.ChangePasswordRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "ChangePassword")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.CurrentPassword)); err != nil {
+		return ErrWrongPassword
+	}
+
+	if err := c.checkPassword("new_password", request.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := c.repos.Customer.UpdatePassword(ctx, customer.ID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	if err := c.repos.Session.DeleteByCustomerID(ctx, customer.ID); err != nil {
+		return fmt.Errorf("error deleting sessions: %w", err)
+	}
+
+	return nil
+}
+
+// Delete anonymizes the account instead of deleting it so the transactions
+// keep pointing to a customer. The username is freed for a new registration.
+func (c *customerSvcImpl) Delete(ctx context.Context, request *model.DeleteCustomerRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "Delete")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.Password)); err != nil {
+		return ErrWrongPassword
+	}
+
+	// an empty password hash never matches, so nobody can log in to the account anymore
+	if err := c.repos.Customer.Anonymize(ctx, &model.CustomerEntity{
+		ID:       customer.ID,
+		Name:     deletedCustomerName,
+		Username: "deleted-" + utils.GenerateUUID(),
+	}); err != nil {
+		return fmt.Errorf("error deleting customer: %w", err)
+	}
+
+	if err := c.repos.Session.DeleteByCustomerID(ctx, customer.ID); err != nil {
+		return fmt.Errorf("error deleting sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (c *customerSvcImpl) SendVerificationEmail(ctx context.Context, customerID int) error {
+	ctx, span := tracing.StartService(ctx, "customer", "SendVerificationEmail")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	if customer.Email == "" {
+		return model.NewUnprocessableError("email_missing", "customer has no email")
+	}
+
+	if customer.EmailVerified {
+		return model.NewConflictError("email_already_verified", "email already verified")
+	}
+
+	return c.sendVerificationEmail(ctx, customer)
+}
+
+func (c *customerSvcImpl) VerifyEmail(ctx context.Context, request *model.VerifyEmailRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "VerifyEmail")
+	defer span.End()
+
+	customerToken, err := c.useToken(ctx, customerEnum.TokenTypeEmailVerification, request.Token)
+	if err != nil {
+		return err
+	}
+
+	if err := c.repos.Customer.VerifyEmail(ctx, customerToken.CustomerID, customerToken.Email); err != nil {
+		// the account is deleted or its email changed after the link was mailed
+		if err == sql.ErrNoRows {
+			return ErrInvalidToken
+		}
+
+		return fmt.Errorf("error verifying email: %w", err)
+	}
+
+	return nil
+}
+
+// ForgotPassword mails a reset link to every account of the email. The links
+// are issued and mailed in the background and it always succeeds, so neither
+// the answer nor its timing tells who is registered.
+func (c *customerSvcImpl) ForgotPassword(ctx context.Context, request *model.ForgotPasswordRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "ForgotPassword")
+	defer span.End()
+
+	// not cancelled with the request, which is answered right away
+	ctx = context.WithoutCancel(ctx)
+	c.resets.Add(1)
+	go func() {
+		defer c.resets.Done()
+		c.sendPasswordResetEmails(ctx, request.Email)
+	}()
+
+	return nil
+}
+
+// Wait waits for the password reset emails sent in the background, the app
+// calls it on shutdown once no request is served before closing the database.
+func (c *customerSvcImpl) Wait() {
+	c.resets.Wait()
+}
+
+// sendPasswordResetEmails logs the errors, nobody is waiting for them.
+func (c *customerSvcImpl) sendPasswordResetEmails(ctx context.Context, email string) {
+	customers, err := c.repos.Customer.GetByEmail(ctx, email)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get customers for password reset", "error", err)
+		return
+	}
+
+	for _, customer := range customers {
+		token, err := c.issueToken(ctx, customer, customerEnum.TokenTypePasswordReset, c.account.PasswordResetTTL)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to issue password reset token", "error", err)
+			continue
+		}
+
+		// one failed email should not stop the others
+		if err := c.mailer.Send(&mailer.Message{
+			To:      customer.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Hi %s,\n\nReset the password of your account %s by opening the link below, it expires in %s.\nIf you did not ask for it, ignore this email.\n\n%s/reset-password?token=%s\n",
+				customer.Name, customer.Username, c.account.PasswordResetTTL, strings.TrimSuffix(c.account.LinkURL, "/"), token),
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to send password reset email", "error", err)
+		}
+	}
+}
+
+// ResetPassword sets the new password and logs the customer out of every device.
+func (c *customerSvcImpl) ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "ResetPassword")
+	defer span.End()
+
+	// checked first so a refused password does not use up the token
+	if err := c.checkPassword("new_password", request.NewPassword); err != nil {
+		return err
+	}
+
+	customerToken, err := c.useToken(ctx, customerEnum.TokenTypePasswordReset, request.Token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := c.repos.Customer.UpdatePassword(ctx, customerToken.CustomerID, string(hashedPassword)); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCustomerNotFound
+		}
+
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	if err := c.repos.Session.DeleteByCustomerID(ctx, customerToken.CustomerID); err != nil {
+		return fmt.Errorf("error deleting sessions: %w", err)
+	}
+
+	return nil
+}
+
+// EnrollTwoFactor starts the enrollment with a new secret for the authenticator
+// app, two-factor authentication is only enabled once ConfirmTwoFactor gets a code of it.
+func (c *customerSvcImpl) EnrollTwoFactor(ctx context.Context, customerID int) (*model.TwoFactorEnrollmentResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "EnrollTwoFactor")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer.TwoFactorEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create two-factor secret: %w", err)
+	}
+
+	if err := c.repos.Customer.SetTOTPSecret(ctx, customer.ID, secret); err != nil {
+		// confirmed by another request in the meantime
+		if err == sql.ErrNoRows {
+			return nil, ErrTwoFactorEnabled
+		}
+
+		return nil, fmt.Errorf("error saving two-factor secret: %w", err)
+	}
+
+	return &model.TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(c.twoFactor.Issuer, customer.Username, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication when the code matches the
+// enrolled secret and returns the recovery codes, which are not shown again.
+func (c *customerSvcImpl) ConfirmTwoFactor(ctx context.Context, request *model.ConfirmTwoFactorRequest) (*model.RecoveryCodesResponse, error) {
+	ctx, span := tracing.StartService(ctx, "customer", "ConfirmTwoFactor")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer.TwoFactorEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	if customer.TOTPSecret == "" {
+		return nil, model.NewUnprocessableError("two_factor_not_started", "two-factor enrollment not started")
+	}
+
+	valid, err := c.useTOTPCode(ctx, customer, request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !valid {
+		return nil, utils.FieldErrors{"code": "is invalid or expired"}
+	}
+
+	codes := make([]string, c.twoFactor.RecoveryCodes)
+	hashes := make([]string, c.twoFactor.RecoveryCodes)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			return nil, fmt.Errorf("failed to create recovery codes: %w", err)
+		}
+		hashes[i] = utils.HashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	if err := c.repos.Customer.EnableTOTP(ctx, customer.ID, hashes); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTwoFactorEnabled
+		}
+
+		return nil, fmt.Errorf("error enabling two-factor authentication: %w", err)
+	}
+
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor asks for the password again, a stolen access token alone
+// must not be enough to remove the second factor.
+func (c *customerSvcImpl) DisableTwoFactor(ctx context.Context, request *model.DisableTwoFactorRequest) error {
+	ctx, span := tracing.StartService(ctx, "customer", "DisableTwoFactor")
+	defer span.End()
+
+	customer, err := c.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.Password)); err != nil {
+		return ErrWrongPassword
+	}
+
+	if customer.TOTPSecret == "" {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := c.repos.Customer.DisableTOTP(ctx, customer.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCustomerNotFound
+		}
+
+		return fmt.Errorf("error disabling two-factor authentication: %w", err)
+	}
+
+	return nil
+}
+
+// checkTwoFactorCode accepts a code of the authenticator app or an unused
+// recovery code, either one works once.
+func (c *customerSvcImpl) checkTwoFactorCode(ctx context.Context, customer *model.CustomerEntity, code string) (bool, error) {
+	valid, err := c.useTOTPCode(ctx, customer, code)
+	if err != nil || valid {
+		return valid, err
+	}
+
+	if err := c.repos.Customer.UseRecoveryCode(ctx, customer.ID, utils.HashToken(normalizeRecoveryCode(code))); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("error using recovery code: %w", err)
+	}
+
+	slog.InfoContext(ctx, "recovery code used", "customer_id", customer.ID)
+
+	return true, nil
+}
+
+// useTOTPCode refuses a code of a time step that was already used, so a code
+// seen over the customer's shoulder can not log in again.
+func (c *customerSvcImpl) useTOTPCode(ctx context.Context, customer *model.CustomerEntity, code string) (bool, error) {
+	step, valid := utils.ValidateTOTP(customer.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !valid {
+		return false, nil
+	}
+
+	if err := c.repos.Customer.UseTOTPStep(ctx, customer.ID, step); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("error using two-factor code: %w", err)
+	}
+
+	return true, nil
+}
+
+// generateRecoveryCode returns a code like "abcde-fghij".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, (recoveryCodeSize*5+7)/8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:recoveryCodeSize]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode lets the customer type a recovery code without the dash and in any case.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func (c *customerSvcImpl) sendVerificationEmail(ctx context.Context, customer *model.CustomerEntity) error {
+	token, err := c.issueToken(ctx, customer, customerEnum.TokenTypeEmailVerification, c.account.VerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := c.mailer.Send(&mailer.Message{
+		To:      customer.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below, it expires in %s.\n\n%s/verify-email?token=%s\n",
+			customer.Name, c.account.VerificationTTL, strings.TrimSuffix(c.account.LinkURL, "/"), token),
+	}); err != nil {
+		return fmt.Errorf("error sending verification email: %w", err)
+	}
+
+	return nil
+}
+
+// issueToken stores the hash of a new single use token with the email it is
+// mailed to, invalidating the previous unused tokens of the type, and returns
+// the token to mail.
+func (c *customerSvcImpl) issueToken(ctx context.Context, customer *model.CustomerEntity, tokenType customerEnum.TokenType, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateToken(accountTokenSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token: %w", err)
+	}
+
+	if err := c.repos.Customer.CreateToken(ctx, &model.CustomerTokenEntity{
+		CustomerID: customer.ID,
+		Type:       tokenType,
+		TokenHash:  utils.HashToken(token),
+		Email:      customer.Email,
+		ExpiresAt:  time.Now().Add(ttl),
+	}); err != nil {
+		return "", fmt.Errorf("error creating token: %w", err)
+	}
+
+	return token, nil
+}
+
+// useToken consumes the token and returns it, with the customer it was issued to.
+func (c *customerSvcImpl) useToken(ctx context.Context, tokenType customerEnum.TokenType, token string) (*model.CustomerTokenEntity, error) {
+	customerToken, err := c.repos.Customer.GetToken(ctx, tokenType, utils.HashToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidToken
+		}
+
+		return nil, fmt.Errorf("error getting token: %w", err)
+	}
+
+	if time.Now().After(customerToken.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	if err := c.repos.Customer.UseToken(ctx, customerToken.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidToken
+		}
+
+		return nil, fmt.Errorf("error using token: %w", err)
+	}
+
+	return customerToken, nil
+}
+
+// checkPassword returns the requirements of the password policy as the error
+// of the field when the password does not meet one of them.
+func (c *customerSvcImpl) checkPassword(field, password string) error {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if len(password) > config.MaxPasswordLength {
+		return utils.FieldErrors{field: fmt.Sprintf("must be at most %d bytes", config.MaxPasswordLength)}
+	}
+
+	if len([]rune(password)) >= c.password.MinLength &&
+		(hasUpper || !c.password.RequireUpper) &&
+		(hasLower || !c.password.RequireLower) &&
+		(hasDigit || !c.password.RequireDigit) &&
+		(hasSymbol || !c.password.RequireSymbol) {
+		return nil
+	}
+
+	requirements := make([]string, 0, 4)
+	if c.password.RequireUpper {
+		requirements = append(requirements, "an uppercase letter")
+	}
+	if c.password.RequireLower {
+		requirements = append(requirements, "a lowercase letter")
+	}
+	if c.password.RequireDigit {
+		requirements = append(requirements, "a digit")
+	}
+	if c.password.RequireSymbol {
+		requirements = append(requirements, "a symbol")
+	}
+
+	message := fmt.Sprintf("must be at least %d characters", c.password.MinLength)
+	switch len(requirements) {
+	case 0:
+	case 1:
+		message += " and contain " + requirements[0]
+	default:
+		message += " and contain " + strings.Join(requirements[:len(requirements)-1], ", ") + " and " + requirements[len(requirements)-1]
+	}
+
+	return utils.FieldErrors{field: message}
+}
+
+// conflictError returns the field a customer write collided with, or nil
+// when the error is not about a taken username or email.
+func conflictError(err error) error {
+	switch err {
+	case customerRepo.ErrUsernameTaken:
+		return model.NewConflictError("username_taken", "username is already taken").WithFields(utils.FieldErrors{"username": "is already taken"})
+	case customerRepo.ErrEmailTaken:
+		return model.NewConflictError("email_taken", "email is already registered").WithFields(utils.FieldErrors{"email": "is already registered"})
+	default:
+		return nil
+	}
+}
+
+func (c *customerSvcImpl) getCustomer(ctx context.Context, customerID int) (*model.CustomerEntity, error) {
+	customer, err := c.repos.Customer.GetByID(ctx, customerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCustomerNotFound
+		}
+
+		return nil, fmt.Errorf("error getting customer: %w", err)
+	}
+
+	return customer, nil
+}
@@ -4,43 +4,71 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
-	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
-	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/config"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	customerEnum "github.com/zakiyalmaya/online-store/constant/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/mailer"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	customerRepo "github.com/zakiyalmaya/online-store/infrastructure/repository/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
+	mockMailer "github.com/zakiyalmaya/online-store/mocks/infrastructure/mailer"
+	mockCartRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/cart"
 	mockCustomerRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/customer"
+	mockGuestCartRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/guestcart"
+	mockLoginAttemptRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/loginattempt"
+	mockLoginChallengeRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/loginchallenge"
+	mockSessionRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/session"
 	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	mockCustomerRepository *mockCustomerRepo.MockRepository
-	mockRedisClient        *redis.Client
-	customerSvc            Service
+	mockCustomerRepository       *mockCustomerRepo.MockRepository
+	mockCartRepository           *mockCartRepo.MockRepository
+	mockGuestCartRepository      *mockGuestCartRepo.MockRepository
+	mockSessionRepository        *mockSessionRepo.MockRepository
+	mockLoginAttemptRepository   *mockLoginAttemptRepo.MockRepository
+	mockLoginChallengeRepository *mockLoginChallengeRepo.MockRepository
+	mockMailerClient             *mockMailer.MockMailer
+	customerSvc                  Service
 )
 
 func Setup(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 
-	mockRedisServer, err := miniredis.Run()
+	mockCustomerRepository = mockCustomerRepo.NewMockRepository(mockCtl)
+	mockCartRepository = mockCartRepo.NewMockRepository(mockCtl)
+	mockGuestCartRepository = mockGuestCartRepo.NewMockRepository(mockCtl)
+	mockSessionRepository = mockSessionRepo.NewMockRepository(mockCtl)
+	mockLoginAttemptRepository = mockLoginAttemptRepo.NewMockRepository(mockCtl)
+	mockLoginChallengeRepository = mockLoginChallengeRepo.NewMockRepository(mockCtl)
+	mockMailerClient = mockMailer.NewMockMailer(mockCtl)
+	// no delay after a failed login in tests
+	cfg := config.Default()
+	cfg.Login.BaseDelay = 0
+
+	tokens, err := token.NewJWTManager(cfg.Auth)
 	if err != nil {
 		t.Fatalf(err.Error())
 	}
 
-	mockRedisClient = redis.NewClient(&redis.Options{
-		Addr: mockRedisServer.Addr(),
-	})
-
-	mockCustomerRepository = mockCustomerRepo.NewMockRepository(mockCtl)
 	customerSvc = NewCustomerService(&repository.Repositories{
-		Customer: mockCustomerRepository,
-		RedCl:    mockRedisClient,
-	})
+		Customer:       mockCustomerRepository,
+		Cart:           mockCartRepository,
+		GuestCart:      mockGuestCartRepository,
+		Session:        mockSessionRepository,
+		LoginAttempt:   mockLoginAttemptRepository,
+		LoginChallenge: mockLoginChallengeRepository,
+	}, tokens, mockMailerClient, cfg)
 }
 
 func TestRegister(t *testing.T) {
@@ -49,7 +77,7 @@ func TestRegister(t *testing.T) {
 	request := &model.CustomerRequest{
 		Name:     "name",
 		Username: "username",
-		Password: "password",
+		Password: "Password-1",
 	}
 
 	mockHashedPass := []byte("password")
@@ -63,12 +91,13 @@ func TestRegister(t *testing.T) {
 		request *model.CustomerRequest
 		mock    func()
 		wantErr bool
+		check   func(err error) bool
 	}{
 		{
 			name:    "Given valid request when register then return success",
 			request: request,
 			mock: func() {
-				mockCustomerRepository.EXPECT().Create(&model.CustomerEntity{
+				mockCustomerRepository.EXPECT().Create(gomock.Any(), &model.CustomerEntity{
 					Name:     request.Name,
 					Username: request.Username,
 					Password: string(mockHashedPass),
@@ -76,11 +105,44 @@ func TestRegister(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "Given email when register then mail a verification link",
+			request: &model.CustomerRequest{Name: "name", Username: "username", Password: "Password-1", Email: "name@example.com"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, customer *model.CustomerEntity) error {
+					customer.ID = 1
+					return nil
+				}).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token *model.CustomerTokenEntity) error {
+					if token.CustomerID != 1 || token.Type != customerEnum.TokenTypeEmailVerification || token.TokenHash == "" {
+						t.Errorf("Register() token = %+v", token)
+					}
+					return nil
+				}).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).DoAndReturn(func(message *mailer.Message) error {
+					if message.To != "name@example.com" {
+						t.Errorf("Register() mailed %s", message.To)
+					}
+					return nil
+				}).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given mail error when register then still return success",
+			request: &model.CustomerRequest{Name: "name", Username: "username", Password: "Password-1", Email: "name@example.com"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: false,
+		},
 		{
 			name:    "Given error create customer to db when register then return error",
 			request: request,
 			mock: func() {
-				mockCustomerRepository.EXPECT().Create(&model.CustomerEntity{
+				mockCustomerRepository.EXPECT().Create(gomock.Any(), &model.CustomerEntity{
 					Name:     request.Name,
 					Username: request.Username,
 					Password: string(mockHashedPass),
@@ -88,14 +150,91 @@ func TestRegister(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:    "Given weak password when register then return field error",
+			request: &model.CustomerRequest{Name: "name", Username: "username", Password: "password"},
+			mock:    func() {},
+			wantErr: true,
+			check: func(err error) bool {
+				fields, ok := err.(utils.FieldErrors)
+				return ok && fields["password"] != ""
+			},
+		},
+		{
+			name:    "Given taken username when register then return conflict",
+			request: request,
+			mock: func() {
+				mockCustomerRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(customerRepo.ErrUsernameTaken).Times(1)
+			},
+			wantErr: true,
+			check: func(err error) bool {
+				conflict, ok := err.(*model.Error)
+				return ok && conflict.Kind == model.ErrorKindConflict && conflict.Fields["username"] != ""
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			err := customerSvc.Register(request)
+			err := customerSvc.Register(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Register() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if tc.check != nil && !tc.check(err) {
+				t.Errorf("Register() error = %#v", err)
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   config.PasswordConfig
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "Given password meeting the default policy when check password then return success",
+			policy:   config.Default().Password,
+			password: "Password-1",
+			wantErr:  false,
+		},
+		{
+			name:     "Given password without digit when check password then return error",
+			policy:   config.Default().Password,
+			password: "Password-one",
+			wantErr:  true,
+		},
+		{
+			name:     "Given short password when check password then return error",
+			policy:   config.Default().Password,
+			password: "Pass-1",
+			wantErr:  true,
+		},
+		{
+			name:     "Given password without symbol when symbol is required then return error",
+			policy:   config.PasswordConfig{MinLength: 8, RequireSymbol: true},
+			password: "password1",
+			wantErr:  true,
+		},
+		{
+			name:     "Given password longer than bcrypt hashes when check password then return error",
+			policy:   config.Default().Password,
+			password: "Password-1" + strings.Repeat("x", config.MaxPasswordLength),
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &customerSvcImpl{password: tc.policy}
+			err := svc.checkPassword("password", tc.password)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("checkPassword() error = %v, wantErr %v", err, tc.wantErr)
 			}
 		})
 	}
@@ -125,11 +264,119 @@ func TestLogin(t *testing.T) {
 			name:    "Given success when login then return success",
 			request: request,
 			mock: func() {
-				mockCustomerRepository.EXPECT().GetByUsername(request.Username).Return(&model.CustomerEntity{
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:username").Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(&model.CustomerEntity{
+					Password: string(mockHashedPass),
+				}, nil).Times(1)
+
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given error creating session when login then return error",
+			request: request,
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:username").Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(&model.CustomerEntity{
 					Password: string(mockHashedPass),
 				}, nil).Times(1)
 
-				mockRedisClient.Set(context.Background(), constant.JWTPrefix+request.Username, gomock.Any(), constant.SessionExpire).Err()
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given cart token and active cart when login then merge guest cart into active cart",
+			request: &model.AuthRequest{
+				Username:  request.Username,
+				Password:  request.Password,
+				CartToken: "token",
+			},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:username").Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(&model.CustomerEntity{
+					ID:       1,
+					Password: string(mockHashedPass),
+				}, nil).Times(1)
+
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+				mockGuestCartRepository.EXPECT().GetItems(gomock.Any(), "token").Return([]*model.CartItemEntity{
+					{ProductID: 1, Quantity: 2},
+				}, nil).Times(1)
+
+				activeCartStatus := int(cartEnum.CartStatusActive)
+				mockCartRepository.EXPECT().GetByParams(gomock.Any(), &model.GetCartRequest{
+					CustomerID: 1,
+					Status:     &activeCartStatus,
+				}).Return([]*model.CartEntity{{ID: 10, CustomerID: 1}}, nil).Times(1)
+
+				mockCartRepository.EXPECT().Upsert(gomock.Any(), 10, []*model.CartItemEntity{
+					{CartID: 10, ProductID: 1, Quantity: 2},
+				}).Return(&model.CartEntity{ID: 10}, nil).Times(1)
+
+				mockGuestCartRepository.EXPECT().Delete(gomock.Any(), "token").Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given cart token without active cart when login then create cart from guest cart",
+			request: &model.AuthRequest{
+				Username:  request.Username,
+				Password:  request.Password,
+				CartToken: "token",
+			},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:username").Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(&model.CustomerEntity{
+					ID:       1,
+					Password: string(mockHashedPass),
+				}, nil).Times(1)
+
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+				items := []*model.CartItemEntity{{ProductID: 1, Quantity: 2}}
+				mockGuestCartRepository.EXPECT().GetItems(gomock.Any(), "token").Return(items, nil).Times(1)
+
+				activeCartStatus := int(cartEnum.CartStatusActive)
+				mockCartRepository.EXPECT().GetByParams(gomock.Any(), &model.GetCartRequest{
+					CustomerID: 1,
+					Status:     &activeCartStatus,
+				}).Return(nil, nil).Times(1)
+
+				mockCartRepository.EXPECT().Create(gomock.Any(), &model.CartEntity{
+					CustomerID: 1,
+					Status:     cartEnum.CartStatusActive,
+					Items:      items,
+				}).Return(&model.CartEntity{ID: 10}, nil).Times(1)
+
+				mockGuestCartRepository.EXPECT().Delete(gomock.Any(), "token").Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error merging guest cart when login then still return success",
+			request: &model.AuthRequest{
+				Username:  request.Username,
+				Password:  request.Password,
+				CartToken: "token",
+			},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:username").Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(&model.CustomerEntity{
+					ID:       1,
+					Password: string(mockHashedPass),
+				}, nil).Times(1)
+
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+				mockGuestCartRepository.EXPECT().GetItems(gomock.Any(), "token").Return(nil, errors.New("error")).Times(1)
 			},
 			wantErr: false,
 		},
@@ -137,7 +384,8 @@ func TestLogin(t *testing.T) {
 			name:    "Given error when login then return error",
 			request: request,
 			mock: func() {
-				mockCustomerRepository.EXPECT().GetByUsername(request.Username).Return(nil, errors.New("error")).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(nil, errors.New("error")).Times(1)
 			},
 			wantErr: true,
 		},
@@ -145,7 +393,9 @@ func TestLogin(t *testing.T) {
 			name:    "Given error user not found request when login then return error",
 			request: request,
 			mock: func() {
-				mockCustomerRepository.EXPECT().GetByUsername(request.Username).Return(nil, sql.ErrNoRows).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), request.Username).Return(nil, sql.ErrNoRows).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:username").Return(1, nil).Times(1)
 			},
 			wantErr: true,
 		},
@@ -154,7 +404,7 @@ func TestLogin(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			_, err := customerSvc.Login(request)
+			_, err := customerSvc.Login(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Login() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -162,31 +412,1277 @@ func TestLogin(t *testing.T) {
 	}
 }
 
-func TestLogout(t *testing.T) {
+func TestLoginSession(t *testing.T) {
+	Setup(t)
+
+	patched := gomonkey.ApplyFunc(bcrypt.CompareHashAndPassword, func([]byte, []byte) error {
+		return nil
+	})
+	defer patched.Reset()
+
+	mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+	mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:username").Return(nil).Times(1)
+	mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "username").Return(&model.CustomerEntity{
+		ID:       1,
+		Username: "username",
+	}, nil).Times(1)
+
+	var session *model.SessionEntity
+	mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, s *model.SessionEntity) error {
+		session = s
+		return nil
+	}).Times(1)
+
+	response, err := customerSvc.Login(context.Background(), &model.AuthRequest{
+		Username: "username",
+		Password: "password",
+		Device:   "phone",
+	})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	if session.CustomerID != 1 || session.Device != "phone" {
+		t.Errorf("Login() session = %+v, want customer 1 on phone", session)
+	}
+
+	if session.RefreshTokenHash != utils.HashToken(response.RefreshToken) {
+		t.Errorf("Login() session does not hold the hash of the refresh token")
+	}
+
+	if response.ExpiresIn != int(config.Default().Auth.AccessTokenTTL.Seconds()) {
+		t.Errorf("Login() expires in = %v, want %v", response.ExpiresIn, config.Default().Auth.AccessTokenTTL.Seconds())
+	}
+}
+
+func TestLoginChallenge(t *testing.T) {
+	Setup(t)
+
+	patched := gomonkey.ApplyFunc(bcrypt.CompareHashAndPassword, func([]byte, []byte) error {
+		return nil
+	})
+	defer patched.Reset()
+
+	// the failures are only reset once the code is right too
+	mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:username").Return(time.Duration(0), nil).Times(1)
+	mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "username").Return(&model.CustomerEntity{
+		ID:               1,
+		Username:         "username",
+		TwoFactorEnabled: true,
+	}, nil).Times(1)
+
+	var challenge *model.LoginChallengeEntity
+	mockLoginChallengeRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *model.LoginChallengeEntity) error {
+		challenge = c
+		return nil
+	}).Times(1)
+
+	response, err := customerSvc.Login(context.Background(), &model.AuthRequest{
+		Username:  "username",
+		Password:  "password",
+		CartToken: "cart-token",
+	})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	if !response.TwoFactorRequired || response.Token != "" || response.RefreshToken != "" {
+		t.Errorf("Login() = %+v, want only a challenge", response)
+	}
+
+	if challenge.ID != utils.HashToken(response.ChallengeToken) || challenge.CustomerID != 1 || challenge.CartToken != "cart-token" {
+		t.Errorf("Login() challenge = %+v", challenge)
+	}
+}
+
+func TestLoginTwoFactor(t *testing.T) {
 	Setup(t)
 
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	code, err := utils.GenerateTOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	challengeID := utils.HashToken("challenge-token")
+	challenge := &model.LoginChallengeEntity{ID: challengeID, CustomerID: 1, Username: "john"}
+	customer := &model.CustomerEntity{ID: 1, Username: "john", TOTPSecret: secret, TwoFactorEnabled: true}
+
 	testCases := []struct {
-		name     string
-		username string
-		mock     func()
-		wantErr  bool
+		name    string
+		request *model.TwoFactorLoginRequest
+		mock    func()
+		wantErr error
 	}{
 		{
-			name:     "Given success when logout then return success",
-			username: "username",
-			mock:     func() {
-				mockRedisClient.Del(context.Background(), constant.JWTPrefix+"username").Err()
+			name:    "Given valid code when login two factor then start a session",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: code, IPAddress: "10.0.0.1"},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(challenge, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "ip:10.0.0.1").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseTOTPStep(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+				mockLoginChallengeRepository.EXPECT().Delete(gomock.Any(), challengeID).Return(true, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:john").Return(nil).Times(1)
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
-			wantErr:  false,
+			wantErr: nil,
+		},
+		{
+			name:    "Given recovery code when login two factor then start a session",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: "ABCDE-FGHIJ"},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(challenge, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseRecoveryCode(gomock.Any(), 1, utils.HashToken("abcdefghij")).Return(nil).Times(1)
+				mockLoginChallengeRepository.EXPECT().Delete(gomock.Any(), challengeID).Return(true, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Reset(gomock.Any(), "user:john").Return(nil).Times(1)
+				mockSessionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "Given reused code when login two factor then count the failure",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: code},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(challenge, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseTOTPStep(gomock.Any(), 1, gomock.Any()).Return(sql.ErrNoRows).Times(1)
+				mockCustomerRepository.EXPECT().UseRecoveryCode(gomock.Any(), 1, gomock.Any()).Return(sql.ErrNoRows).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:john").Return(1, nil).Times(1)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name:    "Given locked username when login two factor then return too many attempts",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: code},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(challenge, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Minute, nil).Times(1)
+			},
+			wantErr: &model.TooManyAttemptsError{RetryAfter: time.Minute},
+		},
+		{
+			name:    "Given expired challenge when login two factor then return invalid challenge",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: code},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(nil, redis.Nil).Times(1)
+			},
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			name:    "Given challenge answered concurrently when login two factor then return invalid challenge",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: "abcde-fghij"},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(challenge, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseRecoveryCode(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+				mockLoginChallengeRepository.EXPECT().Delete(gomock.Any(), challengeID).Return(false, nil).Times(1)
+			},
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			name:    "Given two factor disabled since the password when login two factor then return invalid challenge",
+			request: &model.TwoFactorLoginRequest{ChallengeToken: "challenge-token", Code: code},
+			mock: func() {
+				mockLoginChallengeRepository.EXPECT().Get(gomock.Any(), challengeID).Return(challenge, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Username: "john"}, nil).Times(1)
+			},
+			wantErr: ErrInvalidChallenge,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			err := customerSvc.Logout(tc.username)
-			if (err != nil) != tc.wantErr {
-				t.Errorf("Logout() error = %v, wantErr %v", err, tc.wantErr)
+			response, err := customerSvc.LoginTwoFactor(context.Background(), tc.request)
+			if tc.wantErr == nil {
+				if err != nil || response.Token == "" || response.RefreshToken == "" {
+					t.Errorf("LoginTwoFactor() = %+v, %v, want the tokens", response, err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.wantErr.Error() {
+				t.Errorf("LoginTwoFactor() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	Setup(t)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("Password-1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	customer := &model.CustomerEntity{ID: 1, Username: "john", Password: string(hashedPassword)}
+	lockout := config.Default().Login.LockoutDuration
+
+	testCases := []struct {
+		name    string
+		request *model.AuthRequest
+		mock    func()
+		check   func(err error) bool
+	}{
+		{
+			name:    "Given locked username when login then return too many attempts without checking the password",
+			request: &model.AuthRequest{Username: "John", Password: "Password-1", IPAddress: "10.0.0.1"},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Minute, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "ip:10.0.0.1").Return(time.Duration(0), nil).Times(1)
+			},
+			check: func(err error) bool {
+				tooManyAttempts, ok := err.(*model.TooManyAttemptsError)
+				return ok && tooManyAttempts.RetryAfterSeconds() == 60
+			},
+		},
+		{
+			name:    "Given wrong password when login then count the failure for the username and the ip",
+			request: &model.AuthRequest{Username: "john", Password: "wrong", IPAddress: "10.0.0.1"},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "ip:10.0.0.1").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "john").Return(customer, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:john").Return(2, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "ip:10.0.0.1").Return(7, nil).Times(1)
+			},
+			check: func(err error) bool { return err == ErrInvalidCredentials },
+		},
+		{
+			name:    "Given last allowed failure when login then lock the username out",
+			request: &model.AuthRequest{Username: "john", Password: "wrong", IPAddress: "10.0.0.1"},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "ip:10.0.0.1").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "john").Return(customer, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:john").Return(5, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().Lock(gomock.Any(), "user:john", lockout).Return(nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "ip:10.0.0.1").Return(8, nil).Times(1)
+			},
+			check: func(err error) bool { return err == ErrInvalidCredentials },
+		},
+		{
+			name:    "Given unknown username when login then return the same error as a wrong password",
+			request: &model.AuthRequest{Username: "nobody", Password: "Password-1"},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:nobody").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "nobody").Return(nil, sql.ErrNoRows).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:nobody").Return(1, nil).Times(1)
+			},
+			check: func(err error) bool { return err == ErrInvalidCredentials },
+		},
+		{
+			name:    "Given error counting the failure when login then still return invalid credentials",
+			request: &model.AuthRequest{Username: "john", Password: "wrong"},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "john").Return(customer, nil).Times(1)
+				mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:john").Return(0, errors.New("error")).Times(1)
+			},
+			check: func(err error) bool { return err == ErrInvalidCredentials },
+		},
+		{
+			name:    "Given error checking the lock when login then return error",
+			request: &model.AuthRequest{Username: "john", Password: "Password-1"},
+			mock: func() {
+				mockLoginAttemptRepository.EXPECT().LockedFor(gomock.Any(), "user:john").Return(time.Duration(0), errors.New("error")).Times(1)
+			},
+			check: func(err error) bool { return err != nil && err != ErrInvalidCredentials },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := customerSvc.Login(context.Background(), tc.request)
+			if !tc.check(err) {
+				t.Errorf("Login() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLoginDelay(t *testing.T) {
+	svc := &customerSvcImpl{login: config.LoginConfig{BaseDelay: 250 * time.Millisecond, MaxDelay: 4 * time.Second}}
+
+	testCases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 1, want: 250 * time.Millisecond},
+		{failures: 3, want: time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 40, want: 4 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		if got := svc.loginDelay(tc.failures); got != tc.want {
+			t.Errorf("loginDelay(%d) = %v, want %v", tc.failures, got, tc.want)
+		}
+	}
+}
+
+func TestLoginFailedCancelled(t *testing.T) {
+	Setup(t)
+
+	svc := customerSvc.(*customerSvcImpl)
+	svc.login = config.LoginConfig{BaseDelay: time.Minute, MaxDelay: time.Minute, MaxUserFailures: 5}
+	mockLoginAttemptRepository.EXPECT().RecordFailure(gomock.Any(), "user:name").Return(1, nil).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	svc.loginFailed(ctx, "user:name", "")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("loginFailed() waited %v, want to stop once ctx is done", elapsed)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	Setup(t)
+
+	refreshToken := "session.secret"
+	newSession := func() *model.SessionEntity {
+		return &model.SessionEntity{
+			ID:               "session",
+			CustomerID:       1,
+			Username:         "username",
+			Device:           "laptop",
+			RefreshTokenHash: utils.HashToken(refreshToken),
+			ExpiresAt:        time.Now().Add(time.Hour),
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		refreshToken string
+		mock         func()
+		wantErr      bool
+	}{
+		{
+			name:         "Given current refresh token when refresh then rotate the refresh token",
+			refreshToken: refreshToken,
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "session").Return(newSession(), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "username").Return(&model.CustomerEntity{ID: 1, Username: "username"}, nil).Times(1)
+				mockSessionRepository.EXPECT().Rotate(gomock.Any(), gomock.Any(), utils.HashToken(refreshToken)).DoAndReturn(func(_ context.Context, s *model.SessionEntity, _ string) (bool, error) {
+					if s.RefreshTokenHash == utils.HashToken(refreshToken) {
+						t.Errorf("Refresh() refresh token was not rotated")
+					}
+					return true, nil
+				}).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:         "Given malformed refresh token when refresh then return error",
+			refreshToken: "malformed",
+			mock:         func() {},
+			wantErr:      true,
+		},
+		{
+			name:         "Given expired session when refresh then return error",
+			refreshToken: refreshToken,
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "session").Return(nil, redis.Nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Given already rotated refresh token when refresh then revoke the session",
+			refreshToken: "session.rotated",
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "session").Return(newSession(), nil).Times(1)
+				mockSessionRepository.EXPECT().Delete(gomock.Any(), 1, "session").Return(nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Given concurrent refresh with the same token when refresh then revoke the session",
+			refreshToken: refreshToken,
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "session").Return(newSession(), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "username").Return(&model.CustomerEntity{ID: 1, Username: "username"}, nil).Times(1)
+				mockSessionRepository.EXPECT().Rotate(gomock.Any(), gomock.Any(), utils.HashToken(refreshToken)).Return(false, nil).Times(1)
+				mockSessionRepository.EXPECT().Delete(gomock.Any(), 1, "session").Return(nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Given deleted customer when refresh then return error",
+			refreshToken: refreshToken,
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "session").Return(newSession(), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "username").Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Given error rotating session when refresh then return error",
+			refreshToken: refreshToken,
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "session").Return(newSession(), nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByUsername(gomock.Any(), "username").Return(&model.CustomerEntity{ID: 1, Username: "username"}, nil).Times(1)
+				mockSessionRepository.EXPECT().Rotate(gomock.Any(), gomock.Any(), utils.HashToken(refreshToken)).Return(false, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			response, err := customerSvc.Refresh(context.Background(), &model.RefreshTokenRequest{RefreshToken: tc.refreshToken})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Refresh() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && (response.Token == "" || response.RefreshToken == tc.refreshToken) {
+				t.Errorf("Refresh() response = %+v, want new tokens", response)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	Setup(t)
+
+	request := &model.SessionRequest{CustomerID: 1, SessionID: "session"}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given success when logout then return success",
+			mock: func() {
+				mockSessionRepository.EXPECT().Delete(gomock.Any(), 1, "session").Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error deleting session when logout then return error",
+			mock: func() {
+				mockSessionRepository.EXPECT().Delete(gomock.Any(), 1, "session").Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.Logout(context.Background(), request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Logout() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetSessions(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		want    []bool
+		wantErr bool
+	}{
+		{
+			name: "Given sessions when get sessions then mark the current one",
+			mock: func() {
+				mockSessionRepository.EXPECT().GetByCustomerID(gomock.Any(), 1).Return([]*model.SessionEntity{
+					{ID: "phone"},
+					{ID: "laptop"},
+				}, nil).Times(1)
+			},
+			want:    []bool{false, true},
+			wantErr: false,
+		},
+		{
+			name: "Given error when get sessions then return error",
+			mock: func() {
+				mockSessionRepository.EXPECT().GetByCustomerID(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			sessions, err := customerSvc.GetSessions(context.Background(), &model.SessionRequest{CustomerID: 1, SessionID: "laptop"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetSessions() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			for i, session := range sessions {
+				if session.Current != tc.want[i] {
+					t.Errorf("GetSessions() session %s current = %v, want %v", session.ID, session.Current, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		request *model.SessionRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given own session when revoke session then delete the session",
+			request: &model.SessionRequest{CustomerID: 1, SessionID: "phone"},
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "phone").Return(&model.SessionEntity{ID: "phone", CustomerID: 1}, nil).Times(1)
+				mockSessionRepository.EXPECT().Delete(gomock.Any(), 1, "phone").Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given session of another customer when revoke session then return error",
+			request: &model.SessionRequest{CustomerID: 2, SessionID: "phone"},
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "phone").Return(&model.SessionEntity{ID: "phone", CustomerID: 1}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown session when revoke session then return error",
+			request: &model.SessionRequest{CustomerID: 1, SessionID: "unknown"},
+			mock: func() {
+				mockSessionRepository.EXPECT().Get(gomock.Any(), "unknown").Return(nil, redis.Nil).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.RevokeSession(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("RevokeSession() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given existing customer when get profile then return profile",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Username: "username", Password: "hashed"}, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given deleted customer when get profile then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			response, err := customerSvc.GetProfile(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetProfile() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && response.Username != "username" {
+				t.Errorf("GetProfile() username = %v, want username", response.Username)
+			}
+		})
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	Setup(t)
+
+	request := &model.UpdateCustomerRequest{
+		CustomerID:  1,
+		Name:        "name",
+		Email:       "name@example.com",
+		PhoneNumber: "08123456789",
+		Address:     "address",
+	}
+	entity := &model.CustomerEntity{
+		ID:          1,
+		Name:        "name",
+		Email:       "name@example.com",
+		PhoneNumber: "08123456789",
+		Address:     "address",
+	}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given same email when update profile then return updated profile",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Email: "name@example.com", EmailVerified: true}, nil).Times(1)
+				mockCustomerRepository.EXPECT().Update(gomock.Any(), entity).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(entity, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given new email when update profile then mail a verification link",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Email: "old@example.com", EmailVerified: true}, nil).Times(1)
+				mockCustomerRepository.EXPECT().Update(gomock.Any(), entity).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(entity, nil).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).DoAndReturn(func(message *mailer.Message) error {
+					if message.To != "name@example.com" {
+						t.Errorf("UpdateProfile() mailed %s", message.To)
+					}
+					return nil
+				}).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given email of another account when update profile then return conflict",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Email: "old@example.com"}, nil).Times(1)
+				mockCustomerRepository.EXPECT().Update(gomock.Any(), entity).Return(customerRepo.ErrEmailTaken).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given deleted customer when update profile then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := customerSvc.UpdateProfile(context.Background(), request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateProfile() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	Setup(t)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("current-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	customer := &model.CustomerEntity{ID: 1, Password: string(hashedPassword)}
+
+	testCases := []struct {
+		name    string
+		request *model.ChangePasswordRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given current password when change password then update password and revoke every session",
+			request: &model.ChangePasswordRequest{CustomerID: 1, CurrentPassword: "current-password", NewPassword: "New-password1"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, password string) error {
+					if bcrypt.CompareHashAndPassword([]byte(password), []byte("New-password1")) != nil {
+						t.Errorf("ChangePassword() stored password is not the hash of the new password")
+					}
+					return nil
+				}).Times(1)
+				mockSessionRepository.EXPECT().DeleteByCustomerID(gomock.Any(), 1).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given wrong current password when change password then return error",
+			request: &model.ChangePasswordRequest{CustomerID: 1, CurrentPassword: "wrong-password", NewPassword: "New-password1"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error revoking sessions when change password then return error",
+			request: &model.ChangePasswordRequest{CustomerID: 1, CurrentPassword: "current-password", NewPassword: "New-password1"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+				mockSessionRepository.EXPECT().DeleteByCustomerID(gomock.Any(), 1).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.ChangePassword(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ChangePassword() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	Setup(t)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	customer := &model.CustomerEntity{ID: 1, Name: "name", Username: "username", Password: string(hashedPassword)}
+
+	testCases := []struct {
+		name    string
+		request *model.DeleteCustomerRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given password when delete then anonymize the customer and revoke every session",
+			request: &model.DeleteCustomerRequest{CustomerID: 1, Password: "password"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().Anonymize(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, anonymized *model.CustomerEntity) error {
+					if anonymized.ID != 1 || anonymized.Name != deletedCustomerName || anonymized.Username == "username" || anonymized.Email != "" || anonymized.Password != "" {
+						t.Errorf("Delete() anonymized customer = %+v", anonymized)
+					}
+					return nil
+				}).Times(1)
+				mockSessionRepository.EXPECT().DeleteByCustomerID(gomock.Any(), 1).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given wrong password when delete then return error",
+			request: &model.DeleteCustomerRequest{CustomerID: 1, Password: "wrong-password"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error anonymizing when delete then return error",
+			request: &model.DeleteCustomerRequest{CustomerID: 1, Password: "password"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().Anonymize(gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.Delete(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Delete() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendVerificationEmail(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given unverified email when send verification email then mail a link",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Email: "name@example.com"}, nil).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given verified email when send verification email then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Email: "name@example.com", EmailVerified: true}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given mail error when send verification email then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Email: "name@example.com"}, nil).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.SendVerificationEmail(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("SendVerificationEmail() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	Setup(t)
+
+	tokenHash := utils.HashToken("token")
+	validToken := &model.CustomerTokenEntity{ID: 1, CustomerID: 2, Email: "name@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+	expiredToken := &model.CustomerTokenEntity{ID: 1, CustomerID: 2, Email: "name@example.com", ExpiresAt: time.Now().Add(-time.Minute)}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid token when verify email then mark the email verified",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypeEmailVerification, tokenHash).Return(validToken, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseToken(gomock.Any(), 1).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().VerifyEmail(gomock.Any(), 2, "name@example.com").Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given email changed since the link was mailed when verify email then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypeEmailVerification, tokenHash).Return(validToken, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseToken(gomock.Any(), 1).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().VerifyEmail(gomock.Any(), 2, "name@example.com").Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given unknown or used token when verify email then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypeEmailVerification, tokenHash).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given expired token when verify email then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypeEmailVerification, tokenHash).Return(expiredToken, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given token used concurrently when verify email then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypeEmailVerification, tokenHash).Return(validToken, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseToken(gomock.Any(), 1).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.VerifyEmail(context.Background(), &model.VerifyEmailRequest{Token: "token"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("VerifyEmail() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given registered email when forgot password then mail a reset link",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByEmail(gomock.Any(), "name@example.com").Return([]*model.CustomerEntity{{ID: 1, Email: "name@example.com"}}, nil).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token *model.CustomerTokenEntity) error {
+					if token.Type != customerEnum.TokenTypePasswordReset || token.Email != "name@example.com" {
+						t.Errorf("ForgotPassword() token = %+v", token)
+					}
+					return nil
+				}).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given unknown email when forgot password then return success without mailing",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByEmail(gomock.Any(), "name@example.com").Return([]*model.CustomerEntity{}, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error issuing a token when forgot password then mail the other accounts and return success",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByEmail(gomock.Any(), "name@example.com").Return([]*model.CustomerEntity{{ID: 1, Email: "name@example.com"}, {ID: 2, Email: "name@example.com"}}, nil).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+				mockCustomerRepository.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockMailerClient.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error getting customers when forgot password then return success",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByEmail(gomock.Any(), "name@example.com").Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.ForgotPassword(context.Background(), &model.ForgotPasswordRequest{Email: "name@example.com"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ForgotPassword() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			// the emails are sent in the background
+			customerSvc.Wait()
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	Setup(t)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mockCustomerRepository.EXPECT().GetByEmail(gomock.Any(), "name@example.com").DoAndReturn(func(context.Context, string) ([]*model.CustomerEntity, error) {
+		close(started)
+		<-release
+		return []*model.CustomerEntity{}, nil
+	}).Times(1)
+
+	if err := customerSvc.ForgotPassword(context.Background(), &model.ForgotPasswordRequest{Email: "name@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		customerSvc.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Wait() returned while a password reset is pending")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return once the password reset is done")
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	Setup(t)
+
+	tokenHash := utils.HashToken("token")
+	validToken := &model.CustomerTokenEntity{ID: 1, CustomerID: 2, ExpiresAt: time.Now().Add(time.Hour)}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid token when reset password then update password and revoke every session",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypePasswordReset, tokenHash).Return(validToken, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseToken(gomock.Any(), 1).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().UpdatePassword(gomock.Any(), 2, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, password string) error {
+					if bcrypt.CompareHashAndPassword([]byte(password), []byte("New-password1")) != nil {
+						t.Errorf("ResetPassword() stored password is not the hash of the new password")
+					}
+					return nil
+				}).Times(1)
+				mockSessionRepository.EXPECT().DeleteByCustomerID(gomock.Any(), 2).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given invalid token when reset password then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetToken(gomock.Any(), customerEnum.TokenTypePasswordReset, tokenHash).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.ResetPassword(context.Background(), &model.ResetPasswordRequest{Token: "token", NewPassword: "New-password1"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ResetPassword() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given customer without two factor when enroll then return the secret",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Username: "john"}, nil).Times(1)
+				mockCustomerRepository.EXPECT().SetTOTPSecret(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given customer with two factor when enroll then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, TwoFactorEnabled: true}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error saving the secret when enroll then return error",
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Username: "john"}, nil).Times(1)
+				mockCustomerRepository.EXPECT().SetTOTPSecret(gomock.Any(), 1, gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			response, err := customerSvc.EnrollTwoFactor(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("EnrollTwoFactor() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr && !strings.HasPrefix(response.OTPAuthURI, "otpauth://totp/Online%20Store:john?") {
+				t.Errorf("EnrollTwoFactor() uri = %s", response.OTPAuthURI)
+			}
+		})
+	}
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	Setup(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	code, err := utils.GenerateTOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	customer := &model.CustomerEntity{ID: 1, TOTPSecret: secret}
+
+	testCases := []struct {
+		name    string
+		request *model.ConfirmTwoFactorRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid code when confirm then enable two factor with hashed recovery codes",
+			request: &model.ConfirmTwoFactorRequest{CustomerID: 1, Code: code},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseTOTPStep(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().EnableTOTP(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given wrong code when confirm then return error",
+			request: &model.ConfirmTwoFactorRequest{CustomerID: 1, Code: "000000x"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given enrollment not started when confirm then return error",
+			request: &model.ConfirmTwoFactorRequest{CustomerID: 1, Code: code},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error enabling when confirm then return error",
+			request: &model.ConfirmTwoFactorRequest{CustomerID: 1, Code: code},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().UseTOTPStep(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+				mockCustomerRepository.EXPECT().EnableTOTP(gomock.Any(), 1, gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := customerSvc.ConfirmTwoFactor(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ConfirmTwoFactor() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfirmTwoFactorRecoveryCodes(t *testing.T) {
+	Setup(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	code, err := utils.GenerateTOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var hashes []string
+	mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, TOTPSecret: secret}, nil).Times(1)
+	mockCustomerRepository.EXPECT().UseTOTPStep(gomock.Any(), 1, gomock.Any()).Return(nil).Times(1)
+	mockCustomerRepository.EXPECT().EnableTOTP(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, h []string) error {
+		hashes = h
+		return nil
+	}).Times(1)
+
+	response, err := customerSvc.ConfirmTwoFactor(context.Background(), &model.ConfirmTwoFactorRequest{CustomerID: 1, Code: code})
+	if err != nil {
+		t.Fatalf("ConfirmTwoFactor() error = %v", err)
+	}
+
+	if len(response.RecoveryCodes) != config.Default().TwoFactor.RecoveryCodes {
+		t.Fatalf("ConfirmTwoFactor() recovery codes = %d, want %d", len(response.RecoveryCodes), config.Default().TwoFactor.RecoveryCodes)
+	}
+
+	for i, recoveryCode := range response.RecoveryCodes {
+		if len(recoveryCode) != 11 || recoveryCode[5] != '-' {
+			t.Errorf("ConfirmTwoFactor() recovery code = %s, want xxxxx-xxxxx", recoveryCode)
+		}
+
+		if hashes[i] != utils.HashToken(strings.ReplaceAll(recoveryCode, "-", "")) {
+			t.Errorf("ConfirmTwoFactor() stored hash of %s = %s", recoveryCode, hashes[i])
+		}
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	Setup(t)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	customer := &model.CustomerEntity{ID: 1, Password: string(hashedPassword), TOTPSecret: "SECRET", TwoFactorEnabled: true}
+
+	testCases := []struct {
+		name    string
+		request *model.DisableTwoFactorRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given password when disable then remove two factor",
+			request: &model.DisableTwoFactorRequest{CustomerID: 1, Password: "password"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+				mockCustomerRepository.EXPECT().DisableTOTP(gomock.Any(), 1).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given wrong password when disable then return error",
+			request: &model.DisableTwoFactorRequest{CustomerID: 1, Password: "wrong-password"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(customer, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given two factor not enabled when disable then return error",
+			request: &model.DisableTwoFactorRequest{CustomerID: 1, Password: "password"},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, Password: string(hashedPassword)}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := customerSvc.DisableTwoFactor(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DisableTwoFactor() error = %v, wantErr %v", err, tc.wantErr)
 			}
 		})
 	}
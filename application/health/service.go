@@ -0,0 +1,12 @@
+package health
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=HealthService.go
+type Service interface {
+	Check(ctx context.Context) *model.HealthEntity
+}
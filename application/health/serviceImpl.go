@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	healthEnum "github.com/zakiyalmaya/online-store/constant/health"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// checkTimeout bounds each check, so a probe is answered even when a
+// connection hangs.
+const checkTimeout = 2 * time.Second
+
+type healthSvcImpl struct {
+	repos *repository.Repositories
+}
+
+func NewHealthService(repos *repository.Repositories) Service {
+	return &healthSvcImpl{repos: repos}
+}
+
+// Check is unavailable without the database, nothing is answered without it,
+// and degraded without Redis: sessions, guest carts and login limits fail
+// until it is back, but the catalog is still answered. The checks run at the
+// same time, a probe waits for the slowest one only. It is not traced, the
+// probes would start a trace every few seconds.
+func (h *healthSvcImpl) Check(ctx context.Context) *model.HealthEntity {
+	var database, redis healthEnum.Status
+	var checks sync.WaitGroup
+	checks.Add(2)
+	go func() {
+		defer checks.Done()
+		database = check(ctx, h.repos.Health.PingDatabase)
+	}()
+	go func() {
+		defer checks.Done()
+		redis = check(ctx, h.repos.Health.PingRedis)
+	}()
+	checks.Wait()
+
+	health := &model.HealthEntity{
+		Status: healthEnum.HealthStatusOK,
+		Checks: map[string]healthEnum.Status{
+			model.HealthCheckDatabase: database,
+			model.HealthCheckRedis:    redis,
+		},
+	}
+
+	switch {
+	case health.Checks[model.HealthCheckDatabase] != healthEnum.HealthStatusOK:
+		health.Status = healthEnum.HealthStatusUnavailable
+	case health.Checks[model.HealthCheckRedis] != healthEnum.HealthStatusOK:
+		health.Status = healthEnum.HealthStatusDegraded
+	}
+
+	return health
+}
+
+func check(ctx context.Context, ping func(ctx context.Context) error) healthEnum.Status {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if err := ping(ctx); err != nil {
+		return healthEnum.HealthStatusUnavailable
+	}
+
+	return healthEnum.HealthStatusOK
+}
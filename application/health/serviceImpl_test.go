@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	healthEnum "github.com/zakiyalmaya/online-store/constant/health"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	mockHealthRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/health"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+var (
+	mockHealthRepository *mockHealthRepo.MockRepository
+	healthSvc            Service
+)
+
+func Setup(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	mockHealthRepository = mockHealthRepo.NewMockRepository(mockCtl)
+	healthSvc = NewHealthService(&repository.Repositories{
+		Health: mockHealthRepository,
+	})
+}
+
+func TestCheck(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name       string
+		mock       func()
+		wantStatus healthEnum.Status
+		wantRedis  healthEnum.Status
+	}{
+		{
+			name: "Given database and redis up when check then return ok",
+			mock: func() {
+				mockHealthRepository.EXPECT().PingDatabase(gomock.Any()).Return(nil)
+				mockHealthRepository.EXPECT().PingRedis(gomock.Any()).Return(nil)
+			},
+			wantStatus: healthEnum.HealthStatusOK,
+			wantRedis:  healthEnum.HealthStatusOK,
+		},
+		{
+			name: "Given redis down when check then return degraded",
+			mock: func() {
+				mockHealthRepository.EXPECT().PingDatabase(gomock.Any()).Return(nil)
+				mockHealthRepository.EXPECT().PingRedis(gomock.Any()).Return(errors.New("connection refused"))
+			},
+			wantStatus: healthEnum.HealthStatusDegraded,
+			wantRedis:  healthEnum.HealthStatusUnavailable,
+		},
+		{
+			name: "Given database down when check then return unavailable",
+			mock: func() {
+				mockHealthRepository.EXPECT().PingDatabase(gomock.Any()).Return(errors.New("database is locked"))
+				mockHealthRepository.EXPECT().PingRedis(gomock.Any()).Return(nil)
+			},
+			wantStatus: healthEnum.HealthStatusUnavailable,
+			wantRedis:  healthEnum.HealthStatusOK,
+		},
+		{
+			name: "Given each ping waiting for the other when check then return ok",
+			mock: func() {
+				// run one after the other, the first ping would time out
+				database, redis := make(chan struct{}), make(chan struct{})
+				mockHealthRepository.EXPECT().PingDatabase(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+					close(database)
+					return waitFor(ctx, redis)
+				})
+				mockHealthRepository.EXPECT().PingRedis(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+					close(redis)
+					return waitFor(ctx, database)
+				})
+			},
+			wantStatus: healthEnum.HealthStatusOK,
+			wantRedis:  healthEnum.HealthStatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			health := healthSvc.Check(context.Background())
+			if health.Status != tc.wantStatus {
+				t.Errorf("Check() status = %v, want %v", health.Status.Enum(), tc.wantStatus.Enum())
+			}
+
+			if health.Checks[model.HealthCheckRedis] != tc.wantRedis {
+				t.Errorf("Check() redis = %v, want %v", health.Checks[model.HealthCheckRedis].Enum(), tc.wantRedis.Enum())
+			}
+		})
+	}
+}
+
+func waitFor(ctx context.Context, done <-chan struct{}) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,9 +1,21 @@
 package product
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=ProductService.go
 type Service interface {
-	Create(request *model.CreateProductRequest) error
-	GetAll(request *model.GetProductRequest) ([]*model.ProductResponse, error)
+	Create(ctx context.Context, request *model.CreateProductRequest) error
+	GetAll(ctx context.Context, request *model.GetProductRequest) ([]*model.ProductResponse, error)
+	CreateOption(ctx context.Context, request *model.CreateProductOptionRequest) error
+	CreateVariant(ctx context.Context, request *model.CreateProductVariantRequest) error
+	GetVariants(ctx context.Context, productID int) (*model.ProductVariantsResponse, error)
+	UploadImage(ctx context.Context, request *model.UploadProductImageRequest) (*model.ProductImageResponse, error)
+	GetImages(ctx context.Context, productID int) ([]*model.ProductImageResponse, error)
+	DeleteImage(ctx context.Context, request *model.ProductImageRequest) error
+	SetPrimaryImage(ctx context.Context, request *model.ProductImageRequest) error
+	ReorderImages(ctx context.Context, request *model.ReorderProductImagesRequest) error
 }
\ No newline at end of file
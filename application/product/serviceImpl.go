@@ -1,50 +1,437 @@
 package product
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 
 	"github.com/shopspring/decimal"
+	"github.com/zakiyalmaya/online-store/config"
+	productEnum "github.com/zakiyalmaya/online-store/constant/product"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	productRepo "github.com/zakiyalmaya/online-store/infrastructure/repository/product"
+	"github.com/zakiyalmaya/online-store/infrastructure/storage"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
 )
 
 type productSvcImpl struct {
 	repos *repository.Repositories
+	store storage.Storage
+	media config.MediaConfig
 }
 
-func NewProductService(repos *repository.Repositories) Service {
-	return &productSvcImpl{repos: repos}
+func NewProductService(repos *repository.Repositories, store storage.Storage, media config.MediaConfig) Service {
+	return &productSvcImpl{repos: repos, store: store, media: media}
 }
 
-func (p *productSvcImpl) Create(request *model.CreateProductRequest) error {
-	category, err := p.repos.Category.GetByID(request.CategoryID)
+func (p *productSvcImpl) Create(ctx context.Context, request *model.CreateProductRequest) error {
+	ctx, span := tracing.StartService(ctx, "product", "Create")
+	defer span.End()
+
+	category, err := p.repos.Category.GetByID(ctx, request.CategoryID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("category not found")
+			return model.NewNotFoundError("category_not_found", "category not found")
 		}
 
-		return fmt.Errorf("error getting category by id")
+		return fmt.Errorf("error getting category by id: %w", err)
 	}
 
-	if err := p.repos.Product.Create(&model.ProductEntity{
+	if err := p.repos.Product.Create(ctx, &model.ProductEntity{
 		Name:          request.Name,
 		Description:   request.Description,
 		Price:         decimal.NewFromFloat(request.Price),
 		CategoryID:    category.ID,
 		StockQuantity: request.StockQuantity,
 	}); err != nil {
-		return fmt.Errorf("error creating product")
+		return fmt.Errorf("error creating product: %w", err)
 	}
 
 	return nil
 }
 
-func (p *productSvcImpl) GetAll(request *model.GetProductRequest) ([]*model.ProductResponse, error) {
-	products, err := p.repos.Product.GetAll(request)
+func (p *productSvcImpl) GetAll(ctx context.Context, request *model.GetProductRequest) ([]*model.ProductResponse, error) {
+	ctx, span := tracing.StartService(ctx, "product", "GetAll")
+	defer span.End()
+
+	products, err := p.repos.Product.GetAll(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("error getting all products: %w", err)
+	}
+
+	productIDs := make([]int, len(products))
+	productByID := make(map[int]*model.ProductResponse, len(products))
+	for i, product := range products {
+		product.Images = make([]*model.ProductImageResponse, 0)
+		productIDs[i] = product.ID
+		productByID[product.ID] = product
+	}
+
+	images, err := p.repos.Product.GetImages(ctx, productIDs)
 	if err != nil {
-		return nil, fmt.Errorf("error getting all products")
+		return nil, fmt.Errorf("error getting product images: %w", err)
+	}
+
+	for _, image := range images {
+		if product, ok := productByID[image.ProductID]; ok {
+			product.Images = append(product.Images, image.ToResponse(p.store.URL))
+		}
 	}
 
 	return products, nil
 }
+
+func (p *productSvcImpl) CreateOption(ctx context.Context, request *model.CreateProductOptionRequest) error {
+	ctx, span := tracing.StartService(ctx, "product", "CreateOption")
+	defer span.End()
+
+	if _, err := p.getProduct(ctx, request.ProductID); err != nil {
+		return err
+	}
+
+	// a new option would leave the existing variants without a value for it
+	variants, err := p.repos.Product.GetVariants(ctx, request.ProductID)
+	if err != nil {
+		return fmt.Errorf("error getting product variants: %w", err)
+	}
+
+	if len(variants) > 0 {
+		return model.NewUnprocessableError("product_has_variants", "options cannot be added to a product with variants")
+	}
+
+	values := make([]*model.ProductOptionValueEntity, len(request.Values))
+	for i, value := range request.Values {
+		values[i] = &model.ProductOptionValueEntity{Value: value}
+	}
+
+	if err := p.repos.Product.CreateOption(ctx, &model.ProductOptionEntity{
+		ProductID: request.ProductID,
+		Name:      request.Name,
+		Values:    values,
+	}); err != nil {
+		return fmt.Errorf("error creating product option: %w", err)
+	}
+
+	return nil
+}
+
+func (p *productSvcImpl) CreateVariant(ctx context.Context, request *model.CreateProductVariantRequest) error {
+	ctx, span := tracing.StartService(ctx, "product", "CreateVariant")
+	defer span.End()
+
+	if _, err := p.getProduct(ctx, request.ProductID); err != nil {
+		return err
+	}
+
+	options, err := p.repos.Product.GetOptions(ctx, request.ProductID)
+	if err != nil {
+		return fmt.Errorf("error getting product options: %w", err)
+	}
+
+	if len(options) == 0 {
+		return model.NewUnprocessableError("product_has_no_options", "product has no options")
+	}
+
+	valueByID := make(map[int]*model.ProductOptionValueEntity)
+	for _, option := range options {
+		for _, value := range option.Values {
+			valueByID[value.ID] = value
+		}
+	}
+
+	// a variant picks exactly one value of every option of the product
+	selected := make(map[int]*model.ProductOptionValueEntity, len(request.OptionValueIDs))
+	for _, valueID := range request.OptionValueIDs {
+		value, ok := valueByID[valueID]
+		if !ok {
+			return model.NewValidationError("option_value_not_found", fmt.Sprintf("option value %d not found", valueID))
+		}
+
+		if _, ok := selected[value.OptionID]; ok {
+			return model.NewValidationError("duplicate_option_value", "only one value per option is allowed")
+		}
+		selected[value.OptionID] = value
+	}
+
+	if len(selected) != len(options) {
+		return model.NewValidationError("option_value_required", "a value is required for every option")
+	}
+
+	variants, err := p.repos.Product.GetVariants(ctx, request.ProductID)
+	if err != nil {
+		return fmt.Errorf("error getting product variants: %w", err)
+	}
+
+	for _, variant := range variants {
+		if sameOptionValues(variant.OptionValues, selected) {
+			return model.NewConflictError("variant_exists", "variant already exists")
+		}
+	}
+
+	optionValues := make([]*model.ProductOptionValueEntity, 0, len(selected))
+	for _, option := range options {
+		optionValues = append(optionValues, selected[option.ID])
+	}
+
+	variant := &model.ProductVariantEntity{
+		ProductID:     request.ProductID,
+		SKU:           request.SKU,
+		StockQuantity: request.StockQuantity,
+		OptionValues:  optionValues,
+	}
+	if request.Price != nil {
+		variant.Price = decimal.NewNullDecimal(decimal.NewFromFloat(*request.Price))
+	}
+
+	if err := p.repos.Product.CreateVariant(ctx, variant); err != nil {
+		if err == productRepo.ErrSKUTaken {
+			return model.NewConflictError("sku_taken", "sku is already taken").WithFields(utils.FieldErrors{"sku": "is already taken"})
+		}
+
+		return fmt.Errorf("error creating product variant: %w", err)
+	}
+
+	return nil
+}
+
+func (p *productSvcImpl) GetVariants(ctx context.Context, productID int) (*model.ProductVariantsResponse, error) {
+	ctx, span := tracing.StartService(ctx, "product", "GetVariants")
+	defer span.End()
+
+	product, err := p.getProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := p.repos.Product.GetOptions(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting product options: %w", err)
+	}
+
+	variants, err := p.repos.Product.GetVariants(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting product variants: %w", err)
+	}
+
+	response := &model.ProductVariantsResponse{
+		ProductID: productID,
+		Options:   make([]*model.ProductOptionResponse, len(options)),
+		Variants:  make([]*model.ProductVariantResponse, len(variants)),
+	}
+
+	for i, option := range options {
+		response.Options[i] = option.ToResponse()
+	}
+
+	for i, variant := range variants {
+		response.Variants[i] = variant.ToResponse(product.Price)
+	}
+
+	return response, nil
+}
+
+func (p *productSvcImpl) UploadImage(ctx context.Context, request *model.UploadProductImageRequest) (*model.ProductImageResponse, error) {
+	ctx, span := tracing.StartService(ctx, "product", "UploadImage")
+	defer span.End()
+
+	if _, err := p.getProduct(ctx, request.ProductID); err != nil {
+		return nil, err
+	}
+
+	if len(request.Data) > p.media.MaxImageSize {
+		return nil, model.NewTooLargeError("image_too_large", fmt.Sprintf("image exceeds the maximum size of %d bytes", p.media.MaxImageSize))
+	}
+
+	// trust the content, not the file name or the header sent by the client
+	contentType := productEnum.ImageType(http.DetectContentType(request.Data))
+	if !contentType.IsValid() {
+		return nil, model.NewUnsupportedMediaTypeError("unsupported_image_type", fmt.Sprintf("unsupported image type: %s, allowed: %s, %s, %s", contentType, productEnum.ImageTypeJPEG, productEnum.ImageTypePNG, productEnum.ImageTypeWebP))
+	}
+
+	thumbnail, thumbnailType, err := utils.GenerateThumbnail(request.Data, p.media.ThumbnailSize, p.media.MaxImagePixels)
+	if errors.Is(err, utils.ErrImageTooLarge) {
+		return nil, model.NewTooLargeError("image_too_many_pixels", fmt.Sprintf("image exceeds the maximum of %d pixels", p.media.MaxImagePixels))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error generating thumbnail: %w", err)
+	}
+
+	name := fmt.Sprintf("products/%d/%s", request.ProductID, utils.GenerateUUID())
+	image := &model.ProductImageEntity{
+		ProductID:     request.ProductID,
+		Path:          name + contentType.Extension(),
+		ThumbnailPath: name + "_thumb" + productEnum.ImageType(thumbnailType).Extension(),
+		ContentType:   string(contentType),
+		Size:          len(request.Data),
+	}
+
+	if err := p.store.Save(image.Path, request.Data); err != nil {
+		return nil, fmt.Errorf("error saving image: %w", err)
+	}
+
+	if err := p.store.Save(image.ThumbnailPath, thumbnail); err != nil {
+		p.deleteImageFiles(ctx, image)
+		return nil, fmt.Errorf("error saving thumbnail: %w", err)
+	}
+
+	created, err := p.repos.Product.CreateImage(ctx, image)
+	if err != nil {
+		p.deleteImageFiles(ctx, image)
+		return nil, fmt.Errorf("error creating product image: %w", err)
+	}
+
+	if request.IsPrimary && !created.IsPrimary {
+		if err := p.repos.Product.SetPrimaryImage(ctx, created.ProductID, created.ID); err != nil {
+			return nil, fmt.Errorf("error setting primary image: %w", err)
+		}
+		created.IsPrimary = true
+	}
+
+	return created.ToResponse(p.store.URL), nil
+}
+
+func (p *productSvcImpl) GetImages(ctx context.Context, productID int) ([]*model.ProductImageResponse, error) {
+	ctx, span := tracing.StartService(ctx, "product", "GetImages")
+	defer span.End()
+
+	if _, err := p.getProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images, err := p.repos.Product.GetImages(ctx, []int{productID})
+	if err != nil {
+		return nil, fmt.Errorf("error getting product images: %w", err)
+	}
+
+	imagesResponse := make([]*model.ProductImageResponse, len(images))
+	for i, image := range images {
+		imagesResponse[i] = image.ToResponse(p.store.URL)
+	}
+
+	return imagesResponse, nil
+}
+
+func (p *productSvcImpl) DeleteImage(ctx context.Context, request *model.ProductImageRequest) error {
+	ctx, span := tracing.StartService(ctx, "product", "DeleteImage")
+	defer span.End()
+
+	image, err := p.getImage(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if err := p.repos.Product.DeleteImage(ctx, image); err != nil {
+		return fmt.Errorf("error deleting product image: %w", err)
+	}
+
+	p.deleteImageFiles(ctx, image)
+
+	return nil
+}
+
+func (p *productSvcImpl) SetPrimaryImage(ctx context.Context, request *model.ProductImageRequest) error {
+	ctx, span := tracing.StartService(ctx, "product", "SetPrimaryImage")
+	defer span.End()
+
+	image, err := p.getImage(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if err := p.repos.Product.SetPrimaryImage(ctx, image.ProductID, image.ID); err != nil {
+		return fmt.Errorf("error setting primary image: %w", err)
+	}
+
+	return nil
+}
+
+func (p *productSvcImpl) ReorderImages(ctx context.Context, request *model.ReorderProductImagesRequest) error {
+	ctx, span := tracing.StartService(ctx, "product", "ReorderImages")
+	defer span.End()
+
+	images, err := p.repos.Product.GetImages(ctx, []int{request.ProductID})
+	if err != nil {
+		return fmt.Errorf("error getting product images: %w", err)
+	}
+
+	// the new order has to list every image of the product exactly once
+	if len(images) != len(request.ImageIDs) {
+		return model.NewValidationError("incomplete_image_ids", "image_ids must contain every image of the product")
+	}
+
+	remaining := make(map[int]bool, len(images))
+	for _, image := range images {
+		remaining[image.ID] = true
+	}
+
+	for _, imageID := range request.ImageIDs {
+		if !remaining[imageID] {
+			return model.NewValidationError("incomplete_image_ids", "image_ids must contain every image of the product")
+		}
+		delete(remaining, imageID)
+	}
+
+	if err := p.repos.Product.ReorderImages(ctx, request.ProductID, request.ImageIDs); err != nil {
+		return fmt.Errorf("error reordering product images: %w", err)
+	}
+
+	return nil
+}
+
+func (p *productSvcImpl) getImage(ctx context.Context, request *model.ProductImageRequest) (*model.ProductImageEntity, error) {
+	image, err := p.repos.Product.GetImageByID(ctx, request.ImageID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewNotFoundError("product_image_not_found", "product image not found")
+		}
+
+		return nil, fmt.Errorf("error getting product image by id: %w", err)
+	}
+
+	if image.ProductID != request.ProductID {
+		return nil, model.NewNotFoundError("product_image_not_found", "product image not found")
+	}
+
+	return image, nil
+}
+
+// deleteImageFiles only logs failures, a leftover file does no harm.
+func (p *productSvcImpl) deleteImageFiles(ctx context.Context, image *model.ProductImageEntity) {
+	for _, path := range []string{image.Path, image.ThumbnailPath} {
+		if err := p.store.Delete(path); err != nil {
+			slog.ErrorContext(ctx, "failed to delete product image file", "path", path, "error", err)
+		}
+	}
+}
+
+func (p *productSvcImpl) getProduct(ctx context.Context, productID int) (*model.ProductEntity, error) {
+	product, err := p.repos.Product.GetByID(ctx, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewNotFoundError("product_not_found", "product not found")
+		}
+
+		return nil, fmt.Errorf("error getting product by id: %w", err)
+	}
+
+	return product, nil
+}
+
+func sameOptionValues(values []*model.ProductOptionValueEntity, selected map[int]*model.ProductOptionValueEntity) bool {
+	if len(values) != len(selected) {
+		return false
+	}
+
+	for _, value := range values {
+		if other, ok := selected[value.OptionID]; !ok || other.ID != value.ID {
+			return false
+		}
+	}
+
+	return true
+}
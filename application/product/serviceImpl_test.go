@@ -1,21 +1,31 @@
 package product
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/binary"
 	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/shopspring/decimal"
+	"github.com/zakiyalmaya/online-store/config"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	productRepo "github.com/zakiyalmaya/online-store/infrastructure/repository/product"
 	mockCategoryRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/category"
 	mockProductRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/product"
+	mockStorage "github.com/zakiyalmaya/online-store/mocks/infrastructure/storage"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 var (
 	mockProductRepository  *mockProductRepo.MockRepository
 	mockCategoryRepository *mockCategoryRepo.MockRepository
+	mockStore              *mockStorage.MockStorage
 	productSvc             Service
 )
 
@@ -25,12 +35,13 @@ func Setup(t *testing.T) {
 
 	mockProductRepository = mockProductRepo.NewMockRepository(ctrl)
 	mockCategoryRepository = mockCategoryRepo.NewMockRepository(ctrl)
+	mockStore = mockStorage.NewMockStorage(ctrl)
 
 	repos := &repository.Repositories{
 		Product:  mockProductRepository,
 		Category: mockCategoryRepository,
 	}
-	productSvc = NewProductService(repos)
+	productSvc = NewProductService(repos, mockStore, config.Default().Media)
 }
 
 func TestCreate(t *testing.T) {
@@ -44,11 +55,11 @@ func TestCreate(t *testing.T) {
 		{
 			name: "Given valid request when create product then return success",
 			mock: func() {
-				mockCategoryRepository.EXPECT().GetByID(1).Return(&model.CategoryEntity{
+				mockCategoryRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CategoryEntity{
 					ID: 1,
 				}, nil).Times(1)
 
-				mockProductRepository.EXPECT().Create(&model.ProductEntity{
+				mockProductRepository.EXPECT().Create(gomock.Any(), &model.ProductEntity{
 					Name:          "T-Shirt",
 					Description:   "T-Shirt description",
 					Price:         decimal.NewFromFloat(10000),
@@ -61,11 +72,11 @@ func TestCreate(t *testing.T) {
 		{
 			name: "Given error when create product then return error",
 			mock: func() {
-				mockCategoryRepository.EXPECT().GetByID(1).Return(&model.CategoryEntity{
+				mockCategoryRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CategoryEntity{
 					ID: 1,
 				}, nil).Times(1)
 
-				mockProductRepository.EXPECT().Create(&model.ProductEntity{
+				mockProductRepository.EXPECT().Create(gomock.Any(), &model.ProductEntity{
 					Name:          "T-Shirt",
 					Description:   "T-Shirt description",
 					Price:         decimal.NewFromFloat(10000),
@@ -78,14 +89,14 @@ func TestCreate(t *testing.T) {
 		{
 			name: "Given error when get category by id then return error",
 			mock: func() {
-				mockCategoryRepository.EXPECT().GetByID(1).Return(nil, errors.New("error")).Times(1)
+				mockCategoryRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
 			},
 			wantErr: true,
 		},
 		{
 			name: "Given error no rows when get category by id then return error",
 			mock: func() {
-				mockCategoryRepository.EXPECT().GetByID(1).Return(nil, sql.ErrNoRows).Times(1)
+				mockCategoryRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
 			},
 			wantErr: true,
 		},
@@ -94,7 +105,7 @@ func TestCreate(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			err := productSvc.Create(&model.CreateProductRequest{
+			err := productSvc.Create(context.Background(), &model.CreateProductRequest{
 				Name:          "T-Shirt",
 				Description:   "T-Shirt description",
 				Price:         10000,
@@ -128,7 +139,7 @@ func TestGetAll(t *testing.T) {
 			name:    "Given valid request when get all product then return success",
 			request: request,
 			mock: func() {
-				mockProductRepository.EXPECT().GetAll(request).Return([]*model.ProductResponse{
+				mockProductRepository.EXPECT().GetAll(gomock.Any(), request).Return([]*model.ProductResponse{
 					{
 						ID:            1,
 						Name:          "T-Shirt",
@@ -138,14 +149,27 @@ func TestGetAll(t *testing.T) {
 						Category:      "Fashion",
 					},
 				}, nil).Times(1)
+				mockProductRepository.EXPECT().GetImages(gomock.Any(), []int{1}).Return([]*model.ProductImageEntity{
+					{ID: 1, ProductID: 1, Path: "products/1/image.png", ThumbnailPath: "products/1/image_thumb.png", IsPrimary: true},
+				}, nil).Times(1)
+				mockStore.EXPECT().URL(gomock.Any()).DoAndReturn(func(path string) string { return "/media/" + path }).Times(2)
 			},
 			wantErr: false,
 		},
+		{
+			name:    "Given error when get product images then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetAll(gomock.Any(), request).Return([]*model.ProductResponse{{ID: 1}}, nil).Times(1)
+				mockProductRepository.EXPECT().GetImages(gomock.Any(), []int{1}).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
 		{
 			name:    "Given error when get all product then return error",
 			request: request,
 			mock: func() {
-				mockProductRepository.EXPECT().GetAll(request).Return(nil, errors.New("error")).Times(1)
+				mockProductRepository.EXPECT().GetAll(gomock.Any(), request).Return(nil, errors.New("error")).Times(1)
 			},
 			wantErr: true,
 		},
@@ -154,10 +178,488 @@ func TestGetAll(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
-			_, err := productSvc.GetAll(tc.request)
+			_, err := productSvc.GetAll(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetAll() error = %v, wantErr %v", err, tc.wantErr)
 			}
 		})
 	}
 }
+
+func TestCreateOption(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when create option then return success",
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockProductRepository.EXPECT().CreateOption(gomock.Any(), &model.ProductOptionEntity{
+					ProductID: 1,
+					Name:      "Size",
+					Values: []*model.ProductOptionValueEntity{
+						{Value: "M"},
+						{Value: "L"},
+					},
+				}).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given product not found when create option then return error",
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given product with variants when create option then return error",
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{{ID: 1}}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when create option then return error",
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockProductRepository.EXPECT().CreateOption(gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := productSvc.CreateOption(context.Background(), &model.CreateProductOptionRequest{
+				ProductID: 1,
+				Name:      "Size",
+				Values:    []string{"M", "L"},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateOption() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateVariant(t *testing.T) {
+	Setup(t)
+
+	size := &model.ProductOptionValueEntity{ID: 1, OptionID: 1, OptionName: "Size", Value: "M"}
+	largeSize := &model.ProductOptionValueEntity{ID: 2, OptionID: 1, OptionName: "Size", Value: "L"}
+	color := &model.ProductOptionValueEntity{ID: 3, OptionID: 2, OptionName: "Color", Value: "Red"}
+	options := []*model.ProductOptionEntity{
+		{ID: 1, ProductID: 1, Name: "Size", Values: []*model.ProductOptionValueEntity{size, largeSize}},
+		{ID: 2, ProductID: 1, Name: "Color", Values: []*model.ProductOptionValueEntity{color}},
+	}
+	price := 12000.0
+
+	testCases := []struct {
+		name           string
+		optionValueIDs []int
+		mock           func()
+		wantErr        bool
+		wantKind       model.ErrorKind
+	}{
+		{
+			name:           "Given valid request when create variant then return success",
+			optionValueIDs: []int{3, 1},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{
+					{ID: 1, OptionValues: []*model.ProductOptionValueEntity{largeSize, color}},
+				}, nil).Times(1)
+				mockProductRepository.EXPECT().CreateVariant(gomock.Any(), &model.ProductVariantEntity{
+					ProductID:     1,
+					SKU:           "TSHIRT-M-RED",
+					Price:         decimal.NewNullDecimal(decimal.NewFromFloat(12000)),
+					StockQuantity: 5,
+					OptionValues:  []*model.ProductOptionValueEntity{size, color},
+				}).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:           "Given unknown option value when create variant then return error",
+			optionValueIDs: []int{1, 4},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:           "Given two values of the same option when create variant then return error",
+			optionValueIDs: []int{1, 2},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:           "Given missing option value when create variant then return error",
+			optionValueIDs: []int{1},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:           "Given existing combination when create variant then return error",
+			optionValueIDs: []int{1, 3},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{
+					{ID: 1, OptionValues: []*model.ProductOptionValueEntity{size, color}},
+				}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:           "Given product without options when create variant then return error",
+			optionValueIDs: []int{1, 3},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return([]*model.ProductOptionEntity{}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:           "Given error when create variant then return error",
+			optionValueIDs: []int{1, 3},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockProductRepository.EXPECT().CreateVariant(gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:           "Given sku already used when create variant then return conflict",
+			optionValueIDs: []int{1, 3},
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return(options, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{}, nil).Times(1)
+				mockProductRepository.EXPECT().CreateVariant(gomock.Any(), gomock.Any()).Return(productRepo.ErrSKUTaken).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := productSvc.CreateVariant(context.Background(), &model.CreateProductVariantRequest{
+				ProductID:      1,
+				SKU:            "TSHIRT-M-RED",
+				Price:          &price,
+				StockQuantity:  5,
+				OptionValueIDs: tc.optionValueIDs,
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateVariant() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var domainErr *model.Error
+			if tc.wantKind != 0 && (!errors.As(err, &domainErr) || domainErr.Kind != tc.wantKind) {
+				t.Errorf("CreateVariant() error = %v, want kind %v", err, tc.wantKind)
+			}
+		})
+	}
+}
+
+func TestGetVariants(t *testing.T) {
+	Setup(t)
+
+	testCases := []struct {
+		name      string
+		mock      func()
+		wantPrice float64
+		wantErr   bool
+	}{
+		{
+			name: "Given variant without price when get variants then return product price",
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1, Price: decimal.NewFromFloat(10000)}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return([]*model.ProductOptionEntity{
+					{ID: 1, Name: "Size", Values: []*model.ProductOptionValueEntity{{ID: 1, OptionID: 1, Value: "M"}}},
+				}, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return([]*model.ProductVariantEntity{
+					{ID: 1, SKU: "TSHIRT-M", StockQuantity: 5, OptionValues: []*model.ProductOptionValueEntity{{ID: 1, OptionID: 1, OptionName: "Size", Value: "M"}}},
+				}, nil).Times(1)
+			},
+			wantPrice: 10000,
+			wantErr:   false,
+		},
+		{
+			name: "Given error when get variants then return error",
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockProductRepository.EXPECT().GetOptions(gomock.Any(), 1).Return([]*model.ProductOptionEntity{}, nil).Times(1)
+				mockProductRepository.EXPECT().GetVariants(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			res, err := productSvc.GetVariants(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetVariants() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr && res.Variants[0].Price != tc.wantPrice {
+				t.Errorf("GetVariants() price = %v, want %v", res.Variants[0].Price, tc.wantPrice)
+			}
+		})
+	}
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// pngHeader is the start of a PNG declaring the dimensions, enough for
+// image.DecodeConfig without encoding that many pixels.
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestUploadImage(t *testing.T) {
+	Setup(t)
+
+	data := pngImage(t, 400, 300)
+	created := &model.ProductImageEntity{ID: 2, ProductID: 1, Path: "products/1/image.png", ThumbnailPath: "products/1/image_thumb.png", Position: 2}
+
+	testCases := []struct {
+		name      string
+		data      []byte
+		isPrimary bool
+		mock      func()
+		wantErr   bool
+		wantKind  model.ErrorKind
+	}{
+		{
+			name:      "Given valid image when upload image then save image and thumbnail",
+			data:      data,
+			isPrimary: true,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockStore.EXPECT().Save(gomock.Any(), data).Return(nil).Times(1)
+				mockStore.EXPECT().Save(gomock.Any(), gomock.Not(data)).Return(nil).Times(1)
+				mockProductRepository.EXPECT().CreateImage(gomock.Any(), gomock.Any()).Return(created, nil).Times(1)
+				mockProductRepository.EXPECT().SetPrimaryImage(gomock.Any(), 1, 2).Return(nil).Times(1)
+				mockStore.EXPECT().URL(gomock.Any()).Return("/media/image").Times(2)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given unsupported content when upload image then return error",
+			data: []byte("GIF89a not really an image"),
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindUnsupportedMediaType,
+		},
+		{
+			name: "Given image with too many pixels when upload image then return error",
+			data: pngHeader(100000, 100000),
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindTooLarge,
+		},
+		{
+			name: "Given corrupted image when upload image then return error",
+			data: data[:64],
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when create image then delete the saved files",
+			data: data,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockStore.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				mockProductRepository.EXPECT().CreateImage(gomock.Any(), gomock.Any()).Return(nil, errors.New("error")).Times(1)
+				mockStore.EXPECT().Delete(gomock.Any()).Return(nil).Times(2)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given product not found when upload image then return error",
+			data: data,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := productSvc.UploadImage(context.Background(), &model.UploadProductImageRequest{
+				ProductID: 1,
+				Data:      tc.data,
+				IsPrimary: tc.isPrimary,
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UploadImage() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var domainErr *model.Error
+			if tc.wantKind != 0 && (!errors.As(err, &domainErr) || domainErr.Kind != tc.wantKind) {
+				t.Errorf("UploadImage() error = %v, want kind %v", err, tc.wantKind)
+			}
+		})
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	Setup(t)
+
+	image := &model.ProductImageEntity{ID: 1, ProductID: 1, Path: "products/1/image.png", ThumbnailPath: "products/1/image_thumb.png", IsPrimary: true}
+
+	testCases := []struct {
+		name    string
+		request *model.ProductImageRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when delete image then delete the row and the files",
+			request: &model.ProductImageRequest{ProductID: 1, ImageID: 1},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImageByID(gomock.Any(), 1).Return(image, nil).Times(1)
+				mockProductRepository.EXPECT().DeleteImage(gomock.Any(), image).Return(nil).Times(1)
+				mockStore.EXPECT().Delete("products/1/image.png").Return(nil).Times(1)
+				mockStore.EXPECT().Delete("products/1/image_thumb.png").Return(errors.New("error")).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given image of another product when delete image then return error",
+			request: &model.ProductImageRequest{ProductID: 2, ImageID: 1},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImageByID(gomock.Any(), 1).Return(image, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error when delete image then return error",
+			request: &model.ProductImageRequest{ProductID: 1, ImageID: 1},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImageByID(gomock.Any(), 1).Return(image, nil).Times(1)
+				mockProductRepository.EXPECT().DeleteImage(gomock.Any(), image).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := productSvc.DeleteImage(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DeleteImage() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestReorderImages(t *testing.T) {
+	Setup(t)
+
+	images := []*model.ProductImageEntity{{ID: 1, ProductID: 1}, {ID: 2, ProductID: 1}}
+
+	testCases := []struct {
+		name     string
+		imageIDs []int
+		mock     func()
+		wantErr  bool
+	}{
+		{
+			name:     "Given every image when reorder images then return success",
+			imageIDs: []int{2, 1},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImages(gomock.Any(), []int{1}).Return(images, nil).Times(1)
+				mockProductRepository.EXPECT().ReorderImages(gomock.Any(), 1, []int{2, 1}).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:     "Given missing image when reorder images then return error",
+			imageIDs: []int{2},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImages(gomock.Any(), []int{1}).Return(images, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Given duplicated image when reorder images then return error",
+			imageIDs: []int{2, 2},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImages(gomock.Any(), []int{1}).Return(images, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Given error when reorder images then return error",
+			imageIDs: []int{1, 2},
+			mock: func() {
+				mockProductRepository.EXPECT().GetImages(gomock.Any(), []int{1}).Return(images, nil).Times(1)
+				mockProductRepository.EXPECT().ReorderImages(gomock.Any(), 1, []int{1, 2}).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := productSvc.ReorderImages(context.Background(), &model.ReorderProductImagesRequest{
+				ProductID: 1,
+				ImageIDs:  tc.imageIDs,
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ReorderImages() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
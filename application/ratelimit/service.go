@@ -0,0 +1,13 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=RateLimitService.go
+type Service interface {
+	Take(ctx context.Context, route, subject string) (*model.RateLimitEntity, error)
+	TakePreAuth(ctx context.Context, ip string) (*model.RateLimitEntity, error)
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+const (
+	// defaultQuota names the quota the routes without a policy share.
+	defaultQuota = "default"
+	// preAuthQuota names the quota taken before the credentials are checked.
+	preAuthQuota = "pre-auth"
+)
+
+type rateLimitSvcImpl struct {
+	repos *repository.Repositories
+	cfg   config.RateLimitConfig
+}
+
+func NewRateLimitService(repos *repository.Repositories, cfg config.RateLimitConfig) Service {
+	return &rateLimitSvcImpl{repos: repos, cfg: cfg}
+}
+
+// Take takes a request of the subject, e.g. user:1 or ip:10.0.0.1, on the
+// route, e.g. POST /customer/login, from the quota of the route when it has a
+// policy, from the default quota otherwise.
+func (r *rateLimitSvcImpl) Take(ctx context.Context, route, subject string) (*model.RateLimitEntity, error) {
+	ctx, span := tracing.StartService(ctx, "ratelimit", "Take")
+	defer span.End()
+
+	quota, policy := defaultQuota, r.cfg.Default
+	if routePolicy, ok := r.cfg.Routes[route]; ok {
+		quota, policy = route, routePolicy
+	}
+
+	return r.take(ctx, quota+":"+subject, policy)
+}
+
+// TakePreAuth takes a request of the IP address from its pre-auth quota, shared
+// by every route needing a login or an API key.
+func (r *rateLimitSvcImpl) TakePreAuth(ctx context.Context, ip string) (*model.RateLimitEntity, error) {
+	ctx, span := tracing.StartService(ctx, "ratelimit", "TakePreAuth")
+	defer span.End()
+
+	return r.take(ctx, preAuthQuota+":ip:"+ip, r.cfg.PreAuth)
+}
+
+func (r *rateLimitSvcImpl) take(ctx context.Context, key string, policy config.RateLimitPolicy) (*model.RateLimitEntity, error) {
+	if r.cfg.Algorithm == config.RateLimitAlgorithmSlidingWindow {
+		return r.repos.RateLimit.SlidingWindow(ctx, key, policy.Limit, policy.Window)
+	}
+
+	return r.repos.RateLimit.TokenBucket(ctx, key, policy.Limit, policy.Window)
+}
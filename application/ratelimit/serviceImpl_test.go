@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	mockRateLimitRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/ratelimit"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+var mockRateLimitRepository *mockRateLimitRepo.MockRepository
+
+func Setup(t *testing.T, algorithm string) Service {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRateLimitRepository = mockRateLimitRepo.NewMockRepository(ctrl)
+	return NewRateLimitService(&repository.Repositories{
+		RateLimit: mockRateLimitRepository,
+	}, config.RateLimitConfig{
+		Enabled:   true,
+		Algorithm: algorithm,
+		Default:   config.RateLimitPolicy{Limit: 300, Window: time.Minute},
+		PreAuth:   config.RateLimitPolicy{Limit: 600, Window: time.Minute},
+		Routes: map[string]config.RateLimitPolicy{
+			"POST /customer/login": {Limit: 10, Window: time.Minute},
+		},
+	})
+}
+
+func TestTake(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm string
+		route     string
+		subject   string
+		mock      func()
+		wantErr   bool
+	}{
+		{
+			name:      "Given a route with a policy when take then take from the quota of the route",
+			algorithm: config.RateLimitAlgorithmTokenBucket,
+			route:     "POST /customer/login",
+			subject:   "ip:10.0.0.1",
+			mock: func() {
+				mockRateLimitRepository.EXPECT().TokenBucket(gomock.Any(), "POST /customer/login:ip:10.0.0.1", 10, time.Minute).
+					Return(&model.RateLimitEntity{Allowed: true, Limit: 10, Window: time.Minute, Remaining: 9}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Given a route without a policy when take then take from the default quota",
+			algorithm: config.RateLimitAlgorithmTokenBucket,
+			route:     "GET /carts",
+			subject:   "user:1",
+			mock: func() {
+				mockRateLimitRepository.EXPECT().TokenBucket(gomock.Any(), "default:user:1", 300, time.Minute).
+					Return(&model.RateLimitEntity{Allowed: true, Limit: 300, Window: time.Minute, Remaining: 299}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Given the sliding window algorithm when take then take from a sliding window",
+			algorithm: config.RateLimitAlgorithmSlidingWindow,
+			route:     "GET /carts",
+			subject:   "api-key:2",
+			mock: func() {
+				mockRateLimitRepository.EXPECT().SlidingWindow(gomock.Any(), "default:api-key:2", 300, time.Minute).
+					Return(&model.RateLimitEntity{Allowed: true, Limit: 300, Window: time.Minute, Remaining: 299}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Given redis down when take then return error",
+			algorithm: config.RateLimitAlgorithmTokenBucket,
+			route:     "GET /carts",
+			subject:   "user:1",
+			mock: func() {
+				mockRateLimitRepository.EXPECT().TokenBucket(gomock.Any(), "default:user:1", 300, time.Minute).
+					Return(nil, errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rateLimitSvc := Setup(t, tc.algorithm)
+			tc.mock()
+			_, err := rateLimitSvc.Take(context.Background(), tc.route, tc.subject)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Take() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTakePreAuth(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm string
+		mock      func()
+		wantErr   bool
+	}{
+		{
+			name:      "Given an IP address when take pre auth then take from the pre auth quota",
+			algorithm: config.RateLimitAlgorithmTokenBucket,
+			mock: func() {
+				mockRateLimitRepository.EXPECT().TokenBucket(gomock.Any(), "pre-auth:ip:10.0.0.1", 600, time.Minute).
+					Return(&model.RateLimitEntity{Allowed: true, Limit: 600, Window: time.Minute, Remaining: 599}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Given the sliding window algorithm when take pre auth then take from a sliding window",
+			algorithm: config.RateLimitAlgorithmSlidingWindow,
+			mock: func() {
+				mockRateLimitRepository.EXPECT().SlidingWindow(gomock.Any(), "pre-auth:ip:10.0.0.1", 600, time.Minute).
+					Return(&model.RateLimitEntity{Allowed: true, Limit: 600, Window: time.Minute, Remaining: 599}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Given redis down when take pre auth then return error",
+			algorithm: config.RateLimitAlgorithmTokenBucket,
+			mock: func() {
+				mockRateLimitRepository.EXPECT().TokenBucket(gomock.Any(), "pre-auth:ip:10.0.0.1", 600, time.Minute).
+					Return(nil, errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rateLimitSvc := Setup(t, tc.algorithm)
+			tc.mock()
+			_, err := rateLimitSvc.TakePreAuth(context.Background(), "10.0.0.1")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("TakePreAuth() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package review
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=ReviewService.go
+type Service interface {
+	Create(ctx context.Context, request *model.CreateReviewRequest) error
+	GetByParams(ctx context.Context, request *model.GetReviewRequest) ([]*model.ReviewResponse, error)
+	UpdateStatus(ctx context.Context, request *model.UpdateReviewStatusRequest) error
+}
@@ -0,0 +1,108 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	reviewRepo "github.com/zakiyalmaya/online-store/infrastructure/repository/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// ErrReviewExists is returned when the customer already reviewed the product.
+var ErrReviewExists = model.NewConflictError("review_exists", "product already reviewed")
+
+type reviewSvcImpl struct {
+	repos *repository.Repositories
+}
+
+func NewReviewService(repos *repository.Repositories) Service {
+	return &reviewSvcImpl{repos: repos}
+}
+
+func (r *reviewSvcImpl) Create(ctx context.Context, request *model.CreateReviewRequest) error {
+	ctx, span := tracing.StartService(ctx, "review", "Create")
+	defer span.End()
+
+	// check product existence
+	if _, err := r.repos.Product.GetByID(ctx, request.ProductID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("product_not_found", fmt.Sprintf("product not found: %d", request.ProductID))
+		}
+
+		return fmt.Errorf("error getting product by id: %d: %w", request.ProductID, err)
+	}
+
+	// a customer can only review a product once
+	_, err := r.repos.Review.GetByProductAndCustomer(ctx, request.ProductID, request.CustomerID)
+	if err == nil {
+		return ErrReviewExists
+	}
+
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("error getting review: %w", err)
+	}
+
+	purchased, err := r.repos.Transaction.HasPurchased(ctx, request.CustomerID, request.ProductID)
+	if err != nil {
+		return fmt.Errorf("error checking purchase: %w", err)
+	}
+
+	if err := r.repos.Review.Create(ctx, &model.ReviewEntity{
+		ProductID:        request.ProductID,
+		CustomerID:       request.CustomerID,
+		Rating:           request.Rating,
+		Title:            request.Title,
+		Body:             request.Body,
+		VerifiedPurchase: purchased,
+		Status:           reviewEnum.ReviewStatusPending,
+	}); err != nil {
+		// a concurrent review of the customer was created after the check
+		if err == reviewRepo.ErrReviewExists {
+			return ErrReviewExists
+		}
+
+		return fmt.Errorf("error creating review: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reviewSvcImpl) GetByParams(ctx context.Context, request *model.GetReviewRequest) ([]*model.ReviewResponse, error) {
+	ctx, span := tracing.StartService(ctx, "review", "GetByParams")
+	defer span.End()
+
+	reviews, err := r.repos.Review.GetByParams(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reviews by params: %w", err)
+	}
+
+	reviewsResponse := make([]*model.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		reviewsResponse[i] = review.ToResponse()
+	}
+
+	return reviewsResponse, nil
+}
+
+func (r *reviewSvcImpl) UpdateStatus(ctx context.Context, request *model.UpdateReviewStatusRequest) error {
+	ctx, span := tracing.StartService(ctx, "review", "UpdateStatus")
+	defer span.End()
+
+	if !request.Status.IsValid() {
+		return model.NewValidationError("invalid_status", "invalid review status")
+	}
+
+	if err := r.repos.Review.UpdateStatus(ctx, request); err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("review_not_found", "review not found")
+		}
+
+		return fmt.Errorf("error updating review status: %w", err)
+	}
+
+	return nil
+}
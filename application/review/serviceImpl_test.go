@@ -0,0 +1,198 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	reviewRepo "github.com/zakiyalmaya/online-store/infrastructure/repository/review"
+	mockProductRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/product"
+	mockReviewRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/review"
+	mockTransactionRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/transaction"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+var (
+	mockReviewRepository      *mockReviewRepo.MockRepository
+	mockProductRepository     *mockProductRepo.MockRepository
+	mockTransactionRepository *mockTransactionRepo.MockRepository
+	reviewSvc                 Service
+)
+
+func Setup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReviewRepository = mockReviewRepo.NewMockRepository(ctrl)
+	mockProductRepository = mockProductRepo.NewMockRepository(ctrl)
+	mockTransactionRepository = mockTransactionRepo.NewMockRepository(ctrl)
+	reviewSvc = NewReviewService(&repository.Repositories{
+		Review:      mockReviewRepository,
+		Product:     mockProductRepository,
+		Transaction: mockTransactionRepository,
+	})
+}
+
+func TestCreate(t *testing.T) {
+	Setup(t)
+
+	request := &model.CreateReviewRequest{
+		ProductID:  1,
+		CustomerID: 1,
+		Rating:     5,
+		Title:      "Great",
+		Body:       "Great product",
+	}
+
+	entity := func(verified bool) *model.ReviewEntity {
+		return &model.ReviewEntity{
+			ProductID:        1,
+			CustomerID:       1,
+			Rating:           5,
+			Title:            "Great",
+			Body:             "Great product",
+			VerifiedPurchase: verified,
+			Status:           reviewEnum.ReviewStatusPending,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		request  *model.CreateReviewRequest
+		mock     func()
+		wantErr  bool
+		wantKind model.ErrorKind
+	}{
+		{
+			name:    "Given purchased product when create review then mark as verified purchase",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockReviewRepository.EXPECT().GetByProductAndCustomer(gomock.Any(), 1, 1).Return(nil, sql.ErrNoRows).Times(1)
+				mockTransactionRepository.EXPECT().HasPurchased(gomock.Any(), 1, 1).Return(true, nil).Times(1)
+				mockReviewRepository.EXPECT().Create(gomock.Any(), entity(true)).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given product not purchased when create review then create unverified review",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockReviewRepository.EXPECT().GetByProductAndCustomer(gomock.Any(), 1, 1).Return(nil, sql.ErrNoRows).Times(1)
+				mockTransactionRepository.EXPECT().HasPurchased(gomock.Any(), 1, 1).Return(false, nil).Times(1)
+				mockReviewRepository.EXPECT().Create(gomock.Any(), entity(false)).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given product already reviewed when create review then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockReviewRepository.EXPECT().GetByProductAndCustomer(gomock.Any(), 1, 1).Return(&model.ReviewEntity{ID: 1}, nil).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindConflict,
+		},
+		{
+			name:    "Given concurrent review created after the check when create review then return conflict",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockReviewRepository.EXPECT().GetByProductAndCustomer(gomock.Any(), 1, 1).Return(nil, sql.ErrNoRows).Times(1)
+				mockTransactionRepository.EXPECT().HasPurchased(gomock.Any(), 1, 1).Return(false, nil).Times(1)
+				mockReviewRepository.EXPECT().Create(gomock.Any(), entity(false)).Return(reviewRepo.ErrReviewExists).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindConflict,
+		},
+		{
+			name:    "Given product not found when create review then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error create review when create review then return error",
+			request: request,
+			mock: func() {
+				mockProductRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.ProductEntity{ID: 1}, nil).Times(1)
+				mockReviewRepository.EXPECT().GetByProductAndCustomer(gomock.Any(), 1, 1).Return(nil, sql.ErrNoRows).Times(1)
+				mockTransactionRepository.EXPECT().HasPurchased(gomock.Any(), 1, 1).Return(false, nil).Times(1)
+				mockReviewRepository.EXPECT().Create(gomock.Any(), entity(false)).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := reviewSvc.Create(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var domainErr *model.Error
+			if tc.wantKind != 0 && (!errors.As(err, &domainErr) || domainErr.Kind != tc.wantKind) {
+				t.Errorf("Create() error = %v, want kind %v", err, tc.wantKind)
+			}
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	Setup(t)
+
+	request := &model.UpdateReviewStatusRequest{
+		ID:     1,
+		Status: reviewEnum.ReviewStatusApproved,
+	}
+
+	testCases := []struct {
+		name    string
+		request *model.UpdateReviewStatusRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when update status then return success",
+			request: request,
+			mock: func() {
+				mockReviewRepository.EXPECT().UpdateStatus(gomock.Any(), request).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given invalid status when update status then return error",
+			request: &model.UpdateReviewStatusRequest{ID: 1, Status: 10},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name:    "Given review not found when update status then return error",
+			request: request,
+			mock: func() {
+				mockReviewRepository.EXPECT().UpdateStatus(gomock.Any(), request).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := reviewSvc.UpdateStatus(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
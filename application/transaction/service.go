@@ -1,9 +1,14 @@
 package transaction
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=TransactionService.go
 type Service interface {
-	Checkout(request *model.TransactionRequest) (*model.TransactionResponse, error)
-	GetByID(id int) (*model.TransactionResponse, error)
+	Checkout(ctx context.Context, request *model.TransactionRequest) (*model.TransactionResponse, error)
+	GetByID(ctx context.Context, id, customerID int) (*model.TransactionResponse, error)
+	UpdateStatus(ctx context.Context, request *model.UpdateTransactionStatusRequest) error
 }
\ No newline at end of file
@@ -1,59 +1,173 @@
 package transaction
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 
+	"github.com/zakiyalmaya/online-store/config"
 	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	transactionEnum "github.com/zakiyalmaya/online-store/constant/transaction"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 type transactionSvcImpl struct {
-	repos *repository.Repositories
+	repos   *repository.Repositories
+	account config.AccountConfig
 }
 
-func NewTransactionService(repos *repository.Repositories) Service {
-	return &transactionSvcImpl{repos: repos}
+func NewTransactionService(repos *repository.Repositories, account config.AccountConfig) Service {
+	return &transactionSvcImpl{repos: repos, account: account}
 }
 
-func (t *transactionSvcImpl) Checkout(request *model.TransactionRequest) (*model.TransactionResponse, error) {
+// Checkout counts the checkouts by payment method and the failures by the
+// code of their error, internal_error when it has none.
+func (t *transactionSvcImpl) Checkout(ctx context.Context, request *model.TransactionRequest) (*model.TransactionResponse, error) {
+	ctx, span := tracing.StartService(ctx, "transaction", "Checkout")
+	defer span.End()
+
+	transaction, err := t.checkout(ctx, request)
+	if err != nil {
+		reason := "internal_error"
+		var domainErr *model.Error
+		if errors.As(err, &domainErr) {
+			reason = domainErr.Code
+		}
+		metrics.CheckoutFailed(reason)
+
+		return nil, err
+	}
+
+	metrics.CheckedOut(transaction.PaymentMethod.Enum())
+	return transaction.ToResponse(), nil
+}
+
+func (t *transactionSvcImpl) checkout(ctx context.Context, request *model.TransactionRequest) (*model.TransactionEntity, error) {
 	// check payment method
 	if !request.PaymentMethod.IsValid() {
-		return nil, fmt.Errorf("invalid payment method")
+		return nil, model.NewValidationError("invalid_payment_method", "invalid payment method")
+	}
+
+	if t.account.RequireVerifiedEmail {
+		customer, err := t.repos.Customer.GetByID(ctx, request.CustomerID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, model.NewNotFoundError("customer_not_found", "customer not found")
+			}
+
+			return nil, fmt.Errorf("error getting customer by id: %w", err)
+		}
+
+		if !customer.EmailVerified {
+			return nil, model.NewUnprocessableError("email_not_verified", "email is not verified")
+		}
 	}
 
 	// check shopping cart existence
-	cart, err := t.repos.Cart.GetByID(request.CartID)
+	cart, err := t.repos.Cart.GetByID(ctx, request.CartID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting cart by id")
+		if err == sql.ErrNoRows {
+			return nil, model.NewNotFoundError("cart_not_found", "cart not found")
+		}
+
+		return nil, fmt.Errorf("error getting cart by id: %w", err)
 	}
 
 	if cart.CustomerID != request.CustomerID {
-		return nil, fmt.Errorf("cart does not belong to the customer")
+		return nil, model.NewForbiddenError("cart_forbidden", "cart does not belong to the customer")
 	}
 
 	if cart.Status != cartEnum.CartStatusActive {
-		return nil, fmt.Errorf("cart is not active")
+		return nil, model.NewUnprocessableError("cart_not_active", "cart is not active")
 	}
 
 	transactionEntity := cart.ToTransactionEntity()
 	transactionEntity.PaymentMethod = request.PaymentMethod
 	transactionEntity.CustomerID = request.CustomerID
 
-	// create new transaction
-	transaction, err := t.repos.Transaction.Create(transactionEntity)
+	// close the cart and create the transaction together, the cart is only
+	// closed when it is still active so it is not checked out twice
+	var transaction *model.TransactionEntity
+	err = t.repos.UnitOfWork.Do(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Cart.UpdateStatus(ctx, cart.ID, cartEnum.CartStatusPending); err != nil {
+			return err
+		}
+
+		created, err := repos.Transaction.Create(ctx, transactionEntity)
+		if err != nil {
+			return err
+		}
+
+		transaction = created
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error creating transaction")
+		if err == sql.ErrNoRows {
+			return nil, model.NewUnprocessableError("cart_not_active", "cart is not active")
+		}
+
+		return nil, fmt.Errorf("error creating transaction: %w", err)
 	}
 
-	return transaction.ToResponse(), nil
+	return transaction, nil
 }
 
-func (t *transactionSvcImpl) GetByID(id int) (*model.TransactionResponse, error) {
-	transaction, err := t.repos.Transaction.GetByID(id)
+// GetByID returns the transaction of the customer, or any transaction when
+// customerID is 0, for the API keys allowed to read the orders.
+func (t *transactionSvcImpl) GetByID(ctx context.Context, id, customerID int) (*model.TransactionResponse, error) {
+	ctx, span := tracing.StartService(ctx, "transaction", "GetByID")
+	defer span.End()
+
+	transaction, err := t.repos.Transaction.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error getting transaction by id")
+		if err == sql.ErrNoRows {
+			return nil, model.NewNotFoundError("transaction_not_found", "transaction not found")
+		}
+
+		return nil, fmt.Errorf("error getting transaction by id: %w", err)
+	}
+
+	// the transaction of another customer is not told apart from a missing one
+	if customerID != 0 && transaction.CustomerID != customerID {
+		return nil, model.NewNotFoundError("transaction_not_found", "transaction not found")
 	}
 
 	return transaction.ToResponse(), nil
+}
+
+// UpdateStatus settles an in progress transaction as success or failed.
+func (t *transactionSvcImpl) UpdateStatus(ctx context.Context, request *model.UpdateTransactionStatusRequest) error {
+	ctx, span := tracing.StartService(ctx, "transaction", "UpdateStatus")
+	defer span.End()
+
+	if request.Status != transactionEnum.TransactionStatusSuccess && request.Status != transactionEnum.TransactionStatusFailed {
+		return model.NewValidationError("invalid_status", "invalid transaction status")
+	}
+
+	if err := t.repos.Transaction.UpdateStatus(ctx, request); err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("transaction_not_found", "transaction not found or not in progress")
+		}
+
+		return fmt.Errorf("error updating transaction status: %w", err)
+	}
+
+	// only a transaction in progress is updated, so a settled one counts once
+	if request.Status == transactionEnum.TransactionStatusSuccess {
+		transaction, err := t.repos.Transaction.GetByID(ctx, request.ID)
+		if err != nil {
+			// the status is already updated, only the GMV misses the amount
+			slog.WarnContext(ctx, "error counting the gmv of the transaction", "transaction_id", request.ID, "error", err)
+			return nil
+		}
+
+		metrics.Paid(transaction.TotalAmount.InexactFloat64())
+	}
+
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,327 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/zakiyalmaya/online-store/config"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	transactionEnum "github.com/zakiyalmaya/online-store/constant/transaction"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	mockRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository"
+	mockCartRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/cart"
+	mockCustomerRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/customer"
+	mockTransactionRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/transaction"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+var (
+	mockCartRepository        *mockCartRepo.MockRepository
+	mockCustomerRepository    *mockCustomerRepo.MockRepository
+	mockTransactionRepository *mockTransactionRepo.MockRepository
+	mockUnitOfWork            *mockRepo.MockUnitOfWork
+	repos                     *repository.Repositories
+	transactionSvc            Service
+)
+
+func Setup(t *testing.T, account config.AccountConfig) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	mockCartRepository = mockCartRepo.NewMockRepository(mockCtl)
+	mockCustomerRepository = mockCustomerRepo.NewMockRepository(mockCtl)
+	mockTransactionRepository = mockTransactionRepo.NewMockRepository(mockCtl)
+	mockUnitOfWork = mockRepo.NewMockUnitOfWork(mockCtl)
+
+	repos = &repository.Repositories{
+		UnitOfWork:  mockUnitOfWork,
+		Cart:        mockCartRepository,
+		Customer:    mockCustomerRepository,
+		Transaction: mockTransactionRepository,
+	}
+	transactionSvc = NewTransactionService(repos, account)
+}
+
+// inTransaction runs the unit of work on the mocked repositories.
+func inTransaction(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	return fn(repos)
+}
+
+func TestCheckout(t *testing.T) {
+	request := &model.TransactionRequest{
+		CustomerID:    1,
+		CartID:        2,
+		PaymentMethod: transactionEnum.TransactionMethodCash,
+	}
+	cart := &model.CartEntity{
+		ID:         2,
+		CustomerID: 1,
+		Status:     cartEnum.CartStatusActive,
+		Items:      []*model.CartItemEntity{{ProductID: 3, Quantity: 1, Price: decimal.NewFromInt(10)}},
+	}
+	transaction := &model.TransactionEntity{
+		ID:         4,
+		CustomerID: 1,
+		Details:    []*model.TransactionDetailEntity{{ProductID: 3, Quantity: 1, Price: decimal.NewFromInt(10)}},
+	}
+
+	testCases := []struct {
+		name     string
+		account  config.AccountConfig
+		mock     func()
+		wantErr  bool
+		wantKind model.ErrorKind
+	}{
+		{
+			name:    "Given verification not required when checkout then skip the email check",
+			account: config.AccountConfig{},
+			mock: func() {
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 2).Return(cart, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartRepository.EXPECT().UpdateStatus(gomock.Any(), 2, cartEnum.CartStatusPending).Return(nil).Times(1)
+				mockTransactionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(transaction, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given cart checked out meanwhile when checkout then return error",
+			account: config.AccountConfig{},
+			mock: func() {
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 2).Return(cart, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartRepository.EXPECT().UpdateStatus(gomock.Any(), 2, cartEnum.CartStatusPending).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindUnprocessable,
+		},
+		{
+			name:    "Given cart of another customer when checkout then return forbidden",
+			account: config.AccountConfig{},
+			mock: func() {
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 2).Return(&model.CartEntity{ID: 2, CustomerID: 5, Status: cartEnum.CartStatusActive}, nil).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindForbidden,
+		},
+		{
+			name:    "Given unknown cart when checkout then return not found",
+			account: config.AccountConfig{},
+			mock: func() {
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 2).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindNotFound,
+		},
+		{
+			name:    "Given error creating transaction when checkout then return error",
+			account: config.AccountConfig{},
+			mock: func() {
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 2).Return(cart, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartRepository.EXPECT().UpdateStatus(gomock.Any(), 2, cartEnum.CartStatusPending).Return(nil).Times(1)
+				mockTransactionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given verified email when checkout then return success",
+			account: config.AccountConfig{RequireVerifiedEmail: true},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1, EmailVerified: true}, nil).Times(1)
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 2).Return(cart, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartRepository.EXPECT().UpdateStatus(gomock.Any(), 2, cartEnum.CartStatusPending).Return(nil).Times(1)
+				mockTransactionRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(transaction, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given unverified email when checkout then return error",
+			account: config.AccountConfig{RequireVerifiedEmail: true},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CustomerEntity{ID: 1}, nil).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindUnprocessable,
+		},
+		{
+			name:    "Given error getting customer when checkout then return error",
+			account: config.AccountConfig{RequireVerifiedEmail: true},
+			mock: func() {
+				mockCustomerRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			Setup(t, tc.account)
+			tc.mock()
+			_, err := transactionSvc.Checkout(context.Background(), request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Checkout() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var domainErr *model.Error
+			if tc.wantKind != 0 && (!errors.As(err, &domainErr) || domainErr.Kind != tc.wantKind) {
+				t.Errorf("Checkout() error = %v, want kind %v", err, tc.wantKind)
+			}
+		})
+	}
+}
+
+func TestGetByID(t *testing.T) {
+	Setup(t, config.AccountConfig{})
+
+	testCases := []struct {
+		name       string
+		customerID int
+		mock       func()
+		wantErr    bool
+		wantKind   model.ErrorKind
+	}{
+		{
+			name:       "Given transaction of the customer when get by id then return success",
+			customerID: 1,
+			mock: func() {
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.TransactionEntity{ID: 1, CustomerID: 1}, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Given transaction of another customer when get by id then return not found",
+			customerID: 2,
+			mock: func() {
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.TransactionEntity{ID: 1, CustomerID: 1}, nil).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindNotFound,
+		},
+		{
+			name:       "Given no customer when get by id then return any transaction",
+			customerID: 0,
+			mock: func() {
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.TransactionEntity{ID: 1, CustomerID: 1}, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Given transaction not found when get by id then return not found",
+			customerID: 1,
+			mock: func() {
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr:  true,
+			wantKind: model.ErrorKindNotFound,
+		},
+		{
+			name:       "Given error when get by id then return error",
+			customerID: 1,
+			mock: func() {
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := transactionSvc.GetByID(context.Background(), 1, tc.customerID)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetByID() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var domainErr *model.Error
+			if tc.wantKind != 0 && (!errors.As(err, &domainErr) || domainErr.Kind != tc.wantKind) {
+				t.Errorf("GetByID() error = %v, want kind %v", err, tc.wantKind)
+			}
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	Setup(t, config.AccountConfig{})
+
+	request := &model.UpdateTransactionStatusRequest{
+		ID:     1,
+		Status: transactionEnum.TransactionStatusSuccess,
+	}
+
+	testCases := []struct {
+		name    string
+		request *model.UpdateTransactionStatusRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when update status then return success",
+			request: request,
+			mock: func() {
+				mockTransactionRepository.EXPECT().UpdateStatus(gomock.Any(), request).Return(nil).Times(1)
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.TransactionEntity{ID: 1, TotalAmount: decimal.NewFromInt(20)}, nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given failed status when update status then return success without reading the transaction",
+			request: &model.UpdateTransactionStatusRequest{ID: 1, Status: transactionEnum.TransactionStatusFailed},
+			mock: func() {
+				mockTransactionRepository.EXPECT().UpdateStatus(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given error reading the settled transaction when update status then return success",
+			request: request,
+			mock: func() {
+				mockTransactionRepository.EXPECT().UpdateStatus(gomock.Any(), request).Return(nil).Times(1)
+				mockTransactionRepository.EXPECT().GetByID(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given in progress status when update status then return error",
+			request: &model.UpdateTransactionStatusRequest{ID: 1, Status: transactionEnum.TransactionStatusInprogress},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name:    "Given invalid status when update status then return error",
+			request: &model.UpdateTransactionStatusRequest{ID: 1, Status: 10},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name:    "Given transaction not in progress when update status then return error",
+			request: request,
+			mock: func() {
+				mockTransactionRepository.EXPECT().UpdateStatus(gomock.Any(), request).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error when update status then return error",
+			request: request,
+			mock: func() {
+				mockTransactionRepository.EXPECT().UpdateStatus(gomock.Any(), request).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := transactionSvc.UpdateStatus(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
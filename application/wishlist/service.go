@@ -0,0 +1,18 @@
+package wishlist
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=service.go -destination=WishlistService.go
+type Service interface {
+	Create(ctx context.Context, request *model.WishlistRequest) error
+	GetByCustomerID(ctx context.Context, customerID int) ([]*model.WishlistResponse, error)
+	Delete(ctx context.Context, customerID, productID, productVariantID int) error
+	MoveToCart(ctx context.Context, request *model.MoveToCartRequest) (*model.CartResponse, error)
+	SaveForLater(ctx context.Context, request *model.SaveForLaterRequest) error
+	GetNotifications(ctx context.Context, customerID int) ([]*model.WishlistNotificationResponse, error)
+	NotifyChanges(ctx context.Context) error
+}
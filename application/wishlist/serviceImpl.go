@@ -0,0 +1,221 @@
+package wishlist
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/zakiyalmaya/online-store/application/cart"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+type wishlistSvcImpl struct {
+	repos *repository.Repositories
+	// cartSvc returns the cart service running on repos, the repositories of
+	// a unit of work moving an item between the cart and the wishlist
+	cartSvc func(repos *repository.Repositories) cart.Service
+}
+
+func NewWishlistService(repos *repository.Repositories, cartSvc func(repos *repository.Repositories) cart.Service) Service {
+	return &wishlistSvcImpl{repos: repos, cartSvc: cartSvc}
+}
+
+func (w *wishlistSvcImpl) Create(ctx context.Context, request *model.WishlistRequest) error {
+	ctx, span := tracing.StartService(ctx, "wishlist", "Create")
+	defer span.End()
+
+	return create(ctx, w.repos, request)
+}
+
+func create(ctx context.Context, repos *repository.Repositories, request *model.WishlistRequest) error {
+	if err := repos.Wishlist.Create(ctx, &model.WishlistEntity{
+		CustomerID:       request.CustomerID,
+		ProductID:        request.ProductID,
+		ProductVariantID: request.ProductVariantID,
+		Notify:           request.Notify,
+	}); err != nil {
+		if err == sql.ErrNoRows && request.ProductVariantID != 0 {
+			return model.NewNotFoundError("product_variant_not_found", fmt.Sprintf("product variant not found: %d", request.ProductVariantID))
+		}
+
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("product_not_found", fmt.Sprintf("product not found: %d", request.ProductID))
+		}
+
+		return fmt.Errorf("error creating wishlist: %w", err)
+	}
+
+	return nil
+}
+
+func (w *wishlistSvcImpl) GetByCustomerID(ctx context.Context, customerID int) ([]*model.WishlistResponse, error) {
+	ctx, span := tracing.StartService(ctx, "wishlist", "GetByCustomerID")
+	defer span.End()
+
+	wishlists, err := w.repos.Wishlist.GetByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting wishlist by customer id: %w", err)
+	}
+
+	wishlistsResponse := make([]*model.WishlistResponse, len(wishlists))
+	for i, wishlist := range wishlists {
+		wishlistsResponse[i] = wishlist.ToResponse()
+	}
+
+	return wishlistsResponse, nil
+}
+
+func (w *wishlistSvcImpl) Delete(ctx context.Context, customerID, productID, productVariantID int) error {
+	ctx, span := tracing.StartService(ctx, "wishlist", "Delete")
+	defer span.End()
+
+	if err := w.repos.Wishlist.Delete(ctx, customerID, productID, productVariantID); err != nil {
+		return fmt.Errorf("error deleting wishlist: %w", err)
+	}
+
+	return nil
+}
+
+func (w *wishlistSvcImpl) MoveToCart(ctx context.Context, request *model.MoveToCartRequest) (*model.CartResponse, error) {
+	ctx, span := tracing.StartService(ctx, "wishlist", "MoveToCart")
+	defer span.End()
+
+	// check wishlist existence. A product wishlisted without a variant can be
+	// moved to the cart as one of its variants.
+	wishlist, err := w.repos.Wishlist.GetByProductID(ctx, request.CustomerID, request.ProductID, request.ProductVariantID)
+	if err == sql.ErrNoRows && request.ProductVariantID != 0 {
+		wishlist, err = w.repos.Wishlist.GetByProductID(ctx, request.CustomerID, request.ProductID, 0)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewNotFoundError("wishlist_not_found", fmt.Sprintf("wishlist not found: %d", request.ProductID))
+		}
+
+		return nil, fmt.Errorf("error getting wishlist by product id: %w", err)
+	}
+
+	quantity := request.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	// the item is added to the cart and removed from the wishlist together.
+	// The cart service validates the product the same way as adding to cart
+	// directly.
+	var cart *model.CartResponse
+	err = w.repos.UnitOfWork.Do(ctx, func(repos *repository.Repositories) error {
+		moved, err := w.cartSvc(repos).Create(ctx, &model.CreateCartRequest{
+			CustomerID: request.CustomerID,
+			Items: []*model.CreateCartItemRequest{
+				{
+					ProductID:        request.ProductID,
+					ProductVariantID: request.ProductVariantID,
+					Quantity:         quantity,
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Wishlist.Delete(ctx, request.CustomerID, request.ProductID, wishlist.ProductVariantID); err != nil {
+			return fmt.Errorf("error deleting wishlist: %w", err)
+		}
+
+		cart = moved
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (w *wishlistSvcImpl) SaveForLater(ctx context.Context, request *model.SaveForLaterRequest) error {
+	ctx, span := tracing.StartService(ctx, "wishlist", "SaveForLater")
+	defer span.End()
+
+	// check cart item existence
+	cartItem, err := w.repos.Cart.GetItemByID(ctx, request.CartItemID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("cart_item_not_found", "cart item not found")
+		}
+
+		return fmt.Errorf("error getting cart item by id: %w", err)
+	}
+
+	// only the items of the customer's active cart can be saved for later
+	cart, err := w.repos.Cart.GetByID(ctx, cartItem.CartID)
+	if err != nil {
+		return fmt.Errorf("error getting cart by id: %w", err)
+	}
+
+	if cart.CustomerID != request.CustomerID {
+		return model.NewForbiddenError("cart_forbidden", "cart does not belong to the customer")
+	}
+
+	if cart.Status != cartEnum.CartStatusActive {
+		return model.NewUnprocessableError("cart_not_active", "cart is not active")
+	}
+
+	// the item is added to the wishlist and removed from the cart together
+	return w.repos.UnitOfWork.Do(ctx, func(repos *repository.Repositories) error {
+		if err := create(ctx, repos, &model.WishlistRequest{
+			CustomerID:       request.CustomerID,
+			ProductID:        cartItem.ProductID,
+			ProductVariantID: cartItem.ProductVariantID,
+			Notify:           request.Notify,
+		}); err != nil {
+			return err
+		}
+
+		return w.cartSvc(repos).Delete(ctx, &model.DeleteCartRequest{
+			CartItemID: cartItem.ID,
+			CustomerID: request.CustomerID,
+		})
+	})
+}
+
+func (w *wishlistSvcImpl) GetNotifications(ctx context.Context, customerID int) ([]*model.WishlistNotificationResponse, error) {
+	ctx, span := tracing.StartService(ctx, "wishlist", "GetNotifications")
+	defer span.End()
+
+	notifications, err := w.repos.Wishlist.GetNotifications(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting wishlist notifications: %w", err)
+	}
+
+	notificationsResponse := make([]*model.WishlistNotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		notificationsResponse[i] = notification.ToResponse()
+	}
+
+	return notificationsResponse, nil
+}
+
+// NotifyChanges creates a notification for every wishlisted product whose price dropped
+// or came back in stock since the last check, then stores the current values as the new snapshot.
+func (w *wishlistSvcImpl) NotifyChanges(ctx context.Context) error {
+	ctx, span := tracing.StartService(ctx, "wishlist", "NotifyChanges")
+	defer span.End()
+
+	wishlists, err := w.repos.Wishlist.GetNotifiable(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting notifiable wishlists: %w", err)
+	}
+
+	for _, wishlist := range wishlists {
+		if err := w.repos.Wishlist.UpdateSnapshot(ctx, wishlist, wishlist.Notifications()); err != nil {
+			slog.ErrorContext(ctx, "failed to notify wishlist changes", "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package wishlist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/zakiyalmaya/online-store/application/cart"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	wishlistEnum "github.com/zakiyalmaya/online-store/constant/wishlist"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	mockCartSvc "github.com/zakiyalmaya/online-store/mocks/application/cart"
+	mockRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository"
+	mockCartRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/cart"
+	mockWishlistRepo "github.com/zakiyalmaya/online-store/mocks/infrastructure/repository/wishlist"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+var (
+	mockWishlistRepository *mockWishlistRepo.MockRepository
+	mockCartRepository     *mockCartRepo.MockRepository
+	mockCartService        *mockCartSvc.MockService
+	mockUnitOfWork         *mockRepo.MockUnitOfWork
+	repos                  *repository.Repositories
+	wishlistSvc            Service
+)
+
+func Setup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWishlistRepository = mockWishlistRepo.NewMockRepository(ctrl)
+	mockCartRepository = mockCartRepo.NewMockRepository(ctrl)
+	mockCartService = mockCartSvc.NewMockService(ctrl)
+	mockUnitOfWork = mockRepo.NewMockUnitOfWork(ctrl)
+	repos = &repository.Repositories{
+		UnitOfWork: mockUnitOfWork,
+		Wishlist:   mockWishlistRepository,
+		Cart:       mockCartRepository,
+	}
+	wishlistSvc = NewWishlistService(repos, func(*repository.Repositories) cart.Service { return mockCartService })
+}
+
+// inTransaction runs the unit of work on the mocked repositories.
+func inTransaction(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	return fn(repos)
+}
+
+func TestCreate(t *testing.T) {
+	Setup(t)
+
+	request := &model.WishlistRequest{
+		CustomerID: 1,
+		ProductID:  1,
+		Notify:     true,
+	}
+
+	entity := &model.WishlistEntity{
+		CustomerID: 1,
+		ProductID:  1,
+		Notify:     true,
+	}
+
+	testCases := []struct {
+		name    string
+		request *model.WishlistRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when create wishlist then return success",
+			request: request,
+			mock: func() {
+				mockWishlistRepository.EXPECT().Create(gomock.Any(), entity).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given product not found when create wishlist then return error",
+			request: request,
+			mock: func() {
+				mockWishlistRepository.EXPECT().Create(gomock.Any(), entity).Return(sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := wishlistSvc.Create(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMoveToCart(t *testing.T) {
+	Setup(t)
+
+	request := &model.MoveToCartRequest{
+		CustomerID: 1,
+		ProductID:  1,
+	}
+
+	cartRequest := &model.CreateCartRequest{
+		CustomerID: 1,
+		Items: []*model.CreateCartItemRequest{
+			{
+				ProductID: 1,
+				Quantity:  1,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		request *model.MoveToCartRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when move to cart then return success",
+			request: request,
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 1, 0).Return(&model.WishlistEntity{ID: 1}, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartService.EXPECT().Create(gomock.Any(), cartRequest).Return(&model.CartResponse{ID: 1}, nil).Times(1)
+				mockWishlistRepository.EXPECT().Delete(gomock.Any(), 1, 1, 0).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given error delete wishlist when move to cart then roll back and return error",
+			request: request,
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 1, 0).Return(&model.WishlistEntity{ID: 1}, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartService.EXPECT().Create(gomock.Any(), cartRequest).Return(&model.CartResponse{ID: 1}, nil).Times(1)
+				mockWishlistRepository.EXPECT().Delete(gomock.Any(), 1, 1, 0).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given a variant of a product wishlisted without one when move to cart then move the variant",
+			request: &model.MoveToCartRequest{CustomerID: 1, ProductID: 1, ProductVariantID: 2},
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 1, 2).Return(nil, sql.ErrNoRows).Times(1)
+				mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 1, 0).Return(&model.WishlistEntity{ID: 1}, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartService.EXPECT().Create(gomock.Any(), &model.CreateCartRequest{
+					CustomerID: 1,
+					Items:      []*model.CreateCartItemRequest{{ProductID: 1, ProductVariantID: 2, Quantity: 1}},
+				}).Return(&model.CartResponse{ID: 1}, nil).Times(1)
+				mockWishlistRepository.EXPECT().Delete(gomock.Any(), 1, 1, 0).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given wishlist not found when move to cart then return error",
+			request: request,
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 1, 0).Return(nil, sql.ErrNoRows).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error create cart when move to cart then keep the wishlist and return error",
+			request: request,
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 1, 0).Return(&model.WishlistEntity{ID: 1}, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockCartService.EXPECT().Create(gomock.Any(), cartRequest).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			_, err := wishlistSvc.MoveToCart(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("MoveToCart() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveForLater(t *testing.T) {
+	Setup(t)
+
+	request := &model.SaveForLaterRequest{
+		CustomerID: 1,
+		CartItemID: 1,
+	}
+
+	cartItem := &model.CartItemEntity{
+		ID:        1,
+		CartID:    1,
+		ProductID: 2,
+	}
+
+	testCases := []struct {
+		name    string
+		request *model.SaveForLaterRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "Given valid request when save for later then return success",
+			request: request,
+			mock: func() {
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), 1).Return(cartItem, nil).Times(1)
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CartEntity{ID: 1, CustomerID: 1, Status: cartEnum.CartStatusActive}, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockWishlistRepository.EXPECT().Create(gomock.Any(), &model.WishlistEntity{CustomerID: 1, ProductID: 2}).Return(nil).Times(1)
+				mockCartService.EXPECT().Delete(gomock.Any(), &model.DeleteCartRequest{CartItemID: 1, CustomerID: 1}).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given error delete cart item when save for later then roll back and return error",
+			request: request,
+			mock: func() {
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), 1).Return(cartItem, nil).Times(1)
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CartEntity{ID: 1, CustomerID: 1, Status: cartEnum.CartStatusActive}, nil).Times(1)
+				mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(1)
+				mockWishlistRepository.EXPECT().Create(gomock.Any(), &model.WishlistEntity{CustomerID: 1, ProductID: 2}).Return(nil).Times(1)
+				mockCartService.EXPECT().Delete(gomock.Any(), &model.DeleteCartRequest{CartItemID: 1, CustomerID: 1}).Return(errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given cart of another customer when save for later then return error",
+			request: request,
+			mock: func() {
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), 1).Return(cartItem, nil).Times(1)
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CartEntity{ID: 1, CustomerID: 2, Status: cartEnum.CartStatusActive}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given inactive cart when save for later then return error",
+			request: request,
+			mock: func() {
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), 1).Return(cartItem, nil).Times(1)
+				mockCartRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CartEntity{ID: 1, CustomerID: 1, Status: cartEnum.CartStatusPending}, nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Given error get cart item when save for later then return error",
+			request: request,
+			mock: func() {
+				mockCartRepository.EXPECT().GetItemByID(gomock.Any(), 1).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := wishlistSvc.SaveForLater(context.Background(), tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("SaveForLater() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestSaveForLaterThenMoveToCart moves a variant from the cart to the
+// wishlist and back, the variant is kept by the wishlist.
+func TestSaveForLaterThenMoveToCart(t *testing.T) {
+	Setup(t)
+
+	var saved *model.WishlistEntity
+	mockCartRepository.EXPECT().GetItemByID(gomock.Any(), 1).Return(&model.CartItemEntity{ID: 1, CartID: 1, ProductID: 2, ProductVariantID: 3}, nil).Times(1)
+	mockCartRepository.EXPECT().GetByID(gomock.Any(), 1).Return(&model.CartEntity{ID: 1, CustomerID: 1, Status: cartEnum.CartStatusActive}, nil).Times(1)
+	mockUnitOfWork.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(inTransaction).Times(2)
+	mockWishlistRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, wishlist *model.WishlistEntity) error {
+		saved = wishlist
+		return nil
+	}).Times(1)
+	mockCartService.EXPECT().Delete(gomock.Any(), &model.DeleteCartRequest{CartItemID: 1, CustomerID: 1}).Return(nil).Times(1)
+
+	if err := wishlistSvc.SaveForLater(context.Background(), &model.SaveForLaterRequest{CustomerID: 1, CartItemID: 1}); err != nil {
+		t.Fatalf("SaveForLater() error = %v", err)
+	}
+
+	if saved == nil || saved.ProductVariantID != 3 {
+		t.Fatalf("SaveForLater() saved %+v, want the variant of the cart item", saved)
+	}
+
+	mockWishlistRepository.EXPECT().GetByProductID(gomock.Any(), 1, 2, 3).Return(saved, nil).Times(1)
+	mockCartService.EXPECT().Create(gomock.Any(), &model.CreateCartRequest{
+		CustomerID: 1,
+		Items:      []*model.CreateCartItemRequest{{ProductID: 2, ProductVariantID: 3, Quantity: 1}},
+	}).Return(&model.CartResponse{ID: 1}, nil).Times(1)
+	mockWishlistRepository.EXPECT().Delete(gomock.Any(), 1, 2, 3).Return(nil).Times(1)
+
+	if _, err := wishlistSvc.MoveToCart(context.Background(), &model.MoveToCartRequest{CustomerID: 1, ProductID: saved.ProductID, ProductVariantID: saved.ProductVariantID}); err != nil {
+		t.Errorf("MoveToCart() error = %v", err)
+	}
+}
+
+func TestNotifyChanges(t *testing.T) {
+	Setup(t)
+
+	wishlist := &model.WishlistEntity{
+		ID:            1,
+		CustomerID:    1,
+		ProductID:     1,
+		LastPrice:     decimal.NewFromFloat(10000),
+		LastStock:     0,
+		Price:         decimal.NewFromFloat(9000),
+		StockQuantity: 5,
+	}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given price drop and restock when notify changes then create both notifications",
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetNotifiable(gomock.Any()).Return([]*model.WishlistEntity{wishlist}, nil).Times(1)
+				mockWishlistRepository.EXPECT().UpdateSnapshot(gomock.Any(), wishlist, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ *model.WishlistEntity, notifications []*model.WishlistNotificationEntity) error {
+						if len(notifications) != 2 ||
+							notifications[0].Type != wishlistEnum.NotificationTypePriceDrop ||
+							notifications[1].Type != wishlistEnum.NotificationTypeBackInStock {
+							t.Errorf("NotifyChanges() unexpected notifications %v", notifications)
+						}
+						return nil
+					}).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error get notifiable wishlists when notify changes then return error",
+			mock: func() {
+				mockWishlistRepository.EXPECT().GetNotifiable(gomock.Any()).Return(nil, errors.New("error")).Times(1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			err := wishlistSvc.NotifyChanges(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("NotifyChanges() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
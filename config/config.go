@@ -1,8 +1,844 @@
 package config
 
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ProfileDocker     = "docker"
+	ProfileLocal      = "local"
+	ProfileProduction = "production"
+
+	// defaultSecretKey is only good enough for running the app on a laptop,
+	// every profile but local refuses to start with it
+	defaultSecretKey = "online-store-secret"
+	minSecretKeySize = 32
+
+	// MaxPasswordLength is the most bcrypt hashes, longer passwords are refused
+	// instead of silently truncated.
+	MaxPasswordLength = 72
+)
+
+type Config struct {
+	Profile    string           `yaml:"profile"`
+	App        AppConfig        `yaml:"app"`
+	Log        LogConfig        `yaml:"log"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Database   DatabaseConfig   `yaml:"database"`
+	SQLite     SQLiteConfig     `yaml:"sqlite"`
+	Redis      RedisConfig      `yaml:"redis"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Pagination PaginationConfig `yaml:"pagination"`
+	Media      MediaConfig      `yaml:"media"`
+	GuestCart  GuestCartConfig  `yaml:"guest_cart"`
+	Wishlist   WishlistConfig   `yaml:"wishlist"`
+	Mailer     MailerConfig     `yaml:"mailer"`
+	Account    AccountConfig    `yaml:"account"`
+	Password   PasswordConfig   `yaml:"password"`
+	Login      LoginConfig      `yaml:"login"`
+	TwoFactor  TwoFactorConfig  `yaml:"two_factor"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+}
+
+// AppConfig is the HTTP server. RequestTimeout cancels the SQL and Redis
+// work of a request taking longer, ShutdownTimeout is how long the requests
+// in flight get to finish once the app is asked to stop. MetricsPort serves
+// /metrics apart from the public routes, keep it reachable from Prometheus only.
+// Behind a reverse proxy, ProxyHeader, e.g. X-Forwarded-For, holds the IP
+// address of the client, read only from the TrustedProxies, IP addresses or
+// CIDR ranges: anybody else could send the header.
+type AppConfig struct {
+	Port            string        `yaml:"port"`
+	MetricsPort     string        `yaml:"metrics_port"`
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	ProxyHeader     string        `yaml:"proxy_header"`
+	TrustedProxies  []string      `yaml:"trusted_proxies"`
+}
+
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LogConfig is the lowest Level written and the Format of the lines, JSON
+// for log collectors or text for reading on a terminal.
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// TracingConfig picks where the spans go, none, stdout for reading them
+// locally or OTLP over HTTP to Endpoint, e.g. http://otel-collector:4318/v1/traces.
+// SampleRatio of the traces started by the app are kept, a trace started by
+// the caller is kept when the caller kept it.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
 const (
-	APP_PORT = ":3000"
-	SQLITE_DB = "/app/online_store.db"
-	REDIS_HOST = "redis"
-	REDIS_PORT = "6379"
-)
\ No newline at end of file
+	DatabaseDriverSQLite   = "sqlite3"
+	DatabaseDriverPostgres = "postgres"
+	DatabaseDriverMySQL    = "mysql"
+)
+
+// DatabaseConfig picks the database, SQLite opens sqlite.path and the others
+// connect to DSN. AutoMigrate applies the pending migrations on start, turn it
+// off to run them with the migrate command instead.
+type DatabaseConfig struct {
+	Driver      string `yaml:"driver"`
+	DSN         string `yaml:"dsn"`
+	AutoMigrate bool   `yaml:"auto_migrate"`
+}
+
+type SQLiteConfig struct {
+	Path string `yaml:"path"`
+}
+
+type RedisConfig struct {
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+}
+
+// AuthConfig holds the lifetime of both tokens, the access token is short lived
+// and the session, renewed by every refresh, lives until it goes unused for SessionTTL.
+// Access tokens are signed with the ActiveKeyID key of KeyDir, or with SecretKey
+// when KeyDir is not set, and only accepted with our Issuer and Audience.
+type AuthConfig struct {
+	SecretKey      string        `yaml:"secret_key"`
+	KeyDir         string        `yaml:"key_dir"`
+	ActiveKeyID    string        `yaml:"active_key_id"`
+	Issuer         string        `yaml:"issuer"`
+	Audience       string        `yaml:"audience"`
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl"`
+	SessionTTL     time.Duration `yaml:"session_ttl"`
+}
+
+type PaginationConfig struct {
+	DefaultLimit int `yaml:"default_limit"`
+}
+
+type MediaConfig struct {
+	Dir            string `yaml:"dir"`
+	URL            string `yaml:"url"`
+	MaxImageSize   int    `yaml:"max_image_size"`
+	MaxImagePixels int    `yaml:"max_image_pixels"`
+	ThumbnailSize  int    `yaml:"thumbnail_size"`
+}
+
+type GuestCartConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type WishlistConfig struct {
+	NotifyInterval time.Duration `yaml:"notify_interval"`
+}
+
+const (
+	MailerDriverSMTP   = "smtp"
+	MailerDriverFile   = "file"
+	MailerDriverMemory = "memory"
+)
+
+// MailerConfig picks how emails go out, file writes every email to Dir so
+// the links can be followed without a mail server.
+type MailerConfig struct {
+	Driver string     `yaml:"driver"`
+	From   string     `yaml:"from"`
+	Dir    string     `yaml:"dir"`
+	SMTP   SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// AccountConfig holds the lifetime of the mailed tokens and the LinkURL the
+// token is appended to, RequireVerifiedEmail blocks checkout until the
+// customer verified their email.
+type AccountConfig struct {
+	LinkURL              string        `yaml:"link_url"`
+	VerificationTTL      time.Duration `yaml:"verification_ttl"`
+	PasswordResetTTL     time.Duration `yaml:"password_reset_ttl"`
+	RequireVerifiedEmail bool          `yaml:"require_verified_email"`
+}
+
+// PasswordConfig is the policy of new passwords, existing passwords keep
+// working when it gets stricter.
+type PasswordConfig struct {
+	MinLength     int  `yaml:"min_length"`
+	RequireUpper  bool `yaml:"require_upper"`
+	RequireLower  bool `yaml:"require_lower"`
+	RequireDigit  bool `yaml:"require_digit"`
+	RequireSymbol bool `yaml:"require_symbol"`
+}
+
+// LoginConfig slows down failed logins, each failure of a username doubling the
+// delay from BaseDelay up to MaxDelay, and locks a username or an IP address out
+// for LockoutDuration once it failed MaxUserFailures or MaxIPFailures times
+// within FailureWindow.
+type LoginConfig struct {
+	MaxUserFailures int           `yaml:"max_user_failures"`
+	MaxIPFailures   int           `yaml:"max_ip_failures"`
+	FailureWindow   time.Duration `yaml:"failure_window"`
+	LockoutDuration time.Duration `yaml:"lockout_duration"`
+	BaseDelay       time.Duration `yaml:"base_delay"`
+	MaxDelay        time.Duration `yaml:"max_delay"`
+}
+
+// TwoFactorConfig holds the Issuer authenticator apps list the account under
+// and how long a login has to answer its two-factor challenge.
+type TwoFactorConfig struct {
+	Issuer        string        `yaml:"issuer"`
+	ChallengeTTL  time.Duration `yaml:"challenge_ttl"`
+	RecoveryCodes int           `yaml:"recovery_codes"`
+}
+
+const (
+	RateLimitAlgorithmTokenBucket   = "token_bucket"
+	RateLimitAlgorithmSlidingWindow = "sliding_window"
+)
+
+// RateLimitPolicy lets Limit requests through per Window.
+type RateLimitPolicy struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+// RateLimitConfig limits the requests of each customer, API key or, for the
+// requests without either, IP address. The Routes listed by method and route,
+// e.g. "POST /customer/login", each have their own quota, every other route
+// shares the Default one. The routes needing a login or an API key also take
+// from the PreAuth quota of the IP address before the credentials are checked,
+// so the requests failing the auth are counted too. A token bucket lets a burst of Limit requests through
+// and refills steadily, a sliding window counts the requests of the last
+// Window. While Redis is down the requests are let through with FailOpen,
+// refused otherwise.
+type RateLimitConfig struct {
+	Enabled   bool                       `yaml:"enabled"`
+	Algorithm string                     `yaml:"algorithm"`
+	FailOpen  bool                       `yaml:"fail_open"`
+	Default   RateLimitPolicy            `yaml:"default"`
+	PreAuth   RateLimitPolicy            `yaml:"pre_auth"`
+	Routes    map[string]RateLimitPolicy `yaml:"routes"`
+}
+
+// Default is the configuration of the docker-compose setup.
+func Default() *Config {
+	return &Config{
+		Profile: ProfileDocker,
+		App: AppConfig{
+			Port:            ":3000",
+			MetricsPort:     ":9090",
+			RequestTimeout:  30 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  LogLevelInfo,
+			Format: LogFormatJSON,
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			ServiceName: "online-store",
+			SampleRatio: 1,
+		},
+		Database: DatabaseConfig{
+			Driver:      DatabaseDriverSQLite,
+			AutoMigrate: true,
+		},
+		SQLite: SQLiteConfig{
+			Path: "/app/online_store.db",
+		},
+		Redis: RedisConfig{
+			Host: "redis",
+			Port: "6379",
+		},
+		Auth: AuthConfig{
+			SecretKey:      defaultSecretKey,
+			Issuer:         "online-store",
+			Audience:       "online-store",
+			AccessTokenTTL: 10 * time.Minute,
+			SessionTTL:     30 * 24 * time.Hour,
+		},
+		Pagination: PaginationConfig{
+			DefaultLimit: 10,
+		},
+		Media: MediaConfig{
+			Dir:            "/app/media",
+			URL:            "/media",
+			MaxImageSize:   2 << 20,
+			MaxImagePixels: 25_000_000,
+			ThumbnailSize:  200,
+		},
+		GuestCart: GuestCartConfig{
+			TTL: 7 * 24 * time.Hour,
+		},
+		Wishlist: WishlistConfig{
+			NotifyInterval: time.Minute,
+		},
+		Mailer: MailerConfig{
+			Driver: MailerDriverFile,
+			From:   "Online Store <no-reply@online-store.local>",
+			Dir:    "/app/mail",
+			SMTP: SMTPConfig{
+				Port: "587",
+			},
+		},
+		Account: AccountConfig{
+			LinkURL:          "http://localhost:3000",
+			VerificationTTL:  48 * time.Hour,
+			PasswordResetTTL: time.Hour,
+		},
+		Password: PasswordConfig{
+			MinLength:    8,
+			RequireUpper: true,
+			RequireLower: true,
+			RequireDigit: true,
+		},
+		Login: LoginConfig{
+			MaxUserFailures: 5,
+			MaxIPFailures:   20,
+			FailureWindow:   15 * time.Minute,
+			LockoutDuration: 15 * time.Minute,
+			BaseDelay:       250 * time.Millisecond,
+			MaxDelay:        4 * time.Second,
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:        "Online Store",
+			ChallengeTTL:  5 * time.Minute,
+			RecoveryCodes: 10,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   true,
+			Algorithm: RateLimitAlgorithmTokenBucket,
+			FailOpen:  true,
+			Default:   RateLimitPolicy{Limit: 300, Window: time.Minute},
+			PreAuth:   RateLimitPolicy{Limit: 600, Window: time.Minute},
+			Routes: map[string]RateLimitPolicy{
+				"POST /customer":                    {Limit: 20, Window: time.Hour},
+				"POST /customer/login":              {Limit: 10, Window: time.Minute},
+				"POST /customer/login/2fa":          {Limit: 10, Window: time.Minute},
+				"POST /customer/email/verification": {Limit: 5, Window: 15 * time.Minute},
+				"POST /customer/password/forgot":    {Limit: 5, Window: 15 * time.Minute},
+				"POST /transaction":                 {Limit: 30, Window: time.Minute},
+			},
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, then the config file, then
+// the environment variables, each one overriding the previous. The file is
+// CONFIG_FILE when set, otherwise config/<APP_PROFILE>.yaml or .json if it exists.
+func Load() (*Config, error) {
+	cfg := Default()
+	if profile := os.Getenv("APP_PROFILE"); profile != "" {
+		cfg.Profile = profile
+	}
+
+	file, err := configFile(cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		if err := cfg.loadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func configFile(profile string) (string, error) {
+	if file := os.Getenv("CONFIG_FILE"); file != "" {
+		return file, nil
+	}
+
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		file := filepath.Join("config", profile+ext)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("error reading config file %s: %v", file, err)
+		}
+	}
+
+	return "", nil
+}
+
+// loadFile reads both YAML and JSON files, JSON being a subset of YAML.
+func (c *Config) loadFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading config file %s: %v", file, err)
+	}
+
+	// the profile picked the file, the file does not get to change it
+	profile := c.Profile
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("error parsing config file %s: %v", file, err)
+	}
+	c.Profile = profile
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString("APP_PORT", &c.App.Port)
+	setString("APP_METRICS_PORT", &c.App.MetricsPort)
+	setString("APP_PROXY_HEADER", &c.App.ProxyHeader)
+	setStrings("APP_TRUSTED_PROXIES", &c.App.TrustedProxies)
+	setString("LOG_LEVEL", &c.Log.Level)
+	setString("LOG_FORMAT", &c.Log.Format)
+	setString("TRACING_EXPORTER", &c.Tracing.Exporter)
+	setString("TRACING_ENDPOINT", &c.Tracing.Endpoint)
+	setString("TRACING_SERVICE_NAME", &c.Tracing.ServiceName)
+	setString("DB_DRIVER", &c.Database.Driver)
+	setString("DB_DSN", &c.Database.DSN)
+	setString("SQLITE_DB", &c.SQLite.Path)
+	setString("REDIS_HOST", &c.Redis.Host)
+	setString("REDIS_PORT", &c.Redis.Port)
+	setString("JWT_SECRET_KEY", &c.Auth.SecretKey)
+	setString("JWT_KEY_DIR", &c.Auth.KeyDir)
+	setString("JWT_ACTIVE_KEY_ID", &c.Auth.ActiveKeyID)
+	setString("JWT_ISSUER", &c.Auth.Issuer)
+	setString("JWT_AUDIENCE", &c.Auth.Audience)
+	setString("MEDIA_DIR", &c.Media.Dir)
+	setString("MEDIA_URL", &c.Media.URL)
+	setString("MAILER_DRIVER", &c.Mailer.Driver)
+	setString("MAILER_FROM", &c.Mailer.From)
+	setString("MAILER_DIR", &c.Mailer.Dir)
+	setString("SMTP_HOST", &c.Mailer.SMTP.Host)
+	setString("SMTP_PORT", &c.Mailer.SMTP.Port)
+	setString("SMTP_USERNAME", &c.Mailer.SMTP.Username)
+	setString("SMTP_PASSWORD", &c.Mailer.SMTP.Password)
+	setString("ACCOUNT_LINK_URL", &c.Account.LinkURL)
+	setString("TWO_FACTOR_ISSUER", &c.TwoFactor.Issuer)
+	setString("RATE_LIMIT_ALGORITHM", &c.RateLimit.Algorithm)
+
+	for name, value := range map[string]*int{
+		"DEFAULT_PAGE_SIZE":         &c.Pagination.DefaultLimit,
+		"MEDIA_MAX_IMAGE_SIZE":      &c.Media.MaxImageSize,
+		"MEDIA_MAX_IMAGE_PIXELS":    &c.Media.MaxImagePixels,
+		"MEDIA_THUMBNAIL_SIZE":      &c.Media.ThumbnailSize,
+		"PASSWORD_MIN_LENGTH":       &c.Password.MinLength,
+		"LOGIN_MAX_USER_FAILURES":   &c.Login.MaxUserFailures,
+		"LOGIN_MAX_IP_FAILURES":     &c.Login.MaxIPFailures,
+		"TWO_FACTOR_RECOVERY_CODES": &c.TwoFactor.RecoveryCodes,
+		"RATE_LIMIT_DEFAULT_LIMIT":  &c.RateLimit.Default.Limit,
+		"RATE_LIMIT_PRE_AUTH_LIMIT": &c.RateLimit.PreAuth.Limit,
+	} {
+		if err := setInt(name, value); err != nil {
+			return err
+		}
+	}
+
+	if err := setFloat("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio); err != nil {
+		return err
+	}
+
+	for name, value := range map[string]*time.Duration{
+		"APP_REQUEST_TIMEOUT":        &c.App.RequestTimeout,
+		"APP_SHUTDOWN_TIMEOUT":       &c.App.ShutdownTimeout,
+		"ACCESS_TOKEN_TTL":           &c.Auth.AccessTokenTTL,
+		"SESSION_TTL":                &c.Auth.SessionTTL,
+		"GUEST_CART_TTL":             &c.GuestCart.TTL,
+		"WISHLIST_NOTIFY_INTERVAL":   &c.Wishlist.NotifyInterval,
+		"EMAIL_VERIFICATION_TTL":     &c.Account.VerificationTTL,
+		"PASSWORD_RESET_TTL":         &c.Account.PasswordResetTTL,
+		"LOGIN_FAILURE_WINDOW":       &c.Login.FailureWindow,
+		"LOGIN_LOCKOUT_DURATION":     &c.Login.LockoutDuration,
+		"LOGIN_BASE_DELAY":           &c.Login.BaseDelay,
+		"LOGIN_MAX_DELAY":            &c.Login.MaxDelay,
+		"TWO_FACTOR_CHALLENGE_TTL":   &c.TwoFactor.ChallengeTTL,
+		"RATE_LIMIT_DEFAULT_WINDOW":  &c.RateLimit.Default.Window,
+		"RATE_LIMIT_PRE_AUTH_WINDOW": &c.RateLimit.PreAuth.Window,
+	} {
+		if err := setDuration(name, value); err != nil {
+			return err
+		}
+	}
+
+	// SQLITE_AUTO_MIGRATE is the name DB_AUTO_MIGRATE had before PostgreSQL and
+	// MySQL were supported, read first so DB_AUTO_MIGRATE wins when both are set
+	if err := setBool("SQLITE_AUTO_MIGRATE", &c.Database.AutoMigrate); err != nil {
+		return err
+	}
+
+	for name, value := range map[string]*bool{
+		"DB_AUTO_MIGRATE":         &c.Database.AutoMigrate,
+		"REQUIRE_VERIFIED_EMAIL":  &c.Account.RequireVerifiedEmail,
+		"PASSWORD_REQUIRE_UPPER":  &c.Password.RequireUpper,
+		"PASSWORD_REQUIRE_LOWER":  &c.Password.RequireLower,
+		"PASSWORD_REQUIRE_DIGIT":  &c.Password.RequireDigit,
+		"PASSWORD_REQUIRE_SYMBOL": &c.Password.RequireSymbol,
+		"RATE_LIMIT_ENABLED":      &c.RateLimit.Enabled,
+		"RATE_LIMIT_FAIL_OPEN":    &c.RateLimit.FailOpen,
+	} {
+		if err := setBool(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once so a bad deploy fails with the full list.
+func (c *Config) Validate() error {
+	errs := make([]string, 0)
+
+	switch c.Profile {
+	case ProfileDocker, ProfileLocal, ProfileProduction:
+	default:
+		errs = append(errs, fmt.Sprintf("profile must be one of %s, %s, %s", ProfileDocker, ProfileLocal, ProfileProduction))
+	}
+
+	if !strings.HasPrefix(c.App.Port, ":") {
+		errs = append(errs, "app.port must look like :3000")
+	} else if port, err := strconv.Atoi(c.App.Port[1:]); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, "app.port must be between 1 and 65535")
+	}
+
+	if !strings.HasPrefix(c.App.MetricsPort, ":") {
+		errs = append(errs, "app.metrics_port must look like :9090")
+	} else if port, err := strconv.Atoi(c.App.MetricsPort[1:]); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, "app.metrics_port must be between 1 and 65535")
+	} else if c.App.MetricsPort == c.App.Port {
+		errs = append(errs, "app.metrics_port must differ from app.port")
+	}
+
+	if c.App.ProxyHeader != "" && len(c.App.TrustedProxies) == 0 {
+		errs = append(errs, "app.trusted_proxies must list the proxies sending app.proxy_header")
+	}
+
+	for _, proxy := range c.App.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Sprintf("app.trusted_proxies %q must be an IP address or a CIDR range", proxy))
+			}
+		}
+	}
+
+	if c.App.RequestTimeout <= 0 {
+		errs = append(errs, "app.request_timeout must be positive")
+	}
+
+	if c.App.ShutdownTimeout <= 0 {
+		errs = append(errs, "app.shutdown_timeout must be positive")
+	}
+
+	switch c.Log.Level {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		errs = append(errs, fmt.Sprintf("log.level must be one of %s, %s, %s, %s", LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError))
+	}
+
+	switch c.Log.Format {
+	case LogFormatJSON, LogFormatText:
+	default:
+		errs = append(errs, fmt.Sprintf("log.format must be one of %s, %s", LogFormatJSON, LogFormatText))
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterOTLP:
+		if !strings.HasPrefix(c.Tracing.Endpoint, "http://") && !strings.HasPrefix(c.Tracing.Endpoint, "https://") {
+			errs = append(errs, "tracing.endpoint must be an http or https URL with the otlp exporter")
+		}
+	case TracingExporterNone, TracingExporterStdout:
+	default:
+		errs = append(errs, fmt.Sprintf("tracing.exporter must be one of %s, %s, %s", TracingExporterNone, TracingExporterStdout, TracingExporterOTLP))
+	}
+
+	if c.Tracing.ServiceName == "" {
+		errs = append(errs, "tracing.service_name is required")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "tracing.sample_ratio must be between 0 and 1")
+	}
+
+	switch c.Database.Driver {
+	case DatabaseDriverSQLite:
+		if c.SQLite.Path == "" {
+			errs = append(errs, "sqlite.path is required with the sqlite3 driver")
+		}
+	case DatabaseDriverPostgres, DatabaseDriverMySQL:
+		if c.Database.DSN == "" {
+			errs = append(errs, fmt.Sprintf("database.dsn is required with the %s driver", c.Database.Driver))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("database.driver must be one of %s, %s, %s", DatabaseDriverSQLite, DatabaseDriverPostgres, DatabaseDriverMySQL))
+	}
+
+	if c.Redis.Host == "" {
+		errs = append(errs, "redis.host is required")
+	}
+
+	if port, err := strconv.Atoi(c.Redis.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, "redis.port must be between 1 and 65535")
+	}
+
+	if c.Auth.KeyDir != "" {
+		if c.Auth.ActiveKeyID == "" {
+			errs = append(errs, "auth.active_key_id is required with auth.key_dir")
+		}
+	} else if c.Auth.SecretKey == "" {
+		errs = append(errs, "auth.secret_key is required without auth.key_dir")
+	} else if c.Profile != ProfileLocal && (c.Auth.SecretKey == defaultSecretKey || len(c.Auth.SecretKey) < minSecretKeySize) {
+		errs = append(errs, fmt.Sprintf("auth.secret_key must be set to at least %d characters outside the local profile", minSecretKeySize))
+	}
+
+	if c.Auth.Issuer == "" {
+		errs = append(errs, "auth.issuer is required")
+	}
+
+	if c.Auth.Audience == "" {
+		errs = append(errs, "auth.audience is required")
+	}
+
+	if c.Auth.AccessTokenTTL <= 0 {
+		errs = append(errs, "auth.access_token_ttl must be positive")
+	}
+
+	if c.Auth.SessionTTL <= c.Auth.AccessTokenTTL {
+		errs = append(errs, "auth.session_ttl must be longer than auth.access_token_ttl")
+	}
+
+	if c.Pagination.DefaultLimit <= 0 {
+		errs = append(errs, "pagination.default_limit must be positive")
+	}
+
+	if c.Media.Dir == "" {
+		errs = append(errs, "media.dir is required")
+	}
+
+	if !strings.HasPrefix(c.Media.URL, "/") {
+		errs = append(errs, "media.url must start with /")
+	}
+
+	if c.Media.MaxImageSize <= 0 {
+		errs = append(errs, "media.max_image_size must be positive")
+	}
+
+	if c.Media.MaxImagePixels <= 0 {
+		errs = append(errs, "media.max_image_pixels must be positive")
+	}
+
+	if c.Media.ThumbnailSize <= 0 {
+		errs = append(errs, "media.thumbnail_size must be positive")
+	}
+
+	if c.GuestCart.TTL <= 0 {
+		errs = append(errs, "guest_cart.ttl must be positive")
+	}
+
+	if c.Wishlist.NotifyInterval <= 0 {
+		errs = append(errs, "wishlist.notify_interval must be positive")
+	}
+
+	switch c.Mailer.Driver {
+	case MailerDriverSMTP:
+		if c.Mailer.SMTP.Host == "" {
+			errs = append(errs, "mailer.smtp.host is required with the smtp driver")
+		}
+		if port, err := strconv.Atoi(c.Mailer.SMTP.Port); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, "mailer.smtp.port must be between 1 and 65535")
+		}
+	case MailerDriverFile:
+		if c.Mailer.Dir == "" {
+			errs = append(errs, "mailer.dir is required with the file driver")
+		}
+	case MailerDriverMemory:
+	default:
+		errs = append(errs, fmt.Sprintf("mailer.driver must be one of %s, %s, %s", MailerDriverSMTP, MailerDriverFile, MailerDriverMemory))
+	}
+
+	if c.Mailer.From == "" {
+		errs = append(errs, "mailer.from is required")
+	}
+
+	if !strings.HasPrefix(c.Account.LinkURL, "http://") && !strings.HasPrefix(c.Account.LinkURL, "https://") {
+		errs = append(errs, "account.link_url must be an http or https URL")
+	}
+
+	if c.Account.VerificationTTL <= 0 {
+		errs = append(errs, "account.verification_ttl must be positive")
+	}
+
+	if c.Account.PasswordResetTTL <= 0 {
+		errs = append(errs, "account.password_reset_ttl must be positive")
+	}
+
+	// bcrypt ignores everything after 72 bytes
+	if c.Password.MinLength < 8 || c.Password.MinLength > MaxPasswordLength {
+		errs = append(errs, fmt.Sprintf("password.min_length must be between 8 and %d", MaxPasswordLength))
+	}
+
+	if c.Login.MaxUserFailures <= 0 || c.Login.MaxIPFailures <= 0 {
+		errs = append(errs, "login.max_user_failures and login.max_ip_failures must be positive")
+	}
+
+	if c.Login.FailureWindow <= 0 || c.Login.LockoutDuration <= 0 {
+		errs = append(errs, "login.failure_window and login.lockout_duration must be positive")
+	}
+
+	if c.Login.BaseDelay < 0 || c.Login.MaxDelay < c.Login.BaseDelay {
+		errs = append(errs, "login.base_delay must not be negative nor longer than login.max_delay")
+	}
+
+	if c.TwoFactor.Issuer == "" {
+		errs = append(errs, "two_factor.issuer is required")
+	}
+
+	if c.TwoFactor.ChallengeTTL <= 0 {
+		errs = append(errs, "two_factor.challenge_ttl must be positive")
+	}
+
+	if c.TwoFactor.RecoveryCodes <= 0 {
+		errs = append(errs, "two_factor.recovery_codes must be positive")
+	}
+
+	switch c.RateLimit.Algorithm {
+	case RateLimitAlgorithmTokenBucket, RateLimitAlgorithmSlidingWindow:
+	default:
+		errs = append(errs, fmt.Sprintf("rate_limit.algorithm must be one of %s, %s", RateLimitAlgorithmTokenBucket, RateLimitAlgorithmSlidingWindow))
+	}
+
+	if !c.RateLimit.Default.valid() {
+		errs = append(errs, "rate_limit.default limit and window must be positive")
+	}
+
+	if !c.RateLimit.PreAuth.valid() {
+		errs = append(errs, "rate_limit.pre_auth limit and window must be positive")
+	}
+
+	for route, policy := range c.RateLimit.Routes {
+		if method, path, ok := strings.Cut(route, " "); !ok || method == "" || !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Sprintf("rate_limit.routes %q must look like POST /customer/login", route))
+		} else if !policy.valid() {
+			errs = append(errs, fmt.Sprintf("rate_limit.routes %q limit and window must be positive", route))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// valid windows are at least a millisecond, the precision of the limiter.
+func (r RateLimitPolicy) valid() bool {
+	return r.Limit > 0 && r.Window >= time.Millisecond
+}
+
+func setString(name string, value *string) {
+	if env, ok := os.LookupEnv(name); ok {
+		*value = env
+	}
+}
+
+// setStrings reads a comma separated list, e.g. 10.0.0.1,10.1.0.0/16.
+func setStrings(name string, value *[]string) {
+	if env, ok := os.LookupEnv(name); ok {
+		*value = make([]string, 0)
+		for _, item := range strings.Split(env, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*value = append(*value, item)
+			}
+		}
+	}
+}
+
+func setInt(name string, value *int) error {
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(env)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	*value = parsed
+
+	return nil
+}
+
+func setFloat(name string, value *float64) error {
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseFloat(env, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	*value = parsed
+
+	return nil
+}
+
+func setDuration(name string, value *time.Duration) error {
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(env)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	*value = parsed
+
+	return nil
+}
+
+func setBool(name string, value *bool) error {
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(env)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	*value = parsed
+
+	return nil
+}
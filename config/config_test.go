@@ -0,0 +1,392 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testSecretKey is long enough for every profile.
+const testSecretKey = "0123456789abcdef0123456789abcdef"
+
+func writeFile(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	yamlFile := writeFile(t, "config.yaml", "redis:\n  host: localhost\nauth:\n  session_ttl: 30m\npagination:\n  default_limit: 20\n")
+	jsonFile := writeFile(t, "config.json", `{"sqlite": {"path": "./store.db"}, "guest_cart": {"ttl": "48h"}}`)
+	rateLimitFile := writeFile(t, "rate_limit.yaml", "rate_limit:\n  algorithm: sliding_window\n  routes:\n    GET /products:\n      limit: 50\n      window: 1m\n")
+
+	testCases := []struct {
+		name    string
+		env     map[string]string
+		check   func(cfg *Config) bool
+		wantErr bool
+	}{
+		{
+			name: "Given no file and no env when load then return defaults",
+			env:  map[string]string{},
+			check: func(cfg *Config) bool {
+				want := Default()
+				want.Auth.SecretKey = testSecretKey
+				return reflect.DeepEqual(cfg, want)
+			},
+		},
+		{
+			name: "Given yaml file when load then override the defaults",
+			env:  map[string]string{"CONFIG_FILE": yamlFile},
+			check: func(cfg *Config) bool {
+				return cfg.Redis.Host == "localhost" && cfg.Auth.SessionTTL == 30*time.Minute && cfg.Pagination.DefaultLimit == 20 && cfg.Redis.Port == "6379"
+			},
+		},
+		{
+			name: "Given json file when load then override the defaults",
+			env:  map[string]string{"CONFIG_FILE": jsonFile},
+			check: func(cfg *Config) bool {
+				return cfg.SQLite.Path == "./store.db" && cfg.GuestCart.TTL == 48*time.Hour
+			},
+		},
+		{
+			name: "Given file and env when load then env wins",
+			env:  map[string]string{"CONFIG_FILE": yamlFile, "REDIS_HOST": "cache", "SESSION_TTL": "1h", "DEFAULT_PAGE_SIZE": "5"},
+			check: func(cfg *Config) bool {
+				return cfg.Redis.Host == "cache" && cfg.Auth.SessionTTL == time.Hour && cfg.Pagination.DefaultLimit == 5
+			},
+		},
+		{
+			name:    "Given invalid duration in env when load then return error",
+			env:     map[string]string{"SESSION_TTL": "ten minutes"},
+			wantErr: true,
+		},
+		{
+			name:    "Given missing config file when load then return error",
+			env:     map[string]string{"CONFIG_FILE": filepath.Join(t.TempDir(), "missing.yaml")},
+			wantErr: true,
+		},
+		{
+			name:    "Given docker profile with the default secret when load then return error",
+			env:     map[string]string{"APP_PROFILE": ProfileDocker, "JWT_SECRET_KEY": defaultSecretKey},
+			wantErr: true,
+		},
+		{
+			name:    "Given production profile with a short secret when load then return error",
+			env:     map[string]string{"APP_PROFILE": ProfileProduction, "JWT_SECRET_KEY": "0123456789abcdef"},
+			wantErr: true,
+		},
+		{
+			name: "Given production profile with a strong secret when load then return success",
+			env:  map[string]string{"APP_PROFILE": ProfileProduction},
+			check: func(cfg *Config) bool {
+				return cfg.Profile == ProfileProduction
+			},
+		},
+		{
+			name: "Given local profile with the default secret when load then return success",
+			env:  map[string]string{"APP_PROFILE": ProfileLocal, "JWT_SECRET_KEY": defaultSecretKey},
+			check: func(cfg *Config) bool {
+				return cfg.Profile == ProfileLocal
+			},
+		},
+		{
+			name: "Given mailer, account and password env when load then override the defaults",
+			env:  map[string]string{"MAILER_DRIVER": MailerDriverSMTP, "SMTP_HOST": "smtp.example.com", "REQUIRE_VERIFIED_EMAIL": "true", "PASSWORD_MIN_LENGTH": "12", "PASSWORD_REQUIRE_SYMBOL": "true"},
+			check: func(cfg *Config) bool {
+				return cfg.Mailer.Driver == MailerDriverSMTP && cfg.Mailer.SMTP.Host == "smtp.example.com" && cfg.Account.RequireVerifiedEmail &&
+					cfg.Password.MinLength == 12 && cfg.Password.RequireSymbol && cfg.Password.RequireUpper
+			},
+		},
+		{
+			name: "Given database env when load then override the defaults",
+			env:  map[string]string{"DB_DRIVER": DatabaseDriverPostgres, "DB_DSN": "postgres://store@localhost/store", "DB_AUTO_MIGRATE": "false"},
+			check: func(cfg *Config) bool {
+				return cfg.Database.Driver == DatabaseDriverPostgres && cfg.Database.DSN == "postgres://store@localhost/store" && !cfg.Database.AutoMigrate
+			},
+		},
+		{
+			name: "Given rate limit file and env when load then add the routes to the defaults",
+			env:  map[string]string{"CONFIG_FILE": rateLimitFile, "RATE_LIMIT_FAIL_OPEN": "false", "RATE_LIMIT_DEFAULT_WINDOW": "30s"},
+			check: func(cfg *Config) bool {
+				return !cfg.RateLimit.FailOpen && cfg.RateLimit.Algorithm == RateLimitAlgorithmSlidingWindow && cfg.RateLimit.Default == RateLimitPolicy{Limit: 300, Window: 30 * time.Second} &&
+					cfg.RateLimit.Routes["GET /products"] == RateLimitPolicy{Limit: 50, Window: time.Minute} && cfg.RateLimit.Routes["POST /customer/login"].Limit == 10
+			},
+		},
+		{
+			name: "Given proxy env when load then trust the listed proxies",
+			env:  map[string]string{"APP_PROXY_HEADER": "X-Forwarded-For", "APP_TRUSTED_PROXIES": "10.0.0.1, 10.1.0.0/16", "RATE_LIMIT_PRE_AUTH_LIMIT": "100"},
+			check: func(cfg *Config) bool {
+				return cfg.App.ProxyHeader == "X-Forwarded-For" && reflect.DeepEqual(cfg.App.TrustedProxies, []string{"10.0.0.1", "10.1.0.0/16"}) &&
+					cfg.RateLimit.PreAuth == RateLimitPolicy{Limit: 100, Window: time.Minute}
+			},
+		},
+		{
+			name:    "Given proxy header without trusted proxies when load then return error",
+			env:     map[string]string{"APP_PROXY_HEADER": "X-Forwarded-For"},
+			wantErr: true,
+		},
+		{
+			name:  "Given the former auto migrate env when load then override the default",
+			env:   map[string]string{"SQLITE_AUTO_MIGRATE": "false"},
+			check: func(cfg *Config) bool { return !cfg.Database.AutoMigrate },
+		},
+		{
+			name:  "Given both auto migrate envs when load then the new one wins",
+			env:   map[string]string{"SQLITE_AUTO_MIGRATE": "false", "DB_AUTO_MIGRATE": "true"},
+			check: func(cfg *Config) bool { return cfg.Database.AutoMigrate },
+		},
+		{
+			name:    "Given invalid bool in env when load then return error",
+			env:     map[string]string{"REQUIRE_VERIFIED_EMAIL": "sometimes"},
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown profile when load then return error",
+			env:     map[string]string{"APP_PROFILE": "staging"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{"APP_PROFILE", "CONFIG_FILE", "REDIS_HOST", "SESSION_TTL", "DEFAULT_PAGE_SIZE", "JWT_SECRET_KEY", "MAILER_DRIVER", "SMTP_HOST", "REQUIRE_VERIFIED_EMAIL", "PASSWORD_MIN_LENGTH", "PASSWORD_REQUIRE_SYMBOL", "DB_DRIVER", "DB_DSN", "DB_AUTO_MIGRATE", "SQLITE_AUTO_MIGRATE", "RATE_LIMIT_FAIL_OPEN", "RATE_LIMIT_DEFAULT_WINDOW", "RATE_LIMIT_PRE_AUTH_LIMIT", "APP_PROXY_HEADER", "APP_TRUSTED_PROXIES"} {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			t.Setenv("JWT_SECRET_KEY", testSecretKey)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr && !tc.check(cfg) {
+				t.Errorf("Load() = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{
+			name:    "Given default config when validate then return success",
+			modify:  func(cfg *Config) {},
+			wantErr: false,
+		},
+		{
+			name:    "Given invalid app port when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.Port = "3000" },
+			wantErr: true,
+		},
+		{
+			name:    "Given invalid metrics port when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.MetricsPort = "9090" },
+			wantErr: true,
+		},
+		{
+			name:    "Given metrics port same as app port when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.MetricsPort = cfg.App.Port },
+			wantErr: true,
+		},
+		{
+			name:    "Given proxy header without trusted proxies when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.ProxyHeader = "X-Forwarded-For" },
+			wantErr: true,
+		},
+		{
+			name:    "Given invalid trusted proxy when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.TrustedProxies = []string{"proxy.local"} },
+			wantErr: true,
+		},
+		{
+			name: "Given proxy header with trusted proxies when validate then return success",
+			modify: func(cfg *Config) {
+				cfg.App.ProxyHeader = "X-Forwarded-For"
+				cfg.App.TrustedProxies = []string{"10.0.0.1", "10.1.0.0/16"}
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given zero request timeout when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.RequestTimeout = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given zero shutdown timeout when validate then return error",
+			modify:  func(cfg *Config) { cfg.App.ShutdownTimeout = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown log level when validate then return error",
+			modify:  func(cfg *Config) { cfg.Log.Level = "trace" },
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown log format when validate then return error",
+			modify:  func(cfg *Config) { cfg.Log.Format = "xml" },
+			wantErr: true,
+		},
+		{
+			name:    "Given otlp exporter without endpoint when validate then return error",
+			modify:  func(cfg *Config) { cfg.Tracing.Exporter = TracingExporterOTLP },
+			wantErr: true,
+		},
+		{
+			name:    "Given sample ratio above 1 when validate then return error",
+			modify:  func(cfg *Config) { cfg.Tracing.SampleRatio = 1.5 },
+			wantErr: true,
+		},
+		{
+			name:    "Given invalid redis port when validate then return error",
+			modify:  func(cfg *Config) { cfg.Redis.Port = "70000" },
+			wantErr: true,
+		},
+		{
+			name:    "Given zero session ttl when validate then return error",
+			modify:  func(cfg *Config) { cfg.Auth.SessionTTL = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given default secret when validate then return error",
+			modify:  func(cfg *Config) { cfg.Auth.SecretKey = defaultSecretKey },
+			wantErr: true,
+		},
+		{
+			name: "Given local profile with the default secret when validate then return success",
+			modify: func(cfg *Config) {
+				cfg.Profile = ProfileLocal
+				cfg.Auth.SecretKey = defaultSecretKey
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given key dir without active key when validate then return error",
+			modify:  func(cfg *Config) { cfg.Auth.KeyDir = "keys" },
+			wantErr: true,
+		},
+		{
+			name: "Given production profile with a signing key when validate then return success",
+			modify: func(cfg *Config) {
+				cfg.Profile = ProfileProduction
+				cfg.Auth.KeyDir = "keys"
+				cfg.Auth.ActiveKeyID = "2024-06"
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Given mysql driver without dsn when validate then return error",
+			modify:  func(cfg *Config) { cfg.Database.Driver = DatabaseDriverMySQL },
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown database driver when validate then return error",
+			modify:  func(cfg *Config) { cfg.Database.Driver = "oracle" },
+			wantErr: true,
+		},
+		{
+			name:    "Given smtp driver without host when validate then return error",
+			modify:  func(cfg *Config) { cfg.Mailer.Driver = MailerDriverSMTP },
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown mailer driver when validate then return error",
+			modify:  func(cfg *Config) { cfg.Mailer.Driver = "sendmail" },
+			wantErr: true,
+		},
+		{
+			name:    "Given password min length below 8 when validate then return error",
+			modify:  func(cfg *Config) { cfg.Password.MinLength = 6 },
+			wantErr: true,
+		},
+		{
+			name:    "Given max delay shorter than base delay when validate then return error",
+			modify:  func(cfg *Config) { cfg.Login.MaxDelay = cfg.Login.BaseDelay / 2 },
+			wantErr: true,
+		},
+		{
+			name:    "Given zero lockout duration when validate then return error",
+			modify:  func(cfg *Config) { cfg.Login.LockoutDuration = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given zero two-factor challenge ttl when validate then return error",
+			modify:  func(cfg *Config) { cfg.TwoFactor.ChallengeTTL = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given link url without scheme when validate then return error",
+			modify:  func(cfg *Config) { cfg.Account.LinkURL = "localhost:3000" },
+			wantErr: true,
+		},
+		{
+			name:    "Given empty audience when validate then return error",
+			modify:  func(cfg *Config) { cfg.Auth.Audience = "" },
+			wantErr: true,
+		},
+		{
+			name:    "Given access token outliving the session when validate then return error",
+			modify:  func(cfg *Config) { cfg.Auth.AccessTokenTTL = cfg.Auth.SessionTTL },
+			wantErr: true,
+		},
+		{
+			name:    "Given zero page size when validate then return error",
+			modify:  func(cfg *Config) { cfg.Pagination.DefaultLimit = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given relative media url when validate then return error",
+			modify:  func(cfg *Config) { cfg.Media.URL = "media" },
+			wantErr: true,
+		},
+		{
+			name:    "Given zero image pixels when validate then return error",
+			modify:  func(cfg *Config) { cfg.Media.MaxImagePixels = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown rate limit algorithm when validate then return error",
+			modify:  func(cfg *Config) { cfg.RateLimit.Algorithm = "leaky_bucket" },
+			wantErr: true,
+		},
+		{
+			name:    "Given pre auth rate limit without limit when validate then return error",
+			modify:  func(cfg *Config) { cfg.RateLimit.PreAuth.Limit = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "Given rate limit route without method when validate then return error",
+			modify:  func(cfg *Config) { cfg.RateLimit.Routes["/products"] = RateLimitPolicy{Limit: 50, Window: time.Minute} },
+			wantErr: true,
+		},
+		{
+			name:    "Given rate limit route without window when validate then return error",
+			modify:  func(cfg *Config) { cfg.RateLimit.Routes["GET /products"] = RateLimitPolicy{Limit: 50} },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Auth.SecretKey = testSecretKey
+			tc.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package apikey
+
+type Scope string
+
+const (
+	ScopeCatalogRead  Scope = "catalog:read"
+	ScopeCatalogWrite Scope = "catalog:write"
+	ScopeOrdersRead   Scope = "orders:read"
+	ScopeOrdersWrite  Scope = "orders:write"
+)
+
+var mapScope = map[Scope]string{
+	ScopeCatalogRead:  "CATALOG READ",
+	ScopeCatalogWrite: "CATALOG WRITE",
+	ScopeOrdersRead:   "ORDERS READ",
+	ScopeOrdersWrite:  "ORDERS WRITE",
+}
+
+func (s Scope) Enum() string {
+	if val, ok := mapScope[s]; ok {
+		return val
+	}
+
+	return "UNKNOWN"
+}
+
+func (s Scope) IsValid() bool {
+	if _, ok := mapScope[s]; ok {
+		return true
+	}
+
+	return false
+}
@@ -1,12 +1,21 @@
 package constant
 
-import "time"
-
 const (
-	SecretKey     = "online-store-secret"
-	SessionExpire = 10 * time.Minute
-	JWTPrefix     = "jwt-token-"
+	SessionPrefix          = "session-"
+	CustomerSessionsPrefix = "customer-sessions-"
+
+	LoginFailuresPrefix  = "login-failures-"
+	LoginLockPrefix      = "login-lock-"
+	LoginChallengePrefix = "login-challenge-"
+
+	GuestCartPrefix = "guest-cart-"
+	GuestCartHeader = "X-Cart-Token"
+
+	APIKeyHeader = "X-API-Key"
+
+	RateLimitPrefix = "rate-limit-"
+
+	ProductImageFormField = "image"
 
-	DefaultLimit = 10
-	DefaultPage  = 1
-)
\ No newline at end of file
+	DefaultPage = 1
+)
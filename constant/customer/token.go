@@ -0,0 +1,21 @@
+package customer
+
+type TokenType int
+
+const (
+	TokenTypeEmailVerification TokenType = iota + 1
+	TokenTypePasswordReset
+)
+
+var mapTokenType = map[TokenType]string{
+	TokenTypeEmailVerification: "EMAIL VERIFICATION",
+	TokenTypePasswordReset:     "PASSWORD RESET",
+}
+
+func (t TokenType) Enum() string {
+	if val, ok := mapTokenType[t]; ok {
+		return val
+	}
+
+	return "UNKNOWN"
+}
@@ -0,0 +1,23 @@
+package health
+
+type Status int
+
+const (
+	HealthStatusOK Status = iota + 1
+	HealthStatusDegraded
+	HealthStatusUnavailable
+)
+
+var mapHealthStatus = map[Status]string{
+	HealthStatusOK:          "OK",
+	HealthStatusDegraded:    "DEGRADED",
+	HealthStatusUnavailable: "UNAVAILABLE",
+}
+
+func (s Status) Enum() string {
+	if val, ok := mapHealthStatus[s]; ok {
+		return val
+	}
+
+	return "UNKNOWN"
+}
@@ -0,0 +1,27 @@
+package product
+
+type ImageType string
+
+const (
+	ImageTypeJPEG ImageType = "image/jpeg"
+	ImageTypePNG  ImageType = "image/png"
+	ImageTypeWebP ImageType = "image/webp"
+)
+
+var mapImageExtension = map[ImageType]string{
+	ImageTypeJPEG: ".jpg",
+	ImageTypePNG:  ".png",
+	ImageTypeWebP: ".webp",
+}
+
+func (i ImageType) Extension() string {
+	return mapImageExtension[i]
+}
+
+func (i ImageType) IsValid() bool {
+	if _, ok := mapImageExtension[i]; ok {
+		return true
+	}
+
+	return false
+}
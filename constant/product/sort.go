@@ -0,0 +1,31 @@
+package product
+
+type SortBy string
+
+const (
+	SortByPrice       SortBy = "price"
+	SortByRating      SortBy = "rating"
+	SortByReviewCount SortBy = "review_count"
+)
+
+var mapSortColumn = map[SortBy]string{
+	SortByPrice:       "p.price",
+	SortByRating:      "average_rating",
+	SortByReviewCount: "review_count",
+}
+
+func (s SortBy) Column() string {
+	if val, ok := mapSortColumn[s]; ok {
+		return val
+	}
+
+	return "p.id"
+}
+
+func (s SortBy) IsValid() bool {
+	if _, ok := mapSortColumn[s]; ok {
+		return true
+	}
+
+	return false
+}
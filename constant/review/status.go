@@ -0,0 +1,31 @@
+package review
+
+type Status int
+
+const (
+	ReviewStatusPending Status = iota + 1
+	ReviewStatusApproved
+	ReviewStatusRejected
+)
+
+var mapReviewStatus = map[Status]string{
+	ReviewStatusPending:  "PENDING",
+	ReviewStatusApproved: "APPROVED",
+	ReviewStatusRejected: "REJECTED",
+}
+
+func (s Status) Enum() string {
+	if val, ok := mapReviewStatus[s]; ok {
+		return val
+	}
+
+	return "UNKNOWN"
+}
+
+func (s Status) IsValid() bool {
+	if _, ok := mapReviewStatus[s]; ok {
+		return true
+	}
+
+	return false
+}
@@ -20,4 +20,12 @@ func (s Status) Enum() string {
 	}
 
 	return "UNKNOWN"
+}
+
+func (s Status) IsValid() bool {
+	if _, ok := mapTransactionStatus[s]; ok {
+		return true
+	}
+
+	return false
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package wishlist
+
+type NotificationType int
+
+const (
+	NotificationTypePriceDrop NotificationType = iota + 1
+	NotificationTypeBackInStock
+)
+
+var mapNotificationType = map[NotificationType]string{
+	NotificationTypePriceDrop:   "PRICE DROP",
+	NotificationTypeBackInStock: "BACK IN STOCK",
+}
+
+func (n NotificationType) Enum() string {
+	if val, ok := mapNotificationType[n]; ok {
+		return val
+	}
+
+	return "UNKNOWN"
+}
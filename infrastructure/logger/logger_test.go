@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/zakiyalmaya/online-store/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     config.LogConfig
+		wantErr bool
+	}{
+		{
+			name:    "Given json format when new then return logger",
+			cfg:     config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON},
+			wantErr: false,
+		},
+		{
+			name:    "Given text format when new then return logger",
+			cfg:     config.LogConfig{Level: config.LogLevelDebug, Format: config.LogFormatText},
+			wantErr: false,
+		},
+		{
+			name:    "Given unknown level when new then return error",
+			cfg:     config.LogConfig{Level: "trace", Format: config.LogFormatJSON},
+			wantErr: true,
+		},
+		{
+			name:    "Given unknown format when new then return error",
+			cfg:     config.LogConfig{Level: config.LogLevelInfo, Format: "xml"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.InfoContext(WithRequestID(context.Background(), "req-1"), "with request")
+	log.InfoContext(context.Background(), "without request")
+	log.DebugContext(WithRequestID(context.Background(), "req-2"), "below level")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %s", len(lines), buf.String())
+	}
+
+	for i, want := range []string{"req-1", ""} {
+		line := map[string]interface{}{}
+		if err := json.Unmarshal(lines[i], &line); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		if got, _ := line["request_id"].(string); got != want {
+			t.Errorf("line %d request_id = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	log.InfoContext(trace.ContextWithSpanContext(context.Background(), spanContext), "with trace")
+
+	line := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if got := line["trace_id"]; got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace_id = %v, want 4bf92f3577b34da6a3ce929d0e0e4736", got)
+	}
+
+	if got := line["span_id"]; got != "00f067aa0ba902b7" {
+		t.Errorf("span_id = %v, want 00f067aa0ba902b7", got)
+	}
+}
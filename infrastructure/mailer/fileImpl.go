@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+type fileMailerImpl struct {
+	from string
+	dir  string
+}
+
+// NewFileMailer writes every email to an .eml file under dir instead of sending it.
+func NewFileMailer(from, dir string) Mailer {
+	return &fileMailerImpl{from: from, dir: dir}
+}
+
+func (f *fileMailerImpl) Send(message *Message) error {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		slog.Error("mailer error", "error", err)
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), utils.GenerateUUID())
+	if err := os.WriteFile(filepath.Join(f.dir, name), compose(f.from, message), 0o600); err != nil {
+		slog.Error("mailer error", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer("Online Store <no-reply@online-store.local>", dir)
+
+	err := mailer.Send(&Message{To: "john@example.com", Subject: "Verify your email", Body: "http://localhost/verify?token=abc"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Send() wrote %v, error %v", files, err)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("error reading email: %v", err)
+	}
+
+	for _, want := range []string{"From: Online Store <no-reply@online-store.local>\r\n", "To: john@example.com\r\n", "Subject: Verify your email\r\n", "\r\n\r\nhttp://localhost/verify?token=abc"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Send() wrote %q, want it to contain %q", data, want)
+		}
+	}
+}
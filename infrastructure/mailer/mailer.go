@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/zakiyalmaya/online-store/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=mailer.go -destination=Mailer.go
+type Mailer interface {
+	Send(message *Message) error
+}
+
+// New returns the mailer of the configured driver.
+func New(cfg config.MailerConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case config.MailerDriverSMTP:
+		return NewSMTPMailer(cfg.From, cfg.SMTP), nil
+	case config.MailerDriverFile:
+		return NewFileMailer(cfg.From, cfg.Dir), nil
+	case config.MailerDriverMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"testing"
+
+	"github.com/zakiyalmaya/online-store/config"
+)
+
+func TestMemorySend(t *testing.T) {
+	mailer, err := New(config.MailerConfig{Driver: config.MailerDriverMemory})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	mailer.Send(&Message{To: "john@example.com", Subject: "first"})
+	mailer.Send(&Message{To: "jane@example.com", Subject: "second"})
+
+	messages := mailer.(*MemoryMailer).Messages()
+	if len(messages) != 2 || messages[0].To != "john@example.com" || messages[1].Subject != "second" {
+		t.Errorf("Messages() = %+v", messages)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(config.MailerConfig{Driver: "sendmail"}); err == nil {
+		t.Errorf("New() with an unknown driver should return an error")
+	}
+}
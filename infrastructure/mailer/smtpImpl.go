@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/config"
+)
+
+type smtpMailerImpl struct {
+	from string
+	cfg  config.SMTPConfig
+}
+
+// NewSMTPMailer sends through the SMTP server, authenticating when a username is set.
+func NewSMTPMailer(from string, cfg config.SMTPConfig) Mailer {
+	return &smtpMailerImpl{from: from, cfg: cfg}
+}
+
+func (s *smtpMailerImpl) Send(message *Message) error {
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		slog.Error("mailer error", "error", err)
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	if err := smtp.SendMail(addr, auth, from.Address, []string{message.To}, compose(s.from, message)); err != nil {
+		slog.Error("mailer error", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// compose renders the message as a plain text email.
+func compose(from string, message *Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(message.Body)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,131 @@
+// Package metrics collects the Prometheus metrics of the app, served on
+// /metrics: the HTTP requests, the SQL queries of the repositories, the Redis
+// commands and the business counters of the carts and the checkouts.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "online_store"
+
+const (
+	CartTypeCustomer = "customer"
+	CartTypeGuest    = "guest"
+)
+
+// Registry holds every metric of the app next to the Go runtime and process
+// metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests answered, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to answer an HTTP request, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by a repository method on the database, by repository and method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+
+	redisCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_commands_total",
+		Help:      "Redis commands run, by command and outcome: success, nil when the key does not exist, or error.",
+	}, []string{"command", "outcome"})
+
+	cartsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "carts_created_total",
+		Help:      "Shopping carts created, by type: customer or guest.",
+	}, []string{"type"})
+
+	checkouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkouts_total",
+		Help:      "Carts checked out, by payment method.",
+	}, []string{"payment_method"})
+
+	checkoutFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkout_failures_total",
+		Help:      "Checkouts refused or failed, by reason: the error code answered.",
+	}, []string{"reason"})
+
+	gmv = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gmv_total",
+		Help:      "Gross merchandise value, the total amount of the transactions settled as success.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		queryDuration,
+		redisCommands,
+		cartsCreated,
+		checkouts,
+		checkoutFailures,
+		gmv,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest counts an answered request, route being the matched route
+// and not the path so an id in the path does not make a series per id.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// QueryTimer times a repository method until ObserveDuration is called,
+// deferred at the top of the method.
+func QueryTimer(repository, method string) *prometheus.Timer {
+	return prometheus.NewTimer(queryDuration.WithLabelValues(repository, method))
+}
+
+// CartCreated counts a new cart of the type.
+func CartCreated(cartType string) {
+	cartsCreated.WithLabelValues(cartType).Inc()
+}
+
+// CheckedOut counts a checkout, its amount only counts in the GMV once paid.
+func CheckedOut(paymentMethod string) {
+	checkouts.WithLabelValues(paymentMethod).Inc()
+}
+
+// Paid adds the amount of a transaction settled as success to the GMV.
+func Paid(amount float64) {
+	gmv.Add(amount)
+}
+
+// CheckoutFailed counts a checkout that failed for the reason.
+func CheckoutFailed(reason string) {
+	checkoutFailures.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveRequest(t *testing.T) {
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/product/:product_id/images", "404"))
+
+	ObserveRequest("GET", "/product/:product_id/images", 404, 10*time.Millisecond)
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/product/:product_id/images", "404")) - before; got != 1 {
+		t.Errorf("http_requests_total increased by %v, want 1", got)
+	}
+}
+
+func TestCheckedOut(t *testing.T) {
+	beforeCheckouts := testutil.ToFloat64(checkouts.WithLabelValues("CASH"))
+	beforeGMV := testutil.ToFloat64(gmv)
+
+	CheckedOut("CASH")
+	CheckedOut("CASH")
+
+	if got := testutil.ToFloat64(checkouts.WithLabelValues("CASH")) - beforeCheckouts; got != 2 {
+		t.Errorf("checkouts_total increased by %v, want 2", got)
+	}
+
+	if got := testutil.ToFloat64(gmv) - beforeGMV; got != 0 {
+		t.Errorf("gmv_total increased by %v, want 0", got)
+	}
+}
+
+func TestPaid(t *testing.T) {
+	beforeGMV := testutil.ToFloat64(gmv)
+
+	Paid(12.5)
+	Paid(7.5)
+
+	if got := testutil.ToFloat64(gmv) - beforeGMV; got != 20 {
+		t.Errorf("gmv_total increased by %v, want 20", got)
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+	defer mockRedisServer.Close()
+
+	redcl := redis.NewClient(&redis.Options{Addr: mockRedisServer.Addr()})
+	redcl.AddHook(RedisHook{})
+
+	testCases := []struct {
+		name    string
+		run     func(ctx context.Context)
+		command string
+		outcome string
+	}{
+		{
+			name:    "Given a set when it succeeds then count a success",
+			run:     func(ctx context.Context) { redcl.Set(ctx, "key", "value", 0) },
+			command: "set",
+			outcome: redisOutcomeSuccess,
+		},
+		{
+			name:    "Given a get of a missing key when it runs then count a nil",
+			run:     func(ctx context.Context) { redcl.Get(ctx, "missing") },
+			command: "get",
+			outcome: redisOutcomeNil,
+		},
+		{
+			name:    "Given an incr of a string when it fails then count an error",
+			run:     func(ctx context.Context) { redcl.Incr(ctx, "key") },
+			command: "incr",
+			outcome: redisOutcomeError,
+		},
+		{
+			name: "Given a pipeline when it runs then count each command",
+			run: func(ctx context.Context) {
+				redcl.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Del(ctx, "key")
+					return nil
+				})
+			},
+			command: "del",
+			outcome: redisOutcomeSuccess,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := testutil.ToFloat64(redisCommands.WithLabelValues(tc.command, tc.outcome))
+
+			tc.run(context.Background())
+
+			if got := testutil.ToFloat64(redisCommands.WithLabelValues(tc.command, tc.outcome)) - before; got != 1 {
+				t.Errorf("redis_commands_total{command=%q, outcome=%q} increased by %v, want 1", tc.command, tc.outcome, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	redisOutcomeSuccess = "success"
+	redisOutcomeNil     = "nil"
+	redisOutcomeError   = "error"
+)
+
+// RedisHook counts the outcome of every command of the Redis client it is
+// added to, the commands of a pipeline included.
+type RedisHook struct{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeRedisCommand(cmd)
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	for _, cmd := range cmds {
+		observeRedisCommand(cmd)
+	}
+
+	return nil
+}
+
+func observeRedisCommand(cmd redis.Cmder) {
+	outcome := redisOutcomeSuccess
+	switch err := cmd.Err(); {
+	case err == redis.Nil:
+		outcome = redisOutcomeNil
+	case err != nil:
+		outcome = redisOutcomeError
+	}
+
+	redisCommands.WithLabelValues(cmd.Name(), outcome).Inc()
+}
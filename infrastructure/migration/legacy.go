@@ -0,0 +1,66 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// legacyColumns were added to existing tables by the create-on-boot schema
+// that came before the migrations.
+var legacyColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"customers", "deleted_at", "TIMESTAMP NULL"},
+	{"customers", "email_verified_at", "TIMESTAMP NULL"},
+	{"customers", "totp_secret", "TEXT NULL"},
+	{"customers", "totp_enabled_at", "TIMESTAMP NULL"},
+	{"customers", "totp_last_step", "INTEGER NULL"},
+	{"cart_items", "product_variant_id", "INTEGER NOT NULL DEFAULT 0"},
+	{"transaction_details", "product_variant_id", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// upgradeLegacy brings a database created on boot by an older release to the
+// schema of the first migration, which only creates what does not exist yet.
+// It runs before the first migration is applied and does nothing on an empty database.
+func upgradeLegacy(db *sqlx.DB) error {
+	for _, c := range legacyColumns {
+		var tableExists, columnExists bool
+		if err := db.Get(&tableExists, "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?", c.table); err != nil {
+			return fmt.Errorf("error getting table %s: %v", c.table, err)
+		}
+
+		if !tableExists {
+			continue
+		}
+
+		if err := db.Get(&columnExists, "SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?", c.table, c.column); err != nil {
+			return fmt.Errorf("error getting columns of table %s: %v", c.table, err)
+		}
+
+		if columnExists {
+			continue
+		}
+
+		if _, err := db.Exec("ALTER TABLE " + c.table + " ADD COLUMN " + c.column + " " + c.definition); err != nil {
+			return fmt.Errorf("error adding column %s to table %s: %v", c.column, c.table, err)
+		}
+	}
+
+	// the index used to be on (product_id, shopping_cart_id) only,
+	// drop it so the migration recreates it once per variant
+	var columns int
+	if err := db.Get(&columns, "SELECT COUNT(*) FROM pragma_index_info('idx_product_cart')"); err != nil {
+		return fmt.Errorf("error getting index idx_product_cart: %v", err)
+	}
+
+	if columns == 2 {
+		if _, err := db.Exec("DROP INDEX idx_product_cart"); err != nil {
+			return fmt.Errorf("error dropping index idx_product_cart: %v", err)
+		}
+	}
+
+	return nil
+}
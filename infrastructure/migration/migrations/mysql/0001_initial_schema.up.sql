@@ -0,0 +1,228 @@
+-- The first schema on MySQL, its default collation already compares usernames
+-- and emails regardless of case.
+CREATE TABLE customers (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	username VARCHAR(255) UNIQUE NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password TEXT NOT NULL,
+	phone_number VARCHAR(255) NOT NULL,
+	address TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	email_verified_at DATETIME NULL,
+	totp_secret TEXT NULL,
+	totp_enabled_at DATETIME NULL,
+	totp_last_step BIGINT NULL,
+	deleted_at DATETIME NULL,
+	active_email VARCHAR(255) AS (CASE WHEN deleted_at IS NULL THEN email END) STORED
+);
+
+-- deleted accounts keep their row, so only active accounts are unique. MySQL has
+-- no partial index, active_email is NULL for deleted accounts and NULLs do not collide.
+CREATE UNIQUE INDEX idx_customers_email ON customers (active_email);
+
+CREATE TABLE admins (
+	customer_id INT PRIMARY KEY,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE customer_tokens (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	customer_id INT NOT NULL,
+	type INT NOT NULL,
+	token_hash VARCHAR(255) UNIQUE NOT NULL,
+	expires_at DATETIME NOT NULL,
+	used_at DATETIME NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE customer_recovery_codes (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	customer_id INT NOT NULL,
+	code_hash VARCHAR(255) NOT NULL,
+	used_at DATETIME NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, code_hash),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE categories (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE products (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	stock_quantity INT NOT NULL,
+	category_id INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (category_id) REFERENCES categories(id)
+);
+
+CREATE TABLE product_options (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	product_id INT NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, name),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE product_option_values (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	product_option_id INT NOT NULL,
+	value VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_option_id, value),
+	FOREIGN KEY (product_option_id) REFERENCES product_options(id)
+);
+
+CREATE TABLE product_variants (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	product_id INT NOT NULL,
+	sku VARCHAR(255) UNIQUE NOT NULL,
+	price DECIMAL(10, 2) NULL,
+	stock_quantity INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE product_variant_values (
+	product_variant_id INT NOT NULL,
+	product_option_value_id INT NOT NULL,
+	PRIMARY KEY (product_variant_id, product_option_value_id),
+	FOREIGN KEY (product_variant_id) REFERENCES product_variants(id),
+	FOREIGN KEY (product_option_value_id) REFERENCES product_option_values(id)
+);
+
+CREATE TABLE product_images (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	product_id INT NOT NULL,
+	path VARCHAR(255) NOT NULL,
+	thumbnail_path VARCHAR(255) NOT NULL,
+	content_type VARCHAR(255) NOT NULL,
+	size INT NOT NULL,
+	position INT NOT NULL,
+	is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE shopping_carts (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	customer_id INT NOT NULL,
+	status INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+-- product_variant_id is 0 for products without variants
+CREATE TABLE cart_items (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	shopping_cart_id INT NOT NULL,
+	product_id INT NOT NULL,
+	product_variant_id INT NOT NULL DEFAULT 0,
+	quantity INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX idx_product_cart ON cart_items (product_id, product_variant_id, shopping_cart_id);
+
+CREATE TABLE transactions (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	idempotency_key VARCHAR(255) UNIQUE NOT NULL,
+	shopping_cart_id INT NOT NULL,
+	customer_id INT NOT NULL,
+	status INT NOT NULL,
+	total_amount DECIMAL(10, 2) NOT NULL,
+	payment_method INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE transaction_details (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	transaction_id INT NOT NULL,
+	product_id INT NOT NULL,
+	product_variant_id INT NOT NULL DEFAULT 0,
+	quantity INT NOT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE wishlists (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	customer_id INT NOT NULL,
+	product_id INT NOT NULL,
+	notify BOOLEAN NOT NULL DEFAULT FALSE,
+	last_price DECIMAL(10, 2) NOT NULL,
+	last_stock INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, product_id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE wishlist_notifications (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	customer_id INT NOT NULL,
+	product_id INT NOT NULL,
+	type INT NOT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE reviews (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	product_id INT NOT NULL,
+	customer_id INT NOT NULL,
+	rating INT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL,
+	verified_purchase BOOLEAN NOT NULL DEFAULT FALSE,
+	status INT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, customer_id),
+	FOREIGN KEY (product_id) REFERENCES products(id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE api_keys (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	prefix VARCHAR(255) UNIQUE NOT NULL,
+	key_hash VARCHAR(255) NOT NULL,
+	scopes TEXT NOT NULL,
+	created_by INT NOT NULL,
+	expires_at DATETIME NULL,
+	last_used_at DATETIME NULL,
+	revoked_at DATETIME NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES customers(id)
+);
@@ -0,0 +1,3 @@
+-- The email a token was mailed to, a verification link stops working once
+-- the account changes its email. Tokens issued before have no email.
+ALTER TABLE customer_tokens ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- Only the first wishlisted variant of a product is kept.
+DELETE w FROM wishlists AS w JOIN wishlists AS first ON w.customer_id = first.customer_id AND w.product_id = first.product_id AND w.id > first.id;
+ALTER TABLE wishlists
+	ADD UNIQUE KEY customer_id (customer_id, product_id),
+	DROP INDEX idx_wishlists_customer_product_variant,
+	DROP COLUMN product_variant_id;
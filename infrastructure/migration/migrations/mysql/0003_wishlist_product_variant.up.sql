@@ -0,0 +1,8 @@
+-- The variant of a wishlisted product, 0 for products without variants like
+-- in cart_items. A customer can wishlist several variants of a product. The
+-- unique key of the first migration is named after its first column, the new
+-- one also serves the foreign key of customer_id.
+ALTER TABLE wishlists
+	ADD COLUMN product_variant_id INT NOT NULL DEFAULT 0 AFTER product_id,
+	ADD UNIQUE KEY idx_wishlists_customer_product_variant (customer_id, product_id, product_variant_id),
+	DROP INDEX customer_id;
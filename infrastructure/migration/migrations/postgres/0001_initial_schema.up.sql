@@ -0,0 +1,227 @@
+-- The first schema on PostgreSQL, usernames and emails are compared with
+-- LOWER so their unique indexes are on LOWER too.
+CREATE TABLE customers (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	username VARCHAR(255) UNIQUE NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password TEXT NOT NULL,
+	phone_number VARCHAR(255) NOT NULL,
+	address TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	email_verified_at TIMESTAMPTZ NULL,
+	totp_secret TEXT NULL,
+	totp_enabled_at TIMESTAMPTZ NULL,
+	totp_last_step BIGINT NULL,
+	deleted_at TIMESTAMPTZ NULL
+);
+
+-- deleted accounts keep their row, so only active accounts are unique
+CREATE UNIQUE INDEX idx_customers_username ON customers (LOWER(username)) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_customers_email ON customers (LOWER(email)) WHERE deleted_at IS NULL;
+
+CREATE TABLE admins (
+	customer_id INTEGER PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE customer_tokens (
+	id SERIAL PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	token_hash VARCHAR(255) UNIQUE NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE customer_recovery_codes (
+	id SERIAL PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	code_hash VARCHAR(255) NOT NULL,
+	used_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, code_hash),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE categories (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE products (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	stock_quantity INT NOT NULL,
+	category_id INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (category_id) REFERENCES categories(id)
+);
+
+CREATE TABLE product_options (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, name),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE product_option_values (
+	id SERIAL PRIMARY KEY,
+	product_option_id INTEGER NOT NULL,
+	value VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_option_id, value),
+	FOREIGN KEY (product_option_id) REFERENCES product_options(id)
+);
+
+CREATE TABLE product_variants (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL,
+	sku VARCHAR(255) UNIQUE NOT NULL,
+	price DECIMAL(10, 2) NULL,
+	stock_quantity INT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE product_variant_values (
+	product_variant_id INTEGER NOT NULL,
+	product_option_value_id INTEGER NOT NULL,
+	PRIMARY KEY (product_variant_id, product_option_value_id),
+	FOREIGN KEY (product_variant_id) REFERENCES product_variants(id),
+	FOREIGN KEY (product_option_value_id) REFERENCES product_option_values(id)
+);
+
+CREATE TABLE product_images (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL,
+	path VARCHAR(255) NOT NULL,
+	thumbnail_path VARCHAR(255) NOT NULL,
+	content_type VARCHAR(255) NOT NULL,
+	size INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE shopping_carts (
+	id SERIAL PRIMARY KEY,
+	customer_id INT NOT NULL,
+	status INT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+-- product_variant_id is 0 for products without variants
+CREATE TABLE cart_items (
+	id SERIAL PRIMARY KEY,
+	shopping_cart_id INT NOT NULL,
+	product_id INTEGER NOT NULL,
+	product_variant_id INTEGER NOT NULL DEFAULT 0,
+	quantity INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX idx_product_cart ON cart_items (product_id, product_variant_id, shopping_cart_id);
+
+CREATE TABLE transactions (
+	id SERIAL PRIMARY KEY,
+	idempotency_key VARCHAR(255) UNIQUE NOT NULL,
+	shopping_cart_id INTEGER NOT NULL,
+	customer_id INTEGER NOT NULL,
+	status INTEGER NOT NULL,
+	total_amount DECIMAL(10, 2) NOT NULL,
+	payment_method INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE transaction_details (
+	id SERIAL PRIMARY KEY,
+	transaction_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	product_variant_id INTEGER NOT NULL DEFAULT 0,
+	quantity INTEGER NOT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE wishlists (
+	id SERIAL PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	notify BOOLEAN NOT NULL DEFAULT FALSE,
+	last_price DECIMAL(10, 2) NOT NULL,
+	last_stock INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, product_id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE wishlist_notifications (
+	id SERIAL PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE reviews (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL,
+	customer_id INTEGER NOT NULL,
+	rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL,
+	verified_purchase BOOLEAN NOT NULL DEFAULT FALSE,
+	status INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, customer_id),
+	FOREIGN KEY (product_id) REFERENCES products(id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE api_keys (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	prefix VARCHAR(255) UNIQUE NOT NULL,
+	key_hash VARCHAR(255) NOT NULL,
+	scopes TEXT NOT NULL,
+	created_by INTEGER NOT NULL,
+	expires_at TIMESTAMPTZ NULL,
+	last_used_at TIMESTAMPTZ NULL,
+	revoked_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES customers(id)
+);
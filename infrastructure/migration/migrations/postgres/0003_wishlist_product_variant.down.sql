@@ -0,0 +1,5 @@
+-- Only the first wishlisted variant of a product is kept.
+DELETE FROM wishlists AS w USING wishlists AS first WHERE w.customer_id = first.customer_id AND w.product_id = first.product_id AND w.id > first.id;
+ALTER TABLE wishlists DROP CONSTRAINT wishlists_customer_id_product_id_product_variant_id_key;
+ALTER TABLE wishlists ADD CONSTRAINT wishlists_customer_id_product_id_key UNIQUE (customer_id, product_id);
+ALTER TABLE wishlists DROP COLUMN product_variant_id;
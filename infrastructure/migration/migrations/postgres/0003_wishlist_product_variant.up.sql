@@ -0,0 +1,5 @@
+-- The variant of a wishlisted product, 0 for products without variants like
+-- in cart_items. A customer can wishlist several variants of a product.
+ALTER TABLE wishlists ADD COLUMN product_variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE wishlists DROP CONSTRAINT wishlists_customer_id_product_id_key;
+ALTER TABLE wishlists ADD CONSTRAINT wishlists_customer_id_product_id_product_variant_id_key UNIQUE (customer_id, product_id, product_variant_id);
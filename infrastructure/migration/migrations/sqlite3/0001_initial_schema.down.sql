@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS reviews;
+DROP TABLE IF EXISTS wishlist_notifications;
+DROP TABLE IF EXISTS wishlists;
+DROP TABLE IF EXISTS transaction_details;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS cart_items;
+DROP TABLE IF EXISTS shopping_carts;
+DROP TABLE IF EXISTS product_images;
+DROP TABLE IF EXISTS product_variant_values;
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_option_values;
+DROP TABLE IF EXISTS product_options;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS customer_recovery_codes;
+DROP TABLE IF EXISTS customer_tokens;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,227 @@
+-- The schema the app used to create on boot. IF NOT EXISTS lets a database
+-- created by an earlier release adopt it without changes.
+CREATE TABLE IF NOT EXISTS customers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL,
+	username VARCHAR(255) UNIQUE NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password TEXT NOT NULL,
+	phone_number VARCHAR(255) NOT NULL,
+	address TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	email_verified_at TIMESTAMP NULL,
+	totp_secret TEXT NULL,
+	totp_enabled_at TIMESTAMP NULL,
+	totp_last_step INTEGER NULL,
+	deleted_at TIMESTAMP NULL
+);
+
+-- deleted accounts keep their row, so only active accounts are unique
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_username ON customers (username COLLATE NOCASE) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_email ON customers (email COLLATE NOCASE) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS admins (
+	customer_id INTEGER PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE IF NOT EXISTS customer_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	token_hash VARCHAR(255) UNIQUE NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE IF NOT EXISTS customer_recovery_codes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL,
+	code_hash VARCHAR(255) NOT NULL,
+	used_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, code_hash),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS products (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	stock_quantity INT NOT NULL,
+	category_id INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (category_id) REFERENCES categories(id)
+);
+
+CREATE TABLE IF NOT EXISTS product_options (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INTEGER NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, name),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE IF NOT EXISTS product_option_values (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_option_id INTEGER NOT NULL,
+	value VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_option_id, value),
+	FOREIGN KEY (product_option_id) REFERENCES product_options(id)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INTEGER NOT NULL,
+	sku VARCHAR(255) UNIQUE NOT NULL,
+	price DECIMAL(10, 2) NULL,
+	stock_quantity INT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE IF NOT EXISTS product_variant_values (
+	product_variant_id INTEGER NOT NULL,
+	product_option_value_id INTEGER NOT NULL,
+	PRIMARY KEY (product_variant_id, product_option_value_id),
+	FOREIGN KEY (product_variant_id) REFERENCES product_variants(id),
+	FOREIGN KEY (product_option_value_id) REFERENCES product_option_values(id)
+);
+
+CREATE TABLE IF NOT EXISTS product_images (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INTEGER NOT NULL,
+	path VARCHAR(255) NOT NULL,
+	thumbnail_path VARCHAR(255) NOT NULL,
+	content_type VARCHAR(255) NOT NULL,
+	size INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE IF NOT EXISTS shopping_carts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INT NOT NULL,
+	status int NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+-- product_variant_id is 0 for products without variants
+CREATE TABLE IF NOT EXISTS cart_items (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	shopping_cart_id INT NOT NULL,
+	product_id INTEGER NOT NULL,
+	product_variant_id INTEGER NOT NULL DEFAULT 0,
+	quantity INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_cart ON cart_items (product_id, product_variant_id, shopping_cart_id);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	idempotency_key VARCHAR(255) UNIQUE NOT NULL,
+	shopping_cart_id INTEGER NOT NULL,
+	customer_id INTEGER NOT NULL,
+	status INTEGER NOT NULL,
+	total_amount DECIMAL(10, 2) NOT NULL,
+	payment_method INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_details (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	transaction_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	product_variant_id INTEGER NOT NULL DEFAULT 0,
+	quantity INTEGER NOT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE IF NOT EXISTS wishlists (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	notify BOOLEAN NOT NULL DEFAULT FALSE,
+	last_price DECIMAL(10, 2) NOT NULL,
+	last_stock INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, product_id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE IF NOT EXISTS wishlist_notifications (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	type INTEGER NOT NULL,
+	price DECIMAL(10, 2) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INTEGER NOT NULL,
+	customer_id INTEGER NOT NULL,
+	rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL,
+	verified_purchase BOOLEAN NOT NULL DEFAULT FALSE,
+	status INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, customer_id),
+	FOREIGN KEY (product_id) REFERENCES products(id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL,
+	prefix VARCHAR(255) UNIQUE NOT NULL,
+	key_hash VARCHAR(255) NOT NULL,
+	scopes TEXT NOT NULL,
+	created_by INTEGER NOT NULL,
+	expires_at TIMESTAMP NULL,
+	last_used_at TIMESTAMP NULL,
+	revoked_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES customers(id)
+);
@@ -0,0 +1,20 @@
+-- Only the first wishlisted variant of a product is kept.
+CREATE TABLE wishlists_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	notify BOOLEAN NOT NULL DEFAULT FALSE,
+	last_price DECIMAL(10, 2) NOT NULL,
+	last_stock INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, product_id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+INSERT OR IGNORE INTO wishlists_old (id, customer_id, product_id, notify, last_price, last_stock, created_at, updated_at)
+SELECT id, customer_id, product_id, notify, last_price, last_stock, created_at, updated_at FROM wishlists ORDER BY id;
+
+DROP TABLE wishlists;
+ALTER TABLE wishlists_old RENAME TO wishlists;
@@ -0,0 +1,23 @@
+-- The variant of a wishlisted product, 0 for products without variants like
+-- in cart_items. A customer can wishlist several variants of a product.
+-- SQLite can not change a table constraint, the table is copied.
+CREATE TABLE wishlists_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	customer_id INTEGER NOT NULL,
+	product_id INTEGER NOT NULL,
+	product_variant_id INTEGER NOT NULL DEFAULT 0,
+	notify BOOLEAN NOT NULL DEFAULT FALSE,
+	last_price DECIMAL(10, 2) NOT NULL,
+	last_stock INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (customer_id, product_id, product_variant_id),
+	FOREIGN KEY (customer_id) REFERENCES customers(id),
+	FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+INSERT INTO wishlists_new (id, customer_id, product_id, notify, last_price, last_stock, created_at, updated_at)
+SELECT id, customer_id, product_id, notify, last_price, last_stock, created_at, updated_at FROM wishlists;
+
+DROP TABLE wishlists;
+ALTER TABLE wishlists_new RENAME TO wishlists;
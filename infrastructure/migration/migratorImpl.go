@@ -0,0 +1,320 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+// A runner waits up to lockWait for the lock held by another one, checking
+// every lockInterval.
+const (
+	lockWait     = 30 * time.Second
+	lockInterval = 500 * time.Millisecond
+)
+
+type migratorImpl struct {
+	db         *sqlx.DB
+	migrations []*Migration
+	lockWait   time.Duration
+}
+
+func NewMigrator(db *sqlx.DB, migrations []*Migration) Migrator {
+	return &migratorImpl{db: db, migrations: migrations, lockWait: lockWait}
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Up applies every pending migration in order, each one in its own transaction,
+// and stops at the first failure.
+func (m *migratorImpl) Up() ([]*Migration, error) {
+	applied := make([]*Migration, 0)
+	err := m.withLock(func() error {
+		done, err := m.verify()
+		if err != nil {
+			return err
+		}
+
+		// only SQLite databases were created on boot
+		if len(done) == 0 && dialect.For(m.db).Name() == dialect.SQLite {
+			if err := upgradeLegacy(m.db); err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			if err := m.apply(migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *migratorImpl) Down(steps int) ([]*Migration, error) {
+	rolledBack := make([]*Migration, 0)
+	err := m.withLock(func() error {
+		done, err := m.verify()
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("migration %s has no down script", migration)
+			}
+
+			if err := m.rollback(migration); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+func (m *migratorImpl) Status() ([]*Status, error) {
+	if err := m.createTables(); err != nil {
+		return nil, err
+	}
+
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if row, ok := done[migration.Version]; ok {
+			status.State = StateApplied
+			if row.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+			status.AppliedAt = &row.AppliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range done {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, &Status{Version: row.Version, Name: row.Name, State: StateMissing, AppliedAt: &appliedAt})
+	}
+
+	return statuses, nil
+}
+
+// Unlock releases a lock left behind by a runner that crashed.
+func (m *migratorImpl) Unlock() error {
+	if err := m.createTables(); err != nil {
+		return err
+	}
+
+	if _, err := m.db.Exec("DELETE FROM schema_migrations_lock"); err != nil {
+		return fmt.Errorf("error releasing migration lock: %v", err)
+	}
+
+	return nil
+}
+
+// verify refuses to run when an applied migration was edited or removed since,
+// the database would no longer match the scripts.
+func (m *migratorImpl) verify() (map[int64]*appliedMigration, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		if row, ok := done[migration.Version]; ok && row.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %s was changed after it was applied", migration)
+		}
+	}
+
+	for version, row := range done {
+		if !known[version] {
+			return nil, fmt.Errorf("migration %04d_%s is applied but its script is missing", version, row.Name)
+		}
+	}
+
+	return done, nil
+}
+
+func (m *migratorImpl) applied() (map[int64]*appliedMigration, error) {
+	rows := []*appliedMigration{}
+	if err := m.db.Select(&rows, "SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version"); err != nil {
+		return nil, fmt.Errorf("error getting applied migrations: %v", err)
+	}
+
+	done := make(map[int64]*appliedMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+
+	return done, nil
+}
+
+func (m *migratorImpl) apply(migration *Migration) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error applying migration %s: %v", migration, err)
+	}
+
+	if _, err := tx.Exec(migration.Up); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error applying migration %s: %v", migration, err)
+	}
+
+	if _, err := tx.Exec(tx.Rebind("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)"), migration.Version, migration.Name, migration.Checksum); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error recording migration %s: %v", migration, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error applying migration %s: %v", migration, err)
+	}
+
+	slog.Info("applied migration", "migration", migration.String())
+	return nil
+}
+
+func (m *migratorImpl) rollback(migration *Migration) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("error rolling back migration %s: %v", migration, err)
+	}
+
+	if _, err := tx.Exec(migration.Down); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error rolling back migration %s: %v", migration, err)
+	}
+
+	if _, err := tx.Exec(tx.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error recording rollback of migration %s: %v", migration, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error rolling back migration %s: %v", migration, err)
+	}
+
+	slog.Info("rolled back migration", "migration", migration.String())
+	return nil
+}
+
+// withLock runs fn while holding the single row of schema_migrations_lock, so
+// two instances starting together do not apply the same migration twice.
+func (m *migratorImpl) withLock(fn func() error) error {
+	if err := m.createTables(); err != nil {
+		return err
+	}
+
+	owner := utils.GenerateUUID()
+	deadline := time.Now().Add(m.lockWait)
+	for {
+		locked, err := m.lock(owner)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			var lockedAt time.Time
+			if err := m.db.Get(&lockedAt, "SELECT locked_at FROM schema_migrations_lock WHERE id = 1"); err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("error getting migration lock: %v", err)
+			}
+
+			return fmt.Errorf("%w since %s, run `migrate unlock` if that runner crashed", ErrLocked, lockedAt.Format(time.RFC3339))
+		}
+
+		time.Sleep(lockInterval)
+	}
+
+	defer func() {
+		if _, err := m.db.Exec(m.db.Rebind("DELETE FROM schema_migrations_lock WHERE owner = ?"), owner); err != nil {
+			slog.Error("error releasing migration lock", "error", err)
+		}
+	}()
+
+	return fn()
+}
+
+// lock returns false when another runner holds the lock. The primary key lets
+// a single runner insert the row, the others fail on any database.
+func (m *migratorImpl) lock(owner string) (bool, error) {
+	var insertErr error
+	// the holder may release the lock between the insert and the check
+	for attempt := 0; attempt < 2; attempt++ {
+		_, insertErr = m.db.Exec(m.db.Rebind("INSERT INTO schema_migrations_lock (id, owner) VALUES (1, ?)"), owner)
+		if insertErr == nil {
+			return true, nil
+		}
+
+		var locked bool
+		if err := m.db.Get(&locked, "SELECT COUNT(*) > 0 FROM schema_migrations_lock WHERE id = 1"); err != nil {
+			return false, fmt.Errorf("error taking migration lock: %v", err)
+		}
+
+		if locked {
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("error taking migration lock: %v", insertErr)
+}
+
+func (m *migratorImpl) createTables() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating table schema_migrations: %v", err)
+	}
+
+	_, err = m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id INTEGER PRIMARY KEY,
+		owner VARCHAR(255) NOT NULL,
+		locked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating table schema_migrations_lock: %v", err)
+	}
+
+	return nil
+}
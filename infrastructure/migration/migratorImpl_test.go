@@ -0,0 +1,322 @@
+package migration
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+)
+
+func openDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening the database", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func testMigrations(t *testing.T, files fstest.MapFS) []*Migration {
+	migrations, err := Load(files, "migrations")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when loading migrations", err)
+	}
+
+	return migrations
+}
+
+var testFiles = fstest.MapFS{
+	"migrations/0001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY);")},
+	"migrations/0001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+	"migrations/0002_add_name.up.sql":       {Data: []byte("ALTER TABLE items ADD COLUMN name TEXT;\nCREATE INDEX idx_items_name ON items (name);")},
+	"migrations/0002_add_name.down.sql":     {Data: []byte("DROP INDEX idx_items_name;\nALTER TABLE items DROP COLUMN name;")},
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   fstest.MapFS
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "Given up and down scripts when load then return migrations sorted by version",
+			files:   testFiles,
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:    "Given embedded migrations when load then return migrations",
+			files:   nil,
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:    "Given badly named script when load then return error",
+			files:   fstest.MapFS{"migrations/create_items.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: true,
+		},
+		{
+			name:    "Given down script only when load then return error",
+			files:   fstest.MapFS{"migrations/0001_create_items.down.sql": {Data: []byte("DROP TABLE items;")}},
+			wantErr: true,
+		},
+		{
+			name: "Given two names for a version when load then return error",
+			files: fstest.MapFS{
+				"migrations/0001_create_items.up.sql": {Data: []byte("SELECT 1;")},
+				"migrations/0001_create_users.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []*Migration
+			var err error
+			if tc.files == nil {
+				got, err = Load(Migrations, Dir(dialect.SQLite))
+			} else {
+				got, err = Load(tc.files, "migrations")
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if len(got) < tc.want {
+				t.Errorf("Load() = %d migrations, want at least %d", len(got), tc.want)
+			}
+
+			for i := 1; i < len(got); i++ {
+				if got[i-1].Version >= got[i].Version {
+					t.Errorf("Load() migrations are not sorted by version")
+				}
+			}
+		})
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	db := openDB(t)
+	migrator := NewMigrator(db, testMigrations(t, testFiles))
+
+	applied, err := migrator.Up()
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Up() = %d, %v, want 2 migrations applied", len(applied), err)
+	}
+
+	if _, err := db.Exec("INSERT INTO items (name) VALUES ('a')"); err != nil {
+		t.Fatalf("Up() did not create the schema: %v", err)
+	}
+
+	applied, err = migrator.Up()
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("Up() again = %d, %v, want nothing applied", len(applied), err)
+	}
+
+	rolledBack, err := migrator.Down(1)
+	if err != nil || len(rolledBack) != 1 || rolledBack[0].Version != 2 {
+		t.Fatalf("Down(1) = %v, %v, want migration 2 rolled back", rolledBack, err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	if statuses[0].State != StateApplied || statuses[1].State != StatePending {
+		t.Errorf("Status() = %s, %s, want applied, pending", statuses[0].State, statuses[1].State)
+	}
+
+	if _, err := migrator.Down(5); err != nil {
+		t.Fatalf("Down(5) error = %v", err)
+	}
+
+	var tables int
+	db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'items'")
+	if tables != 0 {
+		t.Errorf("Down() did not drop the table")
+	}
+}
+
+func TestUpFailure(t *testing.T) {
+	db := openDB(t)
+	migrator := NewMigrator(db, testMigrations(t, fstest.MapFS{
+		"migrations/0001_create_items.up.sql": {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY);")},
+		"migrations/0002_broken.up.sql":       {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);\nALTER TABLE unknown ADD COLUMN name TEXT;")},
+	}))
+
+	applied, err := migrator.Up()
+	if err == nil || len(applied) != 1 {
+		t.Fatalf("Up() = %d, %v, want error after 1 migration", len(applied), err)
+	}
+
+	var tables int
+	db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'users'")
+	if tables != 0 {
+		t.Errorf("Up() kept the part of the failed migration")
+	}
+
+	statuses, _ := migrator.Status()
+	if statuses[1].State != StatePending {
+		t.Errorf("Status() = %s, want the failed migration pending", statuses[1].State)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	db := openDB(t)
+	if _, err := NewMigrator(db, testMigrations(t, testFiles)).Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	edited := fstest.MapFS{}
+	for name, file := range testFiles {
+		edited[name] = file
+	}
+	edited["migrations/0001_create_items.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT);")}
+
+	migrator := NewMigrator(db, testMigrations(t, edited))
+	if _, err := migrator.Up(); err == nil {
+		t.Errorf("Up() error = nil, want error for the edited migration")
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil || statuses[0].State != StateModified {
+		t.Errorf("Status() = %v, %v, want the first migration modified", statuses, err)
+	}
+
+	removed := fstest.MapFS{
+		"migrations/0001_create_items.up.sql": testFiles["migrations/0001_create_items.up.sql"],
+	}
+	if _, err := NewMigrator(db, testMigrations(t, removed)).Up(); err == nil {
+		t.Errorf("Up() error = nil, want error for the missing migration")
+	}
+}
+
+func TestLock(t *testing.T) {
+	db := openDB(t)
+	migrator := NewMigrator(db, testMigrations(t, testFiles))
+
+	if _, err := migrator.Status(); err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	db.MustExec("INSERT INTO schema_migrations_lock (id, owner) VALUES (1, 'other')")
+
+	migrator.(*migratorImpl).lockWait = 0
+	if _, err := migrator.Up(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Up() error = %v, want ErrLocked", err)
+	}
+
+	if err := migrator.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up() error = %v after unlock", err)
+	}
+
+	var locks int
+	db.Get(&locks, "SELECT COUNT(*) FROM schema_migrations_lock")
+	if locks != 0 {
+		t.Errorf("Up() did not release the lock")
+	}
+
+	// a runner waits for the lock to be released
+	db.MustExec("INSERT INTO schema_migrations_lock (id, owner) VALUES (1, 'other')")
+	migrator.(*migratorImpl).lockWait = 5 * time.Second
+	go func() {
+		time.Sleep(lockInterval)
+		db.MustExec("DELETE FROM schema_migrations_lock WHERE owner = 'other'")
+	}()
+
+	if _, err := migrator.Down(1); err != nil {
+		t.Errorf("Down() error = %v, want the lock taken once released", err)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	db := openDB(t)
+	migrations, err := Load(Migrations, Dir(dialect.SQLite))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	migrator := NewMigrator(db, migrations)
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	if _, err := migrator.Down(len(migrations)); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+
+	var tables int
+	db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'schema_migrations%' AND name != 'sqlite_sequence'")
+	if tables != 0 {
+		t.Errorf("Down() left %d tables behind", tables)
+	}
+}
+
+// TestDatabases checks every database has the same versions, only SQLite
+// runs in the tests.
+func TestDatabases(t *testing.T) {
+	want, err := Load(Migrations, Dir(dialect.SQLite))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, driverName := range []string{dialect.Postgres, dialect.MySQL} {
+		t.Run(driverName, func(t *testing.T) {
+			got, err := Load(Migrations, Dir(driverName))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("Load() = %d migrations, want %d", len(got), len(want))
+			}
+
+			for i := range got {
+				if got[i].String() != want[i].String() || got[i].Down == "" {
+					t.Errorf("Load() migration %s, want %s with a down script", got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLegacyDatabase(t *testing.T) {
+	db := openDB(t)
+	db.MustExec(`CREATE TABLE customers (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(255) NOT NULL, username VARCHAR(255) UNIQUE NOT NULL, email VARCHAR(255) NOT NULL, password TEXT NOT NULL, phone_number VARCHAR(255) NOT NULL, address TEXT NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)`)
+	db.MustExec(`CREATE TABLE cart_items (id INTEGER PRIMARY KEY AUTOINCREMENT, shopping_cart_id INT NOT NULL, product_id INTEGER NOT NULL, quantity INTEGER NOT NULL)`)
+	db.MustExec(`CREATE UNIQUE INDEX idx_product_cart ON cart_items (product_id, shopping_cart_id)`)
+	db.MustExec(`INSERT INTO customers (name, username, email, password, phone_number, address) VALUES ('a', 'alice', 'a@example.com', 'x', '0812', 'x')`)
+
+	migrations, err := Load(Migrations, Dir(dialect.SQLite))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, err := NewMigrator(db, migrations).Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	var customers int
+	if err := db.Get(&customers, "SELECT COUNT(*) FROM customers WHERE deleted_at IS NULL AND totp_secret IS NULL"); err != nil || customers != 1 {
+		t.Errorf("Up() customers = %d, %v, want the customer kept with the new columns", customers, err)
+	}
+
+	var columns int
+	db.Get(&columns, "SELECT COUNT(*) FROM pragma_index_info('idx_product_cart')")
+	if columns != 3 {
+		t.Errorf("Up() idx_product_cart has %d columns, want 3", columns)
+	}
+}
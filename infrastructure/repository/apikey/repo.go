@@ -0,0 +1,17 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=APIKeyRepository.go
+type Repository interface {
+	Create(ctx context.Context, apiKey *model.APIKeyEntity) error
+	GetByPrefix(ctx context.Context, prefix string) (*model.APIKeyEntity, error)
+	GetAll(ctx context.Context) ([]*model.APIKeyEntity, error)
+	Revoke(ctx context.Context, id int) error
+	Touch(ctx context.Context, id int, usedAt time.Time, interval time.Duration) error
+}
@@ -0,0 +1,118 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+type apiKeyRepoImpl struct {
+	db      dbtx.DB
+	dialect dialect.Dialect
+}
+
+func NewAPIKeyRepository(db dbtx.DB) Repository {
+	return &apiKeyRepoImpl{db: db, dialect: dialect.For(db)}
+}
+
+func (a *apiKeyRepoImpl) Create(ctx context.Context, apiKey *model.APIKeyEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "apikey", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("apikey", "Create").ObserveDuration()
+
+	query := "INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at) VALUES (:name, :prefix, :key_hash, :scopes, :created_by, :expires_at)"
+	id, err := a.dialect.NamedInsert(ctx, a.db, query, apiKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "apikey", "error", err)
+		return err
+	}
+	apiKey.ID = int(id)
+
+	return nil
+}
+
+// GetByPrefix also returns revoked and expired keys, the caller decides.
+func (a *apiKeyRepoImpl) GetByPrefix(ctx context.Context, prefix string) (*model.APIKeyEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "apikey", "GetByPrefix")
+	defer span.End()
+	defer metrics.QueryTimer("apikey", "GetByPrefix").ObserveDuration()
+
+	apiKey := &model.APIKeyEntity{}
+	query := "SELECT id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE prefix = ?"
+
+	err := a.db.GetContext(ctx, apiKey, a.db.Rebind(query), prefix)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.ErrorContext(ctx, "repository error", "repository", "apikey", "error", err)
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (a *apiKeyRepoImpl) GetAll(ctx context.Context) ([]*model.APIKeyEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "apikey", "GetAll")
+	defer span.End()
+	defer metrics.QueryTimer("apikey", "GetAll").ObserveDuration()
+
+	apiKeys := []*model.APIKeyEntity{}
+	query := "SELECT id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at FROM api_keys ORDER BY id"
+
+	err := a.db.SelectContext(ctx, &apiKeys, a.db.Rebind(query))
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "apikey", "error", err)
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+// Revoke returns sql.ErrNoRows when the key does not exist or was already revoked.
+func (a *apiKeyRepoImpl) Revoke(ctx context.Context, id int) error {
+	ctx, span := tracing.StartQuery(ctx, "apikey", "Revoke")
+	defer span.End()
+	defer metrics.QueryTimer("apikey", "Revoke").ObserveDuration()
+
+	res, err := a.db.ExecContext(ctx, a.db.Rebind("UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL"), id)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "apikey", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "apikey", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "apikey", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Touch records the use of the key, at most once per interval so a busy
+// client does not write on every request.
+func (a *apiKeyRepoImpl) Touch(ctx context.Context, id int, usedAt time.Time, interval time.Duration) error {
+	ctx, span := tracing.StartQuery(ctx, "apikey", "Touch")
+	defer span.End()
+	defer metrics.QueryTimer("apikey", "Touch").ObserveDuration()
+
+	query := "UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)"
+	if _, err := a.db.ExecContext(ctx, a.db.Rebind(query), usedAt, id, usedAt.Add(-interval)); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "apikey", "error", err)
+		return err
+	}
+
+	return nil
+}
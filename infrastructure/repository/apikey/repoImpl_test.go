@@ -0,0 +1,201 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+const selectQuery = "SELECT id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at FROM api_keys"
+
+var columns = []string{"id", "name", "prefix", "key_hash", "scopes", "created_by", "expires_at", "last_used_at", "revoked_at", "created_at"}
+
+func TestCreate(t *testing.T) {
+	dialecttest.Run(t, testCreate)
+}
+
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at) VALUES (?, ?, ?, ?, ?, ?)"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantID  int
+		wantErr bool
+	}{
+		{
+			name: "Given valid key when create then set the id",
+			mock: func() {
+				dialecttest.ExpectInsert(mock, driverName, query).
+					WithArgs("warehouse", "osk_1a2b3c4d", "hash", "catalog:read,orders:read", 1, nil).
+					WillReturnID(7)
+			},
+			wantID:  7,
+			wantErr: false,
+		},
+		{
+			name: "Given error when create then return error",
+			mock: func() {
+				dialecttest.ExpectInsert(mock, driverName, query).
+					WithArgs("warehouse", "osk_1a2b3c4d", "hash", "catalog:read,orders:read", 1, nil).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			apiKey := &model.APIKeyEntity{Name: "warehouse", Prefix: "osk_1a2b3c4d", KeyHash: "hash", Scopes: "catalog:read,orders:read", CreatedBy: 1}
+			repo := NewAPIKeyRepository(sqlxDB)
+			err := repo.Create(context.Background(), apiKey)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if apiKey.ID != tc.wantID {
+				t.Errorf("Create() id = %d, want %d", apiKey.ID, tc.wantID)
+			}
+		})
+	}
+}
+
+func TestGetByPrefix(t *testing.T) {
+	dialecttest.Run(t, testGetByPrefix)
+}
+
+func testGetByPrefix(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := selectQuery + " WHERE prefix = ?"
+	now := time.Now()
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given existing prefix when get by prefix then return the key",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("osk_1a2b3c4d").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "warehouse", "osk_1a2b3c4d", "hash", "catalog:read", 1, nil, now, nil, now))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given unknown prefix when get by prefix then return no rows",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("osk_1a2b3c4d").WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewAPIKeyRepository(sqlxDB)
+			apiKey, err := repo.GetByPrefix(context.Background(), "osk_1a2b3c4d")
+			if err != tc.wantErr {
+				t.Fatalf("GetByPrefix() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if err == nil && (apiKey.ID != 1 || apiKey.LastUsedAt == nil || apiKey.ExpiresAt != nil) {
+				t.Errorf("GetByPrefix() = %+v", apiKey)
+			}
+		})
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	dialecttest.Run(t, testGetAll)
+}
+
+func testGetAll(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	now := time.Now()
+	mock.ExpectQuery(selectQuery + " ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "warehouse", "osk_1a2b3c4d", "hash", "catalog:read", 1, nil, nil, nil, now).
+			AddRow(2, "reporting", "osk_5e6f7a8b", "hash", "orders:read", 1, now, nil, now, now))
+
+	repo := NewAPIKeyRepository(sqlxDB)
+	apiKeys, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+
+	if len(apiKeys) != 2 || apiKeys[1].RevokedAt == nil {
+		t.Errorf("GetAll() = %+v", apiKeys)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	dialecttest.Run(t, testRevoke)
+}
+
+func testRevoke(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL"
+
+	testCases := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "Given active key when revoke then return success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "Given revoked or unknown key when revoke then return no rows",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, tc.affected))
+			repo := NewAPIKeyRepository(sqlxDB)
+			if err := repo.Revoke(context.Background(), 1); err != tc.wantErr {
+				t.Errorf("Revoke() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTouch(t *testing.T) {
+	dialecttest.Run(t, testTouch)
+}
+
+func testTouch(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)").
+		WithArgs(now, 1, now.Add(-time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewAPIKeyRepository(sqlxDB)
+	if err := repo.Touch(context.Background(), 1, now, time.Minute); err != nil {
+		t.Errorf("Touch() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
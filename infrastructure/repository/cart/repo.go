@@ -1,13 +1,19 @@
 package cart
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=CartRepository.go
 type Repository interface {
-	Create(cart *model.CartEntity) (*model.CartEntity, error)
-	GetByParams(request *model.GetCartRequest) ([]*model.CartEntity, error)
-	Upsert(cartID int, items []*model.CartItemEntity) (*model.CartEntity, error)
-	Delete(request *model.DeleteCartRequest) error
-	GetItemByID(cartItemID int) (*model.CartItemEntity, error)
-	GetByID(cartID int) (*model.CartEntity, error)
+	Create(ctx context.Context, cart *model.CartEntity) (*model.CartEntity, error)
+	GetByParams(ctx context.Context, request *model.GetCartRequest) ([]*model.CartEntity, error)
+	Upsert(ctx context.Context, cartID int, items []*model.CartItemEntity) (*model.CartEntity, error)
+	Delete(ctx context.Context, request *model.DeleteCartRequest) error
+	GetItemByID(ctx context.Context, cartItemID int) (*model.CartItemEntity, error)
+	GetByID(ctx context.Context, cartID int) (*model.CartEntity, error)
+	UpdateStatus(ctx context.Context, cartID int, status cartEnum.Status) error
 }
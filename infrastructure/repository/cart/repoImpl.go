@@ -1,48 +1,51 @@
 package cart
 
 import (
-	"fmt"
-	"log"
-
-	"github.com/jmoiron/sqlx"
+	"context"
+	"database/sql"
+	"log/slog"
+
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 type cartRepoImpl struct {
-	db *sqlx.DB
+	db      dbtx.DB
+	dialect dialect.Dialect
 }
 
-func NewCartRepository(db *sqlx.DB) Repository {
-	return &cartRepoImpl{db: db}
+func NewCartRepository(db dbtx.DB) Repository {
+	return &cartRepoImpl{db: db, dialect: dialect.For(db)}
 }
 
-func (c *cartRepoImpl) Create(cart *model.CartEntity) (*model.CartEntity, error) {
-	tx, err := c.db.Beginx()
-	if err != nil {
-		log.Println("errorRepository: ", err.Error())
-		return nil, err
-	}
+func (c *cartRepoImpl) Create(ctx context.Context, cart *model.CartEntity) (*model.CartEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "cart", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "Create").ObserveDuration()
 
-	res, err := tx.NamedExec(`INSERT INTO shopping_carts (customer_id, status) VALUES (:customer_id, :status)`, cart)
+	tx, err := dbtx.Begin(ctx, c.db)
 	if err != nil {
-		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
-	cartID, err := res.LastInsertId()
+	cartID, err := c.dialect.NamedInsert(ctx, tx, `INSERT INTO shopping_carts (customer_id, status) VALUES (:customer_id, :status)`, cart)
 	if err != nil {
 		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
 	for _, item := range cart.Items {
 		item.CartID = int(cartID)
-		_, err = tx.NamedExec(`INSERT INTO cart_items (shopping_cart_id, product_id, quantity) VALUES (:shopping_cart_id, :product_id, :quantity)`, item)
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO cart_items (shopping_cart_id, product_id, product_variant_id, quantity) VALUES (:shopping_cart_id, :product_id, :product_variant_id, :quantity)`, item)
 		if err != nil {
 			tx.Rollback()
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 			return nil, err
 		}
 	}
@@ -50,27 +53,27 @@ func (c *cartRepoImpl) Create(cart *model.CartEntity) (*model.CartEntity, error)
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
-	return c.getByID(int(cartID))
+	return c.getByID(ctx, int(cartID))
 }
 
-func (c *cartRepoImpl) getByID(id int) (*model.CartEntity, error) {
+func (c *cartRepoImpl) getByID(ctx context.Context, id int) (*model.CartEntity, error) {
 	cart := &model.CartEntity{}
 	query := "SELECT id, customer_id, status, created_at, updated_at FROM shopping_carts WHERE id = ?"
-	err := c.db.Get(cart, query, id)
+	err := c.db.GetContext(ctx, cart, c.db.Rebind(query), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
 	items := []*model.CartItemEntity{}
-	queryItem := "SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id"
-	err = c.db.Select(&items, queryItem, id)
+	queryItem := "SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id"
+	err = c.db.SelectContext(ctx, &items, c.db.Rebind(queryItem), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
@@ -78,7 +81,11 @@ func (c *cartRepoImpl) getByID(id int) (*model.CartEntity, error) {
 	return cart, nil
 }
 
-func (c *cartRepoImpl) GetByParams(request *model.GetCartRequest) ([]*model.CartEntity, error) {
+func (c *cartRepoImpl) GetByParams(ctx context.Context, request *model.GetCartRequest) ([]*model.CartEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "cart", "GetByParams")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "GetByParams").ObserveDuration()
+
 	carts := []*model.CartEntity{}
 	params := make([]interface{}, 0)
 	query := "SELECT id, customer_id, status, created_at, updated_at FROM shopping_carts WHERE TRUE"
@@ -94,16 +101,16 @@ func (c *cartRepoImpl) GetByParams(request *model.GetCartRequest) ([]*model.Cart
 	}
 
 	query += " ORDER BY id"
-	res, err := c.db.Queryx(query, params...)
+	res, err := c.db.QueryxContext(ctx, c.db.Rebind(query), params...)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
 	for res.Next() {
 		cartEntity := &model.CartEntity{}
 		if err := res.StructScan(cartEntity); err != nil {
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 			return nil, err
 		}
 		carts = append(carts, cartEntity)
@@ -115,16 +122,16 @@ func (c *cartRepoImpl) GetByParams(request *model.GetCartRequest) ([]*model.Cart
 
 	for _, cart := range carts {
 		items := []*model.CartItemEntity{}
-		query = "SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id DESC"
-		res, err = c.db.Queryx(query, cart.ID)
+		query = "SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id DESC"
+		res, err = c.db.QueryxContext(ctx, c.db.Rebind(query), cart.ID)
 		if err != nil {
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 			return nil, err
 		}
 		for res.Next() {
 			item := &model.CartItemEntity{}
 			if err := res.StructScan(item); err != nil {
-				log.Println("errorRepository: ", err.Error())
+				slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 				return nil, err
 			}
 			items = append(items, item)
@@ -135,24 +142,26 @@ func (c *cartRepoImpl) GetByParams(request *model.GetCartRequest) ([]*model.Cart
 	return carts, nil
 }
 
-func (c *cartRepoImpl) Upsert(cartID int, items []*model.CartItemEntity) (*model.CartEntity, error) {
-	tx, err := c.db.Beginx()
+func (c *cartRepoImpl) Upsert(ctx context.Context, cartID int, items []*model.CartItemEntity) (*model.CartEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "cart", "Upsert")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "Upsert").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, c.db)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
 	for _, item := range items {
 		queryUpsert := `
-			INSERT INTO cart_items (product_id, shopping_cart_id, quantity)
-			VALUES (:product_id, :shopping_cart_id, :quantity)
-			ON CONFLICT(product_id, shopping_cart_id) DO UPDATE SET
-				quantity = cart_items.quantity + excluded.quantity
-		`
-		_, err = tx.NamedExec(queryUpsert, item)
+			INSERT INTO cart_items (product_id, product_variant_id, shopping_cart_id, quantity)
+			VALUES (:product_id, :product_variant_id, :shopping_cart_id, :quantity)
+			` + c.dialect.OnConflict([]string{"product_id", "product_variant_id", "shopping_cart_id"}, "quantity = cart_items.quantity + "+c.dialect.Excluded("quantity"))
+		_, err = tx.NamedExecContext(ctx, queryUpsert, item)
 		if err != nil {
 			tx.Rollback()
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 			return nil, err
 		}
 	}
@@ -160,67 +169,108 @@ func (c *cartRepoImpl) Upsert(cartID int, items []*model.CartItemEntity) (*model
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
-	return c.getByID(int(cartID))
+	return c.getByID(ctx, int(cartID))
 }
 
-func (c *cartRepoImpl) Delete(request *model.DeleteCartRequest) error {
-tx, err := c.db.Beginx()
-    if err != nil {
-		log.Println("errorRepository: ", err.Error())
-        return err
-    }
-
-    res, err := c.db.Exec(`
-        DELETE FROM cart_items
-        WHERE id = ? 
-        AND EXISTS (SELECT 1 FROM shopping_carts WHERE id = ? AND status = ? AND customer_id = ?)
-    `, request.CartItemID, request.ID, request.Status, request.CustomerID)
-    if err != nil {
-        tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
-        return err
-    }
-
-    affected, err := res.RowsAffected()
-    if err != nil {
-        tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
-        return err
-    }
-
-    if affected == 0 {
-        tx.Rollback()
-		err := fmt.Errorf("no active cart found with cart_item_id: %d", request.CartItemID)
-		log.Println("errorRepository: ", err.Error())
-        return err
-    }
-
-    err = tx.Commit()
-    if err != nil {
-        tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
-        return err
-    }
+// Delete returns sql.ErrNoRows when the item is not in an active cart of the
+// customer.
+func (c *cartRepoImpl) Delete(ctx context.Context, request *model.DeleteCartRequest) error {
+	ctx, span := tracing.StartQuery(ctx, "cart", "Delete")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "Delete").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, c.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, tx.Rebind(`
+		DELETE FROM cart_items
+		WHERE id = ?
+		AND EXISTS (SELECT 1 FROM shopping_carts WHERE id = ? AND status = ? AND customer_id = ?)
+	`), request.CartItemID, request.ID, request.Status, request.CustomerID)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		tx.Rollback()
+		slog.DebugContext(ctx, "repository error", "repository", "cart", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
+		return err
+	}
 
 	return nil
 }
 
-func (c *cartRepoImpl) GetItemByID(cartItemID int) (*model.CartItemEntity, error) {
+func (c *cartRepoImpl) GetItemByID(ctx context.Context, cartItemID int) (*model.CartItemEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "cart", "GetItemByID")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "GetItemByID").ObserveDuration()
+
 	cartItem := &model.CartItemEntity{}
-	query := "SELECT id, shopping_cart_id, product_id, quantity FROM cart_items WHERE id = ?"
-	err := c.db.Get(cartItem, query, cartItemID)
+	query := "SELECT id, shopping_cart_id, product_id, product_variant_id, quantity FROM cart_items WHERE id = ?"
+	err := c.db.GetContext(ctx, cartItem, c.db.Rebind(query), cartItemID)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
 		return nil, err
 	}
 
 	return cartItem, nil
 }
 
-func (c *cartRepoImpl) GetByID(cartID int) (*model.CartEntity, error) {
-	return c.getByID(cartID)
-}
\ No newline at end of file
+func (c *cartRepoImpl) GetByID(ctx context.Context, cartID int) (*model.CartEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "cart", "GetByID")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "GetByID").ObserveDuration()
+
+	return c.getByID(ctx, cartID)
+}
+
+// UpdateStatus only moves an active cart, it returns sql.ErrNoRows when the
+// cart does not exist or was already checked out.
+func (c *cartRepoImpl) UpdateStatus(ctx context.Context, cartID int, status cartEnum.Status) error {
+	ctx, span := tracing.StartQuery(ctx, "cart", "UpdateStatus")
+	defer span.End()
+	defer metrics.QueryTimer("cart", "UpdateStatus").ObserveDuration()
+
+	query := "UPDATE shopping_carts SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), status, cartID, cartEnum.CartStatusActive)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "cart", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "cart", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -1,25 +1,24 @@
 package cart
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/jmoiron/sqlx"
 	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 func TestCreate(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testCreate)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	items := []*model.CartItemEntity{
 		{
@@ -46,12 +45,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
 					WithArgs(request.CustomerID, request.Status).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, quantity) VALUES (?, ?, ?)").
-					WithArgs(1, request.Items[0].ProductID, request.Items[0].Quantity).
+				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, product_variant_id, quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, request.Items[0].ProductID, request.Items[0].ProductVariantID, request.Items[0].Quantity).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -61,10 +60,10 @@ func TestCreate(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id").
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "quantity", "price", "product_name"}).
-						AddRow(1, 1, 1, 1, 1000, "Product 1"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "product_variant_id", "quantity", "price", "product_name", "sku"}).
+						AddRow(1, 1, 1, 0, 1, 1000, "Product 1", ""))
 			},
 			wantErr: false,
 		},
@@ -82,7 +81,7 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
 					WithArgs(request.CustomerID, request.Status).
 					WillReturnError(errors.New("error"))
 
@@ -96,12 +95,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
 					WithArgs(request.CustomerID, request.Status).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, quantity) VALUES (?, ?, ?)").
-					WithArgs(1, request.Items[0].ProductID, request.Items[0].Quantity).
+				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, product_variant_id, quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, request.Items[0].ProductID, request.Items[0].ProductVariantID, request.Items[0].Quantity).
 					WillReturnError(errors.New("error"))
 
 				mock.ExpectRollback()
@@ -115,12 +114,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
 					WithArgs(request.CustomerID, request.Status).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, quantity) VALUES (?, ?, ?)").
-					WithArgs(1, request.Items[0].ProductID, request.Items[0].Quantity).
+				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, product_variant_id, quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, request.Items[0].ProductID, request.Items[0].ProductVariantID, request.Items[0].Quantity).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -130,7 +129,7 @@ func TestCreate(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id").
 					WithArgs(1).
 					WillReturnError(errors.New("error"))
 			},
@@ -142,12 +141,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
 					WithArgs(request.CustomerID, request.Status).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, quantity) VALUES (?, ?, ?)").
-					WithArgs(1, request.Items[0].ProductID, request.Items[0].Quantity).
+				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, product_variant_id, quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, request.Items[0].ProductID, request.Items[0].ProductVariantID, request.Items[0].Quantity).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -164,12 +163,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO shopping_carts (customer_id, status) VALUES (?, ?)").
 					WithArgs(request.CustomerID, request.Status).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, quantity) VALUES (?, ?, ?)").
-					WithArgs(1, request.Items[0].ProductID, request.Items[0].Quantity).
+				mock.ExpectExec("INSERT INTO cart_items (shopping_cart_id, product_id, product_variant_id, quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, request.Items[0].ProductID, request.Items[0].ProductVariantID, request.Items[0].Quantity).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit().WillReturnError(errors.New("error"))
@@ -184,7 +183,7 @@ func TestCreate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewCartRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.Create(tc.request)
+			_, err := repo.Create(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -194,14 +193,11 @@ func TestCreate(t *testing.T) {
 }
 
 func TestGetByID(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetByID)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetByID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name    string
@@ -216,10 +212,10 @@ func TestGetByID(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id").
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "quantity", "price", "product_name"}).
-						AddRow(1, 1, 1, 1, 1000, "Product 1"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "product_variant_id", "quantity", "price", "product_name", "sku"}).
+						AddRow(1, 1, 1, 0, 1, 1000, "Product 1", ""))
 			},
 			wantErr: false,
 		},
@@ -240,7 +236,7 @@ func TestGetByID(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id").
 					WithArgs(1).
 					WillReturnError(errors.New("error"))
 			},
@@ -252,7 +248,7 @@ func TestGetByID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewCartRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.GetByID(1)
+			_, err := repo.GetByID(context.Background(), 1)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetByID() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -262,14 +258,11 @@ func TestGetByID(t *testing.T) {
 }
 
 func TestGetByParams(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetByParams)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetByParams(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	status := int(cartEnum.CartStatusActive)
 
@@ -291,10 +284,10 @@ func TestGetByParams(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id DESC").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id DESC").
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "quantity", "price", "product_name"}).
-						AddRow(1, 1, 1, 1, 1000, "Product 1"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "product_variant_id", "quantity", "price", "product_name", "sku"}).
+						AddRow(1, 1, 1, 0, 1, 1000, "Product 1", ""))
 			},
 			wantErr: false,
 		},
@@ -310,7 +303,7 @@ func TestGetByParams(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id DESC").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id DESC").
 					WithArgs(1).
 					WillReturnError(errors.New("error"))
 			},
@@ -348,7 +341,7 @@ func TestGetByParams(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewCartRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.GetByParams(tc.request)
+			_, err := repo.GetByParams(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetByParams() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -358,14 +351,11 @@ func TestGetByParams(t *testing.T) {
 }
 
 func TestUpsert(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testUpsert)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testUpsert(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	request := []*model.CartItemEntity{
 		{
@@ -375,6 +365,12 @@ func TestUpsert(t *testing.T) {
 		},
 	}
 
+	upsertQuery := map[string]string{
+		dialect.SQLite:   "INSERT INTO cart_items (product_id, product_variant_id, shopping_cart_id, quantity) VALUES (?, ?, ?, ?) ON CONFLICT(product_id, product_variant_id, shopping_cart_id) DO UPDATE SET quantity = cart_items.quantity + excluded.quantity",
+		dialect.Postgres: "INSERT INTO cart_items (product_id, product_variant_id, shopping_cart_id, quantity) VALUES (?, ?, ?, ?) ON CONFLICT(product_id, product_variant_id, shopping_cart_id) DO UPDATE SET quantity = cart_items.quantity + excluded.quantity",
+		dialect.MySQL:    "INSERT INTO cart_items (product_id, product_variant_id, shopping_cart_id, quantity) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE quantity = cart_items.quantity + VALUES(quantity)",
+	}[driverName]
+
 	testCases := []struct {
 		name    string
 		request []*model.CartItemEntity
@@ -387,8 +383,8 @@ func TestUpsert(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO cart_items (product_id, shopping_cart_id, quantity) VALUES (?, ?, ?) ON CONFLICT(product_id, shopping_cart_id) DO UPDATE SET quantity = cart_items.quantity + excluded.quantity").
-					WithArgs(request[0].ProductID, request[0].CartID, request[0].Quantity).
+				mock.ExpectExec(upsertQuery).
+					WithArgs(request[0].ProductID, request[0].ProductVariantID, request[0].CartID, request[0].Quantity).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -398,10 +394,10 @@ func TestUpsert(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "status", "created_at", "updated_at"}).
 						AddRow(1, 1, cartEnum.CartStatusActive, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.quantity, p.price, p.name AS product_name FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id WHERE shopping_cart_id = ? ORDER BY ci.id").
+				mock.ExpectQuery("SELECT ci.id, ci.shopping_cart_id, ci.product_id, ci.product_variant_id, ci.quantity, COALESCE(v.price, p.price) AS price, p.name AS product_name, COALESCE(v.sku, '') AS sku FROM cart_items AS ci JOIN products AS p ON ci.product_id = p.id LEFT JOIN product_variants AS v ON ci.product_variant_id = v.id WHERE shopping_cart_id = ? ORDER BY ci.id").
 					WithArgs(request[0].CartID).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "quantity", "price", "product_name"}).
-						AddRow(1, 1, 1, 1, 1000, "Product 1"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "product_variant_id", "quantity", "price", "product_name", "sku"}).
+						AddRow(1, 1, 1, 0, 1, 1000, "Product 1", ""))
 			},
 			wantErr: false,
 		},
@@ -411,8 +407,8 @@ func TestUpsert(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO cart_items (product_id, shopping_cart_id, quantity) VALUES (?, ?, ?) ON CONFLICT(product_id, shopping_cart_id) DO UPDATE SET quantity = cart_items.quantity + excluded.quantity").
-					WithArgs(request[0].ProductID, request[0].CartID, request[0].Quantity).
+				mock.ExpectExec(upsertQuery).
+					WithArgs(request[0].ProductID, request[0].ProductVariantID, request[0].CartID, request[0].Quantity).
 					WillReturnError(errors.New("error"))
 
 				mock.ExpectRollback()
@@ -425,8 +421,8 @@ func TestUpsert(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO cart_items (product_id, shopping_cart_id, quantity) VALUES (?, ?, ?) ON CONFLICT(product_id, shopping_cart_id) DO UPDATE SET quantity = cart_items.quantity + excluded.quantity").
-					WithArgs(request[0].ProductID, request[0].CartID, request[0].Quantity).
+				mock.ExpectExec(upsertQuery).
+					WithArgs(request[0].ProductID, request[0].ProductVariantID, request[0].CartID, request[0].Quantity).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit().WillReturnError(errors.New("error"))
@@ -449,7 +445,7 @@ func TestUpsert(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewCartRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.Upsert(1, tc.request)
+			_, err := repo.Upsert(context.Background(), 1, tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Upsert() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -459,14 +455,11 @@ func TestUpsert(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testDelete)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testDelete(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name    string
@@ -540,7 +533,7 @@ func TestDelete(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewCartRepository(sqlxDB)
 			tc.mock()
-			err := repo.Delete(&model.DeleteCartRequest{
+			err := repo.Delete(context.Background(), &model.DeleteCartRequest{
 				CartItemID: 1,
 				ID:     1,
 				CustomerID: 1,
@@ -555,14 +548,11 @@ func TestDelete(t *testing.T) {
 }
 
 func TestGetItemByID(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetItemByID)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetItemByID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name    string
@@ -572,17 +562,17 @@ func TestGetItemByID(t *testing.T) {
 		{
 			name: "Given valid request when get item by id then return success",
 			mock: func() {
-				mock.ExpectQuery("SELECT id, shopping_cart_id, product_id, quantity FROM cart_items WHERE id = ?").
+				mock.ExpectQuery("SELECT id, shopping_cart_id, product_id, product_variant_id, quantity FROM cart_items WHERE id = ?").
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "quantity"}).
-						AddRow(1, 1, 1, 1))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "shopping_cart_id", "product_id", "product_variant_id", "quantity"}).
+						AddRow(1, 1, 1, 0, 1))
 			},
 			wantErr: false,
 		},
 		{
 			name: "Given error when get item by id then return error",
 			mock: func() {
-				mock.ExpectQuery("SELECT id, shopping_cart_id, product_id, quantity FROM cart_items WHERE id = ?").
+				mock.ExpectQuery("SELECT id, shopping_cart_id, product_id, product_variant_id, quantity FROM cart_items WHERE id = ?").
 					WithArgs(1).
 					WillReturnError(errors.New("error"))
 			},
@@ -594,7 +584,7 @@ func TestGetItemByID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewCartRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.GetItemByID(1)
+			_, err := repo.GetItemByID(context.Background(), 1)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetItemByID() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -602,3 +592,58 @@ func TestGetItemByID(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateStatus(t *testing.T) {
+	dialecttest.Run(t, testUpdateStatus)
+}
+
+func testUpdateStatus(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE shopping_carts SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given active cart when update status then return success",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(cartEnum.CartStatusPending, 1, cartEnum.CartStatusActive).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given cart not found or not active when update status then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(cartEnum.CartStatusPending, 1, cartEnum.CartStatusActive).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when update status then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(cartEnum.CartStatusPending, 1, cartEnum.CartStatusActive).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewCartRepository(sqlxDB)
+			tc.mock()
+			err := repo.UpdateStatus(context.Background(), 1, cartEnum.CartStatusPending)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
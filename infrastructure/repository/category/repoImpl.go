@@ -1,37 +1,48 @@
 package category
 
 import (
-	"log"
+	"context"
+	"log/slog"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 type categoryRepoImpl struct {
-	db *sqlx.DB
+	db dbtx.DB
 }
 
-func NewCategoryRepository(db *sqlx.DB) Repository {
+func NewCategoryRepository(db dbtx.DB) Repository {
 	return &categoryRepoImpl{db: db}
 }
 
-func (c *categoryRepoImpl) Create(category *model.CategoryEntity) error {
+func (c *categoryRepoImpl) Create(ctx context.Context, category *model.CategoryEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "category", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("category", "Create").ObserveDuration()
+
 	query := "INSERT INTO categories (name) VALUES (:name)"
-	_, err := c.db.NamedExec(query, category)
+	_, err := c.db.NamedExecContext(ctx, query, category)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "category", "error", err)
 		return err
 	}
 	
 	return nil
 }
 
-func (c *categoryRepoImpl) GetAll() ([]*model.CategoryEntity, error) {
+func (c *categoryRepoImpl) GetAll(ctx context.Context) ([]*model.CategoryEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "category", "GetAll")
+	defer span.End()
+	defer metrics.QueryTimer("category", "GetAll").ObserveDuration()
+
 	categories := make([]*model.CategoryEntity, 0)
 	query := "SELECT id, name, created_at, updated_at FROM categories"
-	res, err := c.db.Query(query)
+	res, err := c.db.QueryContext(ctx, c.db.Rebind(query))
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "category", "error", err)
 		return nil, err
 	}
 
@@ -43,7 +54,7 @@ func (c *categoryRepoImpl) GetAll() ([]*model.CategoryEntity, error) {
 			&category.CreatedAt,
 			&category.UpdatedAt,
 		); err != nil {
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "category", "error", err)
 			return nil, err
 		}
 		categories = append(categories, category)
@@ -52,13 +63,17 @@ func (c *categoryRepoImpl) GetAll() ([]*model.CategoryEntity, error) {
 	return categories, nil
 }
 
-func (c *categoryRepoImpl) GetByID(id int) (*model.CategoryEntity, error) {
+func (c *categoryRepoImpl) GetByID(ctx context.Context, id int) (*model.CategoryEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "category", "GetByID")
+	defer span.End()
+	defer metrics.QueryTimer("category", "GetByID").ObserveDuration()
+
 	category := &model.CategoryEntity{}
 	query := "SELECT id, name, created_at, updated_at FROM categories WHERE id = ?"
 
-	err := c.db.Get(category, query, id)
+	err := c.db.GetContext(ctx, category, c.db.Rebind(query), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "category", "error", err)
 		return nil, err
 	}
 
@@ -1,24 +1,22 @@
 package category
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/jmoiron/sqlx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 func TestCreate(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testCreate)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 	
 	testCases := []struct {
 		name     string
@@ -52,7 +50,7 @@ func TestCreate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			c := NewCategoryRepository(sqlxDB)
 			tc.mock()
-			err := c.Create(&model.CategoryEntity{
+			err := c.Create(context.Background(), &model.CategoryEntity{
 				Name: tc.categoryName,
 			})
 			if (err != nil) != tc.wantErr {
@@ -63,14 +61,11 @@ func TestCreate(t *testing.T) {
 }
 
 func TestGetAll(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetAll)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetAll(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name     string
@@ -109,7 +104,7 @@ func TestGetAll(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			c := NewCategoryRepository(sqlxDB)
 			tc.mock()
-			_, err := c.GetAll()
+			_, err := c.GetAll(context.Background())
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetAll() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -118,14 +113,11 @@ func TestGetAll(t *testing.T) {
 }
 
 func TestGetByID(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetByID)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetByID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name     string
@@ -160,7 +152,7 @@ func TestGetByID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			c := NewCategoryRepository(sqlxDB)
 			tc.mock()
-			_, err := c.GetByID(tc.categoryID)
+			_, err := c.GetByID(context.Background(), tc.categoryID)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetByID() error = %v, wantErr %v", err, tc.wantErr)
 			}
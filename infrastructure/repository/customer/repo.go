@@ -1,9 +1,28 @@
 package customer
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	customerEnum "github.com/zakiyalmaya/online-store/constant/customer"
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=CustomerRepository.go
 type Repository interface {
-	Create(customer *model.CustomerEntity) error
-	GetByUsername(username string) (*model.CustomerEntity, error)
+	Create(ctx context.Context, customer *model.CustomerEntity) error
+	GetByUsername(ctx context.Context, username string) (*model.CustomerEntity, error)
+	GetByID(ctx context.Context, id int) (*model.CustomerEntity, error)
+	GetByEmail(ctx context.Context, email string) ([]*model.CustomerEntity, error)
+	Update(ctx context.Context, customer *model.CustomerEntity) error
+	UpdatePassword(ctx context.Context, id int, password string) error
+	VerifyEmail(ctx context.Context, id int, email string) error
+	Anonymize(ctx context.Context, customer *model.CustomerEntity) error
+	CreateToken(ctx context.Context, token *model.CustomerTokenEntity) error
+	GetToken(ctx context.Context, tokenType customerEnum.TokenType, tokenHash string) (*model.CustomerTokenEntity, error)
+	UseToken(ctx context.Context, id int) error
+	SetTOTPSecret(ctx context.Context, id int, secret string) error
+	EnableTOTP(ctx context.Context, id int, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, id int) error
+	UseTOTPStep(ctx context.Context, id int, step int64) error
+	UseRecoveryCode(ctx context.Context, id int, codeHash string) error
 }
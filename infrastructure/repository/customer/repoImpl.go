@@ -1,40 +1,452 @@
 package customer
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
 
-	"github.com/jmoiron/sqlx"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	customerEnum "github.com/zakiyalmaya/online-store/constant/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
+var (
+	ErrUsernameTaken = errors.New("username already taken")
+	ErrEmailTaken    = errors.New("email already taken")
+)
+
 type customerRepoImpl struct {
-	db *sqlx.DB
+	db      dbtx.DB
+	dialect dialect.Dialect
 }
 
-func NewCustomerRepository(db *sqlx.DB) Repository {
-	return &customerRepoImpl{db: db}
+func NewCustomerRepository(db dbtx.DB) Repository {
+	return &customerRepoImpl{db: db, dialect: dialect.For(db)}
 }
 
-func (c *customerRepoImpl) Create(customer *model.CustomerEntity) error {
+func (c *customerRepoImpl) Create(ctx context.Context, customer *model.CustomerEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "Create").ObserveDuration()
+
 	query := "INSERT INTO customers (name, username, password, email, phone_number, address) VALUES (:name, :username, :password, :email, :phone_number, :address)"
-	_, err := c.db.NamedExec(query, customer)
+	id, err := c.dialect.NamedInsert(ctx, c.db, query, customer)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
-		return err
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return c.uniqueViolation(err)
 	}
+	customer.ID = int(id)
 
 	return nil
 }
 
-func (c *customerRepoImpl) GetByUsername(username string) (*model.CustomerEntity, error) {
+func (c *customerRepoImpl) GetByUsername(ctx context.Context, username string) (*model.CustomerEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "customer", "GetByUsername")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "GetByUsername").ObserveDuration()
+
+	customer := &model.CustomerEntity{}
+	query := "SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE " + c.dialect.EqualFold("username", "?") + " AND deleted_at IS NULL"
+
+	err := c.db.GetContext(ctx, customer, c.db.Rebind(query), username)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (c *customerRepoImpl) GetByID(ctx context.Context, id int) (*model.CustomerEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "customer", "GetByID")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "GetByID").ObserveDuration()
+
 	customer := &model.CustomerEntity{}
-	query := "SELECT id, name, username, password, phone_number, email, address, created_at, updated_at FROM customers WHERE username = ?"
+	query := "SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE id = ? AND deleted_at IS NULL"
 
-	err := c.db.Get(customer, query, username)
+	err := c.db.GetContext(ctx, customer, c.db.Rebind(query), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
 		return nil, err
 	}
 
 	return customer, nil
 }
+
+func (c *customerRepoImpl) Update(ctx context.Context, customer *model.CustomerEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "Update")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "Update").ObserveDuration()
+
+	// a new email address has to be verified again
+	query := "UPDATE customers SET name = :name, email_verified_at = CASE WHEN " + c.dialect.EqualFold("email", ":email") + " THEN email_verified_at ELSE NULL END, email = :email, phone_number = :phone_number, address = :address, updated_at = CURRENT_TIMESTAMP WHERE id = :id AND deleted_at IS NULL"
+	res, err := c.db.NamedExecContext(ctx, query, customer)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return c.uniqueViolation(err)
+	}
+
+	return checkAffected(ctx, res)
+}
+
+func (c *customerRepoImpl) UpdatePassword(ctx context.Context, id int, password string) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "UpdatePassword")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "UpdatePassword").ObserveDuration()
+
+	query := "UPDATE customers SET password = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), password, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return checkAffected(ctx, res)
+}
+
+func (c *customerRepoImpl) GetByEmail(ctx context.Context, email string) ([]*model.CustomerEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "customer", "GetByEmail")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "GetByEmail").ObserveDuration()
+
+	customers := []*model.CustomerEntity{}
+	query := "SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE " + c.dialect.EqualFold("email", "?") + " AND deleted_at IS NULL ORDER BY id"
+
+	err := c.db.SelectContext(ctx, &customers, c.db.Rebind(query), email)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return nil, err
+	}
+
+	return customers, nil
+}
+
+// VerifyEmail returns sql.ErrNoRows when the customer is deleted or has
+// another email than the one verified.
+func (c *customerRepoImpl) VerifyEmail(ctx context.Context, id int, email string) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "VerifyEmail")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "VerifyEmail").ObserveDuration()
+
+	query := "UPDATE customers SET email_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND email = ? AND deleted_at IS NULL"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), id, email)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return checkAffected(ctx, res)
+}
+
+// CreateToken stores the token and invalidates the unused tokens of the same
+// type, only the last mailed link works.
+func (c *customerRepoImpl) CreateToken(ctx context.Context, token *model.CustomerTokenEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "CreateToken")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "CreateToken").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, c.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind("UPDATE customer_tokens SET used_at = CURRENT_TIMESTAMP WHERE customer_id = ? AND type = ? AND used_at IS NULL"), token.CustomerID, token.Type)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	_, err = tx.NamedExecContext(ctx, "INSERT INTO customer_tokens (customer_id, type, token_hash, email, expires_at) VALUES (:customer_id, :type, :token_hash, :email, :expires_at)", token)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetToken returns the unused token of the type with the given hash, expired or not.
+func (c *customerRepoImpl) GetToken(ctx context.Context, tokenType customerEnum.TokenType, tokenHash string) (*model.CustomerTokenEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "customer", "GetToken")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "GetToken").ObserveDuration()
+
+	token := &model.CustomerTokenEntity{}
+	query := "SELECT id, customer_id, type, token_hash, email, expires_at, used_at, created_at FROM customer_tokens WHERE type = ? AND token_hash = ? AND used_at IS NULL"
+
+	err := c.db.GetContext(ctx, token, c.db.Rebind(query), tokenType, tokenHash)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// UseToken returns sql.ErrNoRows when the token was already used, so of two
+// requests with the same token only one goes through.
+func (c *customerRepoImpl) UseToken(ctx context.Context, id int) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "UseToken")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "UseToken").ObserveDuration()
+
+	query := "UPDATE customer_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), id)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return checkAffected(ctx, res)
+}
+
+// SetTOTPSecret starts the two-factor enrollment over with a new secret, it
+// returns sql.ErrNoRows when two-factor authentication is already enabled.
+func (c *customerRepoImpl) SetTOTPSecret(ctx context.Context, id int, secret string) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "SetTOTPSecret")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "SetTOTPSecret").ObserveDuration()
+
+	query := "UPDATE customers SET totp_secret = ?, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND totp_enabled_at IS NULL AND deleted_at IS NULL"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), secret, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return checkAffected(ctx, res)
+}
+
+// EnableTOTP confirms the enrollment and replaces the recovery codes.
+func (c *customerRepoImpl) EnableTOTP(ctx context.Context, id int, recoveryCodeHashes []string) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "EnableTOTP")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "EnableTOTP").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, c.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, tx.Rebind("UPDATE customers SET totp_enabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL AND deleted_at IS NULL"), id)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	if err := checkAffected(ctx, res); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, id, recoveryCodeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DisableTOTP removes the secret and the recovery codes.
+func (c *customerRepoImpl) DisableTOTP(ctx context.Context, id int) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "DisableTOTP")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "DisableTOTP").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, c.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, tx.Rebind("UPDATE customers SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"), id)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	if err := checkAffected(ctx, res); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, id, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code. It returns sql.ErrNoRows
+// when a code of the step or a later one was already used, so a code works once.
+func (c *customerRepoImpl) UseTOTPStep(ctx context.Context, id int, step int64) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "UseTOTPStep")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "UseTOTPStep").ObserveDuration()
+
+	query := "UPDATE customers SET totp_last_step = ? WHERE id = ? AND (totp_last_step IS NULL OR totp_last_step < ?) AND deleted_at IS NULL"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), step, id, step)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return checkAffected(ctx, res)
+}
+
+// UseRecoveryCode returns sql.ErrNoRows when the customer has no unused recovery code with the hash.
+func (c *customerRepoImpl) UseRecoveryCode(ctx context.Context, id int, codeHash string) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "UseRecoveryCode")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "UseRecoveryCode").ObserveDuration()
+
+	query := "UPDATE customer_recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE customer_id = ? AND code_hash = ? AND used_at IS NULL"
+	res, err := c.db.ExecContext(ctx, c.db.Rebind(query), id, codeHash)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return checkAffected(ctx, res)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *dbtx.Tx, customerID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, tx.Rebind("DELETE FROM customer_recovery_codes WHERE customer_id = ?"), customerID); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, tx.Rebind("INSERT INTO customer_recovery_codes (customer_id, code_hash) VALUES (?, ?)"), customerID, codeHash); err != nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Anonymize overwrites the personal data of the customer with the given values
+// and removes what only made sense for an active account: admin role, wishlist
+// and active cart. Transactions and reviews are kept, pointing to the anonymized row.
+func (c *customerRepoImpl) Anonymize(ctx context.Context, customer *model.CustomerEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "customer", "Anonymize")
+	defer span.End()
+	defer metrics.QueryTimer("customer", "Anonymize").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, c.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	res, err := tx.NamedExecContext(ctx, `UPDATE customers SET name = :name, username = :username, email = :email, password = :password, phone_number = :phone_number, address = :address, totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = :id AND deleted_at IS NULL`, customer)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	if err := checkAffected(ctx, res); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	activeCartStatus := cartEnum.CartStatusActive
+	for _, query := range []struct {
+		statement string
+		args      []interface{}
+	}{
+		{"DELETE FROM admins WHERE customer_id = ?", []interface{}{customer.ID}},
+		{"DELETE FROM customer_tokens WHERE customer_id = ?", []interface{}{customer.ID}},
+		{"DELETE FROM customer_recovery_codes WHERE customer_id = ?", []interface{}{customer.ID}},
+		{"DELETE FROM wishlist_notifications WHERE customer_id = ?", []interface{}{customer.ID}},
+		{"DELETE FROM wishlists WHERE customer_id = ?", []interface{}{customer.ID}},
+		{"DELETE FROM cart_items WHERE shopping_cart_id IN (SELECT id FROM shopping_carts WHERE customer_id = ? AND status = ?)", []interface{}{customer.ID, activeCartStatus}},
+		{"DELETE FROM shopping_carts WHERE customer_id = ? AND status = ?", []interface{}{customer.ID, activeCartStatus}},
+	} {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query.statement), query.args...); err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// checkAffected returns sql.ErrNoRows when the customer does not exist or was deleted.
+func checkAffected(ctx context.Context, res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "customer", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "customer", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// uniqueViolation tells which unique field of the customer a failed write collided
+// with, usernames and emails are unique regardless of case among active accounts.
+func (c *customerRepoImpl) uniqueViolation(err error) error {
+	constraint, ok := c.dialect.UniqueViolation(err)
+	if !ok {
+		return err
+	}
+
+	switch {
+	case strings.Contains(constraint, "username"):
+		return ErrUsernameTaken
+	case strings.Contains(constraint, "email"):
+		return ErrEmailTaken
+	default:
+		return err
+	}
+}
@@ -1,24 +1,39 @@
 package customer
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/jmoiron/sqlx"
+	customerEnum "github.com/zakiyalmaya/online-store/constant/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
-func TestCreate(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+// equalFold is the case-insensitive comparison of the column to a parameter
+// the queries use on each dialect.
+func equalFold(driverName, column string) string {
+	switch driverName {
+	case dialect.Postgres:
+		return "LOWER(" + column + ") = LOWER(?)"
+	case dialect.MySQL:
+		return column + " = ?"
+	default:
+		return column + " = ? COLLATE NOCASE"
 	}
-	defer db.Close()
+}
+
+func TestCreate(t *testing.T) {
+	dialecttest.Run(t, testCreate)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name     string
@@ -37,9 +52,9 @@ func TestCreate(t *testing.T) {
 				Address:     "Jl. Raya",
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO customers (name, username, password, email, phone_number, address) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO customers (name, username, password, email, phone_number, address) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs("John", "john", "John-123", "KUZuL@example.com", "08123456789", "Jl. Raya").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 			},
 			wantErr: false,
 		},
@@ -54,7 +69,7 @@ func TestCreate(t *testing.T) {
 				Address:     "Jl. Raya",
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO customers (name, username, password, email, phone_number, address) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO customers (name, username, password, email, phone_number, address) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs("John", "john", "John-123", "KUZuL@example.com", "08123456789", "Jl. Raya").
 					WillReturnError(errors.New("error"))
 			},
@@ -66,24 +81,25 @@ func TestCreate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
 			repo := NewCustomerRepository(sqlxDB)
-			err := repo.Create(&tc.customer)
+			err := repo.Create(context.Background(), &tc.customer)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
 				return
 			}
+
+			if !tc.wantErr && tc.customer.ID != 1 {
+				t.Errorf("Create() id = %d, want 1", tc.customer.ID)
+			}
 		})
 	}
 }
 
 func TestGetByUsername(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetByUsername)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetByUsername(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name     string
@@ -95,10 +111,10 @@ func TestGetByUsername(t *testing.T) {
 			name:     "Given valid request when get by username then return success",
 			username: "john",
 			mock: func() {
-				mock.ExpectQuery("SELECT id, name, username, password, phone_number, email, address, created_at, updated_at FROM customers WHERE username = ?").
+				mock.ExpectQuery("SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE " + equalFold(driverName, "username") + " AND deleted_at IS NULL").
 					WithArgs("john").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "username", "password", "email", "phone_number", "address", "created_at", "updated_at"}).
-						AddRow(1, "John", "john", "John-123", "KUZuL@example.com", "08123456789", "Jl. Raya", time.Time{}, time.Time{}))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "username", "password", "email", "phone_number", "address", "is_admin", "created_at", "updated_at"}).
+						AddRow(1, "John", "john", "John-123", "KUZuL@example.com", "08123456789", "Jl. Raya", false, time.Time{}, time.Time{}))
 			},
 			wantErr: false,
 		},
@@ -106,7 +122,7 @@ func TestGetByUsername(t *testing.T) {
 			name:     "Given error when get by username then return error",
 			username: "john",
 			mock: func() {
-				mock.ExpectQuery("SELECT id, name, username, password, phone_number, email, address, created_at, updated_at FROM customers WHERE username = ?").
+				mock.ExpectQuery("SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE " + equalFold(driverName, "username") + " AND deleted_at IS NULL").
 					WithArgs("john").
 					WillReturnError(errors.New("error"))
 			},
@@ -118,7 +134,7 @@ func TestGetByUsername(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
 			repo := NewCustomerRepository(sqlxDB)
-			_, err := repo.GetByUsername(tc.username)
+			_, err := repo.GetByUsername(context.Background(), tc.username)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetByUsername() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -126,3 +142,703 @@ func TestGetByUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestGetByID(t *testing.T) {
+	dialecttest.Run(t, testGetByID)
+}
+
+func testGetByID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE id = ? AND deleted_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		id      int
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given existing customer when get by id then return success",
+			id:   1,
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "username", "password", "email", "phone_number", "address", "is_admin", "created_at", "updated_at"}).
+						AddRow(1, "John", "john", "John-123", "KUZuL@example.com", "08123456789", "Jl. Raya", false, time.Time{}, time.Time{}))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given deleted customer when get by id then return error",
+			id:   2,
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			_, err := repo.GetByID(context.Background(), tc.id)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetByID() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dialecttest.Run(t, testUpdate)
+}
+
+func testUpdate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customers SET name = ?, email_verified_at = CASE WHEN " + equalFold(driverName, "email") + " THEN email_verified_at ELSE NULL END, email = ?, phone_number = ?, address = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	customer := &model.CustomerEntity{
+		ID:          1,
+		Name:        "John",
+		Email:       "john@example.com",
+		PhoneNumber: "08123456789",
+		Address:     "Jl. Raya",
+	}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given existing customer when update then return success",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs("John", "john@example.com", "john@example.com", "08123456789", "Jl. Raya", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given deleted customer when update then return no rows",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs("John", "john@example.com", "john@example.com", "08123456789", "Jl. Raya", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.Update(context.Background(), customer)
+			if err != tc.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdatePassword(t *testing.T) {
+	dialecttest.Run(t, testUpdatePassword)
+}
+
+func testUpdatePassword(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customers SET password = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given existing customer when update password then return success",
+			mock: func() {
+				mock.ExpectExec(query).WithArgs("hashed", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error when update password then return error",
+			mock: func() {
+				mock.ExpectExec(query).WithArgs("hashed", 1).WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.UpdatePassword(context.Background(), 1, "hashed")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdatePassword() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	dialecttest.Run(t, testAnonymize)
+}
+
+func testAnonymize(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	updateQuery := "UPDATE customers SET name = ?, username = ?, email = ?, password = ?, phone_number = ?, address = ?, totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	customer := &model.CustomerEntity{ID: 1, Name: "Deleted Customer", Username: "deleted-1"}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given existing customer when anonymize then remove the account data",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs("Deleted Customer", "deleted-1", "", "", "", "", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM admins WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM customer_tokens WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM customer_recovery_codes WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectExec("DELETE FROM wishlist_notifications WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM wishlists WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM cart_items WHERE shopping_cart_id IN (SELECT id FROM shopping_carts WHERE customer_id = ? AND status = ?)").WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM shopping_carts WHERE customer_id = ? AND status = ?").WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given deleted customer when anonymize then rollback",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs("Deleted Customer", "deleted-1", "", "", "", "", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error deleting wishlist when anonymize then rollback",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(updateQuery).
+					WithArgs("Deleted Customer", "deleted-1", "", "", "", "", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM admins WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM customer_tokens WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM customer_recovery_codes WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectExec("DELETE FROM wishlist_notifications WHERE customer_id = ?").WithArgs(1).WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.Anonymize(context.Background(), customer)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Anonymize() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestGetByEmail(t *testing.T) {
+	dialecttest.Run(t, testGetByEmail)
+}
+
+func testGetByEmail(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "SELECT id, name, username, password, phone_number, email, address, EXISTS (SELECT 1 FROM admins WHERE customer_id = customers.id) AS is_admin, email_verified_at IS NOT NULL AS email_verified, COALESCE(totp_secret, '') AS totp_secret, totp_enabled_at IS NOT NULL AS two_factor_enabled, created_at, updated_at FROM customers WHERE " + equalFold(driverName, "email") + " AND deleted_at IS NULL ORDER BY id"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Given email of two accounts when get by email then return both",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("john@example.com").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified"}).
+						AddRow(1, "john", "john@example.com", true).
+						AddRow(2, "johnny", "john@example.com", false))
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "Given error when get by email then return error",
+			mock: func() {
+				mock.ExpectQuery(query).WithArgs("john@example.com").WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			customers, err := repo.GetByEmail(context.Background(), "john@example.com")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetByEmail() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if len(customers) != tc.want {
+				t.Errorf("GetByEmail() = %d customers, want %d", len(customers), tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	dialecttest.Run(t, testVerifyEmail)
+}
+
+func testVerifyEmail(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customers SET email_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND email = ? AND deleted_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given existing customer when verify email then return success",
+			mock: func() {
+				mock.ExpectExec(query).WithArgs(1, "name@example.com").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given deleted customer or changed email when verify email then return no rows",
+			mock: func() {
+				mock.ExpectExec(query).WithArgs(1, "name@example.com").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.VerifyEmail(context.Background(), 1, "name@example.com")
+			if err != tc.wantErr {
+				t.Errorf("VerifyEmail() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	dialecttest.Run(t, testCreateToken)
+}
+
+func testCreateToken(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	token := &model.CustomerTokenEntity{
+		CustomerID: 1,
+		Type:       customerEnum.TokenTypePasswordReset,
+		TokenHash:  "hash",
+		Email:      "name@example.com",
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	invalidateQuery := "UPDATE customer_tokens SET used_at = CURRENT_TIMESTAMP WHERE customer_id = ? AND type = ? AND used_at IS NULL"
+	insertQuery := "INSERT INTO customer_tokens (customer_id, type, token_hash, email, expires_at) VALUES (?, ?, ?, ?, ?)"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid token when create token then invalidate the previous tokens",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(invalidateQuery).WithArgs(1, customerEnum.TokenTypePasswordReset).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertQuery).WithArgs(1, customerEnum.TokenTypePasswordReset, "hash", "name@example.com", token.ExpiresAt).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error inserting when create token then rollback",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(invalidateQuery).WithArgs(1, customerEnum.TokenTypePasswordReset).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertQuery).WithArgs(1, customerEnum.TokenTypePasswordReset, "hash", "name@example.com", token.ExpiresAt).WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.CreateToken(context.Background(), token)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateToken() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestGetToken(t *testing.T) {
+	dialecttest.Run(t, testGetToken)
+}
+
+func testGetToken(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "SELECT id, customer_id, type, token_hash, email, expires_at, used_at, created_at FROM customer_tokens WHERE type = ? AND token_hash = ? AND used_at IS NULL"
+	expiresAt := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given unused token when get token then return success",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(customerEnum.TokenTypeEmailVerification, "hash").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "type", "token_hash", "email", "expires_at", "used_at", "created_at"}).
+						AddRow(1, 2, 1, "hash", "name@example.com", expiresAt, nil, time.Now()))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given used or unknown token when get token then return no rows",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(customerEnum.TokenTypeEmailVerification, "hash").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			token, err := repo.GetToken(context.Background(), customerEnum.TokenTypeEmailVerification, "hash")
+			if err != tc.wantErr {
+				t.Errorf("GetToken() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if tc.wantErr == nil && (token.CustomerID != 2 || token.Email != "name@example.com" || !token.ExpiresAt.Equal(expiresAt)) {
+				t.Errorf("GetToken() = %+v", token)
+			}
+		})
+	}
+}
+
+func TestUseToken(t *testing.T) {
+	dialecttest.Run(t, testUseToken)
+}
+
+func testUseToken(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customer_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = ? AND used_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		result  driver.Result
+		wantErr error
+	}{
+		{
+			name:    "Given unused token when use token then return success",
+			result:  sqlmock.NewResult(0, 1),
+			wantErr: nil,
+		},
+		{
+			name:    "Given token used concurrently when use token then return no rows",
+			result:  sqlmock.NewResult(0, 0),
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs(1).WillReturnResult(tc.result)
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.UseToken(context.Background(), 1)
+			if err != tc.wantErr {
+				t.Errorf("UseToken() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetTOTPSecret(t *testing.T) {
+	dialecttest.Run(t, testSetTOTPSecret)
+}
+
+func testSetTOTPSecret(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customers SET totp_secret = ?, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND totp_enabled_at IS NULL AND deleted_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		result  driver.Result
+		wantErr error
+	}{
+		{
+			name:    "Given customer without two-factor when set totp secret then return success",
+			result:  sqlmock.NewResult(0, 1),
+			wantErr: nil,
+		},
+		{
+			name:    "Given customer with two-factor enabled when set totp secret then return no rows",
+			result:  sqlmock.NewResult(0, 0),
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs("SECRET", 1).WillReturnResult(tc.result)
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.SetTOTPSecret(context.Background(), 1, "SECRET")
+			if err != tc.wantErr {
+				t.Errorf("SetTOTPSecret() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnableTOTP(t *testing.T) {
+	dialecttest.Run(t, testEnableTOTP)
+}
+
+func testEnableTOTP(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	enableQuery := "UPDATE customers SET totp_enabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL AND deleted_at IS NULL"
+	deleteQuery := "DELETE FROM customer_recovery_codes WHERE customer_id = ?"
+	insertQuery := "INSERT INTO customer_recovery_codes (customer_id, code_hash) VALUES (?, ?)"
+	insertErr := errors.New("error")
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given pending enrollment when enable totp then replace the recovery codes",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(enableQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertQuery).WithArgs(1, "hash-1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(insertQuery).WithArgs(1, "hash-2").WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given no pending enrollment when enable totp then rollback",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(enableQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "Given error inserting a recovery code when enable totp then rollback",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(enableQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertQuery).WithArgs(1, "hash-1").WillReturnError(insertErr)
+				mock.ExpectRollback()
+			},
+			wantErr: insertErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.EnableTOTP(context.Background(), 1, []string{"hash-1", "hash-2"})
+			if err != tc.wantErr {
+				t.Errorf("EnableTOTP() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDisableTOTP(t *testing.T) {
+	dialecttest.Run(t, testDisableTOTP)
+}
+
+func testDisableTOTP(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	disableQuery := "UPDATE customers SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given existing customer when disable totp then remove the recovery codes",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(disableQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM customer_recovery_codes WHERE customer_id = ?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given deleted customer when disable totp then rollback",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(disableQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.DisableTOTP(context.Background(), 1)
+			if err != tc.wantErr {
+				t.Errorf("DisableTOTP() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	dialecttest.Run(t, testUseTOTPStep)
+}
+
+func testUseTOTPStep(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customers SET totp_last_step = ? WHERE id = ? AND (totp_last_step IS NULL OR totp_last_step < ?) AND deleted_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		result  driver.Result
+		wantErr error
+	}{
+		{
+			name:    "Given new step when use totp step then return success",
+			result:  sqlmock.NewResult(0, 1),
+			wantErr: nil,
+		},
+		{
+			name:    "Given step already used when use totp step then return no rows",
+			result:  sqlmock.NewResult(0, 0),
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs(int64(100), 1, int64(100)).WillReturnResult(tc.result)
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.UseTOTPStep(context.Background(), 1, 100)
+			if err != tc.wantErr {
+				t.Errorf("UseTOTPStep() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	dialecttest.Run(t, testUseRecoveryCode)
+}
+
+func testUseRecoveryCode(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "UPDATE customer_recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE customer_id = ? AND code_hash = ? AND used_at IS NULL"
+
+	testCases := []struct {
+		name    string
+		result  driver.Result
+		wantErr error
+	}{
+		{
+			name:    "Given unused recovery code when use recovery code then return success",
+			result:  sqlmock.NewResult(0, 1),
+			wantErr: nil,
+		},
+		{
+			name:    "Given used or unknown recovery code when use recovery code then return no rows",
+			result:  sqlmock.NewResult(0, 0),
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs(1, "hash").WillReturnResult(tc.result)
+			repo := NewCustomerRepository(sqlxDB)
+			err := repo.UseRecoveryCode(context.Background(), 1, "hash")
+			if err != tc.wantErr {
+				t.Errorf("UseRecoveryCode() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
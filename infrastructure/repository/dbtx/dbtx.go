@@ -0,0 +1,82 @@
+// Package dbtx lets a repository run on the database or on the transaction of
+// a unit of work without knowing which.
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is the connection of a repository, *sqlx.DB or the *sqlx.Tx of a unit
+// of work.
+type DB interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// savepoints numbers the savepoints, their names only have to be unique
+// within a transaction.
+var savepoints atomic.Int64
+
+// Tx is the transaction of a repository method. Begun on a transaction it is
+// a savepoint of it, so its writes are committed or rolled back with the
+// enclosing transaction.
+type Tx struct {
+	*sqlx.Tx
+	savepoint string
+}
+
+// Begin starts a transaction on the database, or a savepoint when db already
+// is a transaction.
+func Begin(ctx context.Context, db DB) (*Tx, error) {
+	switch db := db.(type) {
+	case *sqlx.DB:
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Tx{Tx: tx}, nil
+	case *sqlx.Tx:
+		return savepoint(ctx, db)
+	case *Tx:
+		return savepoint(ctx, db.Tx)
+	default:
+		return nil, fmt.Errorf("can not begin a transaction on %T", db)
+	}
+}
+
+func savepoint(ctx context.Context, tx *sqlx.Tx) (*Tx, error) {
+	name := fmt.Sprintf("sp_%d", savepoints.Add(1))
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx, savepoint: name}, nil
+}
+
+// Commit commits the transaction or releases the savepoint.
+func (t *Tx) Commit() error {
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+
+	_, err := t.Tx.Exec("RELEASE SAVEPOINT " + t.savepoint)
+	return err
+}
+
+// Rollback rolls back the transaction, or the writes made since the savepoint.
+func (t *Tx) Rollback() error {
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+
+	_, err := t.Tx.Exec("ROLLBACK TO SAVEPOINT " + t.savepoint)
+	return err
+}
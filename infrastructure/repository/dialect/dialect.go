@@ -0,0 +1,77 @@
+package dialect
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// The names are the database/sql drivers of each database.
+const (
+	SQLite   = "sqlite3"
+	Postgres = "postgres"
+	MySQL    = "mysql"
+)
+
+// Dialect builds the SQL that differs between the databases. Queries are
+// written with ? placeholders and rebound with Rebind of the connection.
+type Dialect interface {
+	Name() string
+	// Insert runs the INSERT query and returns the id of the new row.
+	Insert(ctx context.Context, db sqlx.ExtContext, query string, args ...interface{}) (int64, error)
+	// NamedInsert is Insert with the :name parameters bound from arg.
+	NamedInsert(ctx context.Context, db sqlx.ExtContext, query string, arg interface{}) (int64, error)
+	// OnConflict is the clause updating the row the INSERT collided with on
+	// the unique columns, updates are column = expression, see Excluded.
+	OnConflict(columns []string, updates ...string) string
+	// Excluded is the value the INSERT tried to write to the column.
+	Excluded(column string) string
+	// EqualFold compares the column to the parameter regardless of case.
+	EqualFold(column, param string) string
+	// TypedParam is a ? placeholder of the SQL type, for the places where the
+	// database cannot infer it like the SELECT list of an INSERT ... SELECT.
+	TypedParam(sqlType string) string
+	// UniqueViolation returns the name of the unique constraint or column
+	// the failed write collided with.
+	UniqueViolation(err error) (string, bool)
+	// Retryable reports whether the transaction failed because of concurrent
+	// transactions and succeeds when it is run again.
+	Retryable(err error) bool
+}
+
+// New returns the dialect of the driver, SQLite for drivers it does not know.
+func New(driverName string) Dialect {
+	switch driverName {
+	case Postgres, "pgx":
+		return &postgresDialect{}
+	case MySQL:
+		return &mysqlDialect{}
+	default:
+		return &sqliteDialect{}
+	}
+}
+
+// For returns the dialect of the connection.
+func For(db sqlx.ExtContext) Dialect {
+	return New(db.DriverName())
+}
+
+// lastInsertID is Insert of the databases reporting the id of the new row
+// with the result of the INSERT.
+func lastInsertID(ctx context.Context, db sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	res, err := db.ExecContext(ctx, db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func namedLastInsertID(ctx context.Context, db sqlx.ExtContext, query string, arg interface{}) (int64, error) {
+	res, err := sqlx.NamedExecContext(ctx, db, query, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
@@ -0,0 +1,219 @@
+package dialect
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+type testEntity struct {
+	Name string `db:"name"`
+}
+
+func TestInsert(t *testing.T) {
+	testCases := []struct {
+		name   string
+		driver string
+		mock   func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name:   "Given sqlite when insert then return last insert id",
+			driver: SQLite,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO items (name) VALUES (?)").WithArgs("a").WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec("INSERT INTO items (name) VALUES (?)").WithArgs("b").WillReturnResult(sqlmock.NewResult(7, 1))
+			},
+		},
+		{
+			name:   "Given mysql when insert then return last insert id",
+			driver: MySQL,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO items (name) VALUES (?)").WithArgs("a").WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectExec("INSERT INTO items (name) VALUES (?)").WithArgs("b").WillReturnResult(sqlmock.NewResult(7, 1))
+			},
+		},
+		{
+			name:   "Given postgres when insert then return the id of RETURNING",
+			driver: Postgres,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO items (name) VALUES ($1) RETURNING id").WithArgs("a").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectQuery("INSERT INTO items (name) VALUES ($1) RETURNING id").WithArgs("b").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, tc.driver)
+			dialect := For(sqlxDB)
+			if dialect.Name() != tc.driver {
+				t.Fatalf("For() = %s, want %s", dialect.Name(), tc.driver)
+			}
+
+			tc.mock(mock)
+			if id, err := dialect.Insert(context.Background(), sqlxDB, "INSERT INTO items (name) VALUES (?)", "a"); err != nil || id != 7 {
+				t.Errorf("Insert() = %d, %v, want 7", id, err)
+			}
+
+			if id, err := dialect.NamedInsert(context.Background(), sqlxDB, "INSERT INTO items (name) VALUES (:name)", &testEntity{Name: "b"}); err != nil || id != 7 {
+				t.Errorf("NamedInsert() = %d, %v, want 7", id, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestClauses(t *testing.T) {
+	testCases := []struct {
+		driver     string
+		onConflict string
+		equalFold  string
+		typedParam string
+	}{
+		{
+			driver:     SQLite,
+			onConflict: "ON CONFLICT(a, b) DO UPDATE SET c = excluded.c",
+			equalFold:  "email = ? COLLATE NOCASE",
+			typedParam: "?",
+		},
+		{
+			driver:     Postgres,
+			onConflict: "ON CONFLICT(a, b) DO UPDATE SET c = excluded.c",
+			equalFold:  "LOWER(email) = LOWER(?)",
+			typedParam: "CAST(? AS INTEGER)",
+		},
+		{
+			driver:     MySQL,
+			onConflict: "ON DUPLICATE KEY UPDATE c = VALUES(c)",
+			equalFold:  "email = ?",
+			typedParam: "?",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.driver, func(t *testing.T) {
+			dialect := New(tc.driver)
+			if got := dialect.OnConflict([]string{"a", "b"}, "c = "+dialect.Excluded("c")); got != tc.onConflict {
+				t.Errorf("OnConflict() = %s, want %s", got, tc.onConflict)
+			}
+
+			if got := dialect.EqualFold("email", "?"); got != tc.equalFold {
+				t.Errorf("EqualFold() = %s, want %s", got, tc.equalFold)
+			}
+
+			if got := dialect.TypedParam("INTEGER"); got != tc.typedParam {
+				t.Errorf("TypedParam() = %s, want %s", got, tc.typedParam)
+			}
+		})
+	}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	testCases := []struct {
+		name   string
+		driver string
+		err    error
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "Given sqlite unique error when unique violation then return the message",
+			driver: SQLite,
+			err:    sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique},
+			want:   sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}.Error(),
+			wantOK: true,
+		},
+		{
+			name:   "Given postgres unique error when unique violation then return the constraint",
+			driver: Postgres,
+			err:    &pq.Error{Code: uniqueViolationCode, Constraint: "idx_customers_email"},
+			want:   "idx_customers_email",
+			wantOK: true,
+		},
+		{
+			name:   "Given mysql duplicate entry when unique violation then return the key without the value",
+			driver: MySQL,
+			err:    &mysql.MySQLError{Number: duplicateEntryCode, Message: "Duplicate entry 'username@example.com' for key 'customers.idx_customers_email'"},
+			want:   "customers.idx_customers_email",
+			wantOK: true,
+		},
+		{
+			name:   "Given other error when unique violation then return false",
+			driver: Postgres,
+			err:    errors.New("error"),
+			wantOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := New(tc.driver).UniqueViolation(tc.err)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("UniqueViolation() = %s, %v, want %s, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	testCases := []struct {
+		name   string
+		driver string
+		err    error
+		want   bool
+	}{
+		{
+			name:   "Given sqlite busy error when retryable then return true",
+			driver: SQLite,
+			err:    sqlite3.Error{Code: sqlite3.ErrBusy},
+			want:   true,
+		},
+		{
+			name:   "Given sqlite unique error when retryable then return false",
+			driver: SQLite,
+			err:    sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique},
+			want:   false,
+		},
+		{
+			name:   "Given postgres serialization failure when retryable then return true",
+			driver: Postgres,
+			err:    &pq.Error{Code: serializationFailureCode},
+			want:   true,
+		},
+		{
+			name:   "Given mysql deadlock when retryable then return true",
+			driver: MySQL,
+			err:    &mysql.MySQLError{Number: deadlockCode},
+			want:   true,
+		},
+		{
+			name:   "Given other error when retryable then return false",
+			driver: MySQL,
+			err:    errors.New("error"),
+			want:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := New(tc.driver).Retryable(tc.err); got != tc.want {
+				t.Errorf("Retryable() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
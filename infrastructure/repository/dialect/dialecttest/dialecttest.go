@@ -0,0 +1,108 @@
+// Package dialecttest runs the sqlmock tests of the repositories once per dialect.
+package dialecttest
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+)
+
+// Drivers are the drivers of the supported databases.
+var Drivers = []string{dialect.SQLite, dialect.Postgres, dialect.MySQL}
+
+// Run runs the test once per driver.
+func Run(t *testing.T, test func(t *testing.T, driverName string)) {
+	for _, driverName := range Drivers {
+		t.Run(driverName, func(t *testing.T) {
+			test(t, driverName)
+		})
+	}
+}
+
+// New returns a sqlmock connection of the driver. The expected queries are
+// written with ? placeholders like the repositories, they are rebound to the
+// placeholders of the driver before they are compared to the executed queries.
+func New(t *testing.T, driverName string) (*sqlx.DB, sqlmock.Sqlmock) {
+	bindType := sqlx.BindType(driverName)
+	matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		return sqlmock.QueryMatcherEqual.Match(sqlx.Rebind(bindType, expectedSQL), actualSQL)
+	})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	sqlxDB := sqlx.NewDb(db, driverName)
+	t.Cleanup(func() {
+		sqlxDB.Close()
+	})
+
+	return sqlxDB, mock
+}
+
+// UniqueViolation returns the error the driver answers a write colliding with
+// the unique constraint, named like on PostgreSQL and MySQL.
+func UniqueViolation(driverName, constraint string) error {
+	switch dialect.New(driverName).Name() {
+	case dialect.Postgres:
+		return &pq.Error{Code: "23505", Constraint: constraint}
+	case dialect.MySQL:
+		return &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'value' for key '" + constraint + "'"}
+	default:
+		return sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}
+	}
+}
+
+// ExpectedInsert is the INSERT of Dialect.Insert and Dialect.NamedInsert, an
+// Exec on SQLite and MySQL and a query reading the id on PostgreSQL.
+type ExpectedInsert struct {
+	exec  *sqlmock.ExpectedExec
+	query *sqlmock.ExpectedQuery
+}
+
+// ExpectInsert expects the INSERT query, without the RETURNING clause.
+func ExpectInsert(mock sqlmock.Sqlmock, driverName, query string) *ExpectedInsert {
+	if dialect.New(driverName).Name() == dialect.Postgres {
+		return &ExpectedInsert{query: mock.ExpectQuery(query + " RETURNING id")}
+	}
+
+	return &ExpectedInsert{exec: mock.ExpectExec(query)}
+}
+
+func (e *ExpectedInsert) WithArgs(args ...driver.Value) *ExpectedInsert {
+	if e.query != nil {
+		e.query.WithArgs(args...)
+	} else {
+		e.exec.WithArgs(args...)
+	}
+
+	return e
+}
+
+// WillReturnID returns the id of the new row.
+func (e *ExpectedInsert) WillReturnID(id int64) *ExpectedInsert {
+	if e.query != nil {
+		e.query.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	} else {
+		e.exec.WillReturnResult(sqlmock.NewResult(id, 1))
+	}
+
+	return e
+}
+
+func (e *ExpectedInsert) WillReturnError(err error) *ExpectedInsert {
+	if e.query != nil {
+		e.query.WillReturnError(err)
+	} else {
+		e.exec.WillReturnError(err)
+	}
+
+	return e
+}
@@ -0,0 +1,75 @@
+package dialect
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// duplicateEntryCode is ER_DUP_ENTRY.
+const duplicateEntryCode = 1062
+
+// lockWaitTimeoutCode is ER_LOCK_WAIT_TIMEOUT and deadlockCode ER_LOCK_DEADLOCK.
+const (
+	lockWaitTimeoutCode = 1205
+	deadlockCode        = 1213
+)
+
+type mysqlDialect struct{}
+
+func (m *mysqlDialect) Name() string {
+	return MySQL
+}
+
+func (m *mysqlDialect) Insert(ctx context.Context, db sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	return lastInsertID(ctx, db, query, args...)
+}
+
+func (m *mysqlDialect) NamedInsert(ctx context.Context, db sqlx.ExtContext, query string, arg interface{}) (int64, error) {
+	return namedLastInsertID(ctx, db, query, arg)
+}
+
+// OnConflict does not need the columns, MySQL updates the row of whichever
+// unique index collided.
+func (m *mysqlDialect) OnConflict(columns []string, updates ...string) string {
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+func (m *mysqlDialect) Excluded(column string) string {
+	return "VALUES(" + column + ")"
+}
+
+// EqualFold relies on the case-insensitive default collation of MySQL.
+func (m *mysqlDialect) EqualFold(column, param string) string {
+	return column + " = " + param
+}
+
+// TypedParam leaves the type to the column the value is written to.
+func (m *mysqlDialect) TypedParam(sqlType string) string {
+	return "?"
+}
+
+// UniqueViolation returns the key of the message, e.g. "customers.idx_customers_email"
+// of "Duplicate entry 'a@example.com' for key 'customers.idx_customers_email'",
+// leaving out the duplicated value.
+func (m *mysqlDialect) UniqueViolation(err error) (string, bool) {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != duplicateEntryCode {
+		return "", false
+	}
+
+	key := mysqlErr.Message
+	if i := strings.LastIndex(key, " for key "); i >= 0 {
+		key = key[i+len(" for key "):]
+	}
+
+	return strings.Trim(key, "'"), true
+}
+
+func (m *mysqlDialect) Retryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && (mysqlErr.Number == lockWaitTimeoutCode || mysqlErr.Number == deadlockCode)
+}
@@ -0,0 +1,76 @@
+package dialect
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolationCode is the SQLSTATE of unique_violation.
+const uniqueViolationCode = "23505"
+
+// serializationFailureCode and deadlockDetectedCode are the SQLSTATE of a
+// transaction aborted because of concurrent transactions.
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+type postgresDialect struct{}
+
+func (p *postgresDialect) Name() string {
+	return Postgres
+}
+
+// Insert reads the id with RETURNING, the driver does not support LastInsertId.
+func (p *postgresDialect) Insert(ctx context.Context, db sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	var id int64
+	err := sqlx.GetContext(ctx, db, &id, db.Rebind(query+" RETURNING id"), args...)
+	return id, err
+}
+
+func (p *postgresDialect) NamedInsert(ctx context.Context, db sqlx.ExtContext, query string, arg interface{}) (int64, error) {
+	query, args, err := db.BindNamed(query+" RETURNING id", arg)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = sqlx.GetContext(ctx, db, &id, query, args...)
+	return id, err
+}
+
+func (p *postgresDialect) OnConflict(columns []string, updates ...string) string {
+	return "ON CONFLICT(" + strings.Join(columns, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+func (p *postgresDialect) Excluded(column string) string {
+	return "excluded." + column
+}
+
+// EqualFold matches the LOWER(column) indexes of the migrations.
+func (p *postgresDialect) EqualFold(column, param string) string {
+	return "LOWER(" + column + ") = LOWER(" + param + ")"
+}
+
+// TypedParam casts the parameter, PostgreSQL types the ones it cannot infer as text.
+func (p *postgresDialect) TypedParam(sqlType string) string {
+	return "CAST(? AS " + sqlType + ")"
+}
+
+func (p *postgresDialect) UniqueViolation(err error) (string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolationCode {
+		return "", false
+	}
+
+	return pqErr.Constraint, true
+}
+
+func (p *postgresDialect) Retryable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == serializationFailureCode || pqErr.Code == deadlockDetectedCode)
+}
@@ -0,0 +1,58 @@
+package dialect
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+type sqliteDialect struct{}
+
+func (s *sqliteDialect) Name() string {
+	return SQLite
+}
+
+func (s *sqliteDialect) Insert(ctx context.Context, db sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	return lastInsertID(ctx, db, query, args...)
+}
+
+func (s *sqliteDialect) NamedInsert(ctx context.Context, db sqlx.ExtContext, query string, arg interface{}) (int64, error) {
+	return namedLastInsertID(ctx, db, query, arg)
+}
+
+func (s *sqliteDialect) OnConflict(columns []string, updates ...string) string {
+	return "ON CONFLICT(" + strings.Join(columns, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+func (s *sqliteDialect) Excluded(column string) string {
+	return "excluded." + column
+}
+
+func (s *sqliteDialect) EqualFold(column, param string) string {
+	return column + " = " + param + " COLLATE NOCASE"
+}
+
+func (s *sqliteDialect) TypedParam(sqlType string) string {
+	return "?"
+}
+
+// UniqueViolation returns the message, it names the columns, e.g.
+// "UNIQUE constraint failed: customers.username".
+func (s *sqliteDialect) UniqueViolation(err error) (string, bool) {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return "", false
+	}
+
+	return sqliteErr.Error(), true
+}
+
+// Retryable is true when the database was locked by another connection longer
+// than the busy timeout, or when the transaction could not upgrade its lock.
+func (s *sqliteDialect) Retryable(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
@@ -0,0 +1,16 @@
+package guestcart
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=GuestCartRepository.go
+type Repository interface {
+	Exists(ctx context.Context, token string) (bool, error)
+	Upsert(ctx context.Context, token string, items []*model.CartItemEntity) error
+	GetItems(ctx context.Context, token string) ([]*model.CartItemEntity, error)
+	DeleteItem(ctx context.Context, token string, productID, variantID int) error
+	Delete(ctx context.Context, token string) error
+}
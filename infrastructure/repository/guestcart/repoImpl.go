@@ -0,0 +1,141 @@
+package guestcart
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+type guestCartRepoImpl struct {
+	redcl *redis.Client
+	ttl   time.Duration
+}
+
+func NewGuestCartRepository(redcl *redis.Client, ttl time.Duration) Repository {
+	return &guestCartRepoImpl{redcl: redcl, ttl: ttl}
+}
+
+func (g *guestCartRepoImpl) Exists(ctx context.Context, token string) (bool, error) {
+	count, err := g.redcl.Exists(ctx, constant.GuestCartPrefix+token).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Upsert adds the items to the guest cart, summing the quantity when the product
+// is already in the cart the same way cart_items does on conflict.
+func (g *guestCartRepoImpl) Upsert(ctx context.Context, token string, items []*model.CartItemEntity) error {
+	key := constant.GuestCartPrefix + token
+
+	pipe := g.redcl.TxPipeline()
+	for _, item := range items {
+		pipe.HIncrBy(ctx, key, itemField(item.ProductID, item.ProductVariantID), int64(item.Quantity))
+	}
+	pipe.Expire(ctx, key, g.ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *guestCartRepoImpl) GetItems(ctx context.Context, token string) ([]*model.CartItemEntity, error) {
+	res, err := g.redcl.HGetAll(ctx, constant.GuestCartPrefix+token).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+		return nil, err
+	}
+
+	items := make([]*model.CartItemEntity, 0, len(res))
+	for field, value := range res {
+		productID, variantID, err := parseItemField(field)
+		if err != nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+			return nil, err
+		}
+
+		quantity, err := strconv.Atoi(value)
+		if err != nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+			return nil, err
+		}
+
+		items = append(items, &model.CartItemEntity{
+			ProductID:        productID,
+			ProductVariantID: variantID,
+			Quantity:         quantity,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].ProductID != items[j].ProductID {
+			return items[i].ProductID < items[j].ProductID
+		}
+		return items[i].ProductVariantID < items[j].ProductVariantID
+	})
+
+	return items, nil
+}
+
+// DeleteItem returns sql.ErrNoRows when the item is not in the guest cart.
+func (g *guestCartRepoImpl) DeleteItem(ctx context.Context, token string, productID, variantID int) error {
+	affected, err := g.redcl.HDel(ctx, constant.GuestCartPrefix+token, itemField(productID, variantID)).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "guestcart", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (g *guestCartRepoImpl) Delete(ctx context.Context, token string) error {
+	if err := g.redcl.Del(ctx, constant.GuestCartPrefix+token).Err(); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "guestcart", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// itemField is the hash field of a cart item, "<product_id>:<product_variant_id>".
+func itemField(productID, variantID int) string {
+	return strconv.Itoa(productID) + ":" + strconv.Itoa(variantID)
+}
+
+// parseItemField also accepts the bare product id written before variants existed.
+func parseItemField(field string) (int, int, error) {
+	product, variant, found := strings.Cut(field, ":")
+	productID, err := strconv.Atoi(product)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !found {
+		return productID, 0, nil
+	}
+
+	variantID, err := strconv.Atoi(variant)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return productID, variantID, nil
+}
@@ -0,0 +1,195 @@
+package guestcart
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+const guestCartTTL = 7 * 24 * time.Hour
+
+func setup(t *testing.T) (*miniredis.Miniredis, Repository) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+
+	redcl := redis.NewClient(&redis.Options{
+		Addr: mockRedisServer.Addr(),
+	})
+
+	return mockRedisServer, NewGuestCartRepository(redcl, guestCartTTL)
+}
+
+func TestUpsert(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	testCases := []struct {
+		name    string
+		items   []*model.CartItemEntity
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Given new product when upsert then add the product",
+			items:   []*model.CartItemEntity{{ProductID: 1, Quantity: 2}},
+			want:    "2",
+			wantErr: false,
+		},
+		{
+			name:    "Given existing product when upsert then sum the quantity",
+			items:   []*model.CartItemEntity{{ProductID: 1, Quantity: 3}},
+			want:    "5",
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := repo.Upsert(context.Background(), "token", tc.items)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Upsert() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if got := mockRedisServer.HGet(constant.GuestCartPrefix+"token", "1:0"); got != tc.want {
+				t.Errorf("Upsert() quantity = %v, want %v", got, tc.want)
+			}
+
+			if ttl := mockRedisServer.TTL(constant.GuestCartPrefix + "token"); ttl != guestCartTTL {
+				t.Errorf("Upsert() ttl = %v, want %v", ttl, guestCartTTL)
+			}
+		})
+	}
+}
+
+func TestGetItems(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	mockRedisServer.HSet(constant.GuestCartPrefix+"token", "2:0", "1")
+	mockRedisServer.HSet(constant.GuestCartPrefix+"token", "1:4", "2")
+	// written before variants existed
+	mockRedisServer.HSet(constant.GuestCartPrefix+"token", "1", "3")
+	mockRedisServer.HSet(constant.GuestCartPrefix+"invalid", "product", "1")
+
+	testCases := []struct {
+		name    string
+		token   string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:    "Given existing cart when get items then return items ordered by product id",
+			token:   "token",
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "Given unknown token when get items then return empty items",
+			token:   "unknown",
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name:    "Given malformed cart when get items then return error",
+			token:   "invalid",
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := repo.GetItems(context.Background(), tc.token)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetItems() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if len(items) != tc.want {
+				t.Errorf("GetItems() len = %v, want %v", len(items), tc.want)
+				return
+			}
+
+			for i := 1; i < len(items); i++ {
+				prev, curr := items[i-1], items[i]
+				if prev.ProductID > curr.ProductID || (prev.ProductID == curr.ProductID && prev.ProductVariantID > curr.ProductVariantID) {
+					t.Errorf("GetItems() items are not ordered by product id")
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteItem(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	mockRedisServer.HSet(constant.GuestCartPrefix+"token", "1:0", "1")
+	mockRedisServer.HSet(constant.GuestCartPrefix+"token", "3:7", "1")
+
+	testCases := []struct {
+		name      string
+		productID int
+		variantID int
+		wantErr   bool
+	}{
+		{
+			name:      "Given existing product when delete item then return success",
+			productID: 1,
+			wantErr:   false,
+		},
+		{
+			name:      "Given existing variant when delete item then return success",
+			productID: 3,
+			variantID: 7,
+			wantErr:   false,
+		},
+		{
+			name:      "Given product not in cart when delete item then return error",
+			productID: 2,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := repo.DeleteItem(context.Background(), "token", tc.productID, tc.variantID)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DeleteItem() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if tc.wantErr && err != sql.ErrNoRows {
+				t.Errorf("DeleteItem() error = %v, want %v", err, sql.ErrNoRows)
+			}
+		})
+	}
+}
+
+func TestExistsAndDelete(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	mockRedisServer.HSet(constant.GuestCartPrefix+"token", "1", "1")
+
+	exists, err := repo.Exists(context.Background(), "token")
+	if err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v, want true", exists, err)
+	}
+
+	if err := repo.Delete(context.Background(), "token"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	exists, err = repo.Exists(context.Background(), "token")
+	if err != nil || exists {
+		t.Errorf("Exists() after delete = %v, %v, want false", exists, err)
+	}
+}
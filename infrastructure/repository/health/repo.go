@@ -0,0 +1,11 @@
+package health
+
+import (
+	"context"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=HealthRepository.go
+type Repository interface {
+	PingDatabase(ctx context.Context) error
+	PingRedis(ctx context.Context) error
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+)
+
+type healthRepoImpl struct {
+	db    *sqlx.DB
+	redcl *redis.Client
+}
+
+// NewHealthRepository checks the connections of the app, the database and
+// Redis, reaching them the way the other repositories do.
+func NewHealthRepository(db *sqlx.DB, redcl *redis.Client) Repository {
+	return &healthRepoImpl{db: db, redcl: redcl}
+}
+
+func (h *healthRepoImpl) PingDatabase(ctx context.Context) error {
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.WarnContext(ctx, "repository error", "repository", "health", "check", "database", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (h *healthRepoImpl) PingRedis(ctx context.Context) error {
+	if err := h.redcl.Ping(ctx).Err(); err != nil {
+		slog.WarnContext(ctx, "repository error", "repository", "health", "check", "redis", "error", err)
+		return err
+	}
+
+	return nil
+}
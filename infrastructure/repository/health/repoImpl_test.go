@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestPingDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewHealthRepository(sqlx.NewDb(db, "sqlmock"), nil)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given reachable database when ping then return success",
+			mock: func() {
+				mock.ExpectPing()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given unreachable database when ping then return error",
+			mock: func() {
+				mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			if err := repo.PingDatabase(context.Background()); (err != nil) != tc.wantErr {
+				t.Errorf("PingDatabase() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPingRedis(t *testing.T) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+	defer mockRedisServer.Close()
+
+	redcl := redis.NewClient(&redis.Options{
+		Addr:       mockRedisServer.Addr(),
+		MaxRetries: -1,
+	})
+	repo := NewHealthRepository(nil, redcl)
+
+	if err := repo.PingRedis(context.Background()); err != nil {
+		t.Errorf("PingRedis() error = %v, want nil", err)
+	}
+
+	mockRedisServer.Close()
+	if err := repo.PingRedis(context.Background()); err == nil {
+		t.Errorf("PingRedis() with redis down error = nil, want error")
+	}
+}
@@ -0,0 +1,14 @@
+package loginattempt
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=LoginAttemptRepository.go
+type Repository interface {
+	LockedFor(ctx context.Context, subject string) (time.Duration, error)
+	RecordFailure(ctx context.Context, subject string) (int, error)
+	Lock(ctx context.Context, subject string, duration time.Duration) error
+	Reset(ctx context.Context, subject string) error
+}
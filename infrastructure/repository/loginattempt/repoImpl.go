@@ -0,0 +1,78 @@
+package loginattempt
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+)
+
+type loginAttemptRepoImpl struct {
+	redcl  *redis.Client
+	window time.Duration
+}
+
+// NewLoginAttemptRepository counts the failed logins of a subject, a username or
+// an IP address, and forgets them once the subject did not fail for the window.
+func NewLoginAttemptRepository(redcl *redis.Client, window time.Duration) Repository {
+	return &loginAttemptRepoImpl{redcl: redcl, window: window}
+}
+
+// LockedFor returns how long the subject stays locked, zero when it is not locked.
+func (l *loginAttemptRepoImpl) LockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := l.redcl.PTTL(ctx, constant.LoginLockPrefix+subject).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginattempt", "error", err)
+		return 0, err
+	}
+
+	// negative when the key does not exist or has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// RecordFailure returns the number of failures of the subject within the window.
+func (l *loginAttemptRepoImpl) RecordFailure(ctx context.Context, subject string) (int, error) {
+	key := constant.LoginFailuresPrefix + subject
+
+	pipe := l.redcl.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, l.window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginattempt", "error", err)
+		return 0, err
+	}
+
+	return int(incr.Val()), nil
+}
+
+// Lock locks the subject out for the duration and starts its failure count over.
+func (l *loginAttemptRepoImpl) Lock(ctx context.Context, subject string, duration time.Duration) error {
+
+	pipe := l.redcl.TxPipeline()
+	pipe.Set(ctx, constant.LoginLockPrefix+subject, 1, duration)
+	pipe.Del(ctx, constant.LoginFailuresPrefix+subject)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginattempt", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Reset forgets the failures of the subject, a lock is left to expire.
+func (l *loginAttemptRepoImpl) Reset(ctx context.Context, subject string) error {
+	if err := l.redcl.Del(ctx, constant.LoginFailuresPrefix+subject).Err(); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginattempt", "error", err)
+		return err
+	}
+
+	return nil
+}
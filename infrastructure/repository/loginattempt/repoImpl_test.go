@@ -0,0 +1,92 @@
+package loginattempt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+)
+
+const failureWindow = 15 * time.Minute
+
+func setup(t *testing.T) (*miniredis.Miniredis, Repository) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+
+	redcl := redis.NewClient(&redis.Options{
+		Addr: mockRedisServer.Addr(),
+	})
+
+	return mockRedisServer, NewLoginAttemptRepository(redcl, failureWindow)
+}
+
+func TestRecordFailure(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	for want := 1; want <= 3; want++ {
+		got, err := repo.RecordFailure(context.Background(), "user:john")
+		if err != nil {
+			t.Fatalf("RecordFailure() error = %v", err)
+		}
+
+		if got != want {
+			t.Errorf("RecordFailure() = %d, want %d", got, want)
+		}
+	}
+
+	if ttl := mockRedisServer.TTL(constant.LoginFailuresPrefix + "user:john"); ttl != failureWindow {
+		t.Errorf("RecordFailure() ttl = %v, want %v", ttl, failureWindow)
+	}
+
+	mockRedisServer.FastForward(failureWindow)
+	if got, _ := repo.RecordFailure(context.Background(), "user:john"); got != 1 {
+		t.Errorf("RecordFailure() after the window = %d, want 1", got)
+	}
+}
+
+func TestLock(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	if locked, err := repo.LockedFor(context.Background(), "ip:10.0.0.1"); err != nil || locked != 0 {
+		t.Fatalf("LockedFor() = %v, %v, want not locked", locked, err)
+	}
+
+	repo.RecordFailure(context.Background(), "ip:10.0.0.1")
+	if err := repo.Lock(context.Background(), "ip:10.0.0.1", time.Minute); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	if locked, err := repo.LockedFor(context.Background(), "ip:10.0.0.1"); err != nil || locked != time.Minute {
+		t.Errorf("LockedFor() = %v, %v, want %v", locked, err, time.Minute)
+	}
+
+	if mockRedisServer.Exists(constant.LoginFailuresPrefix + "ip:10.0.0.1") {
+		t.Errorf("Lock() should start the failure count over")
+	}
+
+	mockRedisServer.FastForward(time.Minute)
+	if locked, _ := repo.LockedFor(context.Background(), "ip:10.0.0.1"); locked != 0 {
+		t.Errorf("LockedFor() after the lock = %v, want 0", locked)
+	}
+}
+
+func TestReset(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	repo.RecordFailure(context.Background(), "user:john")
+	if err := repo.Reset(context.Background(), "user:john"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	if mockRedisServer.Exists(constant.LoginFailuresPrefix + "user:john") {
+		t.Errorf("Reset() should delete the failures")
+	}
+}
@@ -0,0 +1,14 @@
+package loginchallenge
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=LoginChallengeRepository.go
+type Repository interface {
+	Create(ctx context.Context, challenge *model.LoginChallengeEntity) error
+	Get(ctx context.Context, id string) (*model.LoginChallengeEntity, error)
+	Delete(ctx context.Context, id string) (bool, error)
+}
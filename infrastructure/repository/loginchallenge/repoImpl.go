@@ -0,0 +1,69 @@
+package loginchallenge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+type loginChallengeRepoImpl struct {
+	redcl *redis.Client
+	ttl   time.Duration
+}
+
+// NewLoginChallengeRepository keeps a challenge for ttl, the customer has that
+// long to enter the two-factor code.
+func NewLoginChallengeRepository(redcl *redis.Client, ttl time.Duration) Repository {
+	return &loginChallengeRepoImpl{redcl: redcl, ttl: ttl}
+}
+
+func (l *loginChallengeRepoImpl) Create(ctx context.Context, challenge *model.LoginChallengeEntity) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginchallenge", "error", err)
+		return err
+	}
+
+	if err := l.redcl.Set(ctx, constant.LoginChallengePrefix+challenge.ID, data, l.ttl).Err(); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginchallenge", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Get returns redis.Nil when the challenge expired or was answered.
+func (l *loginChallengeRepoImpl) Get(ctx context.Context, id string) (*model.LoginChallengeEntity, error) {
+	data, err := l.redcl.Get(ctx, constant.LoginChallengePrefix+id).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "loginchallenge", "error", err)
+		}
+		return nil, err
+	}
+
+	challenge := &model.LoginChallengeEntity{}
+	if err := json.Unmarshal(data, challenge); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginchallenge", "error", err)
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// Delete returns false when the challenge was already gone, so of two requests
+// answering the same challenge only one logs in.
+func (l *loginChallengeRepoImpl) Delete(ctx context.Context, id string) (bool, error) {
+	deleted, err := l.redcl.Del(ctx, constant.LoginChallengePrefix+id).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "loginchallenge", "error", err)
+		return false, err
+	}
+
+	return deleted > 0, nil
+}
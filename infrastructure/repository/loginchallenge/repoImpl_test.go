@@ -0,0 +1,92 @@
+package loginchallenge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+const challengeTTL = 5 * time.Minute
+
+func setup(t *testing.T) (*miniredis.Miniredis, Repository) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+
+	redcl := redis.NewClient(&redis.Options{
+		Addr: mockRedisServer.Addr(),
+	})
+
+	return mockRedisServer, NewLoginChallengeRepository(redcl, challengeTTL)
+}
+
+func TestCreateAndGet(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	challenge := &model.LoginChallengeEntity{
+		ID:         "hash",
+		CustomerID: 1,
+		Username:   "john",
+		CartToken:  "cart-token",
+		CreatedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if err := repo.Create(context.Background(), challenge); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if ttl := mockRedisServer.TTL(constant.LoginChallengePrefix + "hash"); ttl != challengeTTL {
+		t.Errorf("Create() ttl = %v, want %v", ttl, challengeTTL)
+	}
+
+	got, err := repo.Get(context.Background(), "hash")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if *got != *challenge {
+		t.Errorf("Get() = %+v, want %+v", got, challenge)
+	}
+
+	mockRedisServer.FastForward(challengeTTL)
+	if _, err := repo.Get(context.Background(), "hash"); err != redis.Nil {
+		t.Errorf("Get() after the ttl error = %v, want redis.Nil", err)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	mockRedisServer.Set(constant.LoginChallengePrefix+"hash", "not json")
+	if _, err := repo.Get(context.Background(), "hash"); err == nil {
+		t.Errorf("Get() error = nil, want an error")
+	}
+
+	mockRedisServer.Close()
+	if _, err := repo.Get(context.Background(), "hash"); err == nil || err == redis.Nil {
+		t.Errorf("Get() error = %v, want a connection error", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	repo.Create(context.Background(), &model.LoginChallengeEntity{ID: "hash", CustomerID: 1})
+
+	if deleted, err := repo.Delete(context.Background(), "hash"); err != nil || !deleted {
+		t.Errorf("Delete() = %v, %v, want true", deleted, err)
+	}
+
+	if deleted, err := repo.Delete(context.Background(), "hash"); err != nil || deleted {
+		t.Errorf("Delete() again = %v, %v, want false", deleted, err)
+	}
+}
@@ -1,10 +1,25 @@
 package product
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=ProductRepository.go
 type Repository interface {
-	Create(product *model.ProductEntity) error
-	GetAll(request *model.GetProductRequest) ([]*model.ProductResponse, error)
-	GetByID(id int) (*model.ProductEntity, error)
+	Create(ctx context.Context, product *model.ProductEntity) error
+	GetAll(ctx context.Context, request *model.GetProductRequest) ([]*model.ProductResponse, error)
+	GetByID(ctx context.Context, id int) (*model.ProductEntity, error)
+	CreateOption(ctx context.Context, option *model.ProductOptionEntity) error
+	GetOptions(ctx context.Context, productID int) ([]*model.ProductOptionEntity, error)
+	CreateVariant(ctx context.Context, variant *model.ProductVariantEntity) error
+	GetVariants(ctx context.Context, productID int) ([]*model.ProductVariantEntity, error)
+	GetVariantByID(ctx context.Context, id int) (*model.ProductVariantEntity, error)
+	CreateImage(ctx context.Context, image *model.ProductImageEntity) (*model.ProductImageEntity, error)
+	GetImageByID(ctx context.Context, id int) (*model.ProductImageEntity, error)
+	GetImages(ctx context.Context, productIDs []int) ([]*model.ProductImageEntity, error)
+	DeleteImage(ctx context.Context, image *model.ProductImageEntity) error
+	ReorderImages(ctx context.Context, productID int, imageIDs []int) error
+	SetPrimaryImage(ctx context.Context, productID, imageID int) error
 }
\ No newline at end of file
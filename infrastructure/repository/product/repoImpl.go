@@ -1,41 +1,72 @@
 package product
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
 
 	"github.com/jmoiron/sqlx"
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
+// ErrSKUTaken is returned when the SKU of a new variant is already used.
+var ErrSKUTaken = errors.New("sku already taken")
+
 type productRepoImpl struct {
-	db *sqlx.DB
+	db      dbtx.DB
+	dialect dialect.Dialect
 }
 
-func NewProductRepository(db *sqlx.DB) Repository {
-	return &productRepoImpl{db: db}
+func NewProductRepository(db dbtx.DB) Repository {
+	return &productRepoImpl{db: db, dialect: dialect.For(db)}
 }
 
-func (p *productRepoImpl) Create(product *model.ProductEntity) error {
+func (p *productRepoImpl) Create(ctx context.Context, product *model.ProductEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "product", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("product", "Create").ObserveDuration()
+
 	query := "INSERT INTO products (name, description, price, stock_quantity, category_id) VALUES (:name, :description, :price, :stock_quantity, :category_id)"
-	_, err := p.db.NamedExec(query, product)
+	_, err := p.db.NamedExecContext(ctx, query, product)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (p *productRepoImpl) GetAll(request *model.GetProductRequest) ([]*model.ProductResponse, error) {
+func (p *productRepoImpl) GetAll(ctx context.Context, request *model.GetProductRequest) ([]*model.ProductResponse, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetAll")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetAll").ObserveDuration()
+
 	products := make([]*model.ProductResponse, 0)
 	params := make([]interface{}, 0)
 	
-	query := "SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name FROM products AS p JOIN categories AS c ON p.category_id = c.id WHERE TRUE"
+	// only approved reviews count towards the rating
+	query := "SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name, COALESCE(r.average_rating, 0) AS average_rating, COALESCE(r.review_count, 0) AS review_count FROM products AS p JOIN categories AS c ON p.category_id = c.id LEFT JOIN (SELECT product_id, AVG(rating) AS average_rating, COUNT(*) AS review_count FROM reviews WHERE status = ? GROUP BY product_id) AS r ON r.product_id = p.id WHERE TRUE"
+	params = append(params, reviewEnum.ReviewStatusApproved)
+
 	if request.CategoryID != nil {
 		query += " AND p.category_id = ?"
 		params = append(params, request.CategoryID)
 	}
 
+	if request.SortBy.IsValid() {
+		direction := "ASC"
+		if request.Descending {
+			direction = "DESC"
+		}
+		query += " ORDER BY " + request.SortBy.Column() + " " + direction + ", p.id"
+	}
+
 	if request.Limit != 0 {
 		query += " LIMIT ?"
 		params = append(params, request.Limit)
@@ -46,9 +77,9 @@ func (p *productRepoImpl) GetAll(request *model.GetProductRequest) ([]*model.Pro
 		params = append(params, (request.Page-1)*request.Limit)
 	}
 
-	res, err := p.db.Query(query, params...)
+	res, err := p.db.QueryContext(ctx, p.db.Rebind(query), params...)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
 		return nil, err
 	}
 
@@ -61,8 +92,10 @@ func (p *productRepoImpl) GetAll(request *model.GetProductRequest) ([]*model.Pro
 			&product.Price,
 			&product.StockQuantity,
 			&product.Category,
+			&product.AverageRating,
+			&product.ReviewCount,
 		); err != nil {
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
 			return nil, err
 		}
 		products = append(products, product)
@@ -71,15 +104,348 @@ func (p *productRepoImpl) GetAll(request *model.GetProductRequest) ([]*model.Pro
 	return products, nil
 }
 
-func (p *productRepoImpl) GetByID(id int) (*model.ProductEntity, error) {
+func (p *productRepoImpl) GetByID(ctx context.Context, id int) (*model.ProductEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetByID")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetByID").ObserveDuration()
+
 	product := &model.ProductEntity{}
 	query := "SELECT id, name, description, price, stock_quantity, category_id, created_at, updated_at FROM products WHERE id = ?"
 
-	err := p.db.Get(product, query, id)
+	err := p.db.GetContext(ctx, product, p.db.Rebind(query), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
 		return nil, err
 	}
 
 	return product, nil
-}
\ No newline at end of file
+}
+
+func (p *productRepoImpl) CreateOption(ctx context.Context, option *model.ProductOptionEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "product", "CreateOption")
+	defer span.End()
+	defer metrics.QueryTimer("product", "CreateOption").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, p.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	optionID, err := p.dialect.NamedInsert(ctx, tx, "INSERT INTO product_options (product_id, name) VALUES (:product_id, :name)", option)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	for _, value := range option.Values {
+		value.OptionID = int(optionID)
+		_, err = tx.NamedExecContext(ctx, "INSERT INTO product_option_values (product_option_id, value) VALUES (:product_option_id, :value)", value)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (p *productRepoImpl) GetOptions(ctx context.Context, productID int) ([]*model.ProductOptionEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetOptions")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetOptions").ObserveDuration()
+
+	options := []*model.ProductOptionEntity{}
+	query := "SELECT id, product_id, name, created_at, updated_at FROM product_options WHERE product_id = ? ORDER BY id"
+	err := p.db.SelectContext(ctx, &options, p.db.Rebind(query), productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	values := []*model.ProductOptionValueEntity{}
+	query = "SELECT v.id, v.product_option_id, o.name AS option_name, v.value FROM product_option_values AS v JOIN product_options AS o ON v.product_option_id = o.id WHERE o.product_id = ? ORDER BY v.id"
+	err = p.db.SelectContext(ctx, &values, p.db.Rebind(query), productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	optionByID := make(map[int]*model.ProductOptionEntity, len(options))
+	for _, option := range options {
+		option.Values = make([]*model.ProductOptionValueEntity, 0)
+		optionByID[option.ID] = option
+	}
+
+	for _, value := range values {
+		if option, ok := optionByID[value.OptionID]; ok {
+			option.Values = append(option.Values, value)
+		}
+	}
+
+	return options, nil
+}
+
+func (p *productRepoImpl) CreateVariant(ctx context.Context, variant *model.ProductVariantEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "product", "CreateVariant")
+	defer span.End()
+	defer metrics.QueryTimer("product", "CreateVariant").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, p.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	variantID, err := p.dialect.NamedInsert(ctx, tx, "INSERT INTO product_variants (product_id, sku, price, stock_quantity) VALUES (:product_id, :sku, :price, :stock_quantity)", variant)
+	if err != nil {
+		tx.Rollback()
+		// the SKU is the only unique column of the variants
+		if _, ok := p.dialect.UniqueViolation(err); ok {
+			slog.DebugContext(ctx, "repository error", "repository", "product", "error", err)
+			return ErrSKUTaken
+		}
+
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	for _, value := range variant.OptionValues {
+		_, err = tx.ExecContext(ctx, tx.Rebind("INSERT INTO product_variant_values (product_variant_id, product_option_value_id) VALUES (?, ?)"), variantID, value.ID)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (p *productRepoImpl) GetVariants(ctx context.Context, productID int) ([]*model.ProductVariantEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetVariants")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetVariants").ObserveDuration()
+
+	variants := []*model.ProductVariantEntity{}
+	query := "SELECT id, product_id, sku, price, stock_quantity, created_at, updated_at FROM product_variants WHERE product_id = ? ORDER BY id"
+	err := p.db.SelectContext(ctx, &variants, p.db.Rebind(query), productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	values := []*variantOptionValue{}
+	query = "SELECT vv.product_variant_id, v.id, v.product_option_id, o.name AS option_name, v.value FROM product_variant_values AS vv JOIN product_option_values AS v ON vv.product_option_value_id = v.id JOIN product_options AS o ON v.product_option_id = o.id WHERE o.product_id = ? ORDER BY o.id"
+	err = p.db.SelectContext(ctx, &values, p.db.Rebind(query), productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	variantByID := make(map[int]*model.ProductVariantEntity, len(variants))
+	for _, variant := range variants {
+		variant.OptionValues = make([]*model.ProductOptionValueEntity, 0)
+		variantByID[variant.ID] = variant
+	}
+
+	for _, value := range values {
+		if variant, ok := variantByID[value.VariantID]; ok {
+			variant.OptionValues = append(variant.OptionValues, &value.ProductOptionValueEntity)
+		}
+	}
+
+	return variants, nil
+}
+
+// variantOptionValue is an option value row tagged with the variant it belongs to.
+type variantOptionValue struct {
+	VariantID int `db:"product_variant_id"`
+	model.ProductOptionValueEntity
+}
+
+func (p *productRepoImpl) GetVariantByID(ctx context.Context, id int) (*model.ProductVariantEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetVariantByID")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetVariantByID").ObserveDuration()
+
+	variant := &model.ProductVariantEntity{}
+	query := "SELECT id, product_id, sku, price, stock_quantity, created_at, updated_at FROM product_variants WHERE id = ?"
+	err := p.db.GetContext(ctx, variant, p.db.Rebind(query), id)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (p *productRepoImpl) CreateImage(ctx context.Context, image *model.ProductImageEntity) (*model.ProductImageEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "CreateImage")
+	defer span.End()
+	defer metrics.QueryTimer("product", "CreateImage").ObserveDuration()
+
+	// new images go last, the first image of a product becomes its primary image
+	integer := p.dialect.TypedParam("INTEGER")
+	query := "INSERT INTO product_images (product_id, path, thumbnail_path, content_type, size, position, is_primary) SELECT " + integer + ", ?, ?, ?, " + integer + ", COALESCE(MAX(position), 0) + 1, COUNT(*) = 0 FROM product_images WHERE product_id = ?"
+	imageID, err := p.dialect.Insert(ctx, p.db, query, image.ProductID, image.Path, image.ThumbnailPath, image.ContentType, image.Size, image.ProductID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	return p.GetImageByID(ctx, int(imageID))
+}
+
+func (p *productRepoImpl) GetImageByID(ctx context.Context, id int) (*model.ProductImageEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetImageByID")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetImageByID").ObserveDuration()
+
+	image := &model.ProductImageEntity{}
+	query := "SELECT id, product_id, path, thumbnail_path, content_type, size, position, is_primary, created_at, updated_at FROM product_images WHERE id = ?"
+	err := p.db.GetContext(ctx, image, p.db.Rebind(query), id)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (p *productRepoImpl) GetImages(ctx context.Context, productIDs []int) ([]*model.ProductImageEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "product", "GetImages")
+	defer span.End()
+	defer metrics.QueryTimer("product", "GetImages").ObserveDuration()
+
+	images := []*model.ProductImageEntity{}
+	if len(productIDs) == 0 {
+		return images, nil
+	}
+
+	query, args, err := sqlx.In("SELECT id, product_id, path, thumbnail_path, content_type, size, position, is_primary, created_at, updated_at FROM product_images WHERE product_id IN (?) ORDER BY product_id, position, id", productIDs)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	err = p.db.SelectContext(ctx, &images, p.db.Rebind(query), args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (p *productRepoImpl) DeleteImage(ctx context.Context, image *model.ProductImageEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "product", "DeleteImage")
+	defer span.End()
+	defer metrics.QueryTimer("product", "DeleteImage").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, p.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind("DELETE FROM product_images WHERE id = ?"), image.ID)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	// hand the primary flag over to the next image in line, the derived table
+	// lets MySQL select from the table it updates
+	if image.IsPrimary {
+		_, err = tx.ExecContext(ctx, tx.Rebind("UPDATE product_images SET is_primary = TRUE, updated_at = CURRENT_TIMESTAMP WHERE id = (SELECT id FROM (SELECT id FROM product_images WHERE product_id = ? ORDER BY position, id LIMIT 1) AS next_image)"), image.ProductID)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (p *productRepoImpl) ReorderImages(ctx context.Context, productID int, imageIDs []int) error {
+	ctx, span := tracing.StartQuery(ctx, "product", "ReorderImages")
+	defer span.End()
+	defer metrics.QueryTimer("product", "ReorderImages").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, p.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	for i, imageID := range imageIDs {
+		res, err := tx.ExecContext(ctx, tx.Rebind("UPDATE product_images SET position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND product_id = ?"), i+1, imageID, productID)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+			return err
+		}
+
+		if affected == 0 {
+			tx.Rollback()
+			slog.DebugContext(ctx, "repository error", "repository", "product", "error", sql.ErrNoRows)
+			return sql.ErrNoRows
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (p *productRepoImpl) SetPrimaryImage(ctx context.Context, productID, imageID int) error {
+	ctx, span := tracing.StartQuery(ctx, "product", "SetPrimaryImage")
+	defer span.End()
+	defer metrics.QueryTimer("product", "SetPrimaryImage").ObserveDuration()
+
+	_, err := p.db.ExecContext(ctx, p.db.Rebind("UPDATE product_images SET is_primary = (id = ?), updated_at = CURRENT_TIMESTAMP WHERE product_id = ?"), imageID, productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "product", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -1,25 +1,26 @@
 package product
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/jmoiron/sqlx"
 	"github.com/shopspring/decimal"
+	productEnum "github.com/zakiyalmaya/online-store/constant/product"
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 func TestCreate(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testCreate)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name    string
@@ -50,7 +51,7 @@ func TestCreate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewProductRepository(sqlxDB)
 			tc.mock()
-			err := repo.Create(&model.ProductEntity{
+			err := repo.Create(context.Background(), &model.ProductEntity{
 				Name:          "T-Shirt",
 				Price:         decimal.NewFromFloat(10000),
 				StockQuantity: 10,
@@ -65,14 +66,11 @@ func TestCreate(t *testing.T) {
 }
 
 func TestGetByID(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetByID)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetByID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name    string
@@ -104,7 +102,7 @@ func TestGetByID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewProductRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.GetByID(1)
+			_, err := repo.GetByID(context.Background(), 1)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetByID() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -113,14 +111,11 @@ func TestGetByID(t *testing.T) {
 }
 
 func TestGetAll(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetAll)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetAll(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	categoryID := 1
 	request := &model.GetProductRequest{
@@ -139,10 +134,24 @@ func TestGetAll(t *testing.T) {
 			name: "Given valid request when get all then return success",
 			request: request,
 			mock: func() {
-				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name FROM products AS p JOIN categories AS c ON p.category_id = c.id WHERE TRUE AND p.category_id = ? LIMIT ? OFFSET ?").
-					WithArgs(request.CategoryID, request.Limit, (request.Page-1)*request.Limit).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "price", "stock_quantity", "name"}).
-						AddRow(1, "T-Shirt", "T-Shirt description", "10000", 10, "Fashion"))
+				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name, COALESCE(r.average_rating, 0) AS average_rating, COALESCE(r.review_count, 0) AS review_count FROM products AS p JOIN categories AS c ON p.category_id = c.id LEFT JOIN (SELECT product_id, AVG(rating) AS average_rating, COUNT(*) AS review_count FROM reviews WHERE status = ? GROUP BY product_id) AS r ON r.product_id = p.id WHERE TRUE AND p.category_id = ? LIMIT ? OFFSET ?").
+					WithArgs(reviewEnum.ReviewStatusApproved, request.CategoryID, request.Limit, (request.Page-1)*request.Limit).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "price", "stock_quantity", "name", "average_rating", "review_count"}).
+						AddRow(1, "T-Shirt", "T-Shirt description", "10000", 10, "Fashion", 4.5, 2))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given sort by rating when get all then order by average rating",
+			request: &model.GetProductRequest{
+				SortBy:     productEnum.SortByRating,
+				Descending: true,
+			},
+			mock: func() {
+				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name, COALESCE(r.average_rating, 0) AS average_rating, COALESCE(r.review_count, 0) AS review_count FROM products AS p JOIN categories AS c ON p.category_id = c.id LEFT JOIN (SELECT product_id, AVG(rating) AS average_rating, COUNT(*) AS review_count FROM reviews WHERE status = ? GROUP BY product_id) AS r ON r.product_id = p.id WHERE TRUE ORDER BY average_rating DESC, p.id").
+					WithArgs(reviewEnum.ReviewStatusApproved).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "price", "stock_quantity", "name", "average_rating", "review_count"}).
+						AddRow(1, "T-Shirt", "T-Shirt description", "10000", 10, "Fashion", 4.5, 2))
 			},
 			wantErr: false,
 		},
@@ -150,8 +159,8 @@ func TestGetAll(t *testing.T) {
 			name: "Given error when get all then return error",
 			request: request,
 			mock: func() {
-				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name FROM products AS p JOIN categories AS c ON p.category_id = c.id WHERE TRUE AND p.category_id = ? LIMIT ? OFFSET ?").
-					WithArgs(request.CategoryID, request.Limit, (request.Page-1)*request.Limit).
+				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name, COALESCE(r.average_rating, 0) AS average_rating, COALESCE(r.review_count, 0) AS review_count FROM products AS p JOIN categories AS c ON p.category_id = c.id LEFT JOIN (SELECT product_id, AVG(rating) AS average_rating, COUNT(*) AS review_count FROM reviews WHERE status = ? GROUP BY product_id) AS r ON r.product_id = p.id WHERE TRUE AND p.category_id = ? LIMIT ? OFFSET ?").
+					WithArgs(reviewEnum.ReviewStatusApproved, request.CategoryID, request.Limit, (request.Page-1)*request.Limit).
 					WillReturnError(errors.New("error"))
 			},
 			wantErr: true,
@@ -160,10 +169,10 @@ func TestGetAll(t *testing.T) {
 			name:    "Given error scan row when get all then return error",
 			request: request,
 			mock:    func() {
-				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name FROM products AS p JOIN categories AS c ON p.category_id = c.id WHERE TRUE AND p.category_id = ? LIMIT ? OFFSET ?").
-					WithArgs(request.CategoryID, request.Limit, (request.Page-1)*request.Limit).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "price", "stock_quantity", "name", "id"}).
-						AddRow(1, "T-Shirt", "T-Shirt description", "10000", 10, "Fashion", 1))
+				mock.ExpectQuery("SELECT p.id, p.name, p.description, p.price, p.stock_quantity, c.name, COALESCE(r.average_rating, 0) AS average_rating, COALESCE(r.review_count, 0) AS review_count FROM products AS p JOIN categories AS c ON p.category_id = c.id LEFT JOIN (SELECT product_id, AVG(rating) AS average_rating, COUNT(*) AS review_count FROM reviews WHERE status = ? GROUP BY product_id) AS r ON r.product_id = p.id WHERE TRUE AND p.category_id = ? LIMIT ? OFFSET ?").
+					WithArgs(reviewEnum.ReviewStatusApproved, request.CategoryID, request.Limit, (request.Page-1)*request.Limit).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "price", "stock_quantity", "name", "average_rating", "review_count", "id"}).
+						AddRow(1, "T-Shirt", "T-Shirt description", "10000", 10, "Fashion", 4.5, 2, 1))
 			},
 			wantErr: true,
 		},
@@ -173,10 +182,489 @@ func TestGetAll(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			repo := NewProductRepository(sqlxDB)
 			tc.mock()
-			_, err := repo.GetAll(tc.request)
+			_, err := repo.GetAll(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetAll() error = %v, wantErr %v", err, tc.wantErr)
 			}
 		})
 	}
 }
+
+func TestCreateOption(t *testing.T) {
+	dialecttest.Run(t, testCreateOption)
+}
+
+func testCreateOption(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when create option then return success",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_options (product_id, name) VALUES (?, ?)").
+					WithArgs(1, "Size").
+					WillReturnID(1)
+				mock.ExpectExec("INSERT INTO product_option_values (product_option_id, value) VALUES (?, ?)").
+					WithArgs(1, "M").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO product_option_values (product_option_id, value) VALUES (?, ?)").
+					WithArgs(1, "L").
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error when insert option then return error",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_options (product_id, name) VALUES (?, ?)").
+					WithArgs(1, "Size").
+					WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when insert option value then return error",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_options (product_id, name) VALUES (?, ?)").
+					WithArgs(1, "Size").
+					WillReturnID(1)
+				mock.ExpectExec("INSERT INTO product_option_values (product_option_id, value) VALUES (?, ?)").
+					WithArgs(1, "M").
+					WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			err := repo.CreateOption(context.Background(), &model.ProductOptionEntity{
+				ProductID: 1,
+				Name:      "Size",
+				Values: []*model.ProductOptionValueEntity{
+					{Value: "M"},
+					{Value: "L"},
+				},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateOption() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateVariant(t *testing.T) {
+	dialecttest.Run(t, testCreateVariant)
+}
+
+func testCreateVariant(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	testCases := []struct {
+		name         string
+		mock         func()
+		wantErr      bool
+		wantSKUTaken bool
+	}{
+		{
+			name: "Given valid request when create variant then return success",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_variants (product_id, sku, price, stock_quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, "TSHIRT-M-RED", "12000", 5).
+					WillReturnID(1)
+				mock.ExpectExec("INSERT INTO product_variant_values (product_variant_id, product_option_value_id) VALUES (?, ?)").
+					WithArgs(1, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO product_variant_values (product_variant_id, product_option_value_id) VALUES (?, ?)").
+					WithArgs(1, 3).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error when insert variant then return error",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_variants (product_id, sku, price, stock_quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, "TSHIRT-M-RED", "12000", 5).
+					WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given sku already used when insert variant then return sku taken",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_variants (product_id, sku, price, stock_quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, "TSHIRT-M-RED", "12000", 5).
+					WillReturnError(dialecttest.UniqueViolation(driverName, "product_variants_sku_key"))
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantSKUTaken: true,
+		},
+		{
+			name: "Given error when insert variant value then return error",
+			mock: func() {
+				mock.ExpectBegin()
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO product_variants (product_id, sku, price, stock_quantity) VALUES (?, ?, ?, ?)").
+					WithArgs(1, "TSHIRT-M-RED", "12000", 5).
+					WillReturnID(1)
+				mock.ExpectExec("INSERT INTO product_variant_values (product_variant_id, product_option_value_id) VALUES (?, ?)").
+					WithArgs(1, 1).
+					WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			err := repo.CreateVariant(context.Background(), &model.ProductVariantEntity{
+				ProductID:     1,
+				SKU:           "TSHIRT-M-RED",
+				Price:         decimal.NewNullDecimal(decimal.NewFromFloat(12000)),
+				StockQuantity: 5,
+				OptionValues: []*model.ProductOptionValueEntity{
+					{ID: 1},
+					{ID: 3},
+				},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateVariant() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if (err == ErrSKUTaken) != tc.wantSKUTaken {
+				t.Errorf("CreateVariant() error = %v, want sku taken %v", err, tc.wantSKUTaken)
+			}
+		})
+	}
+}
+
+func TestGetVariants(t *testing.T) {
+	dialecttest.Run(t, testGetVariants)
+}
+
+func testGetVariants(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Given valid product id when get variants then return variants with option values",
+			mock: func() {
+				mock.ExpectQuery("SELECT id, product_id, sku, price, stock_quantity, created_at, updated_at FROM product_variants WHERE product_id = ? ORDER BY id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "sku", "price", "stock_quantity", "created_at", "updated_at"}).
+						AddRow(1, 1, "TSHIRT-M-RED", nil, 5, time.Time{}, time.Time{}))
+				mock.ExpectQuery("SELECT vv.product_variant_id, v.id, v.product_option_id, o.name AS option_name, v.value FROM product_variant_values AS vv JOIN product_option_values AS v ON vv.product_option_value_id = v.id JOIN product_options AS o ON v.product_option_id = o.id WHERE o.product_id = ? ORDER BY o.id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"product_variant_id", "id", "product_option_id", "option_name", "value"}).
+						AddRow(1, 1, 1, "Size", "M").
+						AddRow(1, 3, 2, "Color", "Red"))
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "Given error when get variants then return error",
+			mock: func() {
+				mock.ExpectQuery("SELECT id, product_id, sku, price, stock_quantity, created_at, updated_at FROM product_variants WHERE product_id = ? ORDER BY id").
+					WithArgs(1).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when get variant values then return error",
+			mock: func() {
+				mock.ExpectQuery("SELECT id, product_id, sku, price, stock_quantity, created_at, updated_at FROM product_variants WHERE product_id = ? ORDER BY id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "sku", "price", "stock_quantity", "created_at", "updated_at"}).
+						AddRow(1, 1, "TSHIRT-M-RED", nil, 5, time.Time{}, time.Time{}))
+				mock.ExpectQuery("SELECT vv.product_variant_id, v.id, v.product_option_id, o.name AS option_name, v.value FROM product_variant_values AS vv JOIN product_option_values AS v ON vv.product_option_value_id = v.id JOIN product_options AS o ON v.product_option_id = o.id WHERE o.product_id = ? ORDER BY o.id").
+					WithArgs(1).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			variants, err := repo.GetVariants(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetVariants() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr && len(variants[0].OptionValues) != tc.want {
+				t.Errorf("GetVariants() option values = %v, want %v", len(variants[0].OptionValues), tc.want)
+			}
+		})
+	}
+}
+
+func TestCreateImage(t *testing.T) {
+	dialecttest.Run(t, testCreateImage)
+}
+
+func testCreateImage(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	image := &model.ProductImageEntity{
+		ProductID:     1,
+		Path:          "products/1/image.png",
+		ThumbnailPath: "products/1/image_thumb.png",
+		ContentType:   "image/png",
+		Size:          1024,
+	}
+
+	query := map[string]string{
+		dialect.SQLite:   "INSERT INTO product_images (product_id, path, thumbnail_path, content_type, size, position, is_primary) SELECT ?, ?, ?, ?, ?, COALESCE(MAX(position), 0) + 1, COUNT(*) = 0 FROM product_images WHERE product_id = ?",
+		dialect.Postgres: "INSERT INTO product_images (product_id, path, thumbnail_path, content_type, size, position, is_primary) SELECT CAST(? AS INTEGER), ?, ?, ?, CAST(? AS INTEGER), COALESCE(MAX(position), 0) + 1, COUNT(*) = 0 FROM product_images WHERE product_id = ?",
+		dialect.MySQL:    "INSERT INTO product_images (product_id, path, thumbnail_path, content_type, size, position, is_primary) SELECT ?, ?, ?, ?, ?, COALESCE(MAX(position), 0) + 1, COUNT(*) = 0 FROM product_images WHERE product_id = ?",
+	}[driverName]
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when create image then return the image",
+			mock: func() {
+				dialecttest.ExpectInsert(mock, driverName, query).
+					WithArgs(1, "products/1/image.png", "products/1/image_thumb.png", "image/png", 1024, 1).
+					WillReturnID(1)
+				mock.ExpectQuery("SELECT id, product_id, path, thumbnail_path, content_type, size, position, is_primary, created_at, updated_at FROM product_images WHERE id = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "path", "thumbnail_path", "content_type", "size", "position", "is_primary", "created_at", "updated_at"}).
+						AddRow(1, 1, "products/1/image.png", "products/1/image_thumb.png", "image/png", 1024, 1, true, time.Time{}, time.Time{}))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error when create image then return error",
+			mock: func() {
+				dialecttest.ExpectInsert(mock, driverName, query).
+					WithArgs(1, "products/1/image.png", "products/1/image_thumb.png", "image/png", 1024, 1).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			_, err := repo.CreateImage(context.Background(), image)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CreateImage() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetImages(t *testing.T) {
+	dialecttest.Run(t, testGetImages)
+}
+
+func testGetImages(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	testCases := []struct {
+		name       string
+		productIDs []int
+		mock       func()
+		want       int
+		wantErr    bool
+	}{
+		{
+			name:       "Given product ids when get images then return images",
+			productIDs: []int{1, 2},
+			mock: func() {
+				mock.ExpectQuery("SELECT id, product_id, path, thumbnail_path, content_type, size, position, is_primary, created_at, updated_at FROM product_images WHERE product_id IN (?, ?) ORDER BY product_id, position, id").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "path", "thumbnail_path", "content_type", "size", "position", "is_primary", "created_at", "updated_at"}).
+						AddRow(1, 1, "products/1/image.png", "products/1/image_thumb.png", "image/png", 1024, 1, true, time.Time{}, time.Time{}).
+						AddRow(2, 2, "products/2/image.png", "products/2/image_thumb.png", "image/png", 1024, 1, true, time.Time{}, time.Time{}))
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:       "Given no product ids when get images then return empty images",
+			productIDs: []int{},
+			mock:       func() {},
+			want:       0,
+			wantErr:    false,
+		},
+		{
+			name:       "Given error when get images then return error",
+			productIDs: []int{1},
+			mock: func() {
+				mock.ExpectQuery("SELECT id, product_id, path, thumbnail_path, content_type, size, position, is_primary, created_at, updated_at FROM product_images WHERE product_id IN (?) ORDER BY product_id, position, id").
+					WithArgs(1).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			images, err := repo.GetImages(context.Background(), tc.productIDs)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetImages() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr && len(images) != tc.want {
+				t.Errorf("GetImages() len = %v, want %v", len(images), tc.want)
+			}
+		})
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	dialecttest.Run(t, testDeleteImage)
+}
+
+func testDeleteImage(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	testCases := []struct {
+		name    string
+		image   *model.ProductImageEntity
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:  "Given primary image when delete image then promote the next image",
+			image: &model.ProductImageEntity{ID: 1, ProductID: 1, IsPrimary: true},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM product_images WHERE id = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE product_images SET is_primary = TRUE, updated_at = CURRENT_TIMESTAMP WHERE id = (SELECT id FROM (SELECT id FROM product_images WHERE product_id = ? ORDER BY position, id LIMIT 1) AS next_image)").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name:  "Given other image when delete image then keep the primary image",
+			image: &model.ProductImageEntity{ID: 2, ProductID: 1},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM product_images WHERE id = ?").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name:  "Given error when delete image then return error",
+			image: &model.ProductImageEntity{ID: 1, ProductID: 1, IsPrimary: true},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM product_images WHERE id = ?").
+					WithArgs(1).
+					WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			err := repo.DeleteImage(context.Background(), tc.image)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DeleteImage() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestReorderImages(t *testing.T) {
+	dialecttest.Run(t, testReorderImages)
+}
+
+func testReorderImages(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given image ids when reorder images then update the positions",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE product_images SET position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND product_id = ?").
+					WithArgs(1, 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE product_images SET position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND product_id = ?").
+					WithArgs(2, 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given image of another product when reorder images then return error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE product_images SET position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND product_id = ?").
+					WithArgs(1, 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewProductRepository(sqlxDB)
+			tc.mock()
+			err := repo.ReorderImages(context.Background(), 1, []int{2, 1})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ReorderImages() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
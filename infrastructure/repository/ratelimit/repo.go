@@ -0,0 +1,14 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=RateLimitRepository.go
+type Repository interface {
+	TokenBucket(ctx context.Context, key string, limit int, window time.Duration) (*model.RateLimitEntity, error)
+	SlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (*model.RateLimitEntity, error)
+}
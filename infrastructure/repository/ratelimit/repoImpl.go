@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+// Both scripts take a request from the quota of KEYS[1] in one round trip, so
+// concurrent requests of a client on several instances are counted once each.
+// ARGV holds the limit, the window and the time in milliseconds, the time of
+// the instance rather than of Redis so the scripts replicate as they are. They
+// answer whether the request is allowed, the requests remaining, and the
+// milliseconds until the quota is full and until the next request is allowed.
+
+// tokenBucketScript keeps the tokens left and when they were counted, the
+// bucket refilling limit tokens per window up to limit.
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local rate = limit / window
+
+local tokens = limit
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+if bucket[1] and bucket[2] then
+	tokens = math.min(limit, tonumber(bucket[1]) + math.max(0, now - tonumber(bucket[2])) * rate)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+local reset = math.ceil((limit - tokens) / rate)
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(now))
+redis.call("PEXPIRE", KEYS[1], reset)
+
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+// slidingWindowScript keeps the time of every request allowed within the last
+// window in a sorted set, ARGV[4] naming the request.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", KEYS[1], window)
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+local newest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+local retry = 0
+if allowed == 0 then
+	retry = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, tonumber(newest[2]) + window - now, retry}
+`)
+
+type rateLimitRepoImpl struct {
+	redcl *redis.Client
+	now   func() time.Time
+}
+
+// NewRateLimitRepository counts the requests of each quota, a policy and a
+// client, in Redis so every instance of the app shares them.
+func NewRateLimitRepository(redcl *redis.Client) Repository {
+	return &rateLimitRepoImpl{redcl: redcl, now: time.Now}
+}
+
+// TokenBucket takes a token from the bucket of key, holding up to limit tokens
+// and refilled with limit tokens per window.
+func (r *rateLimitRepoImpl) TokenBucket(ctx context.Context, key string, limit int, window time.Duration) (*model.RateLimitEntity, error) {
+	return r.take(ctx, tokenBucketScript, key, limit, window)
+}
+
+// SlidingWindow lets a request of key through while less than limit of them
+// were let through within the last window.
+func (r *rateLimitRepoImpl) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (*model.RateLimitEntity, error) {
+	return r.take(ctx, slidingWindowScript, key, limit, window, utils.GenerateUUID())
+}
+
+func (r *rateLimitRepoImpl) take(ctx context.Context, script *redis.Script, key string, limit int, window time.Duration, args ...interface{}) (*model.RateLimitEntity, error) {
+	args = append([]interface{}{limit, window.Milliseconds(), r.now().UnixMilli()}, args...)
+	result, err := script.Run(ctx, r.redcl, []string{constant.RateLimitPrefix + key}, args...).Int64Slice()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "ratelimit", "error", err)
+		return nil, err
+	}
+
+	return &model.RateLimitEntity{
+		Allowed:    result[0] == 1,
+		Limit:      limit,
+		Window:     window,
+		Remaining:  int(result[1]),
+		Reset:      time.Duration(result[2]) * time.Millisecond,
+		RetryAfter: time.Duration(result[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// setup returns the repository with a clock the tests move by hand.
+func setup(t *testing.T) (*miniredis.Miniredis, Repository, *time.Time) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+
+	redcl := redis.NewClient(&redis.Options{
+		Addr: mockRedisServer.Addr(),
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return mockRedisServer, &rateLimitRepoImpl{redcl: redcl, now: func() time.Time { return now }}, &now
+}
+
+func TestTokenBucket(t *testing.T) {
+	mockRedisServer, repo, now := setup(t)
+	defer mockRedisServer.Close()
+
+	testCases := []struct {
+		name    string
+		elapsed time.Duration
+		want    model.RateLimitEntity
+	}{
+		{
+			name: "Given a full bucket when take then allow and leave the rest",
+			want: model.RateLimitEntity{Allowed: true, Remaining: 3, Reset: 15 * time.Second},
+		},
+		{
+			name: "Given tokens left when take then allow",
+			want: model.RateLimitEntity{Allowed: true, Remaining: 2, Reset: 30 * time.Second},
+		},
+		{
+			name:    "Given a partly refilled bucket when take then count the refill",
+			elapsed: 5 * time.Second,
+			want:    model.RateLimitEntity{Allowed: true, Remaining: 1, Reset: 40 * time.Second},
+		},
+		{
+			name: "Given the last token when take then allow and leave none",
+			want: model.RateLimitEntity{Allowed: true, Remaining: 0, Reset: 55 * time.Second},
+		},
+		{
+			name: "Given an empty bucket when take then refuse until the next token",
+			want: model.RateLimitEntity{Allowed: false, Remaining: 0, Reset: 55 * time.Second, RetryAfter: 10 * time.Second},
+		},
+		{
+			name:    "Given the next token when take then allow",
+			elapsed: 10 * time.Second,
+			want:    model.RateLimitEntity{Allowed: true, Remaining: 0, Reset: 60 * time.Second},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			*now = now.Add(tc.elapsed)
+			tc.want.Limit, tc.want.Window = 4, time.Minute
+
+			got, err := repo.TokenBucket(context.Background(), "default:user:1", 4, time.Minute)
+			if err != nil {
+				t.Fatalf("TokenBucket() error = %v", err)
+			}
+
+			if *got != tc.want {
+				t.Errorf("TokenBucket() = %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+
+	if ttl := mockRedisServer.TTL(constant.RateLimitPrefix + "default:user:1"); ttl != time.Minute {
+		t.Errorf("TokenBucket() ttl = %v, want %v", ttl, time.Minute)
+	}
+
+	if got, _ := repo.TokenBucket(context.Background(), "default:user:2", 4, time.Minute); !got.Allowed || got.Remaining != 3 {
+		t.Errorf("TokenBucket() of another key = %+v, want its own full bucket", *got)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	mockRedisServer, repo, now := setup(t)
+	defer mockRedisServer.Close()
+
+	testCases := []struct {
+		name    string
+		elapsed time.Duration
+		want    model.RateLimitEntity
+	}{
+		{
+			name: "Given an empty window when take then allow",
+			want: model.RateLimitEntity{Allowed: true, Remaining: 1, Reset: time.Minute},
+		},
+		{
+			name:    "Given requests left in the window when take then allow",
+			elapsed: 10 * time.Second,
+			want:    model.RateLimitEntity{Allowed: true, Remaining: 0, Reset: time.Minute},
+		},
+		{
+			name:    "Given a full window when take then refuse until the oldest request leaves it",
+			elapsed: 20 * time.Second,
+			want:    model.RateLimitEntity{Allowed: false, Remaining: 0, Reset: 40 * time.Second, RetryAfter: 30 * time.Second},
+		},
+		{
+			name:    "Given the oldest request left the window when take then allow",
+			elapsed: 30 * time.Second,
+			want:    model.RateLimitEntity{Allowed: true, Remaining: 0, Reset: time.Minute},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			*now = now.Add(tc.elapsed)
+			tc.want.Limit, tc.want.Window = 2, time.Minute
+
+			got, err := repo.SlidingWindow(context.Background(), "POST /customer/login:ip:10.0.0.1", 2, time.Minute)
+			if err != nil {
+				t.Fatalf("SlidingWindow() error = %v", err)
+			}
+
+			if *got != tc.want {
+				t.Errorf("SlidingWindow() = %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+
+	if ttl := mockRedisServer.TTL(constant.RateLimitPrefix + "POST /customer/login:ip:10.0.0.1"); ttl != time.Minute {
+		t.Errorf("SlidingWindow() ttl = %v, want %v", ttl, time.Minute)
+	}
+}
+
+func TestRedisDown(t *testing.T) {
+	mockRedisServer, repo, _ := setup(t)
+	mockRedisServer.Close()
+
+	if _, err := repo.TokenBucket(context.Background(), "default:ip:10.0.0.1", 4, time.Minute); err == nil {
+		t.Errorf("TokenBucket() error = nil, want an error without redis")
+	}
+}
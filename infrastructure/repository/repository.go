@@ -3,177 +3,159 @@ package repository
 import (
 	"context"
 	"log"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/apikey"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository/cart"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository/category"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/guestcart"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/health"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/loginattempt"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/loginchallenge"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository/product"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/ratelimit"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/session"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository/transaction"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/wishlist"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 )
 
 type Repositories struct {
-	db          *sqlx.DB
-	RedCl       *redis.Client
-	Category    category.Repository
-	Customer    customer.Repository
-	Product     product.Repository
-	Cart        cart.Repository
-	Transaction transaction.Repository
+	RedCl          *redis.Client
+	UnitOfWork     UnitOfWork
+	Category       category.Repository
+	Customer       customer.Repository
+	Product        product.Repository
+	Cart           cart.Repository
+	GuestCart      guestcart.Repository
+	Health         health.Repository
+	Transaction    transaction.Repository
+	Wishlist       wishlist.Repository
+	Review         review.Repository
+	Session        session.Repository
+	LoginAttempt   loginattempt.Repository
+	LoginChallenge loginchallenge.Repository
+	APIKey         apikey.Repository
+	RateLimit      ratelimit.Repository
 }
 
-func NewRepository(db *sqlx.DB, redcl *redis.Client) *Repositories {
-	return &Repositories{
-		db:          db,
-		RedCl:       redcl,
-		Category:    category.NewCategoryRepository(db),
-		Customer:    customer.NewCustomerRepository(db),
-		Product:     product.NewProductRepository(db),
-		Cart:        cart.NewCartRepository(db),
-		Transaction: transaction.NewTransactionRepository(db),
-	}
+func NewRepository(db *sqlx.DB, redcl *redis.Client, cfg *config.Config) *Repositories {
+	return withDB(&Repositories{
+		RedCl:          redcl,
+		GuestCart:      guestcart.NewGuestCartRepository(redcl, cfg.GuestCart.TTL),
+		Health:         health.NewHealthRepository(db, redcl),
+		Session:        session.NewSessionRepository(redcl),
+		LoginAttempt:   loginattempt.NewLoginAttemptRepository(redcl, cfg.Login.FailureWindow),
+		LoginChallenge: loginchallenge.NewLoginChallengeRepository(redcl, cfg.TwoFactor.ChallengeTTL),
+		RateLimit:      ratelimit.NewRateLimitRepository(redcl),
+	}, db)
+}
+
+// withDB returns a copy of the repositories running their SQL on db, the
+// database or the transaction of a unit of work. The Redis repositories are
+// shared, Redis is not part of the transaction.
+func withDB(repos *Repositories, db dbtx.DB) *Repositories {
+	scoped := *repos
+	scoped.UnitOfWork = &unitOfWorkImpl{db: db, dialect: dialect.For(db), repos: &scoped}
+	scoped.Category = category.NewCategoryRepository(db)
+	scoped.Customer = customer.NewCustomerRepository(db)
+	scoped.Product = product.NewProductRepository(db)
+	scoped.Cart = cart.NewCartRepository(db)
+	scoped.Transaction = transaction.NewTransactionRepository(db)
+	scoped.Wishlist = wishlist.NewWishlistRepository(db)
+	scoped.Review = review.NewReviewRepository(db)
+	scoped.APIKey = apikey.NewAPIKeyRepository(db)
+
+	return &scoped
 }
 
-func DBConnection(sqlLite string) *sqlx.DB {
-	db, err := sqlx.Open("sqlite3", sqlLite)
+// DBConnection only opens the database of the configured driver, its schema
+// is created by the migrations of infrastructure/migration.
+func DBConnection(cfg *config.Config) *sqlx.DB {
+	dsn := cfg.Database.DSN
+	switch cfg.Database.Driver {
+	case config.DatabaseDriverSQLite:
+		dsn = sqliteDSN(cfg.SQLite.Path)
+	case config.DatabaseDriverMySQL:
+		var err error
+		if dsn, err = mysqlDSN(dsn); err != nil {
+			log.Panicln("error connecting to database: ", err.Error())
+		}
+	}
+
+	db, err := sqlx.Open(cfg.Database.Driver, dsn)
 	if err != nil {
 		log.Panicln("error connecting to database: ", err.Error())
 		panic(err)
 	}
 
-	createTableCustomer(db)
-	createTableCategories(db)
-	createTableProduct(db)
-	createTableShoppingCart(db)
-	createTableCartItems(db)
-	createTableTransaction(db)
-	createTableTransactionDetails(db)
-	createIndexTabelCartItems(db)
-	return db
-}
+	// the database server may still be starting next to the app
+	for i := 0; i < 10; i++ {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
 
-func createTableCustomer(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS customers (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name VARCHAR(255) NOT NULL,
-		username VARCHAR(255) UNIQUE NOT NULL,
-		email VARCHAR(255) NOT NULL,
-		password TEXT NOT NULL,
-		phone_number VARCHAR(255) NOT NULL,
-		address TEXT NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-	)`)
-	if err != nil {
-		log.Panicln("error creating table customers: ", err.Error())
+		slog.Warn("failed to connect to database, retrying", "error", err)
+		time.Sleep(2 * time.Second)
 	}
-}
 
-func createTableCategories(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS categories (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name VARCHAR(255) NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-	)`)
 	if err != nil {
-		log.Panicln("error creating table categories: ", err.Error())
+		log.Panicln("Could not connect to database:", err)
 	}
-}
 
-func createTableProduct(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS products (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name VARCHAR(255) NOT NULL,
-		description TEXT NULL,
-		price DECIMAL(10, 2) NOT NULL,
-		stock_quantity INT NOT NULL,
-		category_id INTEGER NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (category_id) REFERENCES categories(id)
-	)`)
-	if err != nil {
-		log.Panicln("error creating table product: ", err.Error())
-	}
+	return db
 }
 
-func createTableShoppingCart(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS shopping_carts (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		customer_id INT NOT NULL,
-		status int NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (customer_id) REFERENCES customers(id)
-	)`)
-	if err != nil {
-		log.Panicln("error creating table shopping_cart: ", err.Error())
+// sqliteDSN waits for the lock of another connection instead of failing right
+// away, and takes the write lock when a transaction begins: a transaction that
+// read first can not upgrade its lock while another one writes, and fails.
+func sqliteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
 	}
-}
 
-func createTableCartItems(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS cart_items (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		shopping_cart_id INT NOT NULL,
-		product_id INTEGER NOT NULL,
-		quantity INTEGER NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
-		FOREIGN KEY (product_id) REFERENCES products(id)
-	)`)
-	if err != nil {
-		log.Panicln("error creating table cart_items: ", err.Error())
-	}
+	return path + separator + "_busy_timeout=5000&_txlock=immediate"
 }
 
-func createTableTransaction(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS transactions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		idempotency_key VARCHAR(255) UNIQUE NOT NULL,
-		shopping_cart_id INTEGER NOT NULL,
-		customer_id INTEGER NOT NULL,
-		status INTEGER NOT NULL,
-		total_amount DECIMAL(10, 2) NOT NULL,
-		payment_method INTEGER NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (shopping_cart_id) REFERENCES shopping_carts(id),
-		FOREIGN KEY (customer_id) REFERENCES customers(id)
-	)`)
+// mysqlDSN sets what the repositories rely on: DATETIME columns scanned into
+// time.Time in UTC, migrations of several statements and the matched instead of
+// the changed rows as the affected rows, an update writing the same values
+// still finds its row.
+func mysqlDSN(dsn string) (string, error) {
+	mysqlCfg, err := mysql.ParseDSN(dsn)
 	if err != nil {
-		log.Panicln("error creating table transactions: ", err.Error())
+		return "", err
 	}
-}
 
-func createTableTransactionDetails(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS transaction_details (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		transaction_id INTEGER NOT NULL,
-		product_id INTEGER NOT NULL,
-		quantity INTEGER NOT NULL,
-		price DECIMAL(10, 2) NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (transaction_id) REFERENCES transactions(id),
-		FOREIGN KEY (product_id) REFERENCES products(id)
-	)`)
-	if err != nil {
-		log.Panicln("error creating table transaction_details: ", err.Error())
+	mysqlCfg.ParseTime = true
+	mysqlCfg.Loc = time.UTC
+	mysqlCfg.MultiStatements = true
+	mysqlCfg.ClientFoundRows = true
+	if mysqlCfg.Params == nil {
+		mysqlCfg.Params = make(map[string]string)
 	}
-}
+	mysqlCfg.Params["time_zone"] = "'+00:00'"
 
-func createIndexTabelCartItems(db *sqlx.DB) {
-	_, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_product_cart ON cart_items (product_id, shopping_cart_id)`)
-	if err != nil {
-		log.Panicln("error creating index cart_items: ", err.Error())
-	}
+	return mysqlCfg.FormatDSN(), nil
 }
 
+// RedisClient waits a while for Redis, then starts without it: the client
+// reconnects once it is back, until then /readyz reports the app degraded.
 func RedisClient(redisHost, redisPort string) *redis.Client {
 	option := &redis.Options{
 		Addr:     redisHost + ":" + redisPort,
@@ -182,20 +164,24 @@ func RedisClient(redisHost, redisPort string) *redis.Client {
 	}
 
 	redcl := redis.NewClient(option)
+	redcl.AddHook(metrics.RedisHook{})
+	redcl.AddHook(tracing.RedisHook{})
 	ctx := context.Background()
 
 	for i := 0; i < 10; i++ {
 		_, err := redcl.Ping(ctx).Result()
 		if err == nil {
-			log.Println("Connected to Redis")
+			slog.Info("connected to redis")
 			break
 		}
 		
-		log.Println("Failed to connect to Redis. Retrying...")
-		time.Sleep(2 * time.Second)
 		if i == 9 {
-			log.Panicln("Could not connect to Redis:", err)
+			slog.Error("could not connect to redis, starting without it", "error", err)
+			break
 		}
+
+		slog.Warn("failed to connect to redis, retrying", "error", err)
+		time.Sleep(2 * time.Second)
 	}
 
 	return redcl
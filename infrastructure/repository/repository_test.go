@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/zakiyalmaya/online-store/config"
+	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/migration"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/category"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/customer"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/product"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/wishlist"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// TestSQLite runs the queries the dialects build against a migrated SQLite
+// database, the sqlmock tests of the repositories only compare them as text.
+func TestSQLite(t *testing.T) {
+	cfg := config.Default()
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "test.db")
+	db := DBConnection(cfg)
+	defer db.Close()
+
+	migrations, err := migration.Load(migration.Migrations, migration.Dir(cfg.Database.Driver))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, err := migration.NewMigrator(db, migrations).Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	customerRepo := customer.NewCustomerRepository(db)
+	alice := &model.CustomerEntity{Name: "Alice", Username: "alice", Email: "alice@example.com", Password: "x", PhoneNumber: "0812", Address: "x"}
+	if err := customerRepo.Create(context.Background(), alice); err != nil || alice.ID == 0 {
+		t.Fatalf("customer Create() = %d, %v", alice.ID, err)
+	}
+
+	if err := customerRepo.Create(context.Background(), &model.CustomerEntity{Name: "Alice", Username: "alice", Email: "other@example.com", Password: "x", PhoneNumber: "0812", Address: "x"}); err != customer.ErrUsernameTaken {
+		t.Errorf("customer Create() error = %v, want %v", err, customer.ErrUsernameTaken)
+	}
+
+	if err := customerRepo.Create(context.Background(), &model.CustomerEntity{Name: "Bob", Username: "bob", Email: "ALICE@example.com", Password: "x", PhoneNumber: "0812", Address: "x"}); err != customer.ErrEmailTaken {
+		t.Errorf("customer Create() error = %v, want %v", err, customer.ErrEmailTaken)
+	}
+
+	if got, err := customerRepo.GetByUsername(context.Background(), "ALICE"); err != nil || got.ID != alice.ID {
+		t.Errorf("customer GetByUsername() = %+v, %v, want the customer regardless of case", got, err)
+	}
+
+	categoryEntity := &model.CategoryEntity{Name: "Books"}
+	if err := category.NewCategoryRepository(db).Create(context.Background(), categoryEntity); err != nil {
+		t.Fatalf("category Create() error = %v", err)
+	}
+
+	productRepo := product.NewProductRepository(db)
+	if err := productRepo.Create(context.Background(), &model.ProductEntity{Name: "Book", Price: decimal.NewFromInt(1000), StockQuantity: 5, CategoryID: 1}); err != nil {
+		t.Fatalf("product Create() error = %v", err)
+	}
+
+	first, err := productRepo.CreateImage(context.Background(), &model.ProductImageEntity{ProductID: 1, Path: "a.png", ThumbnailPath: "a_thumb.png", ContentType: "image/png", Size: 10})
+	if err != nil || first.Position != 1 || !first.IsPrimary {
+		t.Fatalf("product CreateImage() = %+v, %v, want the first image primary", first, err)
+	}
+
+	second, err := productRepo.CreateImage(context.Background(), &model.ProductImageEntity{ProductID: 1, Path: "b.png", ThumbnailPath: "b_thumb.png", ContentType: "image/png", Size: 10})
+	if err != nil || second.Position != 2 || second.IsPrimary {
+		t.Fatalf("product CreateImage() = %+v, %v, want the second image last", second, err)
+	}
+
+	if err := productRepo.DeleteImage(context.Background(), first); err != nil {
+		t.Fatalf("product DeleteImage() error = %v", err)
+	}
+
+	if got, err := productRepo.GetImageByID(context.Background(), second.ID); err != nil || !got.IsPrimary {
+		t.Errorf("product GetImageByID() = %+v, %v, want the next image primary", got, err)
+	}
+
+	cartRepo := cart.NewCartRepository(db)
+	cartEntity, err := cartRepo.Create(context.Background(), &model.CartEntity{CustomerID: alice.ID, Status: cartEnum.CartStatusActive, Items: []*model.CartItemEntity{{ProductID: 1, Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("cart Create() error = %v", err)
+	}
+
+	cartEntity, err = cartRepo.Upsert(context.Background(), cartEntity.ID, []*model.CartItemEntity{{ProductID: 1, Quantity: 2, CartID: cartEntity.ID}})
+	if err != nil || len(cartEntity.Items) != 1 || cartEntity.Items[0].Quantity != 3 {
+		t.Errorf("cart Upsert() = %+v, %v, want the quantities added up", cartEntity, err)
+	}
+
+	// the delete runs on the transaction it begins, a statement on another
+	// connection would wait for the write lock of the transaction
+	deleteRequest := &model.DeleteCartRequest{ID: cartEntity.ID, CartItemID: cartEntity.Items[0].ID, CustomerID: alice.ID, Status: cartEnum.CartStatusActive}
+	if err := cartRepo.Delete(context.Background(), deleteRequest); err != nil {
+		t.Errorf("cart Delete() error = %v", err)
+	}
+
+	if err := cartRepo.Delete(context.Background(), deleteRequest); err != sql.ErrNoRows {
+		t.Errorf("cart Delete() error = %v, want %v for a deleted item", err, sql.ErrNoRows)
+	}
+
+	wishlistRepo := wishlist.NewWishlistRepository(db)
+	for _, notify := range []bool{false, true} {
+		if err := wishlistRepo.Create(context.Background(), &model.WishlistEntity{CustomerID: alice.ID, ProductID: 1, Notify: notify}); err != nil {
+			t.Fatalf("wishlist Create() error = %v", err)
+		}
+
+		// the price drops while the wishlist is not notified
+		if _, err := db.Exec("UPDATE products SET price = 800 WHERE id = 1"); err != nil {
+			t.Fatalf("error updating product price: %v", err)
+		}
+	}
+
+	if got, err := wishlistRepo.GetByProductID(context.Background(), alice.ID, 1, 0); err != nil || !got.Notify || !got.LastPrice.Equal(decimal.NewFromInt(800)) {
+		t.Errorf("wishlist GetByProductID() = %+v, %v, want the second create to update notify and the snapshot", got, err)
+	}
+
+	if err := wishlistRepo.Create(context.Background(), &model.WishlistEntity{CustomerID: alice.ID, ProductID: 1, Notify: true}); err != nil {
+		t.Fatalf("wishlist Create() error = %v", err)
+	}
+
+	if _, err := db.Exec("UPDATE products SET price = 600 WHERE id = 1"); err != nil {
+		t.Fatalf("error updating product price: %v", err)
+	}
+
+	if got, err := wishlistRepo.GetNotifiable(context.Background()); err != nil || len(got) != 1 || !got[0].LastPrice.Equal(decimal.NewFromInt(800)) {
+		t.Errorf("wishlist GetNotifiable() = %+v, %v, want a notified wishlist to keep its snapshot", got, err)
+	}
+
+	if err := productRepo.CreateVariant(context.Background(), &model.ProductVariantEntity{ProductID: 1, SKU: "BOOK-HC", Price: decimal.NewNullDecimal(decimal.NewFromInt(900)), StockQuantity: 2}); err != nil {
+		t.Fatalf("product CreateVariant() error = %v", err)
+	}
+
+	if err := wishlistRepo.Create(context.Background(), &model.WishlistEntity{CustomerID: alice.ID, ProductID: 1, ProductVariantID: 1}); err != nil {
+		t.Fatalf("wishlist Create() error = %v", err)
+	}
+
+	if got, err := wishlistRepo.GetByProductID(context.Background(), alice.ID, 1, 1); err != nil || !got.Price.Equal(decimal.NewFromInt(900)) || !got.LastPrice.Equal(decimal.NewFromInt(900)) || got.StockQuantity != 2 {
+		t.Errorf("wishlist GetByProductID() = %+v, %v, want the price and stock of the variant", got, err)
+	}
+
+	if err := wishlistRepo.Create(context.Background(), &model.WishlistEntity{CustomerID: alice.ID, ProductID: 1, ProductVariantID: 2}); err != sql.ErrNoRows {
+		t.Errorf("wishlist Create() error = %v, want %v for a variant of another product", err, sql.ErrNoRows)
+	}
+
+	if err := wishlistRepo.Delete(context.Background(), alice.ID, 1, 1); err != nil {
+		t.Fatalf("wishlist Delete() error = %v", err)
+	}
+
+	if got, err := wishlistRepo.GetByCustomerID(context.Background(), alice.ID); err != nil || len(got) != 1 || got[0].ProductVariantID != 0 {
+		t.Errorf("wishlist GetByCustomerID() = %+v, %v, want only the product without a variant left", got, err)
+	}
+}
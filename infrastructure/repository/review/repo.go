@@ -0,0 +1,15 @@
+package review
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=ReviewRepository.go
+type Repository interface {
+	Create(ctx context.Context, review *model.ReviewEntity) error
+	GetByParams(ctx context.Context, request *model.GetReviewRequest) ([]*model.ReviewEntity, error)
+	GetByProductAndCustomer(ctx context.Context, productID, customerID int) (*model.ReviewEntity, error)
+	UpdateStatus(ctx context.Context, request *model.UpdateReviewStatusRequest) error
+}
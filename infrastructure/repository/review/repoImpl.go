@@ -0,0 +1,123 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// ErrReviewExists is returned when the customer already reviewed the product,
+// a concurrent review may have been created after the caller checked.
+var ErrReviewExists = errors.New("review already exists")
+
+type reviewRepoImpl struct {
+	db      dbtx.DB
+	dialect dialect.Dialect
+}
+
+func NewReviewRepository(db dbtx.DB) Repository {
+	return &reviewRepoImpl{db: db, dialect: dialect.For(db)}
+}
+
+func (r *reviewRepoImpl) Create(ctx context.Context, review *model.ReviewEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "review", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("review", "Create").ObserveDuration()
+
+	query := "INSERT INTO reviews (product_id, customer_id, rating, title, body, verified_purchase, status) VALUES (:product_id, :customer_id, :rating, :title, :body, :verified_purchase, :status)"
+	_, err := r.db.NamedExecContext(ctx, query, review)
+	if err != nil {
+		// reviews only have the product and customer unique
+		if _, ok := r.dialect.UniqueViolation(err); ok {
+			slog.DebugContext(ctx, "repository error", "repository", "review", "error", err)
+			return ErrReviewExists
+		}
+
+		slog.ErrorContext(ctx, "repository error", "repository", "review", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *reviewRepoImpl) GetByParams(ctx context.Context, request *model.GetReviewRequest) ([]*model.ReviewEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "review", "GetByParams")
+	defer span.End()
+	defer metrics.QueryTimer("review", "GetByParams").ObserveDuration()
+
+	reviews := []*model.ReviewEntity{}
+	params := make([]interface{}, 0)
+	query := "SELECT r.id, r.product_id, r.customer_id, r.rating, r.title, r.body, r.verified_purchase, r.status, r.created_at, r.updated_at, c.name AS customer_name FROM reviews AS r JOIN customers AS c ON r.customer_id = c.id WHERE TRUE"
+
+	if request.ProductID != nil {
+		query += " AND r.product_id = ?"
+		params = append(params, request.ProductID)
+	}
+
+	if request.Status != nil {
+		query += " AND r.status = ?"
+		params = append(params, request.Status)
+	}
+
+	query += " ORDER BY r.id DESC"
+	err := r.db.SelectContext(ctx, &reviews, r.db.Rebind(query), params...)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "review", "error", err)
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *reviewRepoImpl) GetByProductAndCustomer(ctx context.Context, productID, customerID int) (*model.ReviewEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "review", "GetByProductAndCustomer")
+	defer span.End()
+	defer metrics.QueryTimer("review", "GetByProductAndCustomer").ObserveDuration()
+
+	review := &model.ReviewEntity{}
+	query := "SELECT id, product_id, customer_id, rating, title, body, verified_purchase, status, created_at, updated_at FROM reviews WHERE product_id = ? AND customer_id = ?"
+	err := r.db.GetContext(ctx, review, r.db.Rebind(query), productID, customerID)
+	if err != nil {
+		// no review yet is the usual case before posting one
+		if err == sql.ErrNoRows {
+			slog.DebugContext(ctx, "repository error", "repository", "review", "error", err)
+		} else {
+			slog.ErrorContext(ctx, "repository error", "repository", "review", "error", err)
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (r *reviewRepoImpl) UpdateStatus(ctx context.Context, request *model.UpdateReviewStatusRequest) error {
+	ctx, span := tracing.StartQuery(ctx, "review", "UpdateStatus")
+	defer span.End()
+	defer metrics.QueryTimer("review", "UpdateStatus").ObserveDuration()
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind("UPDATE reviews SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"), request.Status, request.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "review", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "review", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "review", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
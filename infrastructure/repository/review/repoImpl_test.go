@@ -0,0 +1,182 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+func TestCreate(t *testing.T) {
+	dialecttest.Run(t, testCreate)
+}
+
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	errInsert := errors.New("error")
+	review := &model.ReviewEntity{
+		ProductID:        1,
+		CustomerID:       1,
+		Rating:           5,
+		Title:            "Great",
+		Body:             "Great product",
+		VerifiedPurchase: true,
+		Status:           reviewEnum.ReviewStatusPending,
+	}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Given valid request when create then return success",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO reviews (product_id, customer_id, rating, title, body, verified_purchase, status) VALUES (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs(1, 1, 5, "Great", "Great product", true, reviewEnum.ReviewStatusPending).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Given product already reviewed by the customer when create then return review exists",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO reviews (product_id, customer_id, rating, title, body, verified_purchase, status) VALUES (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs(1, 1, 5, "Great", "Great product", true, reviewEnum.ReviewStatusPending).
+					WillReturnError(dialecttest.UniqueViolation(driverName, "reviews_product_id_customer_id_key"))
+			},
+			wantErr: ErrReviewExists,
+		},
+		{
+			name: "Given error when create then return error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO reviews (product_id, customer_id, rating, title, body, verified_purchase, status) VALUES (?, ?, ?, ?, ?, ?, ?)").
+					WithArgs(1, 1, 5, "Great", "Great product", true, reviewEnum.ReviewStatusPending).
+					WillReturnError(errInsert)
+			},
+			wantErr: errInsert,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewReviewRepository(sqlxDB)
+			tc.mock()
+			err := repo.Create(context.Background(), review)
+			if err != tc.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetByParams(t *testing.T) {
+	dialecttest.Run(t, testGetByParams)
+}
+
+func testGetByParams(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	productID := 1
+	status := int(reviewEnum.ReviewStatusApproved)
+	request := &model.GetReviewRequest{
+		ProductID: &productID,
+		Status:    &status,
+	}
+
+	query := "SELECT r.id, r.product_id, r.customer_id, r.rating, r.title, r.body, r.verified_purchase, r.status, r.created_at, r.updated_at, c.name AS customer_name FROM reviews AS r JOIN customers AS c ON r.customer_id = c.id WHERE TRUE AND r.product_id = ? AND r.status = ? ORDER BY r.id DESC"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when get by params then return success",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(request.ProductID, request.Status).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "customer_id", "rating", "title", "body", "verified_purchase", "status", "created_at", "updated_at", "customer_name"}).
+						AddRow(1, 1, 1, 5, "Great", "Great product", true, reviewEnum.ReviewStatusApproved, time.Time{}, time.Time{}, "John"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error when get by params then return error",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(request.ProductID, request.Status).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewReviewRepository(sqlxDB)
+			tc.mock()
+			_, err := repo.GetByParams(context.Background(), request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetByParams() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	dialecttest.Run(t, testUpdateStatus)
+}
+
+func testUpdateStatus(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	request := &model.UpdateReviewStatusRequest{
+		ID:     1,
+		Status: reviewEnum.ReviewStatusApproved,
+	}
+
+	query := "UPDATE reviews SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when update status then return success",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(request.Status, request.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given review not found when update status then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(request.Status, request.ID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewReviewRepository(sqlxDB)
+			tc.mock()
+			err := repo.UpdateStatus(context.Background(), request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
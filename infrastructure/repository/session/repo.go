@@ -0,0 +1,17 @@
+package session
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=SessionRepository.go
+type Repository interface {
+	Create(ctx context.Context, session *model.SessionEntity) error
+	Get(ctx context.Context, sessionID string) (*model.SessionEntity, error)
+	GetByCustomerID(ctx context.Context, customerID int) ([]*model.SessionEntity, error)
+	Rotate(ctx context.Context, session *model.SessionEntity, refreshTokenHash string) (bool, error)
+	Delete(ctx context.Context, customerID int, sessionID string) error
+	DeleteByCustomerID(ctx context.Context, customerID int) error
+}
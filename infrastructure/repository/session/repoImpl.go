@@ -0,0 +1,210 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+type sessionRepoImpl struct {
+	redcl *redis.Client
+}
+
+func NewSessionRepository(redcl *redis.Client) Repository {
+	return &sessionRepoImpl{redcl: redcl}
+}
+
+func (s *sessionRepoImpl) Create(ctx context.Context, session *model.SessionEntity) error {
+	if _, err := s.redcl.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return set(ctx, pipe, session)
+	}); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Get returns redis.Nil when the session expired or was revoked.
+func (s *sessionRepoImpl) Get(ctx context.Context, sessionID string) (*model.SessionEntity, error) {
+	session, err := get(ctx, s.redcl, sessionID)
+	if err != nil {
+		if err != redis.Nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetByCustomerID returns the sessions most recently used first and forgets
+// the ones that have expired since they were listed.
+func (s *sessionRepoImpl) GetByCustomerID(ctx context.Context, customerID int) ([]*model.SessionEntity, error) {
+	setKey := customerSessionsKey(customerID)
+
+	ids, err := s.redcl.SMembers(ctx, setKey).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		return nil, err
+	}
+
+	sessions := make([]*model.SessionEntity, 0, len(ids))
+	if len(ids) == 0 {
+		return sessions, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = constant.SessionPrefix + id
+	}
+
+	values, err := s.redcl.MGet(ctx, keys...).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		return nil, err
+	}
+
+	expired := make([]interface{}, 0)
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		session := &model.SessionEntity{}
+		if err := json.Unmarshal([]byte(data), session); err != nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		if err := s.redcl.SRem(ctx, setKey, expired...).Err(); err != nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+// Rotate replaces the session only while it still holds refreshTokenHash, so of
+// two requests refreshing with the same token only one wins. It returns false
+// when the stored hash is different, meaning the token was already rotated.
+func (s *sessionRepoImpl) Rotate(ctx context.Context, session *model.SessionEntity, refreshTokenHash string) (bool, error) {
+	rotated := false
+
+	err := s.redcl.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := get(ctx, tx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.RefreshTokenHash != refreshTokenHash {
+			return nil
+		}
+
+		if _, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return set(ctx, pipe, session)
+		}); err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	}, constant.SessionPrefix+session.ID)
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+
+	if err != nil {
+		if err != redis.Nil {
+			slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		}
+		return false, err
+	}
+
+	return rotated, nil
+}
+
+func (s *sessionRepoImpl) Delete(ctx context.Context, customerID int, sessionID string) error {
+	if _, err := s.redcl.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, constant.SessionPrefix+sessionID)
+		pipe.SRem(ctx, customerSessionsKey(customerID), sessionID)
+		return nil
+	}); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *sessionRepoImpl) DeleteByCustomerID(ctx context.Context, customerID int) error {
+	setKey := customerSessionsKey(customerID)
+
+	ids, err := s.redcl.SMembers(ctx, setKey).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		return err
+	}
+
+	keys := []string{setKey}
+	for _, id := range ids {
+		keys = append(keys, constant.SessionPrefix+id)
+	}
+
+	if err := s.redcl.Del(ctx, keys...).Err(); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "session", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func get(ctx context.Context, cmd redis.Cmdable, sessionID string) (*model.SessionEntity, error) {
+	data, err := cmd.Get(ctx, constant.SessionPrefix+sessionID).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.SessionEntity{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// set stores the session until it expires and keeps the customer's session list
+// alive as long as its newest session.
+func set(ctx context.Context, pipe redis.Pipeliner, session *model.SessionEntity) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	setKey := customerSessionsKey(session.CustomerID)
+	pipe.Set(ctx, constant.SessionPrefix+session.ID, data, time.Until(session.ExpiresAt))
+	pipe.SAdd(ctx, setKey, session.ID)
+	pipe.ExpireAt(ctx, setKey, session.ExpiresAt)
+
+	return nil
+}
+
+func customerSessionsKey(customerID int) string {
+	return constant.CustomerSessionsPrefix + strconv.Itoa(customerID)
+}
@@ -0,0 +1,221 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+const sessionTTL = 24 * time.Hour
+
+func setup(t *testing.T) (*miniredis.Miniredis, Repository) {
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+
+	redcl := redis.NewClient(&redis.Options{
+		Addr: mockRedisServer.Addr(),
+	})
+
+	return mockRedisServer, NewSessionRepository(redcl)
+}
+
+func newSession(id string, lastUsedAt time.Time) *model.SessionEntity {
+	return &model.SessionEntity{
+		ID:               id,
+		CustomerID:       1,
+		Username:         "username",
+		RefreshTokenHash: "hash-" + id,
+		CreatedAt:        lastUsedAt,
+		LastUsedAt:       lastUsedAt,
+		ExpiresAt:        time.Now().Add(sessionTTL),
+	}
+}
+
+func TestCreateAndGet(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	if err := repo.Create(context.Background(), newSession("session", time.Now())); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if ttl := mockRedisServer.TTL(constant.SessionPrefix + "session"); ttl <= 0 || ttl > sessionTTL {
+		t.Errorf("Create() ttl = %v, want up to %v", ttl, sessionTTL)
+	}
+
+	if ok, _ := mockRedisServer.IsMember(constant.CustomerSessionsPrefix+"1", "session"); !ok {
+		t.Errorf("Create() session is not listed for the customer")
+	}
+
+	testCases := []struct {
+		name      string
+		sessionID string
+		wantErr   error
+	}{
+		{
+			name:      "Given existing session when get then return session",
+			sessionID: "session",
+			wantErr:   nil,
+		},
+		{
+			name:      "Given unknown session when get then return redis nil",
+			sessionID: "unknown",
+			wantErr:   redis.Nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			session, err := repo.Get(context.Background(), tc.sessionID)
+			if err != tc.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && session.RefreshTokenHash != "hash-session" {
+				t.Errorf("Get() refresh token hash = %v, want %v", session.RefreshTokenHash, "hash-session")
+			}
+		})
+	}
+}
+
+func TestGetByCustomerID(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	now := time.Now()
+	for _, session := range []*model.SessionEntity{
+		newSession("laptop", now.Add(-time.Hour)),
+		newSession("phone", now),
+		newSession("expired", now),
+	} {
+		if err := repo.Create(context.Background(), session); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	mockRedisServer.Del(constant.SessionPrefix + "expired")
+
+	sessions, err := repo.GetByCustomerID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetByCustomerID() error = %v", err)
+	}
+
+	if len(sessions) != 2 || sessions[0].ID != "phone" || sessions[1].ID != "laptop" {
+		t.Errorf("GetByCustomerID() = %v, want phone then laptop", sessions)
+	}
+
+	if ok, _ := mockRedisServer.IsMember(constant.CustomerSessionsPrefix+"1", "expired"); ok {
+		t.Errorf("GetByCustomerID() expired session is still listed")
+	}
+
+	sessions, err = repo.GetByCustomerID(context.Background(), 2)
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("GetByCustomerID() without sessions = %v, %v, want empty", sessions, err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	if err := repo.Create(context.Background(), newSession("session", time.Now())); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		sessionID   string
+		currentHash string
+		newHash     string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name:        "Given current refresh token when rotate then replace the session",
+			sessionID:   "session",
+			currentHash: "hash-session",
+			newHash:     "rotated",
+			want:        true,
+			wantErr:     false,
+		},
+		{
+			name:        "Given already rotated refresh token when rotate then keep the session",
+			sessionID:   "session",
+			currentHash: "hash-session",
+			newHash:     "reused",
+			want:        false,
+			wantErr:     false,
+		},
+		{
+			name:        "Given unknown session when rotate then return error",
+			sessionID:   "unknown",
+			currentHash: "hash-unknown",
+			newHash:     "rotated",
+			want:        false,
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			session := newSession(tc.sessionID, time.Now())
+			session.RefreshTokenHash = tc.newHash
+
+			got, err := repo.Rotate(context.Background(), session, tc.currentHash)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Rotate() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got != tc.want {
+				t.Errorf("Rotate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	session, err := repo.Get(context.Background(), "session")
+	if err != nil || session.RefreshTokenHash != "rotated" {
+		t.Errorf("Rotate() stored = %v, %v, want rotated", session, err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	mockRedisServer, repo := setup(t)
+	defer mockRedisServer.Close()
+
+	for _, id := range []string{"laptop", "phone", "tablet"} {
+		if err := repo.Create(context.Background(), newSession(id, time.Now())); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if err := repo.Delete(context.Background(), 1, "laptop"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := repo.Get(context.Background(), "laptop"); err != redis.Nil {
+		t.Errorf("Get() after delete error = %v, want redis nil", err)
+	}
+
+	sessions, err := repo.GetByCustomerID(context.Background(), 1)
+	if err != nil || len(sessions) != 2 {
+		t.Errorf("GetByCustomerID() after delete = %v, %v, want 2 sessions", sessions, err)
+	}
+
+	if err := repo.DeleteByCustomerID(context.Background(), 1); err != nil {
+		t.Fatalf("DeleteByCustomerID() error = %v", err)
+	}
+
+	for _, key := range []string{constant.CustomerSessionsPrefix + "1", constant.SessionPrefix + "phone", constant.SessionPrefix + "tablet"} {
+		if mockRedisServer.Exists(key) {
+			t.Errorf("DeleteByCustomerID() key %s still exists", key)
+		}
+	}
+}
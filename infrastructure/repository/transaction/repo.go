@@ -1,9 +1,15 @@
 package transaction
 
-import "github.com/zakiyalmaya/online-store/model"
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
 
 //go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=TransactionRepository.go
 type Repository interface {
-	Create(transaction *model.TransactionEntity) (*model.TransactionEntity, error)
-	GetByID(id int) (*model.TransactionEntity, error)
+	Create(ctx context.Context, transaction *model.TransactionEntity) (*model.TransactionEntity, error)
+	GetByID(ctx context.Context, id int) (*model.TransactionEntity, error)
+	HasPurchased(ctx context.Context, customerID, productID int) (bool, error)
+	UpdateStatus(ctx context.Context, request *model.UpdateTransactionStatusRequest) error
 }
\ No newline at end of file
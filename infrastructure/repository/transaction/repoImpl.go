@@ -1,83 +1,79 @@
 package transaction
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"log/slog"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/zakiyalmaya/online-store/model"
-	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	transactionEnum "github.com/zakiyalmaya/online-store/constant/transaction"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 )
 
 type transactonRepoImpl struct {
-	db *sqlx.DB
+	db      dbtx.DB
+	dialect dialect.Dialect
 }
 
-func NewTransactionRepository(db *sqlx.DB) Repository {
-	return &transactonRepoImpl{db: db}
+func NewTransactionRepository(db dbtx.DB) Repository {
+	return &transactonRepoImpl{db: db, dialect: dialect.For(db)}
 }
 
-func (t *transactonRepoImpl) Create(transaction *model.TransactionEntity) (*model.TransactionEntity, error) {
-	tx, err := t.db.Beginx()
+func (t *transactonRepoImpl) Create(ctx context.Context, transaction *model.TransactionEntity) (*model.TransactionEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "transaction", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("transaction", "Create").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, t.db)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
 		return nil, err
 	}
 	
-	res, err := tx.NamedExec(`INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (:idempotency_key, :customer_id, :shopping_cart_id, :status, :total_amount, :payment_method)`, transaction)
-	if err != nil {
-		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
-		return nil, err
-	}
-
-	transactionID, err := res.LastInsertId()
+	transactionID, err := t.dialect.NamedInsert(ctx, tx, `INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (:idempotency_key, :customer_id, :shopping_cart_id, :status, :total_amount, :payment_method)`, transaction)
 	if err != nil {
 		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
 		return nil, err
 	}
 
 	for _, detail := range transaction.Details {
 		detail.TransactionID = int(transactionID)
-		_, err = tx.NamedExec(`INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (:transaction_id, :product_id, :quantity, :price)`, detail)
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO transaction_details (transaction_id, product_id, product_variant_id, quantity, price) VALUES (:transaction_id, :product_id, :product_variant_id, :quantity, :price)`, detail)
 		if err != nil {
 			tx.Rollback()
-			log.Println("errorRepository: ", err.Error())
+			slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
 			return nil, err
 		}
 	}
 
-	_, err = tx.Exec(`UPDATE shopping_carts SET status = ? WHERE id = ?`, cartEnum.CartStatusPending, transaction.CartID)
-	if err != nil {
-		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
-		return nil, err
-	}
-
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
 		return nil, err
 	}
 
-	return t.getByID(int(transactionID))
+	return t.getByID(ctx, int(transactionID))
 }
 
-func (t *transactonRepoImpl) getByID(id int) (*model.TransactionEntity, error) {
+func (t *transactonRepoImpl) getByID(ctx context.Context, id int) (*model.TransactionEntity, error) {
 	transaction := &model.TransactionEntity{}
 	query := "SELECT id, idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method, created_at, updated_at FROM transactions WHERE id = ?"
-	err := t.db.Get(transaction, query, id)
+	err := t.db.GetContext(ctx, transaction, t.db.Rebind(query), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
 		return nil, err
 	}
 
 	details := []*model.TransactionDetailEntity{}
-	query = "SELECT td.id, td.transaction_id, td.product_id, p.name AS product_name, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id WHERE td.transaction_id = ? ORDER BY td.id"
-	err = t.db.Select(&details, query, id)
+	query = "SELECT td.id, td.transaction_id, td.product_id, td.product_variant_id, p.name AS product_name, COALESCE(v.sku, '') AS sku, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id LEFT JOIN product_variants AS v ON td.product_variant_id = v.id WHERE td.transaction_id = ? ORDER BY td.id"
+	err = t.db.SelectContext(ctx, &details, t.db.Rebind(query), id)
 	if err != nil {
-		log.Println("errorRepository: ", err.Error())
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
 		return nil, err
 	}
 
@@ -85,6 +81,54 @@ func (t *transactonRepoImpl) getByID(id int) (*model.TransactionEntity, error) {
 	return transaction, nil
 }
 
-func (t *transactonRepoImpl) GetByID(id int) (*model.TransactionEntity, error) {
-	return t.getByID(id)
+func (t *transactonRepoImpl) GetByID(ctx context.Context, id int) (*model.TransactionEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "transaction", "GetByID")
+	defer span.End()
+	defer metrics.QueryTimer("transaction", "GetByID").ObserveDuration()
+
+	return t.getByID(ctx, id)
+}
+
+func (t *transactonRepoImpl) HasPurchased(ctx context.Context, customerID, productID int) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "transaction", "HasPurchased")
+	defer span.End()
+	defer metrics.QueryTimer("transaction", "HasPurchased").ObserveDuration()
+
+	var purchased bool
+	query := "SELECT EXISTS (SELECT 1 FROM transactions AS t JOIN transaction_details AS td ON td.transaction_id = t.id WHERE t.customer_id = ? AND t.status = ? AND td.product_id = ?)"
+	err := t.db.GetContext(ctx, &purchased, t.db.Rebind(query), customerID, transactionEnum.TransactionStatusSuccess, productID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
+		return false, err
+	}
+
+	return purchased, nil
+}
+
+// UpdateStatus only moves a transaction out of IN PROGRESS, it returns
+// sql.ErrNoRows when the transaction does not exist or was already settled.
+func (t *transactonRepoImpl) UpdateStatus(ctx context.Context, request *model.UpdateTransactionStatusRequest) error {
+	ctx, span := tracing.StartQuery(ctx, "transaction", "UpdateStatus")
+	defer span.End()
+	defer metrics.QueryTimer("transaction", "UpdateStatus").ObserveDuration()
+
+	query := "UPDATE transactions SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?"
+	res, err := t.db.ExecContext(ctx, t.db.Rebind(query), request.Status, request.ID, transactionEnum.TransactionStatusInprogress)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "transaction", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "transaction", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
 }
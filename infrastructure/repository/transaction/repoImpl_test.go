@@ -1,27 +1,24 @@
 package transaction
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/jmoiron/sqlx"
 	"github.com/shopspring/decimal"
 	transactionEnum "github.com/zakiyalmaya/online-store/constant/transaction"
-	cartEnum "github.com/zakiyalmaya/online-store/constant/cart"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
 func TestCreate(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testCreate)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	request := &model.TransactionEntity{
 		TotalAmount:     decimal.NewFromFloat(10000),
@@ -48,16 +45,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (?, ?, ?, ?)").
-					WithArgs(1, request.Details[0].ProductID, request.Details[0].Quantity, request.Details[0].Price).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
-				mock.ExpectExec("UPDATE shopping_carts SET status = ? WHERE id = ?").
-					WithArgs(cartEnum.CartStatusPending, request.CartID).
+				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, product_variant_id, quantity, price) VALUES (?, ?, ?, ?, ?)").
+					WithArgs(1, request.Details[0].ProductID, request.Details[0].ProductVariantID, request.Details[0].Quantity, request.Details[0].Price).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -67,10 +60,10 @@ func TestCreate(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "customer_id", "shopping_cart_id", "status", "total_amount", "payment_method", "created_at", "updated_at"}).
 						AddRow(1, request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, p.name AS product_name, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id WHERE td.transaction_id = ? ORDER BY td.id").
+				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, td.product_variant_id, p.name AS product_name, COALESCE(v.sku, '') AS sku, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id LEFT JOIN product_variants AS v ON td.product_variant_id = v.id WHERE td.transaction_id = ? ORDER BY td.id").
 					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "product_id", "product_name", "quantity", "price", "created_at", "updated_at"}).
-						AddRow(1, 1, request.Details[0].ProductID, request.Details[0].ProductName, request.Details[0].Quantity, request.Details[0].Price, time.Time{}, time.Time{}))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "product_id", "product_variant_id", "product_name", "sku", "quantity", "price", "created_at", "updated_at"}).
+						AddRow(1, 1, request.Details[0].ProductID, 0, request.Details[0].ProductName, "", request.Details[0].Quantity, request.Details[0].Price, time.Time{}, time.Time{}))
 			},
 			wantErr: false,
 		},
@@ -88,7 +81,7 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
 					WillReturnError(errors.New("error insert transaction"))
 
@@ -102,56 +95,30 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (?, ?, ?, ?)").
-					WithArgs(1, request.Details[0].ProductID, request.Details[0].Quantity, request.Details[0].Price).
+				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, product_variant_id, quantity, price) VALUES (?, ?, ?, ?, ?)").
+					WithArgs(1, request.Details[0].ProductID, request.Details[0].ProductVariantID, request.Details[0].Quantity, request.Details[0].Price).
 					WillReturnError(errors.New("error insert transaction details"))
 
 				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
-		{
-			name:    "Given error update shopping cart when create then return error",
-			request: request,
-			mock: func() {
-				mock.ExpectBegin()
-
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
-					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
-				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (?, ?, ?, ?)").
-					WithArgs(1, request.Details[0].ProductID, request.Details[0].Quantity, request.Details[0].Price).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
-				mock.ExpectExec("UPDATE shopping_carts SET status = ? WHERE id = ?").
-					WithArgs(cartEnum.CartStatusPending, request.CartID).
-					WillReturnError(errors.New("error update shopping cart"))
-
-				mock.ExpectRollback()
-			},
-			wantErr: true,
-		},
 		{
 			name:    "Given error committing transaction when create then return error",
 			request: request,
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
-				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (?, ?, ?, ?)").
-					WithArgs(1, request.Details[0].ProductID, request.Details[0].Quantity, request.Details[0].Price).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("UPDATE shopping_carts SET status = ? WHERE id = ?").
-					WithArgs(cartEnum.CartStatusPending, request.CartID).
+				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, product_variant_id, quantity, price) VALUES (?, ?, ?, ?, ?)").
+					WithArgs(1, request.Details[0].ProductID, request.Details[0].ProductVariantID, request.Details[0].Quantity, request.Details[0].Price).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit().WillReturnError(errors.New("error"))
@@ -164,16 +131,12 @@ func TestCreate(t *testing.T) {
 			mock: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
-				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (?, ?, ?, ?)").
-					WithArgs(1, request.Details[0].ProductID, request.Details[0].Quantity, request.Details[0].Price).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("UPDATE shopping_carts SET status = ? WHERE id = ?").
-					WithArgs(cartEnum.CartStatusPending, request.CartID).
+				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, product_variant_id, quantity, price) VALUES (?, ?, ?, ?, ?)").
+					WithArgs(1, request.Details[0].ProductID, request.Details[0].ProductVariantID, request.Details[0].Quantity, request.Details[0].Price).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -190,16 +153,12 @@ func TestCreate(t *testing.T) {
 			mock:    func() {
 				mock.ExpectBegin()
 
-				mock.ExpectExec("INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
+				dialecttest.ExpectInsert(mock, driverName, "INSERT INTO transactions (idempotency_key, customer_id, shopping_cart_id, status, total_amount, payment_method) VALUES (?, ?, ?, ?, ?, ?)").
 					WithArgs(request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
-				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, quantity, price) VALUES (?, ?, ?, ?)").
-					WithArgs(1, request.Details[0].ProductID, request.Details[0].Quantity, request.Details[0].Price).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnID(1)
 
-				mock.ExpectExec("UPDATE shopping_carts SET status = ? WHERE id = ?").
-					WithArgs(cartEnum.CartStatusPending, request.CartID).
+				mock.ExpectExec("INSERT INTO transaction_details (transaction_id, product_id, product_variant_id, quantity, price) VALUES (?, ?, ?, ?, ?)").
+					WithArgs(1, request.Details[0].ProductID, request.Details[0].ProductVariantID, request.Details[0].Quantity, request.Details[0].Price).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -209,7 +168,7 @@ func TestCreate(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "customer_id", "shopping_cart_id", "status", "total_amount", "payment_method", "created_at", "updated_at"}).
 						AddRow(1, request.IdempotencyKey, request.CustomerID, request.CartID, request.Status, request.TotalAmount, request.PaymentMethod, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, p.name AS product_name, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id WHERE td.transaction_id = ? ORDER BY td.id").
+				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, td.product_variant_id, p.name AS product_name, COALESCE(v.sku, '') AS sku, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id LEFT JOIN product_variants AS v ON td.product_variant_id = v.id WHERE td.transaction_id = ? ORDER BY td.id").
 					WithArgs(1).
 					WillReturnError(errors.New("error"))
 			},
@@ -221,7 +180,7 @@ func TestCreate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
 			repo := NewTransactionRepository(sqlxDB)
-			_, err := repo.Create(tc.request)
+			_, err := repo.Create(context.Background(), tc.request)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -231,14 +190,11 @@ func TestCreate(t *testing.T) {
 }
 
 func TestGetByID(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
+	dialecttest.Run(t, testGetByID)
+}
 
-	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	defer sqlxDB.Close()
+func testGetByID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
 
 	testCases := []struct {
 		name    string
@@ -253,7 +209,7 @@ func TestGetByID(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "customer_id", "shopping_cart_id", "status", "total_amount", "payment_method", "created_at", "updated_at"}).
 						AddRow(1, "idempotency_key", 1, 1, 1, "1000", 1, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, p.name AS product_name, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id WHERE td.transaction_id = ? ORDER BY td.id").
+				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, td.product_variant_id, p.name AS product_name, COALESCE(v.sku, '') AS sku, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id LEFT JOIN product_variants AS v ON td.product_variant_id = v.id WHERE td.transaction_id = ? ORDER BY td.id").
 					WithArgs(1).
 					WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "product_id", "product_name", "quantity", "price", "created_at", "updated_at"}).
 						AddRow(1, 1, 1, "product_name", 1, "1000", time.Time{}, time.Time{}))
@@ -268,7 +224,7 @@ func TestGetByID(t *testing.T) {
 					WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "customer_id", "shopping_cart_id", "status", "total_amount", "payment_method", "created_at", "updated_at"}).
 						AddRow(1, "idempotency_key", 1, 1, 1, "1000", 1, time.Time{}, time.Time{}))
 
-				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, p.name AS product_name, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id WHERE td.transaction_id = ? ORDER BY td.id").
+				mock.ExpectQuery("SELECT td.id, td.transaction_id, td.product_id, td.product_variant_id, p.name AS product_name, COALESCE(v.sku, '') AS sku, td.quantity, td.price, td.created_at, td.updated_at FROM transaction_details AS td JOIN products AS p ON td.product_id = p.id LEFT JOIN product_variants AS v ON td.product_variant_id = v.id WHERE td.transaction_id = ? ORDER BY td.id").
 					WithArgs(1).
 					WillReturnError(errors.New("error"))
 			},
@@ -289,7 +245,7 @@ func TestGetByID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mock()
 			repo := NewTransactionRepository(sqlxDB)
-			_, err := repo.GetByID(1)
+			_, err := repo.GetByID(context.Background(), 1)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("GetByID() error = %v, wantErr %v", err, tc.wantErr)
 				return
@@ -297,3 +253,117 @@ func TestGetByID(t *testing.T) {
 		})
 	}
 }
+
+func TestHasPurchased(t *testing.T) {
+	dialecttest.Run(t, testHasPurchased)
+}
+
+func testHasPurchased(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "SELECT EXISTS (SELECT 1 FROM transactions AS t JOIN transaction_details AS td ON td.transaction_id = t.id WHERE t.customer_id = ? AND t.status = ? AND td.product_id = ?)"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Given success transaction with the product when has purchased then return true",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1, transactionEnum.TransactionStatusSuccess, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Given error when has purchased then return error",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1, transactionEnum.TransactionStatusSuccess, 1).
+					WillReturnError(errors.New("error"))
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewTransactionRepository(sqlxDB)
+			tc.mock()
+			got, err := repo.HasPurchased(context.Background(), 1, 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("HasPurchased() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got != tc.want {
+				t.Errorf("HasPurchased() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	dialecttest.Run(t, testUpdateStatus)
+}
+
+func testUpdateStatus(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	request := &model.UpdateTransactionStatusRequest{
+		ID:     1,
+		Status: transactionEnum.TransactionStatusSuccess,
+	}
+
+	query := "UPDATE transactions SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given transaction in progress when update status then return success",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(request.Status, request.ID, transactionEnum.TransactionStatusInprogress).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given transaction not found or settled when update status then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(request.Status, request.ID, transactionEnum.TransactionStatusInprogress).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when update status then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(request.Status, request.ID, transactionEnum.TransactionStatusInprogress).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewTransactionRepository(sqlxDB)
+			tc.mock()
+			err := repo.UpdateStatus(context.Background(), request)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
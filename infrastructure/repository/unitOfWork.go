@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+)
+
+// a transaction failing because of concurrent ones is run again up to
+// maxRetries times, waiting a little longer after each attempt.
+const (
+	maxRetries = 3
+	retryDelay = 50 * time.Millisecond
+)
+
+// UnitOfWork runs the calls of several repositories in one database transaction.
+//
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=unitOfWork.go -destination=UnitOfWork.go
+type UnitOfWork interface {
+	// Do runs fn with the repositories scoped to a new transaction, committed
+	// when fn returns nil and rolled back when it returns an error or panics.
+	// fn runs again when the database reports the transaction collided with
+	// another one, so it must not have effects outside of the database. Do of
+	// the repositories given to fn runs in a savepoint of their transaction.
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+type unitOfWorkImpl struct {
+	db      dbtx.DB
+	dialect dialect.Dialect
+	repos   *Repositories
+}
+
+func (u *unitOfWorkImpl) Do(ctx context.Context, fn func(repos *Repositories) error) error {
+	// only the outermost transaction can be run again, a savepoint leaves it
+	// to the transaction it belongs to
+	_, outermost := u.db.(*sqlx.DB)
+
+	for attempt := 1; ; attempt++ {
+		err := u.do(ctx, fn)
+		if err == nil || !outermost || attempt > maxRetries || !u.dialect.Retryable(err) {
+			return err
+		}
+
+		slog.WarnContext(ctx, "retrying transaction", "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * retryDelay):
+		}
+	}
+}
+
+func (u *unitOfWorkImpl) do(ctx context.Context, fn func(repos *Repositories) error) error {
+	tx, err := dbtx.Begin(ctx, u.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "repository", "error", err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(withDB(u.repos, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "repository", "error", err)
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattn/go-sqlite3"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/migration"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	cfg := config.Default()
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "test.db")
+	db := DBConnection(cfg)
+	defer db.Close()
+
+	migrations, err := migration.Load(migration.Migrations, migration.Dir(cfg.Database.Driver))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, err := migration.NewMigrator(db, migrations).Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	repos := NewRepository(db, nil, cfg)
+	ctx := context.Background()
+	categories := func() int {
+		all, err := repos.Category.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("category GetAll() error = %v", err)
+		}
+		return len(all)
+	}
+
+	err = repos.UnitOfWork.Do(ctx, func(repos *Repositories) error {
+		return repos.Category.Create(ctx, &model.CategoryEntity{Name: "Books"})
+	})
+	if err != nil || categories() != 1 {
+		t.Fatalf("Do() error = %v, want the category committed", err)
+	}
+
+	err = repos.UnitOfWork.Do(ctx, func(repos *Repositories) error {
+		if err := repos.Category.Create(ctx, &model.CategoryEntity{Name: "Games"}); err != nil {
+			return err
+		}
+		return errors.New("error")
+	})
+	if err == nil || categories() != 1 {
+		t.Errorf("Do() error = %v, want the category rolled back", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Do() did not panic")
+			}
+		}()
+
+		repos.UnitOfWork.Do(ctx, func(repos *Repositories) error {
+			repos.Category.Create(ctx, &model.CategoryEntity{Name: "Games"})
+			panic("panic")
+		})
+	}()
+	if categories() != 1 {
+		t.Errorf("Do() did not roll back the category of a panic")
+	}
+
+	err = repos.UnitOfWork.Do(ctx, func(repos *Repositories) error {
+		if err := repos.Category.Create(ctx, &model.CategoryEntity{Name: "Games"}); err != nil {
+			return err
+		}
+
+		// the failed inner unit only rolls back its own writes
+		repos.UnitOfWork.Do(ctx, func(repos *Repositories) error {
+			repos.Category.Create(ctx, &model.CategoryEntity{Name: "Toys"})
+			return errors.New("error")
+		})
+		return nil
+	})
+	if err != nil || categories() != 2 {
+		t.Errorf("Do() error = %v, categories = %d, want only the outer category committed", err, categories())
+	}
+}
+
+func TestUnitOfWorkRetry(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	testCases := []struct {
+		name     string
+		mock     func(mock sqlmock.Sqlmock)
+		attempts int
+		wantErr  bool
+	}{
+		{
+			name: "Given busy database when do then run the transaction again",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO categories (name) VALUES (?)").WillReturnError(busy)
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO categories (name) VALUES (?)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			attempts: 2,
+			wantErr:  false,
+		},
+		{
+			name: "Given database busy every time when do then return error",
+			mock: func(mock sqlmock.Sqlmock) {
+				for i := 0; i <= maxRetries; i++ {
+					mock.ExpectBegin()
+					mock.ExpectExec("INSERT INTO categories (name) VALUES (?)").WillReturnError(busy)
+					mock.ExpectRollback()
+				}
+			},
+			attempts: maxRetries + 1,
+			wantErr:  true,
+		},
+		{
+			name: "Given other error when do then return error without retrying",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO categories (name) VALUES (?)").WillReturnError(errors.New("error"))
+				mock.ExpectRollback()
+			},
+			attempts: 1,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlxDB, mock := dialecttest.New(t, dialect.SQLite)
+			tc.mock(mock)
+
+			attempts := 0
+			err := withDB(&Repositories{}, sqlxDB).UnitOfWork.Do(context.Background(), func(repos *Repositories) error {
+				attempts++
+				return repos.Category.Create(context.Background(), &model.CategoryEntity{Name: "Books"})
+			})
+			if (err != nil) != tc.wantErr || attempts != tc.attempts {
+				t.Errorf("Do() error = %v, attempts = %d, wantErr %v, attempts %d", err, attempts, tc.wantErr, tc.attempts)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
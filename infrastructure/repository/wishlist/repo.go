@@ -0,0 +1,18 @@
+package wishlist
+
+import (
+	"context"
+
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=repo.go -destination=WishlistRepository.go
+type Repository interface {
+	Create(ctx context.Context, wishlist *model.WishlistEntity) error
+	GetByCustomerID(ctx context.Context, customerID int) ([]*model.WishlistEntity, error)
+	GetByProductID(ctx context.Context, customerID, productID, productVariantID int) (*model.WishlistEntity, error)
+	Delete(ctx context.Context, customerID, productID, productVariantID int) error
+	GetNotifiable(ctx context.Context) ([]*model.WishlistEntity, error)
+	UpdateSnapshot(ctx context.Context, wishlist *model.WishlistEntity, notifications []*model.WishlistNotificationEntity) error
+	GetNotifications(ctx context.Context, customerID int) ([]*model.WishlistNotificationEntity, error)
+}
@@ -0,0 +1,193 @@
+package wishlist
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dbtx"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// selectWishlists reads the price and stock of the variant when the wishlist has one.
+const selectWishlists = "SELECT w.id, w.customer_id, w.product_id, w.product_variant_id, w.notify, w.last_price, w.last_stock, w.created_at, w.updated_at, p.name AS product_name, COALESCE(v.price, p.price) AS price, COALESCE(v.stock_quantity, p.stock_quantity) AS stock_quantity FROM wishlists AS w JOIN products AS p ON w.product_id = p.id LEFT JOIN product_variants AS v ON w.product_variant_id = v.id"
+
+type wishlistRepoImpl struct {
+	db      dbtx.DB
+	dialect dialect.Dialect
+}
+
+func NewWishlistRepository(db dbtx.DB) Repository {
+	return &wishlistRepoImpl{db: db, dialect: dialect.For(db)}
+}
+
+func (w *wishlistRepoImpl) Create(ctx context.Context, wishlist *model.WishlistEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "Create")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "Create").ObserveDuration()
+
+	// the current product price and stock, or the ones of the variant, are
+	// stored as the first snapshot used to detect price drops and restocks.
+	// A wishlist that was not notified takes the current ones too, so turning
+	// notify back on does not report the changes made while it was off. The
+	// snapshot is set before notify, MySQL reads the columns already updated.
+	// Nothing is inserted when the variant is not one of the product.
+	query := `
+		INSERT INTO wishlists (customer_id, product_id, product_variant_id, notify, last_price, last_stock)
+		SELECT ` + w.dialect.TypedParam("INTEGER") + `, p.id, COALESCE(v.id, 0), ` + w.dialect.TypedParam("BOOLEAN") + `, COALESCE(v.price, p.price), COALESCE(v.stock_quantity, p.stock_quantity)
+		FROM products AS p LEFT JOIN product_variants AS v ON v.product_id = p.id AND v.id = ?
+		WHERE p.id = ? AND (v.id IS NOT NULL OR ` + w.dialect.TypedParam("INTEGER") + ` = 0)
+		` + w.dialect.OnConflict([]string{"customer_id", "product_id", "product_variant_id"},
+		"last_price = CASE WHEN wishlists.notify THEN wishlists.last_price ELSE "+w.dialect.Excluded("last_price")+" END",
+		"last_stock = CASE WHEN wishlists.notify THEN wishlists.last_stock ELSE "+w.dialect.Excluded("last_stock")+" END",
+		"notify = "+w.dialect.Excluded("notify"), "updated_at = CURRENT_TIMESTAMP")
+	res, err := w.db.ExecContext(ctx, w.db.Rebind(query), wishlist.CustomerID, wishlist.Notify, wishlist.ProductVariantID, wishlist.ProductID, wishlist.ProductVariantID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		slog.DebugContext(ctx, "repository error", "repository", "wishlist", "error", sql.ErrNoRows)
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (w *wishlistRepoImpl) GetByCustomerID(ctx context.Context, customerID int) ([]*model.WishlistEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "GetByCustomerID")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "GetByCustomerID").ObserveDuration()
+
+	wishlists := []*model.WishlistEntity{}
+	query := selectWishlists + " WHERE w.customer_id = ? ORDER BY w.id DESC"
+	err := w.db.SelectContext(ctx, &wishlists, w.db.Rebind(query), customerID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return nil, err
+	}
+
+	return wishlists, nil
+}
+
+func (w *wishlistRepoImpl) GetByProductID(ctx context.Context, customerID, productID, productVariantID int) (*model.WishlistEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "GetByProductID")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "GetByProductID").ObserveDuration()
+
+	wishlist := &model.WishlistEntity{}
+	query := selectWishlists + " WHERE w.customer_id = ? AND w.product_id = ? AND w.product_variant_id = ?"
+	err := w.db.GetContext(ctx, wishlist, w.db.Rebind(query), customerID, productID, productVariantID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+func (w *wishlistRepoImpl) Delete(ctx context.Context, customerID, productID, productVariantID int) error {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "Delete")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "Delete").ObserveDuration()
+
+	res, err := w.db.ExecContext(ctx, w.db.Rebind("DELETE FROM wishlists WHERE customer_id = ? AND product_id = ? AND product_variant_id = ?"), customerID, productID, productVariantID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	if affected == 0 {
+		err := fmt.Errorf("no wishlist found with product_id: %d", productID)
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (w *wishlistRepoImpl) GetNotifiable(ctx context.Context) ([]*model.WishlistEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "GetNotifiable")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "GetNotifiable").ObserveDuration()
+
+	wishlists := []*model.WishlistEntity{}
+	query := selectWishlists + " WHERE w.notify = TRUE AND (COALESCE(v.price, p.price) <> w.last_price OR COALESCE(v.stock_quantity, p.stock_quantity) <> w.last_stock) ORDER BY w.id"
+	err := w.db.SelectContext(ctx, &wishlists, w.db.Rebind(query))
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return nil, err
+	}
+
+	return wishlists, nil
+}
+
+func (w *wishlistRepoImpl) UpdateSnapshot(ctx context.Context, wishlist *model.WishlistEntity, notifications []*model.WishlistNotificationEntity) error {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "UpdateSnapshot")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "UpdateSnapshot").ObserveDuration()
+
+	tx, err := dbtx.Begin(ctx, w.db)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(`UPDATE wishlists SET last_price = ?, last_stock = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`), wishlist.Price, wishlist.StockQuantity, wishlist.ID)
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	for _, notification := range notifications {
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO wishlist_notifications (customer_id, product_id, type, price) VALUES (:customer_id, :product_id, :type, :price)`, notification)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (w *wishlistRepoImpl) GetNotifications(ctx context.Context, customerID int) ([]*model.WishlistNotificationEntity, error) {
+	ctx, span := tracing.StartQuery(ctx, "wishlist", "GetNotifications")
+	defer span.End()
+	defer metrics.QueryTimer("wishlist", "GetNotifications").ObserveDuration()
+
+	notifications := []*model.WishlistNotificationEntity{}
+	query := "SELECT n.id, n.customer_id, n.product_id, n.type, n.price, n.created_at, p.name AS product_name FROM wishlist_notifications AS n JOIN products AS p ON n.product_id = p.id WHERE n.customer_id = ? ORDER BY n.id DESC"
+	err := w.db.SelectContext(ctx, &notifications, w.db.Rebind(query), customerID)
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error", "repository", "wishlist", "error", err)
+		return nil, err
+	}
+
+	return notifications, nil
+}
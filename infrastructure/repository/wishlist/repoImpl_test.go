@@ -0,0 +1,265 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	wishlistEnum "github.com/zakiyalmaya/online-store/constant/wishlist"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect"
+	"github.com/zakiyalmaya/online-store/infrastructure/repository/dialect/dialecttest"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+func TestCreate(t *testing.T) {
+	dialecttest.Run(t, testCreate)
+}
+
+func testCreate(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := map[string]string{
+		dialect.SQLite:   "INSERT INTO wishlists (customer_id, product_id, product_variant_id, notify, last_price, last_stock) SELECT ?, p.id, COALESCE(v.id, 0), ?, COALESCE(v.price, p.price), COALESCE(v.stock_quantity, p.stock_quantity) FROM products AS p LEFT JOIN product_variants AS v ON v.product_id = p.id AND v.id = ? WHERE p.id = ? AND (v.id IS NOT NULL OR ? = 0) ON CONFLICT(customer_id, product_id, product_variant_id) DO UPDATE SET last_price = CASE WHEN wishlists.notify THEN wishlists.last_price ELSE excluded.last_price END, last_stock = CASE WHEN wishlists.notify THEN wishlists.last_stock ELSE excluded.last_stock END, notify = excluded.notify, updated_at = CURRENT_TIMESTAMP",
+		dialect.Postgres: "INSERT INTO wishlists (customer_id, product_id, product_variant_id, notify, last_price, last_stock) SELECT CAST(? AS INTEGER), p.id, COALESCE(v.id, 0), CAST(? AS BOOLEAN), COALESCE(v.price, p.price), COALESCE(v.stock_quantity, p.stock_quantity) FROM products AS p LEFT JOIN product_variants AS v ON v.product_id = p.id AND v.id = ? WHERE p.id = ? AND (v.id IS NOT NULL OR CAST(? AS INTEGER) = 0) ON CONFLICT(customer_id, product_id, product_variant_id) DO UPDATE SET last_price = CASE WHEN wishlists.notify THEN wishlists.last_price ELSE excluded.last_price END, last_stock = CASE WHEN wishlists.notify THEN wishlists.last_stock ELSE excluded.last_stock END, notify = excluded.notify, updated_at = CURRENT_TIMESTAMP",
+		dialect.MySQL:    "INSERT INTO wishlists (customer_id, product_id, product_variant_id, notify, last_price, last_stock) SELECT ?, p.id, COALESCE(v.id, 0), ?, COALESCE(v.price, p.price), COALESCE(v.stock_quantity, p.stock_quantity) FROM products AS p LEFT JOIN product_variants AS v ON v.product_id = p.id AND v.id = ? WHERE p.id = ? AND (v.id IS NOT NULL OR ? = 0) ON DUPLICATE KEY UPDATE last_price = CASE WHEN wishlists.notify THEN wishlists.last_price ELSE VALUES(last_price) END, last_stock = CASE WHEN wishlists.notify THEN wishlists.last_stock ELSE VALUES(last_stock) END, notify = VALUES(notify), updated_at = CURRENT_TIMESTAMP",
+	}[driverName]
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when create then return success",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, true, 2, 1, 2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given product not found when create then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, true, 2, 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when create then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, true, 2, 1, 2).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewWishlistRepository(sqlxDB)
+			tc.mock()
+			err := repo.Create(context.Background(), &model.WishlistEntity{
+				CustomerID:       1,
+				ProductID:        1,
+				ProductVariantID: 2,
+				Notify:           true,
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetByCustomerID(t *testing.T) {
+	dialecttest.Run(t, testGetByCustomerID)
+}
+
+func testGetByCustomerID(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "SELECT w.id, w.customer_id, w.product_id, w.product_variant_id, w.notify, w.last_price, w.last_stock, w.created_at, w.updated_at, p.name AS product_name, COALESCE(v.price, p.price) AS price, COALESCE(v.stock_quantity, p.stock_quantity) AS stock_quantity FROM wishlists AS w JOIN products AS p ON w.product_id = p.id LEFT JOIN product_variants AS v ON w.product_variant_id = v.id WHERE w.customer_id = ? ORDER BY w.id DESC"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when get by customer id then return success",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "product_id", "product_variant_id", "notify", "last_price", "last_stock", "created_at", "updated_at", "product_name", "price", "stock_quantity"}).
+						AddRow(1, 1, 1, 0, true, "10000", 1, time.Time{}, time.Time{}, "T-Shirt", "10000", 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error when get by customer id then return error",
+			mock: func() {
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewWishlistRepository(sqlxDB)
+			tc.mock()
+			_, err := repo.GetByCustomerID(context.Background(), 1)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GetByCustomerID() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	dialecttest.Run(t, testDelete)
+}
+
+func testDelete(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	query := "DELETE FROM wishlists WHERE customer_id = ? AND product_id = ? AND product_variant_id = ?"
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when delete then return success",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given no wishlist found when delete then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error when delete then return error",
+			mock: func() {
+				mock.ExpectExec(query).
+					WithArgs(1, 1, 2).
+					WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewWishlistRepository(sqlxDB)
+			tc.mock()
+			err := repo.Delete(context.Background(), 1, 1, 2)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Delete() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateSnapshot(t *testing.T) {
+	dialecttest.Run(t, testUpdateSnapshot)
+}
+
+func testUpdateSnapshot(t *testing.T, driverName string) {
+	sqlxDB, mock := dialecttest.New(t, driverName)
+
+	wishlist := &model.WishlistEntity{
+		ID:            1,
+		CustomerID:    1,
+		ProductID:     1,
+		Price:         decimal.NewFromFloat(9000),
+		StockQuantity: 1,
+	}
+
+	notifications := []*model.WishlistNotificationEntity{
+		{
+			CustomerID: 1,
+			ProductID:  1,
+			Type:       wishlistEnum.NotificationTypePriceDrop,
+			Price:      decimal.NewFromFloat(9000),
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Given valid request when update snapshot then return success",
+			mock: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectExec("UPDATE wishlists SET last_price = ?, last_stock = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?").
+					WithArgs(wishlist.Price, wishlist.StockQuantity, wishlist.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectExec("INSERT INTO wishlist_notifications (customer_id, product_id, type, price) VALUES (?, ?, ?, ?)").
+					WithArgs(1, 1, wishlistEnum.NotificationTypePriceDrop, notifications[0].Price).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Given error insert notification when update snapshot then return error",
+			mock: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectExec("UPDATE wishlists SET last_price = ?, last_stock = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?").
+					WithArgs(wishlist.Price, wishlist.StockQuantity, wishlist.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectExec("INSERT INTO wishlist_notifications (customer_id, product_id, type, price) VALUES (?, ?, ?, ?)").
+					WithArgs(1, 1, wishlistEnum.NotificationTypePriceDrop, notifications[0].Price).
+					WillReturnError(errors.New("error"))
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Given error begin db transaction when update snapshot then return error",
+			mock: func() {
+				mock.ExpectBegin().WillReturnError(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewWishlistRepository(sqlxDB)
+			tc.mock()
+			err := repo.UpdateSnapshot(context.Background(), wishlist, notifications)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("UpdateSnapshot() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
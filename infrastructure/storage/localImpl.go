@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type localStorageImpl struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage stores files under dir, served by the app under baseURL.
+func NewLocalStorage(dir, baseURL string) Storage {
+	return &localStorageImpl{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (l *localStorageImpl) Save(name string, data []byte) error {
+	fullPath, err := l.fullPath(name)
+	if err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	// write to a temporary file first so a failed upload never leaves a partial file behind
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (l *localStorageImpl) Delete(name string) error {
+	fullPath, err := l.fullPath(name)
+	if err != nil {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+		slog.Error("storage error", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (l *localStorageImpl) URL(name string) string {
+	return l.baseURL + "/" + path.Clean(name)
+}
+
+// fullPath resolves the slash separated name inside the storage directory.
+func (l *localStorageImpl) fullPath(name string) (string, error) {
+	cleaned := path.Clean("/" + name)
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid file name: %q", name)
+	}
+
+	return filepath.Join(l.dir, filepath.FromSlash(cleaned)), nil
+}
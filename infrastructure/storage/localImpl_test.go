@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStorage(dir, "/media/")
+
+	testCases := []struct {
+		name     string
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Given valid name when save then write the file",
+			fileName: "products/1/image.png",
+			want:     filepath.Join(dir, "products", "1", "image.png"),
+			wantErr:  false,
+		},
+		{
+			name:     "Given name outside the directory when save then keep the file inside the directory",
+			fileName: "../../image.png",
+			want:     filepath.Join(dir, "image.png"),
+			wantErr:  false,
+		},
+		{
+			name:     "Given empty name when save then return error",
+			fileName: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := store.Save(tc.fileName, []byte("data"))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Save() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if tc.wantErr {
+				return
+			}
+
+			if got, err := os.ReadFile(tc.want); err != nil || string(got) != "data" {
+				t.Errorf("Save() file = %q, %v, want %q", got, err, "data")
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStorage(dir, "/media")
+
+	if err := store.Save("products/1/image.png", []byte("data")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := store.Delete("products/1/image.png"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "products", "1", "image.png")); !os.IsNotExist(err) {
+		t.Errorf("Delete() file still exists")
+	}
+
+	// deleting a missing file is not an error
+	if err := store.Delete("products/1/image.png"); err != nil {
+		t.Errorf("Delete() missing file error = %v", err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	store := NewLocalStorage(t.TempDir(), "/media/")
+
+	if got := store.URL("products/1/image.png"); got != "/media/products/1/image.png" {
+		t.Errorf("URL() = %v, want %v", got, "/media/products/1/image.png")
+	}
+}
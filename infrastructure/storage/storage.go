@@ -0,0 +1,8 @@
+package storage
+
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=storage.go -destination=Storage.go
+type Storage interface {
+	Save(path string, data []byte) error
+	Delete(path string) error
+	URL(path string) string
+}
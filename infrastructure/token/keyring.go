@@ -0,0 +1,193 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zakiyalmaya/online-store/config"
+)
+
+// minRSAKeySize is the smallest RSA modulus in bits accepted for signing keys.
+const minRSAKeySize = 2048
+
+type key struct {
+	id         string
+	method     jwt.SigningMethod
+	signingKey interface{}
+	verifyKey  interface{}
+}
+
+// keyRing signs tokens with the active key and verifies them with any key it
+// holds, so tokens signed by the previous key keep working during a rotation.
+type keyRing struct {
+	active *key
+	keys   map[string]*key
+}
+
+// JWK is the public part of a signing key as published in the JWKS endpoint.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// newKeyRing loads every <kid>.pem file of auth.KeyDir, private keys sign and verify
+// while public keys only verify tokens of a retired key. Without a key dir the
+// tokens are signed with HS256 and auth.SecretKey, and nothing is published.
+func newKeyRing(auth config.AuthConfig) (*keyRing, error) {
+	if auth.KeyDir == "" {
+		hmacKey := &key{
+			method:     jwt.SigningMethodHS256,
+			signingKey: []byte(auth.SecretKey),
+			verifyKey:  []byte(auth.SecretKey),
+		}
+		return &keyRing{active: hmacKey, keys: map[string]*key{"": hmacKey}}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(auth.KeyDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing signing keys: %v", err)
+	}
+
+	keys := make(map[string]*key, len(files))
+	for _, file := range files {
+		k, err := loadKey(file)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.id] = k
+	}
+
+	active, ok := keys[auth.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s not found in %s", auth.ActiveKeyID, auth.KeyDir)
+	}
+
+	if active.signingKey == nil {
+		return nil, fmt.Errorf("signing key %s is a public key, the active key must be a private key", auth.ActiveKeyID)
+	}
+
+	return &keyRing{active: active, keys: keys}, nil
+}
+
+func loadKey(file string) (*key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signing key %s: %v", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", file)
+	}
+
+	k := &key{id: strings.TrimSuffix(filepath.Base(file), ".pem")}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signing key %s: %v", file, err)
+	}
+
+	switch parsed := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.signingKey, k.verifyKey = jwt.SigningMethodRS256, parsed, &parsed.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.verifyKey = jwt.SigningMethodRS256, parsed
+	case ed25519.PrivateKey:
+		k.method, k.signingKey, k.verifyKey = jwt.SigningMethodEdDSA, parsed, parsed.Public()
+	case ed25519.PublicKey:
+		k.method, k.verifyKey = jwt.SigningMethodEdDSA, parsed
+	default:
+		return nil, fmt.Errorf("signing key %s must be an RSA or Ed25519 key", file)
+	}
+
+	if publicKey, ok := k.verifyKey.(*rsa.PublicKey); ok && publicKey.N.BitLen() < minRSAKeySize {
+		return nil, fmt.Errorf("signing key %s must be at least %d bits", file, minRSAKeySize)
+	}
+
+	return k, nil
+}
+
+// sign signs the claims with the active key, naming it in the kid header.
+func (k *keyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	if k.active.id != "" {
+		token.Header["kid"] = k.active.id
+	}
+
+	return token.SignedString(k.active.signingKey)
+}
+
+// keyfunc is the jwt.Keyfunc verifying tokens against the key named by their kid,
+// refusing tokens whose alg is not the one of that key.
+func (k *keyRing) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	verifying, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != verifying.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+
+	return verifying.verifyKey, nil
+}
+
+// jwks returns the public keys ordered by kid, HMAC secrets are never published.
+func (k *keyRing) jwks() *JWKS {
+	jwks := &JWKS{Keys: make([]*JWK, 0, len(k.keys))}
+	for _, verifying := range k.keys {
+		jwk := &JWK{KeyID: verifying.id, Algorithm: verifying.method.Alg(), Use: "sig"}
+
+		switch publicKey := verifying.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID
+	})
+
+	return jwks
+}
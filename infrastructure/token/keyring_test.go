@@ -0,0 +1,264 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zakiyalmaya/online-store/config"
+)
+
+func writeKey(t *testing.T, dir, kid string, key interface{}, public bool) {
+	var (
+		der       []byte
+		blockType string
+		err       error
+	)
+	if public {
+		der, err = x509.MarshalPKIXPublicKey(key)
+		blockType = "PUBLIC KEY"
+	} else {
+		der, err = x509.MarshalPKCS8PrivateKey(key)
+		blockType = "PRIVATE KEY"
+	}
+	if err != nil {
+		t.Fatalf("error encoding key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+}
+
+// setupKeyDir holds an RSA key, an Ed25519 key and the public half of a retired Ed25519 key.
+func setupKeyDir(t *testing.T) (string, ed25519.PrivateKey) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minRSAKeySize)
+	if err != nil {
+		t.Fatalf("error generating rsa key: %v", err)
+	}
+	writeKey(t, dir, "rsa", rsaKey, false)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating ed25519 key: %v", err)
+	}
+	writeKey(t, dir, "ed", edKey, false)
+
+	_, retiredKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating ed25519 key: %v", err)
+	}
+	writeKey(t, dir, "retired", retiredKey.Public(), true)
+
+	return dir, retiredKey
+}
+
+func TestNewKeyRing(t *testing.T) {
+	dir, _ := setupKeyDir(t)
+
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("error generating rsa key: %v", err)
+	}
+	smallKeyDir := t.TempDir()
+	writeKey(t, smallKeyDir, "small", smallKey, false)
+
+	invalidDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(invalidDir, "invalid.pem"), []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		auth    config.AuthConfig
+		wantErr bool
+	}{
+		{
+			name:    "Given no key dir when new then use the secret key",
+			auth:    config.AuthConfig{SecretKey: "secret"},
+			wantErr: false,
+		},
+		{
+			name:    "Given private active key when new then return success",
+			auth:    config.AuthConfig{KeyDir: dir, ActiveKeyID: "ed"},
+			wantErr: false,
+		},
+		{
+			name:    "Given unknown active key when new then return error",
+			auth:    config.AuthConfig{KeyDir: dir, ActiveKeyID: "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "Given public active key when new then return error",
+			auth:    config.AuthConfig{KeyDir: dir, ActiveKeyID: "retired"},
+			wantErr: true,
+		},
+		{
+			name:    "Given rsa key below 2048 bits when new then return error",
+			auth:    config.AuthConfig{KeyDir: smallKeyDir, ActiveKeyID: "small"},
+			wantErr: true,
+		},
+		{
+			name:    "Given file that is not a key when new then return error",
+			auth:    config.AuthConfig{KeyDir: invalidDir, ActiveKeyID: "invalid"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newKeyRing(tc.auth)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("newKeyRing() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir, retiredKey := setupKeyDir(t)
+
+	rsaRing, err := newKeyRing(config.AuthConfig{KeyDir: dir, ActiveKeyID: "rsa"})
+	if err != nil {
+		t.Fatalf("newKeyRing() error = %v", err)
+	}
+
+	edRing, err := newKeyRing(config.AuthConfig{KeyDir: dir, ActiveKeyID: "ed"})
+	if err != nil {
+		t.Fatalf("newKeyRing() error = %v", err)
+	}
+
+	hmacRing, err := newKeyRing(config.AuthConfig{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("newKeyRing() error = %v", err)
+	}
+
+	sign := func(ring *keyRing) string {
+		token, err := ring.sign(jwt.MapClaims{"username": "username"})
+		if err != nil {
+			t.Fatalf("sign() error = %v", err)
+		}
+		return token
+	}
+
+	retiredToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"username": "username"})
+	retiredToken.Header["kid"] = "retired"
+	retired, err := retiredToken.SignedString(retiredKey)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	// an HS256 token signed with the public RSA key as the secret must not pass as the RSA key
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"username": "username"})
+	confused.Header["kid"] = "rsa"
+	confusedToken, err := confused.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		ring    *keyRing
+		token   string
+		wantErr bool
+	}{
+		{
+			name:    "Given rsa signed token when verify then return success",
+			ring:    rsaRing,
+			token:   sign(rsaRing),
+			wantErr: false,
+		},
+		{
+			name:    "Given token of the previous active key when verify then return success",
+			ring:    edRing,
+			token:   sign(rsaRing),
+			wantErr: false,
+		},
+		{
+			name:    "Given token of a retired public key when verify then return success",
+			ring:    edRing,
+			token:   retired,
+			wantErr: false,
+		},
+		{
+			name:    "Given hmac token when verify with the secret key then return success",
+			ring:    hmacRing,
+			token:   sign(hmacRing),
+			wantErr: false,
+		},
+		{
+			name:    "Given token of an unknown key when verify then return error",
+			ring:    hmacRing,
+			token:   sign(edRing),
+			wantErr: true,
+		},
+		{
+			name:    "Given hmac token naming an rsa key when verify then return error",
+			ring:    rsaRing,
+			token:   confusedToken,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := jwt.Parse(tc.token, tc.ring.keyfunc)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && !token.Valid {
+				t.Errorf("Parse() token is not valid")
+			}
+		})
+	}
+}
+
+func TestKeyRingJWKS(t *testing.T) {
+	dir, _ := setupKeyDir(t)
+
+	ring, err := newKeyRing(config.AuthConfig{KeyDir: dir, ActiveKeyID: "ed"})
+	if err != nil {
+		t.Fatalf("newKeyRing() error = %v", err)
+	}
+
+	jwks := ring.jwks()
+	want := []struct{ kid, kty, alg string }{
+		{"ed", "OKP", "EdDSA"},
+		{"retired", "OKP", "EdDSA"},
+		{"rsa", "RSA", "RS256"},
+	}
+
+	if len(jwks.Keys) != len(want) {
+		t.Fatalf("jwks() len = %v, want %v", len(jwks.Keys), len(want))
+	}
+
+	for i, key := range jwks.Keys {
+		if key.KeyID != want[i].kid || key.KeyType != want[i].kty || key.Algorithm != want[i].alg {
+			t.Errorf("jwks() key %d = %+v, want %+v", i, key, want[i])
+		}
+	}
+
+	if key := jwks.Keys[2]; key.N == "" || key.E != "AQAB" {
+		t.Errorf("jwks() rsa key = %+v, want modulus and exponent AQAB", key)
+	}
+
+	hmacRing, err := newKeyRing(config.AuthConfig{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("newKeyRing() error = %v", err)
+	}
+
+	if keys := hmacRing.jwks().Keys; len(keys) != 0 {
+		t.Errorf("jwks() published the secret key: %+v", keys)
+	}
+}
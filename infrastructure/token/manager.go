@@ -0,0 +1,13 @@
+package token
+
+import "github.com/zakiyalmaya/online-store/model"
+
+// Manager issues and verifies the customer access tokens, it is the only place
+// knowing about the JWT library.
+//
+//go:generate go run github.com/golang/mock/mockgen --build_flags=--mod=vendor -package mocks -source=manager.go -destination=Manager.go
+type Manager interface {
+	Issue(claims *model.AuthClaims) (string, error)
+	Verify(token string) (*model.AuthClaims, error)
+	JWKS() *JWKS
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+// leeway absorbs the clock difference between us and the services verifying our tokens.
+const leeway = 30 * time.Second
+
+type tokenClaims struct {
+	model.AuthClaims
+	jwt.RegisteredClaims
+}
+
+type jwtManagerImpl struct {
+	keys   *keyRing
+	auth   config.AuthConfig
+	parser *jwt.Parser
+}
+
+// NewJWTManager loads the signing keys of auth, see newKeyRing.
+func NewJWTManager(auth config.AuthConfig) (Manager, error) {
+	keys, err := newKeyRing(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithIssuer(auth.Issuer),
+		jwt.WithAudience(auth.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithNotBeforeRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	)
+
+	return &jwtManagerImpl{keys: keys, auth: auth, parser: parser}, nil
+}
+
+func (j *jwtManagerImpl) Issue(claims *model.AuthClaims) (string, error) {
+	now := time.Now()
+	return j.keys.sign(&tokenClaims{
+		AuthClaims: *claims,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        utils.GenerateUUID(),
+			Issuer:    j.auth.Issuer,
+			Subject:   strconv.Itoa(claims.UserID),
+			Audience:  jwt.ClaimStrings{j.auth.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.auth.AccessTokenTTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+// Verify checks the signature, exp, nbf, iat, iss and aud, all of them required,
+// and that the token carries the customer claims.
+func (j *jwtManagerImpl) Verify(token string) (*model.AuthClaims, error) {
+	claims := &tokenClaims{}
+	if _, err := j.parser.ParseWithClaims(token, claims, j.keys.keyfunc); err != nil {
+		return nil, err
+	}
+
+	// the parser validates iat only when it is present
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("token is missing the iat claim")
+	}
+
+	if claims.UserID <= 0 || claims.Username == "" || claims.SessionID == "" {
+		return nil, fmt.Errorf("token is missing the customer claims")
+	}
+
+	return &claims.AuthClaims, nil
+}
+
+func (j *jwtManagerImpl) JWKS() *JWKS {
+	return j.keys.jwks()
+}
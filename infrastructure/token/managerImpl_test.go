@@ -0,0 +1,166 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	auth := config.Default().Auth
+	manager, err := NewJWTManager(auth)
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+
+	want := &model.AuthClaims{UserID: 1, Username: "username", IsAdmin: true, SessionID: "session"}
+	token, err := manager.Issue(want)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	got, err := manager.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if *got != *want {
+		t.Errorf("Verify() = %+v, want %+v", got, want)
+	}
+
+	other := auth
+	other.SecretKey = "another-secret"
+	otherManager, err := NewJWTManager(other)
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+
+	if _, err := otherManager.Verify(token); err == nil {
+		t.Errorf("Verify() accepted a token signed with another key")
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	auth := config.Default().Auth
+	manager, err := NewJWTManager(auth)
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+	keys := manager.(*jwtManagerImpl).keys
+
+	now := time.Now()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"user_id":  1,
+			"username": "username",
+			"sid":      "session",
+			"iss":      auth.Issuer,
+			"aud":      auth.Audience,
+			"exp":      now.Add(time.Minute).Unix(),
+			"nbf":      now.Unix(),
+			"iat":      now.Unix(),
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(claims jwt.MapClaims)
+		wantErr bool
+	}{
+		{
+			name:    "Given valid claims when verify then return success",
+			modify:  func(claims jwt.MapClaims) {},
+			wantErr: false,
+		},
+		{
+			name:    "Given exp within the leeway when verify then return success",
+			modify:  func(claims jwt.MapClaims) { claims["exp"] = now.Add(-leeway / 2).Unix() },
+			wantErr: false,
+		},
+		{
+			name:    "Given expired token when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["exp"] = now.Add(-time.Minute).Unix() },
+			wantErr: true,
+		},
+		{
+			name:    "Given missing exp when verify then return error",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "exp") },
+			wantErr: true,
+		},
+		{
+			name:    "Given nbf in the future when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["nbf"] = now.Add(time.Minute).Unix() },
+			wantErr: true,
+		},
+		{
+			name:    "Given missing nbf when verify then return error",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "nbf") },
+			wantErr: true,
+		},
+		{
+			name:    "Given iat in the future when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["iat"] = now.Add(time.Minute).Unix() },
+			wantErr: true,
+		},
+		{
+			name:    "Given missing iat when verify then return error",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "iat") },
+			wantErr: true,
+		},
+		{
+			name:    "Given another issuer when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["iss"] = "another-issuer" },
+			wantErr: true,
+		},
+		{
+			name:    "Given another audience when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["aud"] = []string{"another-service"} },
+			wantErr: true,
+		},
+		{
+			name:    "Given missing audience when verify then return error",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "aud") },
+			wantErr: true,
+		},
+		{
+			name:    "Given fractional user id when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["user_id"] = 1.5 },
+			wantErr: true,
+		},
+		{
+			name:    "Given user id as string when verify then return error",
+			modify:  func(claims jwt.MapClaims) { claims["user_id"] = "1" },
+			wantErr: true,
+		},
+		{
+			name:    "Given missing session when verify then return error",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "sid") },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := validClaims()
+			tc.modify(claims)
+
+			token, err := keys.sign(claims)
+			if err != nil {
+				t.Fatalf("sign() error = %v", err)
+			}
+
+			got, err := manager.Verify(token)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if err == nil && got.UserID != 1 {
+				t.Errorf("Verify() user id = %v, want 1", got.UserID)
+			}
+		})
+	}
+}
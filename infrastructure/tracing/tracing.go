@@ -0,0 +1,86 @@
+// Package tracing records the OpenTelemetry spans of the app: the HTTP
+// requests, the service and repository methods and the Redis commands, sent
+// to the exporter of the configuration.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/zakiyalmaya/online-store/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/zakiyalmaya/online-store"
+
+// New sets the tracer provider exporting to the configured exporter, stdout
+// writing to w, and the W3C trace context propagation. Without an exporter the
+// spans are not recorded but still carry a trace id for the logs. The provider
+// has to be shut down to send the last spans.
+func New(ctx context.Context, w io.Writer, cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	switch cfg.Exporter {
+	case config.TracingExporterNone:
+		options = append(options, sdktrace.WithSampler(sdktrace.NeverSample()))
+	case config.TracingExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("error creating stdout exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case config.TracingExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("error creating otlp exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %s", cfg.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider, nil
+}
+
+// Start starts a span of the app named name, a child of the span of ctx.
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, options...)
+}
+
+// StartService starts the span of a method of a service, e.g. cart.Service/Create.
+func StartService(ctx context.Context, service, method string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, service+".Service/"+method)
+}
+
+// StartQuery starts the span of a repository method running SQL, e.g.
+// cart.Repository/Create.
+func StartQuery(ctx context.Context, repository, method string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, repository+".Repository/"+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.repository", repository), attribute.String("db.operation", method)))
+}
+
+// TraceID returns the trace id of the span of ctx, or "" without a span.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
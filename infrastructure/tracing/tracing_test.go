@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/zakiyalmaya/online-store/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     config.TracingConfig
+		wantErr bool
+	}{
+		{
+			name:    "Given no exporter when new then return provider",
+			cfg:     config.TracingConfig{Exporter: config.TracingExporterNone, ServiceName: "online-store", SampleRatio: 1},
+			wantErr: false,
+		},
+		{
+			name:    "Given stdout exporter when new then return provider",
+			cfg:     config.TracingConfig{Exporter: config.TracingExporterStdout, ServiceName: "online-store", SampleRatio: 1},
+			wantErr: false,
+		},
+		{
+			name:    "Given otlp exporter when new then return provider",
+			cfg:     config.TracingConfig{Exporter: config.TracingExporterOTLP, Endpoint: "http://localhost:4318/v1/traces", ServiceName: "online-store", SampleRatio: 1},
+			wantErr: false,
+		},
+		{
+			name:    "Given unknown exporter when new then return error",
+			cfg:     config.TracingConfig{Exporter: "jaeger", ServiceName: "online-store", SampleRatio: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, err := New(context.Background(), &bytes.Buffer{}, tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if provider != nil {
+				provider.Shutdown(context.Background())
+			}
+		})
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	provider, err := New(context.Background(), &buf, config.TracingConfig{Exporter: config.TracingExporterStdout, ServiceName: "online-store", SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, span := StartService(context.Background(), "cart", "Create")
+	if TraceID(ctx) == "" {
+		t.Errorf("TraceID() is empty within a span")
+	}
+	span.End()
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"Name":"cart.Service/Create"`)) {
+		t.Errorf("stdout exporter did not write the span: %s", buf.String())
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	mockRedisServer, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when starting a stub redis server", err)
+	}
+	defer mockRedisServer.Close()
+
+	redcl := redis.NewClient(&redis.Options{Addr: mockRedisServer.Addr()})
+	redcl.AddHook(RedisHook{})
+
+	ctx, parent := Start(context.Background(), "parent")
+	redcl.Set(ctx, "key", "value", 0)
+	redcl.Get(ctx, "missing")
+	redcl.Incr(ctx, "key")
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want 4", len(spans))
+	}
+
+	testCases := []struct {
+		name   string
+		status codes.Code
+	}{
+		{name: "redis set", status: codes.Unset},
+		{name: "redis get", status: codes.Unset},
+		{name: "redis incr", status: codes.Error},
+	}
+
+	for i, tc := range testCases {
+		span := spans[i]
+		if span.Name() != tc.name {
+			t.Errorf("span %d name = %s, want %s", i, span.Name(), tc.name)
+		}
+
+		if span.Status().Code != tc.status {
+			t.Errorf("span %s status = %v, want %v", span.Name(), span.Status().Code, tc.status)
+		}
+
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the span of the context", span.Name())
+		}
+	}
+}
@@ -1,47 +1,182 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zakiyalmaya/online-store/application"
+	"github.com/zakiyalmaya/online-store/application/wishlist"
 	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/logger"
+	"github.com/zakiyalmaya/online-store/infrastructure/mailer"
+	"github.com/zakiyalmaya/online-store/infrastructure/migration"
 	"github.com/zakiyalmaya/online-store/infrastructure/repository"
+	"github.com/zakiyalmaya/online-store/infrastructure/storage"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/transport"
 )
 
 func main() {
-	var sqliteDB, redisHost, redisPort, appPort string
-	
-	// Get environment variables
-	sqliteDB = config.SQLITE_DB
-	redisHost = config.REDIS_HOST
-	redisPort = config.REDIS_PORT
-	appPort = config.APP_PORT
-	fmt.Println("configuration ", sqliteDB, redisHost, redisPort, appPort)
-
-	// for testing run locally
-	// sqliteDB = "./online_store.db"
-	// redisHost = "localhost"
-	// redisPort = "6379"
-	// appPort = ":3000"
+	// load configuration from the config file and environment variables,
+	// run locally with APP_PROFILE=local
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	// log structured lines, the log package included
+	logs, err := logger.New(os.Stderr, cfg.Log)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	slog.SetDefault(logs)
+	slog.Info("configuration", "profile", cfg.Profile, "tracing_exporter", cfg.Tracing.Exporter, "database_driver", cfg.Database.Driver, "sqlite_path", cfg.SQLite.Path, "redis_host", cfg.Redis.Host, "redis_port", cfg.Redis.Port, "port", cfg.App.Port, "media_dir", cfg.Media.Dir)
+
+	// trace the requests, spans of the stdout exporter going to stdout next to the logs on stderr
+	tracer, err := tracing.New(context.Background(), os.Stdout, cfg.Tracing)
+	if err != nil {
+		fatal(err)
+	}
+	defer tracer.Shutdown(context.Background())
 
 	// instatiate repository
-	db := repository.DBConnection(sqliteDB)
-	redcl := repository.RedisClient(redisHost, redisPort)
+	db := repository.DBConnection(cfg)
 	defer db.Close()
 
-	repository := repository.NewRepository(db, redcl)
+	migrations, err := migration.Load(migration.Migrations, migration.Dir(cfg.Database.Driver))
+	if err != nil {
+		fatal(err)
+	}
+	migrator := migration.NewMigrator(db, migrations)
+
+	// `online_store_app migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(migrator, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.Database.AutoMigrate {
+		if _, err := migrator.Up(); err != nil {
+			fatal(err)
+		}
+	}
+
+	redcl := repository.RedisClient(cfg.Redis.Host, cfg.Redis.Port)
+	defer redcl.Close()
+
+	repository := repository.NewRepository(db, redcl, cfg)
+	store := storage.NewLocalStorage(cfg.Media.Dir, cfg.Media.URL)
+
+	// load the token signing keys
+	tokens, err := token.NewJWTManager(cfg.Auth)
+	if err != nil {
+		fatal(err)
+	}
+
+	mail, err := mailer.New(cfg.Mailer)
+	if err != nil {
+		fatal(err)
+	}
 
 	// instantiate application
-	application := application.NewApplication(repository)
+	application := application.NewApplication(repository, store, tokens, mail, cfg)
+
+	// stop on SIGTERM or Ctrl+C, the background workers first stop with ctx
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// notify wishlist price drops and restocks in the background
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		notifyWishlistChanges(ctx, application.WishlistSvc, cfg.Wishlist.NotifyInterval)
+	}()
 
-	// instantiate fiber
-	r := fiber.New()
+	// instantiate fiber, leaving room for the multipart overhead of an image upload
+	// and reading the IP address of the client from the header of the trusted proxies only
+	r := fiber.New(fiber.Config{
+		BodyLimit:               max(fiber.DefaultBodyLimit, cfg.Media.MaxImageSize+1<<20),
+		ErrorHandler:            transport.ErrorHandler,
+		ProxyHeader:             cfg.App.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.App.TrustedProxies) > 0,
+		TrustedProxies:          cfg.App.TrustedProxies,
+	})
+
+	// serve uploaded product images
+	r.Static(cfg.Media.URL, cfg.Media.Dir)
 
 	// instantiate transport
-	transport.Handler(application, redcl, r)
+	transport.Handler(application, redcl, tokens, r, cfg)
+
+	// serve the metrics on a port of their own, not published with the app
+	metricsApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+	transport.MetricsHandler(metricsApp)
+
+	listenErr := make(chan error, 2)
+	go func() {
+		slog.Info("server is running", "port", cfg.App.Port)
+		listenErr <- r.Listen(cfg.App.Port)
+	}()
+	go func() {
+		slog.Info("metrics server is running", "port", cfg.App.MetricsPort)
+		listenErr <- metricsApp.Listen(cfg.App.MetricsPort)
+	}()
+
+	select {
+	case err := <-listenErr:
+		// the port could not be listened on, no request was served yet
+		fatal(err)
+	case <-ctx.Done():
+		stop()
+	}
+
+	// stop accepting connections and let the requests in flight finish, then
+	// wait for the workers before the deferred calls close the connections
+	slog.Info("shutting down", "timeout", cfg.App.ShutdownTimeout)
+	if err := r.ShutdownWithTimeout(cfg.App.ShutdownTimeout); err != nil {
+		slog.Error("error draining requests", "error", err)
+	}
+	if err := metricsApp.ShutdownWithTimeout(cfg.App.ShutdownTimeout); err != nil {
+		slog.Error("error stopping the metrics server", "error", err)
+	}
+	workers.Wait()
+	application.CustomerSvc.Wait()
+	slog.Info("server stopped")
+}
+
+// notifyWishlistChanges notifies the wishlist changes every interval until ctx
+// is done, a notification in progress is cancelled with it.
+func notifyWishlistChanges(ctx context.Context, wishlistSvc wishlist.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := wishlistSvc.NotifyChanges(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("error notifying wishlist changes", "error", err)
+			}
+		}
+	}
+}
 
-	fmt.Println("Server is running on port", appPort)
-	r.Listen(appPort)
+// fatal logs the error that keeps the app from starting and exits.
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
 }
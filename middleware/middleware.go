@@ -2,66 +2,354 @@ package middleware
 
 import (
 	"context"
-	"log"
-	"net/http"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/application/apikey"
+	"github.com/zakiyalmaya/online-store/application/ratelimit"
+	"github.com/zakiyalmaya/online-store/config"
 	"github.com/zakiyalmaya/online-store/constant"
+	apikeyEnum "github.com/zakiyalmaya/online-store/constant/apikey"
+	"github.com/zakiyalmaya/online-store/infrastructure/logger"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
+	"github.com/zakiyalmaya/online-store/infrastructure/tracing"
 	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func AuthMiddleware(redcl *redis.Client) fiber.Handler {
+// requestIDRegex keeps a request id of the client out of the logs unless it is
+// short and plain enough to not forge a log line.
+var requestIDRegex = regexp.MustCompile(`^[a-zA-Z0-9._:-]{1,128}$`)
+
+// headerTraceID answers the trace id of the request.
+const headerTraceID = "X-Trace-ID"
+
+// The headers of the IETF RateLimit draft answering the quota of the client.
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// errInvalidToken is the answer to every refused JWT, it does not tell why.
+var errInvalidToken = model.NewUnauthorizedError("invalid_token", "Invalid or expired token")
+
+// AuthMiddleware accepts a JWT of a logged in customer or, when the route lists
+// scopes, an API key holding every one of them.
+func AuthMiddleware(redcl *redis.Client, tokens token.Manager, apiKeys apikey.Service, scopes ...apikeyEnum.Scope) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if key := c.Get(constant.APIKeyHeader); key != "" {
+			return apiKeyAuth(c, apiKeys, key, scopes)
+		}
 
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Missing Authorization header"))
+			return model.NewUnauthorizedError("missing_authorization", "Missing Authorization header")
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the token signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(http.StatusUnauthorized, "Invalid signing method")
+		claims, err := tokens.Verify(tokenString)
+		if err != nil {
+			return errInvalidToken
+		}
+
+		c.Locals("username", claims.Username)
+		c.Locals("user_id", claims.UserID)
+		c.Locals("is_admin", claims.IsAdmin)
+		c.Locals("session_id", claims.SessionID)
+
+		// Check the session is not logged out or revoked
+		exists, err := redcl.Exists(c.UserContext(), constant.SessionPrefix+claims.SessionID).Result()
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "redis error checking session", "error", err)
+			return errInvalidToken
+		}
+
+		if exists == 0 {
+			return errInvalidToken
+		}
+
+		return c.Next()
+	}
+}
+
+func apiKeyAuth(c *fiber.Ctx, apiKeys apikey.Service, key string, scopes []apikeyEnum.Scope) error {
+	if len(scopes) == 0 {
+		return model.NewUnauthorizedError("api_key_not_accepted", "API keys are not accepted for this endpoint")
+	}
+
+	apiKey, err := apiKeys.Authenticate(c.UserContext(), key)
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range scopes {
+		if !apiKey.HasScope(scope) {
+			return model.NewForbiddenError("missing_scope", "API key is missing scope "+string(scope))
+		}
+	}
+
+	c.Locals("api_key_id", apiKey.ID)
+	c.Locals("api_key", apiKey)
+	return c.Next()
+}
+
+// AdminMiddleware must be registered after AuthMiddleware. A customer must be
+// an admin, an API key must hold every one of the scopes. Without scopes the
+// route is only for admins and API keys are refused.
+func AdminMiddleware(scopes ...apikeyEnum.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if apiKey, ok := c.Locals("api_key").(*model.APIKeyEntity); ok {
+			if len(scopes) == 0 {
+				return model.NewForbiddenError("admin_required", "Admin access required")
+			}
+
+			for _, scope := range scopes {
+				if !apiKey.HasScope(scope) {
+					return model.NewForbiddenError("missing_scope", "API key is missing scope "+string(scope))
+				}
 			}
-			return []byte(constant.SecretKey), nil
-		})
 
-		if err != nil || !token.Valid {
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Invalid or expired token"))
+			return c.Next()
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Invalid token claims"))
+		isAdmin, _ := c.Locals("is_admin").(bool)
+		if !isAdmin {
+			return model.NewForbiddenError("admin_required", "Admin access required")
 		}
 
-		username, ok := claims["username"].(string)
-		if !ok {
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Invalid username in token claims"))
+		return c.Next()
+	}
+}
+
+// RateLimitMiddleware must be registered after AuthMiddleware on a route that
+// has it, so the requests are counted by customer or API key instead of by IP
+// address. It answers the quota left in the RateLimit headers and refuses the
+// requests over it with 429 and Retry-After. While Redis is down the requests
+// are let through with fail open, refused with 503 otherwise.
+func RateLimitMiddleware(rateLimits ratelimit.Service, cfg config.RateLimitConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !cfg.Enabled {
+			return c.Next()
 		}
-		c.Locals("username", username)
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Invalid user_id in token claims"))
+		quota, err := rateLimits.Take(c.UserContext(), c.Method()+" "+c.Route().Path, rateLimitSubject(c))
+		return limitRate(c, cfg, quota, err)
+	}
+}
+
+// PreAuthRateLimitMiddleware must be registered before AuthMiddleware, it
+// counts the requests by IP address whether their credentials are valid or not,
+// so guessing tokens or API keys is limited too.
+func PreAuthRateLimitMiddleware(rateLimits ratelimit.Service, cfg config.RateLimitConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !cfg.Enabled {
+			return c.Next()
 		}
-		c.Locals("user_id", int(userID))
 
-		// Check the token in Redis cache
-		tokenCache, err := redcl.Get(context.Background(), constant.JWTPrefix+username).Result()
-		if err != nil {
-			log.Println("Redis error:", err)
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Invalid or expired token"))
+		quota, err := rateLimits.TakePreAuth(c.UserContext(), c.IP())
+		return limitRate(c, cfg, quota, err)
+	}
+}
+
+// limitRate answers the quota taken, or the error taking it.
+func limitRate(c *fiber.Ctx, cfg config.RateLimitConfig, quota *model.RateLimitEntity, err error) error {
+	if err != nil {
+		if cfg.FailOpen {
+			slog.WarnContext(c.UserContext(), "rate limiter unavailable, letting the request through", "error", err)
+			return c.Next()
 		}
 
-		if tokenCache != tokenString {
-			return c.Status(http.StatusUnauthorized).JSON(model.HTTPErrorResponse("Invalid or expired token"))
+		return fiber.NewError(fiber.StatusServiceUnavailable, "rate limiter unavailable")
+	}
+
+	c.Set(headerRateLimitLimit, strconv.Itoa(quota.Limit))
+	c.Set(headerRateLimitRemaining, strconv.Itoa(quota.Remaining))
+	c.Set(headerRateLimitReset, strconv.Itoa(seconds(quota.Reset)))
+	c.Set(headerRateLimitPolicy, fmt.Sprintf("%d;w=%d", quota.Limit, seconds(quota.Window)))
+	if !quota.Allowed {
+		return &model.RateLimitedError{RetryAfter: quota.RetryAfter}
+	}
+
+	return c.Next()
+}
+
+// rateLimitSubject is whose quota a request is taken from.
+func rateLimitSubject(c *fiber.Ctx) string {
+	if userID, ok := c.Locals("user_id").(int); ok {
+		return "user:" + strconv.Itoa(userID)
+	}
+
+	if apiKeyID, ok := c.Locals("api_key_id").(int); ok {
+		return "api-key:" + strconv.Itoa(apiKeyID)
+	}
+
+	return "ip:" + c.IP()
+}
+
+// seconds rounds up, a client waiting that long finds its quota back.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// TimeoutMiddleware cancels the context of the request once the timeout passes,
+// stopping the SQL and Redis calls the handlers make with it.
+func TimeoutMiddleware(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// RequestIDMiddleware keeps the X-Request-ID of the client, or makes one up,
+// answers it back and puts it in the context so every log line of the request
+// carries it.
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := strings.Clone(c.Get(fiber.HeaderXRequestID))
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = utils.GenerateUUID()
 		}
 
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.SetUserContext(logger.WithRequestID(c.UserContext(), requestID))
 		return c.Next()
 	}
 }
+
+// AccessLogMiddleware logs every request once it is answered, with the route
+// it matched, the status, how long it took and who made it. Failed requests
+// are logged as warnings and server errors as errors.
+func AccessLogMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		// answer an error here so the line has the status the client gets
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", c.Route().Path),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("ip", c.IP()),
+		}
+		if userID, ok := c.Locals("user_id").(int); ok {
+			attrs = append(attrs, slog.Int("user_id", userID))
+		}
+		if apiKeyID, ok := c.Locals("api_key_id").(int); ok {
+			attrs = append(attrs, slog.Int("api_key_id", apiKeyID))
+		}
+
+		slog.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
+
+// MetricsMiddleware counts every request once it is answered and observes
+// how long it took, by method, matched route and status.
+func MetricsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// fiber reuses the memory of these strings, the metric keeps them
+		method, route := strings.Clone(c.Method()), strings.Clone(c.Route().Path)
+		metrics.ObserveRequest(method, route, c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// TracingMiddleware records a span for every request, continuing the trace of
+// the traceparent header of the caller, and answers the trace id in X-Trace-ID
+// so a client can report it. Server errors mark the span as failed.
+func TracingMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// fiber reuses the memory of these strings, the span keeps them
+		method, path := strings.Clone(c.Method()), strings.Clone(c.Path())
+
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c: c})
+		ctx, span := tracing.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.request.method", method), attribute.String("url.path", path)))
+		defer span.End()
+
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			c.Set(headerTraceID, traceID)
+		}
+		c.SetUserContext(ctx)
+
+		if err := c.Next(); err != nil {
+			span.RecordError(err)
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		route := strings.Clone(c.Route().Path)
+		span.SetName(method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route), attribute.Int("http.response.status_code", status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "server error")
+		}
+
+		return nil
+	}
+}
+
+// headerCarrier reads the trace context from the headers of the request.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return strings.Clone(h.c.Get(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0)
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+
+	return keys
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/zakiyalmaya/online-store/infrastructure/migration"
+)
+
+const migrateUsage = `usage: online_store_app migrate <command>
+
+commands:
+  up            apply every pending migration
+  down [steps]  roll back the last steps migrations, 1 by default
+  status        list the migrations and whether they are applied
+  unlock        release the lock of a migrate run that crashed`
+
+// runMigrate is the migrate command, args are the arguments after "migrate".
+func runMigrate(migrator migration.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if len(applied) == 0 && err == nil {
+			fmt.Println("No pending migrations")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+			steps = n
+		}
+
+		rolledBack, err := migrator.Down(steps)
+		if len(rolledBack) == 0 && err == nil {
+			fmt.Println("No applied migrations")
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+		}
+		return w.Flush()
+
+	case "unlock":
+		if err := migrator.Unlock(); err != nil {
+			return err
+		}
+		fmt.Println("Migration lock released")
+		return nil
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,45 +36,89 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockService) Create(request *model.CreateCartRequest) (*model.CartResponse, error) {
+func (m *MockService) Create(ctx context.Context, request *model.CreateCartRequest) (*model.CartResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", request)
+	ret := m.ctrl.Call(m, "Create", ctx, request)
 	ret0, _ := ret[0].(*model.CartResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockServiceMockRecorder) Create(request interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Create(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, request)
+}
+
+// CreateGuest mocks base method.
+func (m *MockService) CreateGuest(ctx context.Context, request *model.GuestCartRequest) (*model.GuestCartResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGuest", ctx, request)
+	ret0, _ := ret[0].(*model.GuestCartResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGuest indicates an expected call of CreateGuest.
+func (mr *MockServiceMockRecorder) CreateGuest(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGuest", reflect.TypeOf((*MockService)(nil).CreateGuest), ctx, request)
 }
 
 // Delete mocks base method.
-func (m *MockService) Delete(request *model.DeleteCartRequest) error {
+func (m *MockService) Delete(ctx context.Context, request *model.DeleteCartRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", request)
+	ret := m.ctrl.Call(m, "Delete", ctx, request)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockServiceMockRecorder) Delete(request interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, request)
+}
+
+// DeleteGuest mocks base method.
+func (m *MockService) DeleteGuest(ctx context.Context, request *model.DeleteGuestCartRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGuest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGuest indicates an expected call of DeleteGuest.
+func (mr *MockServiceMockRecorder) DeleteGuest(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGuest", reflect.TypeOf((*MockService)(nil).DeleteGuest), ctx, request)
 }
 
 // GetByParams mocks base method.
-func (m *MockService) GetByParams(request *model.GetCartRequest) ([]*model.CartResponse, error) {
+func (m *MockService) GetByParams(ctx context.Context, request *model.GetCartRequest) ([]*model.CartResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByParams", request)
+	ret := m.ctrl.Call(m, "GetByParams", ctx, request)
 	ret0, _ := ret[0].([]*model.CartResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByParams indicates an expected call of GetByParams.
-func (mr *MockServiceMockRecorder) GetByParams(request interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetByParams(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByParams", reflect.TypeOf((*MockService)(nil).GetByParams), ctx, request)
+}
+
+// GetGuest mocks base method.
+func (m *MockService) GetGuest(ctx context.Context, token string) (*model.GuestCartResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuest", ctx, token)
+	ret0, _ := ret[0].(*model.GuestCartResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuest indicates an expected call of GetGuest.
+func (mr *MockServiceMockRecorder) GetGuest(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByParams", reflect.TypeOf((*MockService)(nil).GetByParams), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuest", reflect.TypeOf((*MockService)(nil).GetGuest), ctx, token)
 }
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,30 +36,145 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockService) Create(request *model.CreateProductRequest) error {
+func (m *MockService) Create(ctx context.Context, request *model.CreateProductRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", request)
+	ret := m.ctrl.Call(m, "Create", ctx, request)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockServiceMockRecorder) Create(request interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Create(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, request)
+}
+
+// CreateOption mocks base method.
+func (m *MockService) CreateOption(ctx context.Context, request *model.CreateProductOptionRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOption", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOption indicates an expected call of CreateOption.
+func (mr *MockServiceMockRecorder) CreateOption(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOption", reflect.TypeOf((*MockService)(nil).CreateOption), ctx, request)
+}
+
+// CreateVariant mocks base method.
+func (m *MockService) CreateVariant(ctx context.Context, request *model.CreateProductVariantRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockServiceMockRecorder) CreateVariant(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*MockService)(nil).CreateVariant), ctx, request)
+}
+
+// DeleteImage mocks base method.
+func (m *MockService) DeleteImage(ctx context.Context, request *model.ProductImageRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockServiceMockRecorder) DeleteImage(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockService)(nil).DeleteImage), ctx, request)
 }
 
 // GetAll mocks base method.
-func (m *MockService) GetAll(request *model.GetProductRequest) ([]*model.ProductResponse, error) {
+func (m *MockService) GetAll(ctx context.Context, request *model.GetProductRequest) ([]*model.ProductResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", request)
+	ret := m.ctrl.Call(m, "GetAll", ctx, request)
 	ret0, _ := ret[0].([]*model.ProductResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockServiceMockRecorder) GetAll(request interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetAll(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockService)(nil).GetAll), ctx, request)
+}
+
+// GetImages mocks base method.
+func (m *MockService) GetImages(ctx context.Context, productID int) ([]*model.ProductImageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImages", ctx, productID)
+	ret0, _ := ret[0].([]*model.ProductImageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImages indicates an expected call of GetImages.
+func (mr *MockServiceMockRecorder) GetImages(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImages", reflect.TypeOf((*MockService)(nil).GetImages), ctx, productID)
+}
+
+// GetVariants mocks base method.
+func (m *MockService) GetVariants(ctx context.Context, productID int) (*model.ProductVariantsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", ctx, productID)
+	ret0, _ := ret[0].(*model.ProductVariantsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariants indicates an expected call of GetVariants.
+func (mr *MockServiceMockRecorder) GetVariants(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*MockService)(nil).GetVariants), ctx, productID)
+}
+
+// ReorderImages mocks base method.
+func (m *MockService) ReorderImages(ctx context.Context, request *model.ReorderProductImagesRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderImages", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderImages indicates an expected call of ReorderImages.
+func (mr *MockServiceMockRecorder) ReorderImages(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderImages", reflect.TypeOf((*MockService)(nil).ReorderImages), ctx, request)
+}
+
+// SetPrimaryImage mocks base method.
+func (m *MockService) SetPrimaryImage(ctx context.Context, request *model.ProductImageRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrimaryImage", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPrimaryImage indicates an expected call of SetPrimaryImage.
+func (mr *MockServiceMockRecorder) SetPrimaryImage(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrimaryImage", reflect.TypeOf((*MockService)(nil).SetPrimaryImage), ctx, request)
+}
+
+// UploadImage mocks base method.
+func (m *MockService) UploadImage(ctx context.Context, request *model.UploadProductImageRequest) (*model.ProductImageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadImage", ctx, request)
+	ret0, _ := ret[0].(*model.ProductImageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadImage indicates an expected call of UploadImage.
+func (mr *MockServiceMockRecorder) UploadImage(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockService)(nil).GetAll), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadImage", reflect.TypeOf((*MockService)(nil).UploadImage), ctx, request)
 }
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/zakiyalmaya/online-store/model"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Take mocks base method.
+func (m *MockService) Take(ctx context.Context, route, subject string) (*model.RateLimitEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", ctx, route, subject)
+	ret0, _ := ret[0].(*model.RateLimitEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Take indicates an expected call of Take.
+func (mr *MockServiceMockRecorder) Take(ctx, route, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*MockService)(nil).Take), ctx, route, subject)
+}
+
+// TakePreAuth mocks base method.
+func (m *MockService) TakePreAuth(ctx context.Context, ip string) (*model.RateLimitEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakePreAuth", ctx, ip)
+	ret0, _ := ret[0].(*model.RateLimitEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakePreAuth indicates an expected call of TakePreAuth.
+func (mr *MockServiceMockRecorder) TakePreAuth(ctx, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakePreAuth", reflect.TypeOf((*MockService)(nil).TakePreAuth), ctx, ip)
+}
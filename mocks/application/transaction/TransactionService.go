@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,31 +36,45 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Checkout mocks base method.
-func (m *MockService) Checkout(request *model.TransactionRequest) (*model.TransactionResponse, error) {
+func (m *MockService) Checkout(ctx context.Context, request *model.TransactionRequest) (*model.TransactionResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Checkout", request)
+	ret := m.ctrl.Call(m, "Checkout", ctx, request)
 	ret0, _ := ret[0].(*model.TransactionResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Checkout indicates an expected call of Checkout.
-func (mr *MockServiceMockRecorder) Checkout(request interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Checkout(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockService)(nil).Checkout), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockService)(nil).Checkout), ctx, request)
 }
 
 // GetByID mocks base method.
-func (m *MockService) GetByID(id int) (*model.TransactionResponse, error) {
+func (m *MockService) GetByID(ctx context.Context, id, customerID int) (*model.TransactionResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", id)
+	ret := m.ctrl.Call(m, "GetByID", ctx, id, customerID)
 	ret0, _ := ret[0].(*model.TransactionResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockServiceMockRecorder) GetByID(id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetByID(ctx, id, customerID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockService)(nil).GetByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockService)(nil).GetByID), ctx, id, customerID)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockService) UpdateStatus(ctx context.Context, request *model.UpdateTransactionStatusRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockServiceMockRecorder) UpdateStatus(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockService)(nil).UpdateStatus), ctx, request)
 }
@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/zakiyalmaya/online-store/model"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockService) Create(ctx context.Context, request *model.WishlistRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockServiceMockRecorder) Create(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, request)
+}
+
+// Delete mocks base method.
+func (m *MockService) Delete(ctx context.Context, customerID, productID, productVariantID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, customerID, productID, productVariantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockServiceMockRecorder) Delete(ctx, customerID, productID, productVariantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, customerID, productID, productVariantID)
+}
+
+// GetByCustomerID mocks base method.
+func (m *MockService) GetByCustomerID(ctx context.Context, customerID int) ([]*model.WishlistResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCustomerID", ctx, customerID)
+	ret0, _ := ret[0].([]*model.WishlistResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCustomerID indicates an expected call of GetByCustomerID.
+func (mr *MockServiceMockRecorder) GetByCustomerID(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCustomerID", reflect.TypeOf((*MockService)(nil).GetByCustomerID), ctx, customerID)
+}
+
+// GetNotifications mocks base method.
+func (m *MockService) GetNotifications(ctx context.Context, customerID int) ([]*model.WishlistNotificationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, customerID)
+	ret0, _ := ret[0].([]*model.WishlistNotificationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockServiceMockRecorder) GetNotifications(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockService)(nil).GetNotifications), ctx, customerID)
+}
+
+// MoveToCart mocks base method.
+func (m *MockService) MoveToCart(ctx context.Context, request *model.MoveToCartRequest) (*model.CartResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveToCart", ctx, request)
+	ret0, _ := ret[0].(*model.CartResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveToCart indicates an expected call of MoveToCart.
+func (mr *MockServiceMockRecorder) MoveToCart(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveToCart", reflect.TypeOf((*MockService)(nil).MoveToCart), ctx, request)
+}
+
+// NotifyChanges mocks base method.
+func (m *MockService) NotifyChanges(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyChanges", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyChanges indicates an expected call of NotifyChanges.
+func (mr *MockServiceMockRecorder) NotifyChanges(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChanges", reflect.TypeOf((*MockService)(nil).NotifyChanges), ctx)
+}
+
+// SaveForLater mocks base method.
+func (m *MockService) SaveForLater(ctx context.Context, request *model.SaveForLaterRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveForLater", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveForLater indicates an expected call of SaveForLater.
+func (mr *MockServiceMockRecorder) SaveForLater(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveForLater", reflect.TypeOf((*MockService)(nil).SaveForLater), ctx, request)
+}
@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	cart "github.com/zakiyalmaya/online-store/constant/cart"
 	model "github.com/zakiyalmaya/online-store/model"
 )
 
@@ -35,90 +37,104 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockRepository) Create(cart *model.CartEntity) (*model.CartEntity, error) {
+func (m *MockRepository) Create(ctx context.Context, cart *model.CartEntity) (*model.CartEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", cart)
+	ret := m.ctrl.Call(m, "Create", ctx, cart)
 	ret0, _ := ret[0].(*model.CartEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryMockRecorder) Create(cart interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Create(ctx, cart interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), cart)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, cart)
 }
 
 // Delete mocks base method.
-func (m *MockRepository) Delete(request *model.DeleteCartRequest) error {
+func (m *MockRepository) Delete(ctx context.Context, request *model.DeleteCartRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", request)
+	ret := m.ctrl.Call(m, "Delete", ctx, request)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockRepositoryMockRecorder) Delete(request interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Delete(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, request)
 }
 
 // GetByID mocks base method.
-func (m *MockRepository) GetByID(cartID int) (*model.CartEntity, error) {
+func (m *MockRepository) GetByID(ctx context.Context, cartID int) (*model.CartEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", cartID)
+	ret := m.ctrl.Call(m, "GetByID", ctx, cartID)
 	ret0, _ := ret[0].(*model.CartEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockRepositoryMockRecorder) GetByID(cartID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetByID(ctx, cartID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), cartID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, cartID)
 }
 
 // GetByParams mocks base method.
-func (m *MockRepository) GetByParams(request *model.GetCartRequest) ([]*model.CartEntity, error) {
+func (m *MockRepository) GetByParams(ctx context.Context, request *model.GetCartRequest) ([]*model.CartEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByParams", request)
+	ret := m.ctrl.Call(m, "GetByParams", ctx, request)
 	ret0, _ := ret[0].([]*model.CartEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByParams indicates an expected call of GetByParams.
-func (mr *MockRepositoryMockRecorder) GetByParams(request interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetByParams(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByParams", reflect.TypeOf((*MockRepository)(nil).GetByParams), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByParams", reflect.TypeOf((*MockRepository)(nil).GetByParams), ctx, request)
 }
 
 // GetItemByID mocks base method.
-func (m *MockRepository) GetItemByID(cartItemID int) (*model.CartItemEntity, error) {
+func (m *MockRepository) GetItemByID(ctx context.Context, cartItemID int) (*model.CartItemEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetItemByID", cartItemID)
+	ret := m.ctrl.Call(m, "GetItemByID", ctx, cartItemID)
 	ret0, _ := ret[0].(*model.CartItemEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetItemByID indicates an expected call of GetItemByID.
-func (mr *MockRepositoryMockRecorder) GetItemByID(cartItemID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetItemByID(ctx, cartItemID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemByID", reflect.TypeOf((*MockRepository)(nil).GetItemByID), cartItemID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemByID", reflect.TypeOf((*MockRepository)(nil).GetItemByID), ctx, cartItemID)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockRepository) UpdateStatus(ctx context.Context, cartID int, status cart.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, cartID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockRepositoryMockRecorder) UpdateStatus(ctx, cartID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRepository)(nil).UpdateStatus), ctx, cartID, status)
 }
 
 // Upsert mocks base method.
-func (m *MockRepository) Upsert(cartID int, items []*model.CartItemEntity) (*model.CartEntity, error) {
+func (m *MockRepository) Upsert(ctx context.Context, cartID int, items []*model.CartItemEntity) (*model.CartEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Upsert", cartID, items)
+	ret := m.ctrl.Call(m, "Upsert", ctx, cartID, items)
 	ret0, _ := ret[0].(*model.CartEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Upsert indicates an expected call of Upsert.
-func (mr *MockRepositoryMockRecorder) Upsert(cartID, items interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Upsert(ctx, cartID, items interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockRepository)(nil).Upsert), cartID, items)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockRepository)(nil).Upsert), ctx, cartID, items)
 }
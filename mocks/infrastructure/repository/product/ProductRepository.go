@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,45 +36,205 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockRepository) Create(product *model.ProductEntity) error {
+func (m *MockRepository) Create(ctx context.Context, product *model.ProductEntity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", product)
+	ret := m.ctrl.Call(m, "Create", ctx, product)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryMockRecorder) Create(product interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Create(ctx, product interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), product)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, product)
+}
+
+// CreateImage mocks base method.
+func (m *MockRepository) CreateImage(ctx context.Context, image *model.ProductImageEntity) (*model.ProductImageEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImage", ctx, image)
+	ret0, _ := ret[0].(*model.ProductImageEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImage indicates an expected call of CreateImage.
+func (mr *MockRepositoryMockRecorder) CreateImage(ctx, image interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockRepository)(nil).CreateImage), ctx, image)
+}
+
+// CreateOption mocks base method.
+func (m *MockRepository) CreateOption(ctx context.Context, option *model.ProductOptionEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOption", ctx, option)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOption indicates an expected call of CreateOption.
+func (mr *MockRepositoryMockRecorder) CreateOption(ctx, option interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOption", reflect.TypeOf((*MockRepository)(nil).CreateOption), ctx, option)
+}
+
+// CreateVariant mocks base method.
+func (m *MockRepository) CreateVariant(ctx context.Context, variant *model.ProductVariantEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockRepositoryMockRecorder) CreateVariant(ctx, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*MockRepository)(nil).CreateVariant), ctx, variant)
+}
+
+// DeleteImage mocks base method.
+func (m *MockRepository) DeleteImage(ctx context.Context, image *model.ProductImageEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", ctx, image)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockRepositoryMockRecorder) DeleteImage(ctx, image interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockRepository)(nil).DeleteImage), ctx, image)
 }
 
 // GetAll mocks base method.
-func (m *MockRepository) GetAll(request *model.GetProductRequest) ([]*model.ProductResponse, error) {
+func (m *MockRepository) GetAll(ctx context.Context, request *model.GetProductRequest) ([]*model.ProductResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", request)
+	ret := m.ctrl.Call(m, "GetAll", ctx, request)
 	ret0, _ := ret[0].([]*model.ProductResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockRepositoryMockRecorder) GetAll(request interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetAll(ctx, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), ctx, request)
 }
 
 // GetByID mocks base method.
-func (m *MockRepository) GetByID(id int) (*model.ProductEntity, error) {
+func (m *MockRepository) GetByID(ctx context.Context, id int) (*model.ProductEntity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", id)
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
 	ret0, _ := ret[0].(*model.ProductEntity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockRepositoryMockRecorder) GetByID(id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, id)
+}
+
+// GetImageByID mocks base method.
+func (m *MockRepository) GetImageByID(ctx context.Context, id int) (*model.ProductImageEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageByID", ctx, id)
+	ret0, _ := ret[0].(*model.ProductImageEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageByID indicates an expected call of GetImageByID.
+func (mr *MockRepositoryMockRecorder) GetImageByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageByID", reflect.TypeOf((*MockRepository)(nil).GetImageByID), ctx, id)
+}
+
+// GetImages mocks base method.
+func (m *MockRepository) GetImages(ctx context.Context, productIDs []int) ([]*model.ProductImageEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImages", ctx, productIDs)
+	ret0, _ := ret[0].([]*model.ProductImageEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImages indicates an expected call of GetImages.
+func (mr *MockRepositoryMockRecorder) GetImages(ctx, productIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImages", reflect.TypeOf((*MockRepository)(nil).GetImages), ctx, productIDs)
+}
+
+// GetOptions mocks base method.
+func (m *MockRepository) GetOptions(ctx context.Context, productID int) ([]*model.ProductOptionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOptions", ctx, productID)
+	ret0, _ := ret[0].([]*model.ProductOptionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOptions indicates an expected call of GetOptions.
+func (mr *MockRepositoryMockRecorder) GetOptions(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOptions", reflect.TypeOf((*MockRepository)(nil).GetOptions), ctx, productID)
+}
+
+// GetVariantByID mocks base method.
+func (m *MockRepository) GetVariantByID(ctx context.Context, id int) (*model.ProductVariantEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantByID", ctx, id)
+	ret0, _ := ret[0].(*model.ProductVariantEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariantByID indicates an expected call of GetVariantByID.
+func (mr *MockRepositoryMockRecorder) GetVariantByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantByID", reflect.TypeOf((*MockRepository)(nil).GetVariantByID), ctx, id)
+}
+
+// GetVariants mocks base method.
+func (m *MockRepository) GetVariants(ctx context.Context, productID int) ([]*model.ProductVariantEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", ctx, productID)
+	ret0, _ := ret[0].([]*model.ProductVariantEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariants indicates an expected call of GetVariants.
+func (mr *MockRepositoryMockRecorder) GetVariants(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*MockRepository)(nil).GetVariants), ctx, productID)
+}
+
+// ReorderImages mocks base method.
+func (m *MockRepository) ReorderImages(ctx context.Context, productID int, imageIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderImages", ctx, productID, imageIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderImages indicates an expected call of ReorderImages.
+func (mr *MockRepositoryMockRecorder) ReorderImages(ctx, productID, imageIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderImages", reflect.TypeOf((*MockRepository)(nil).ReorderImages), ctx, productID, imageIDs)
+}
+
+// SetPrimaryImage mocks base method.
+func (m *MockRepository) SetPrimaryImage(ctx context.Context, productID, imageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrimaryImage", ctx, productID, imageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPrimaryImage indicates an expected call of SetPrimaryImage.
+func (mr *MockRepositoryMockRecorder) SetPrimaryImage(ctx, productID, imageID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrimaryImage", reflect.TypeOf((*MockRepository)(nil).SetPrimaryImage), ctx, productID, imageID)
 }
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/zakiyalmaya/online-store/model"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SlidingWindow mocks base method.
+func (m *MockRepository) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (*model.RateLimitEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlidingWindow", ctx, key, limit, window)
+	ret0, _ := ret[0].(*model.RateLimitEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SlidingWindow indicates an expected call of SlidingWindow.
+func (mr *MockRepositoryMockRecorder) SlidingWindow(ctx, key, limit, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlidingWindow", reflect.TypeOf((*MockRepository)(nil).SlidingWindow), ctx, key, limit, window)
+}
+
+// TokenBucket mocks base method.
+func (m *MockRepository) TokenBucket(ctx context.Context, key string, limit int, window time.Duration) (*model.RateLimitEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenBucket", ctx, key, limit, window)
+	ret0, _ := ret[0].(*model.RateLimitEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TokenBucket indicates an expected call of TokenBucket.
+func (mr *MockRepositoryMockRecorder) TokenBucket(ctx, key, limit, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenBucket", reflect.TypeOf((*MockRepository)(nil).TokenBucket), ctx, key, limit, window)
+}
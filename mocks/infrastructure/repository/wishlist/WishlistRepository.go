@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/zakiyalmaya/online-store/model"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(ctx context.Context, wishlist *model.WishlistEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, wishlist)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(ctx, wishlist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, wishlist)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(ctx context.Context, customerID, productID, productVariantID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, customerID, productID, productVariantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(ctx, customerID, productID, productVariantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, customerID, productID, productVariantID)
+}
+
+// GetByCustomerID mocks base method.
+func (m *MockRepository) GetByCustomerID(ctx context.Context, customerID int) ([]*model.WishlistEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCustomerID", ctx, customerID)
+	ret0, _ := ret[0].([]*model.WishlistEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCustomerID indicates an expected call of GetByCustomerID.
+func (mr *MockRepositoryMockRecorder) GetByCustomerID(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCustomerID", reflect.TypeOf((*MockRepository)(nil).GetByCustomerID), ctx, customerID)
+}
+
+// GetByProductID mocks base method.
+func (m *MockRepository) GetByProductID(ctx context.Context, customerID, productID, productVariantID int) (*model.WishlistEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProductID", ctx, customerID, productID, productVariantID)
+	ret0, _ := ret[0].(*model.WishlistEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProductID indicates an expected call of GetByProductID.
+func (mr *MockRepositoryMockRecorder) GetByProductID(ctx, customerID, productID, productVariantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProductID", reflect.TypeOf((*MockRepository)(nil).GetByProductID), ctx, customerID, productID, productVariantID)
+}
+
+// GetNotifiable mocks base method.
+func (m *MockRepository) GetNotifiable(ctx context.Context) ([]*model.WishlistEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifiable", ctx)
+	ret0, _ := ret[0].([]*model.WishlistEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifiable indicates an expected call of GetNotifiable.
+func (mr *MockRepositoryMockRecorder) GetNotifiable(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifiable", reflect.TypeOf((*MockRepository)(nil).GetNotifiable), ctx)
+}
+
+// GetNotifications mocks base method.
+func (m *MockRepository) GetNotifications(ctx context.Context, customerID int) ([]*model.WishlistNotificationEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, customerID)
+	ret0, _ := ret[0].([]*model.WishlistNotificationEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockRepositoryMockRecorder) GetNotifications(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockRepository)(nil).GetNotifications), ctx, customerID)
+}
+
+// UpdateSnapshot mocks base method.
+func (m *MockRepository) UpdateSnapshot(ctx context.Context, wishlist *model.WishlistEntity, notifications []*model.WishlistNotificationEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSnapshot", ctx, wishlist, notifications)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSnapshot indicates an expected call of UpdateSnapshot.
+func (mr *MockRepositoryMockRecorder) UpdateSnapshot(ctx, wishlist, notifications interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSnapshot", reflect.TypeOf((*MockRepository)(nil).UpdateSnapshot), ctx, wishlist, notifications)
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	apikeyEnum "github.com/zakiyalmaya/online-store/constant/apikey"
+)
+
+// APIKeyEntity is a key of a machine client. Only the hash of the key is
+// stored, the Prefix is kept in clear to tell the keys apart.
+type APIKeyEntity struct {
+	ID         int        `db:"id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	Scopes     string     `db:"scopes"`
+	CreatedBy  int        `db:"created_by"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// ScopeList splits the comma separated scopes of the key.
+func (a *APIKeyEntity) ScopeList() []apikeyEnum.Scope {
+	scopes := make([]apikeyEnum.Scope, 0)
+	for _, scope := range strings.Split(a.Scopes, ",") {
+		if scope != "" {
+			scopes = append(scopes, apikeyEnum.Scope(scope))
+		}
+	}
+
+	return scopes
+}
+
+func (a *APIKeyEntity) HasScope(scope apikeyEnum.Scope) bool {
+	for _, s := range a.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *APIKeyEntity) ToResponse() *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         a.ID,
+		Name:       a.Name,
+		Prefix:     a.Prefix,
+		Scopes:     a.ScopeList(),
+		ExpiresAt:  a.ExpiresAt,
+		LastUsedAt: a.LastUsedAt,
+		RevokedAt:  a.RevokedAt,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string             `json:"name" validate:"required,max=255"`
+	Scopes    []apikeyEnum.Scope `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty"`
+	CreatedBy int                `json:"-"`
+}
+
+// APIKeyResponse only holds the Key when the key is created, it can not be read back.
+type APIKeyResponse struct {
+	ID         int                `json:"id"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	Key        string             `json:"key,omitempty"`
+	Scopes     []apikeyEnum.Scope `json:"scopes"`
+	ExpiresAt  *time.Time         `json:"expires_at"`
+	LastUsedAt *time.Time         `json:"last_used_at"`
+	RevokedAt  *time.Time         `json:"revoked_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
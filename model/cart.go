@@ -17,14 +17,16 @@ type CartEntity struct {
 }
 
 type CartItemEntity struct {
-	ID          int             `db:"id"`
-	ProductID   int             `db:"product_id"`
-	Quantity    int             `db:"quantity"`
-	CartID      int             `db:"shopping_cart_id"`
-	CreatedAt   time.Time       `db:"created_at"`
-	UpdatedAt   time.Time       `db:"updated_at"`
-	Price       decimal.Decimal `db:"price"`
-	ProductName string          `db:"product_name"`
+	ID               int             `db:"id"`
+	ProductID        int             `db:"product_id"`
+	ProductVariantID int             `db:"product_variant_id"`
+	Quantity         int             `db:"quantity"`
+	CartID           int             `db:"shopping_cart_id"`
+	CreatedAt        time.Time       `db:"created_at"`
+	UpdatedAt        time.Time       `db:"updated_at"`
+	Price            decimal.Decimal `db:"price"`
+	ProductName      string          `db:"product_name"`
+	SKU              string          `db:"sku"`
 }
 
 type GetCartRequest struct {
@@ -38,9 +40,10 @@ type CreateCartRequest struct {
 }
 
 type CreateCartItemRequest struct {
-	ProductID int             `json:"product_id" validate:"required"`
-	Quantity  int             `json:"quantity" validate:"required"`
-	Price     decimal.Decimal `json:"price"`
+	ProductID        int             `json:"product_id" validate:"required"`
+	ProductVariantID int             `json:"product_variant_id,omitempty"`
+	Quantity         int             `json:"quantity" validate:"required"`
+	Price            decimal.Decimal `json:"price"`
 }
 
 type CartResponse struct {
@@ -51,11 +54,29 @@ type CartResponse struct {
 }
 
 type CartItemResponse struct {
-	ID          int     `json:"id"`
-	ProductID   int     `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
+	ID               int     `json:"id"`
+	ProductID        int     `json:"product_id"`
+	ProductVariantID int     `json:"product_variant_id,omitempty"`
+	SKU              string  `json:"sku,omitempty"`
+	ProductName      string  `json:"product_name"`
+	Quantity         int     `json:"quantity"`
+	Price            float64 `json:"price"`
+}
+
+type GuestCartRequest struct {
+	Token string                   `json:"-"`
+	Items []*CreateCartItemRequest `json:"items" validate:"required"`
+}
+
+type GuestCartResponse struct {
+	Token string              `json:"cart_token"`
+	Items []*CartItemResponse `json:"items"`
+}
+
+type DeleteGuestCartRequest struct {
+	Token            string
+	ProductID        int
+	ProductVariantID int
 }
 
 type DeleteCartRequest struct {
@@ -69,9 +90,10 @@ func (c *CreateCartRequest) ToEntity() *CartEntity {
 	cartItemEntity := make([]*CartItemEntity, len(c.Items))
 	for i, item := range c.Items {
 		cartItemEntity[i] = &CartItemEntity{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			Price:     item.Price,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+			Price:            item.Price,
 		}
 	}
 
@@ -86,11 +108,13 @@ func (c *CartEntity) ToResponse() *CartResponse {
 	cartItemResponse := make([]*CartItemResponse, len(c.Items))
 	for i, item := range c.Items {
 		cartItemResponse[i] = &CartItemResponse{
-			ID:          item.ID,
-			ProductID:   item.ProductID,
-			ProductName: item.ProductName,
-			Quantity:    item.Quantity,
-			Price:       item.Price.InexactFloat64(),
+			ID:               item.ID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			SKU:              item.SKU,
+			ProductName:      item.ProductName,
+			Quantity:         item.Quantity,
+			Price:            item.Price.InexactFloat64(),
 		}
 	}
 
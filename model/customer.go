@@ -3,52 +3,158 @@ package model
 import (
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	customerEnum "github.com/zakiyalmaya/online-store/constant/customer"
 )
 
+// CustomerEntity has a TOTPSecret from the start of the two-factor enrollment,
+// TwoFactorEnabled is only set once the customer confirmed it with a code.
 type CustomerEntity struct {
-	ID          int       `db:"id"`
-	Name        string    `db:"name"`
-	Username    string    `db:"username"`
-	Email       string    `db:"email"`
-	Password    string    `db:"password"`
-	Address     string    `db:"address"`
-	PhoneNumber string    `db:"phone_number"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID               int       `db:"id"`
+	Name             string    `db:"name"`
+	Username         string    `db:"username"`
+	Email            string    `db:"email"`
+	Password         string    `db:"password"`
+	Address          string    `db:"address"`
+	PhoneNumber      string    `db:"phone_number"`
+	IsAdmin          bool      `db:"is_admin"`
+	EmailVerified    bool      `db:"email_verified"`
+	TOTPSecret       string    `db:"totp_secret"`
+	TwoFactorEnabled bool      `db:"two_factor_enabled"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
 }
 
+// CustomerRequest only checks the format, the password policy is configurable
+// and checked by the service.
 type CustomerRequest struct {
-	Name        string `json:"name" validate:"required"`
-	Username    string `json:"username" validate:"required"`
-	Email       string `json:"email" validate:"required"`
+	Name        string `json:"name" validate:"required,max=255"`
+	Username    string `json:"username" validate:"required,min=3,max=50,username"`
+	Email       string `json:"email" validate:"required,max=255,email"`
 	Password    string `json:"password" validate:"required"`
-	PhoneNumber string `json:"phone_number" validate:"required"`
-	Address     string `json:"address" validate:"required"`
+	PhoneNumber string `json:"phone_number" validate:"required,phone"`
+	Address     string `json:"address" validate:"required,max=1000"`
+}
+
+func (c *CustomerEntity) ToResponse() *CustomerResponse {
+	return &CustomerResponse{
+		ID:               c.ID,
+		Name:             c.Name,
+		Username:         c.Username,
+		Email:            c.Email,
+		PhoneNumber:      c.PhoneNumber,
+		Address:          c.Address,
+		EmailVerified:    c.EmailVerified,
+		TwoFactorEnabled: c.TwoFactorEnabled,
+	}
+}
+
+type UpdateCustomerRequest struct {
+	CustomerID  int    `json:"customer_id" validate:"required"`
+	Name        string `json:"name" validate:"required,max=255"`
+	Email       string `json:"email" validate:"required,max=255,email"`
+	PhoneNumber string `json:"phone_number" validate:"required,phone"`
+	Address     string `json:"address" validate:"required,max=1000"`
+}
+
+type ChangePasswordRequest struct {
+	CustomerID      int    `json:"customer_id" validate:"required"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,nefield=CurrentPassword"`
+}
+
+type DeleteCustomerRequest struct {
+	CustomerID int    `json:"customer_id" validate:"required"`
+	Password   string `json:"password" validate:"required"`
 }
 
 type CustomerResponse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Username    string `json:"username"`
-	Email       string `json:"email"`
-	PhoneNumber string `json:"phone_number"`
-	Address     string `json:"address"`
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	Username         string `json:"username"`
+	Email            string `json:"email"`
+	PhoneNumber      string `json:"phone_number"`
+	Address          string `json:"address"`
+	EmailVerified    bool   `json:"email_verified"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
 }
 
 type AuthRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username  string `json:"username" validate:"required"`
+	Password  string `json:"password" validate:"required"`
+	CartToken string `json:"cart_token,omitempty"`
+	Device    string `json:"-"`
+	IPAddress string `json:"-"`
 }
 
+// AuthResponse of an account with two-factor authentication only holds the
+// challenge token, the tokens are issued once the code is checked.
 type AuthResponse struct {
-	Username string `json:"username"`
-	Name     string `json:"name"`
-	Token    string `json:"token"`
+	Username          string `json:"username"`
+	Name              string `json:"name"`
+	Token             string `json:"token,omitempty"`
+	ExpiresIn         int    `json:"expires_in,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+// TwoFactorLoginRequest answers the challenge of a login with a code of the
+// authenticator app or one of the recovery codes.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	Device         string `json:"-"`
+	IPAddress      string `json:"-"`
 }
 
 type AuthClaims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	jwt.StandardClaims
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	IsAdmin   bool   `json:"is_admin,omitempty"`
+	SessionID string `json:"sid"`
+}
+
+// CustomerTokenEntity is a single use token mailed to the customer, only its hash is stored.
+type CustomerTokenEntity struct {
+	ID         int                    `db:"id"`
+	CustomerID int                    `db:"customer_id"`
+	Type       customerEnum.TokenType `db:"type"`
+	TokenHash  string                 `db:"token_hash"`
+	Email      string                 `db:"email"`
+	ExpiresAt  time.Time              `db:"expires_at"`
+	UsedAt     *time.Time             `db:"used_at"`
+	CreatedAt  time.Time              `db:"created_at"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	CustomerID int    `json:"customer_id" validate:"required"`
+	Code       string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse is the only time the recovery codes are shown, only their hashes are stored.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableTwoFactorRequest struct {
+	CustomerID int    `json:"customer_id" validate:"required"`
+	Password   string `json:"password" validate:"required"`
 }
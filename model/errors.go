@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+// ErrorKind is what went wrong from the client's point of view, the transport
+// answers each kind with its own status.
+type ErrorKind int
+
+const (
+	// ErrorKindValidation is a malformed request or a field breaking a rule.
+	ErrorKindValidation ErrorKind = iota + 1
+	// ErrorKindUnauthorized is a request with missing or wrong credentials.
+	ErrorKindUnauthorized
+	// ErrorKindForbidden is a request for something the client may not touch.
+	ErrorKindForbidden
+	// ErrorKindNotFound is a request for a record that does not exist.
+	ErrorKindNotFound
+	// ErrorKindConflict is a write colliding with an existing record.
+	ErrorKindConflict
+	// ErrorKindUnprocessable is a well formed request the current state of the
+	// records does not allow, e.g. checking out a cart that is not active.
+	ErrorKindUnprocessable
+	// ErrorKindTooLarge is an upload over the allowed size.
+	ErrorKindTooLarge
+	// ErrorKindUnsupportedMediaType is an upload of a type that is not accepted.
+	ErrorKindUnsupportedMediaType
+)
+
+// Error is an error the client can act on. Code names it for programs and
+// stays the same when Message is reworded, Fields lists the request fields to
+// fix. Err is the cause, it is logged but never shown to the client.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  utils.FieldErrors
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of the error caused by err.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithFields returns a copy of the error listing the fields to fix.
+func (e *Error) WithFields(fields utils.FieldErrors) *Error {
+	withFields := *e
+	withFields.Fields = fields
+	return &withFields
+}
+
+// ErrInvalidRequestBody is returned for a body that can not be parsed.
+var ErrInvalidRequestBody = NewValidationError("invalid_request_body", "invalid request body")
+
+func NewValidationError(code, message string) *Error {
+	return &Error{Kind: ErrorKindValidation, Code: code, Message: message}
+}
+
+func NewUnauthorizedError(code, message string) *Error {
+	return &Error{Kind: ErrorKindUnauthorized, Code: code, Message: message}
+}
+
+func NewForbiddenError(code, message string) *Error {
+	return &Error{Kind: ErrorKindForbidden, Code: code, Message: message}
+}
+
+func NewNotFoundError(code, message string) *Error {
+	return &Error{Kind: ErrorKindNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code, message string) *Error {
+	return &Error{Kind: ErrorKindConflict, Code: code, Message: message}
+}
+
+func NewUnprocessableError(code, message string) *Error {
+	return &Error{Kind: ErrorKindUnprocessable, Code: code, Message: message}
+}
+
+func NewTooLargeError(code, message string) *Error {
+	return &Error{Kind: ErrorKindTooLarge, Code: code, Message: message}
+}
+
+func NewUnsupportedMediaTypeError(code, message string) *Error {
+	return &Error{Kind: ErrorKindUnsupportedMediaType, Code: code, Message: message}
+}
+
+// TooManyAttemptsError is returned while a login is locked out after too many failures.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (t *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", t.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds rounds up, a client waiting that long is not locked anymore.
+func (t *TooManyAttemptsError) RetryAfterSeconds() int {
+	return int(math.Ceil(t.RetryAfter.Seconds()))
+}
+
+// RateLimitedError is returned once a client used up the quota of a route.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (r *RateLimitedError) Error() string {
+	return fmt.Sprintf("too many requests, try again in %d seconds", r.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds rounds up, a client waiting that long is let through.
+func (r *RateLimitedError) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	healthEnum "github.com/zakiyalmaya/online-store/constant/health"
+)
+
+const (
+	HealthCheckDatabase = "database"
+	HealthCheckRedis    = "redis"
+)
+
+// HealthEntity is the status of the app and of each of its connections.
+type HealthEntity struct {
+	Status healthEnum.Status
+	Checks map[string]healthEnum.Status
+}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (h *HealthEntity) ToResponse() *HealthResponse {
+	checks := make(map[string]string, len(h.Checks))
+	for name, status := range h.Checks {
+		checks[name] = status.Enum()
+	}
+
+	return &HealthResponse{
+		Status: h.Status.Enum(),
+		Checks: checks,
+	}
+}
@@ -1,8 +1,10 @@
 package model
 
 type ResponseSystem struct {
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code    string            `json:"code,omitempty"`
+	Message string            `json:"message"`
+	Data    interface{}       `json:"data,omitempty"`
+	Errors  map[string]string `json:"errors,omitempty"`
 }
 
 func HTTPSuccessResponse(res interface{}) ResponseSystem {
@@ -12,8 +14,12 @@ func HTTPSuccessResponse(res interface{}) ResponseSystem {
 	}
 }
 
-func HTTPErrorResponse(errMsg string) ResponseSystem {
+// HTTPCodeErrorResponse is the body of every failed request: a stable code for
+// programs, a message for people and the request fields to fix, if any.
+func HTTPCodeErrorResponse(code, errMsg string, fields map[string]string) ResponseSystem {
 	return ResponseSystem{
+		Code:    code,
 		Message: errMsg,
+		Errors:  fields,
 	}
 }
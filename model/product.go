@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	productEnum "github.com/zakiyalmaya/online-store/constant/product"
 )
 
 type ProductEntity struct {
@@ -26,16 +27,176 @@ type CreateProductRequest struct {
 }
 
 type GetProductRequest struct {
-	CategoryID *int `json:"category_id,omitempty"`
-	Limit      int  `json:"limit,omitempty"`
-	Page       int  `json:"page,omitempty"`
+	CategoryID *int               `json:"category_id,omitempty"`
+	Limit      int                `json:"limit,omitempty"`
+	Page       int                `json:"page,omitempty"`
+	SortBy     productEnum.SortBy `json:"sort_by,omitempty"`
+	Descending bool               `json:"descending,omitempty"`
 }
 
 type ProductResponse struct {
-	ID            int     `json:"id"`
-	Name          string  `json:"name"`
-	Price         float64 `json:"price"`
-	StockQuantity int     `json:"stock_quantity"`
-	Category      string  `json:"category"`
-	Description   string  `json:"description"`
+	ID            int                     `json:"id"`
+	Name          string                  `json:"name"`
+	Price         float64                 `json:"price"`
+	StockQuantity int                     `json:"stock_quantity"`
+	Category      string                  `json:"category"`
+	Description   string                  `json:"description"`
+	AverageRating float64                 `json:"average_rating"`
+	ReviewCount   int                     `json:"review_count"`
+	Images        []*ProductImageResponse `json:"images"`
+}
+
+type ProductOptionEntity struct {
+	ID        int       `db:"id"`
+	ProductID int       `db:"product_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	Values    []*ProductOptionValueEntity
+}
+
+type ProductOptionValueEntity struct {
+	ID         int    `db:"id"`
+	OptionID   int    `db:"product_option_id"`
+	OptionName string `db:"option_name"`
+	Value      string `db:"value"`
+}
+
+type ProductVariantEntity struct {
+	ID            int                 `db:"id"`
+	ProductID     int                 `db:"product_id"`
+	SKU           string              `db:"sku"`
+	Price         decimal.NullDecimal `db:"price"`
+	StockQuantity int                 `db:"stock_quantity"`
+	CreatedAt     time.Time           `db:"created_at"`
+	UpdatedAt     time.Time           `db:"updated_at"`
+	OptionValues  []*ProductOptionValueEntity
+}
+
+type CreateProductOptionRequest struct {
+	ProductID int      `json:"product_id" validate:"required"`
+	Name      string   `json:"name" validate:"required"`
+	Values    []string `json:"values" validate:"required,min=1,dive,required"`
+}
+
+type CreateProductVariantRequest struct {
+	ProductID      int      `json:"product_id" validate:"required"`
+	SKU            string   `json:"sku" validate:"required"`
+	Price          *float64 `json:"price,omitempty" validate:"omitempty,gt=0"`
+	StockQuantity  int      `json:"stock_quantity" validate:"min=0"`
+	OptionValueIDs []int    `json:"option_value_ids" validate:"required,min=1"`
+}
+
+type ProductVariantsResponse struct {
+	ProductID int                       `json:"product_id"`
+	Options   []*ProductOptionResponse  `json:"options"`
+	Variants  []*ProductVariantResponse `json:"variants"`
+}
+
+type ProductOptionResponse struct {
+	ID     int                           `json:"id"`
+	Name   string                        `json:"name"`
+	Values []*ProductOptionValueResponse `json:"values"`
+}
+
+type ProductOptionValueResponse struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+}
+
+type ProductVariantResponse struct {
+	ID            int               `json:"id"`
+	SKU           string            `json:"sku"`
+	Price         float64           `json:"price"`
+	StockQuantity int               `json:"stock_quantity"`
+	Options       map[string]string `json:"options"`
+}
+
+func (o *ProductOptionEntity) ToResponse() *ProductOptionResponse {
+	values := make([]*ProductOptionValueResponse, len(o.Values))
+	for i, value := range o.Values {
+		values[i] = &ProductOptionValueResponse{
+			ID:    value.ID,
+			Value: value.Value,
+		}
+	}
+
+	return &ProductOptionResponse{
+		ID:     o.ID,
+		Name:   o.Name,
+		Values: values,
+	}
+}
+
+// ToResponse falls back to the product price when the variant has no price override.
+func (v *ProductVariantEntity) ToResponse(productPrice decimal.Decimal) *ProductVariantResponse {
+	price := productPrice
+	if v.Price.Valid {
+		price = v.Price.Decimal
+	}
+
+	options := make(map[string]string, len(v.OptionValues))
+	for _, value := range v.OptionValues {
+		options[value.OptionName] = value.Value
+	}
+
+	return &ProductVariantResponse{
+		ID:            v.ID,
+		SKU:           v.SKU,
+		Price:         price.InexactFloat64(),
+		StockQuantity: v.StockQuantity,
+		Options:       options,
+	}
+}
+
+type ProductImageEntity struct {
+	ID            int       `db:"id"`
+	ProductID     int       `db:"product_id"`
+	Path          string    `db:"path"`
+	ThumbnailPath string    `db:"thumbnail_path"`
+	ContentType   string    `db:"content_type"`
+	Size          int       `db:"size"`
+	Position      int       `db:"position"`
+	IsPrimary     bool      `db:"is_primary"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+type UploadProductImageRequest struct {
+	ProductID int    `validate:"required"`
+	Data      []byte `validate:"required"`
+	IsPrimary bool
+}
+
+type ProductImageRequest struct {
+	ProductID int `validate:"required"`
+	ImageID   int `validate:"required"`
+}
+
+type ReorderProductImagesRequest struct {
+	ProductID int   `json:"product_id" validate:"required"`
+	ImageIDs  []int `json:"image_ids" validate:"required,min=1"`
+}
+
+type ProductImageResponse struct {
+	ID           int    `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int    `json:"size"`
+	Position     int    `json:"position"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+// ToResponse takes the storage URL builder so the entity stays unaware of where files live.
+func (i *ProductImageEntity) ToResponse(url func(path string) string) *ProductImageResponse {
+	return &ProductImageResponse{
+		ID:           i.ID,
+		URL:          url(i.Path),
+		ThumbnailURL: url(i.ThumbnailPath),
+		ContentType:  i.ContentType,
+		Size:         i.Size,
+		Position:     i.Position,
+		IsPrimary:    i.IsPrimary,
+	}
 }
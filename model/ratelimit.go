@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RateLimitEntity is the quota of a client on a route once a request was taken
+// from it. Reset is how long until the quota is full again and RetryAfter how
+// long a refused client has to wait for the next request to be let through.
+type RateLimitEntity struct {
+	Allowed    bool
+	Limit      int
+	Window     time.Duration
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+)
+
+type ReviewEntity struct {
+	ID               int               `db:"id"`
+	ProductID        int               `db:"product_id"`
+	CustomerID       int               `db:"customer_id"`
+	Rating           int               `db:"rating"`
+	Title            string            `db:"title"`
+	Body             string            `db:"body"`
+	VerifiedPurchase bool              `db:"verified_purchase"`
+	Status           reviewEnum.Status `db:"status"`
+	CreatedAt        time.Time         `db:"created_at"`
+	UpdatedAt        time.Time         `db:"updated_at"`
+	CustomerName     string            `db:"customer_name"`
+}
+
+type CreateReviewRequest struct {
+	ProductID  int    `json:"product_id" validate:"required"`
+	CustomerID int    `json:"customer_id" validate:"required"`
+	Rating     int    `json:"rating" validate:"required,min=1,max=5"`
+	Title      string `json:"title" validate:"required,max=255"`
+	Body       string `json:"body" validate:"max=5000"`
+}
+
+type GetReviewRequest struct {
+	ProductID *int `json:"product_id,omitempty"`
+	Status    *int `json:"status,omitempty"`
+}
+
+type UpdateReviewStatusRequest struct {
+	ID     int               `json:"id" validate:"required"`
+	Status reviewEnum.Status `json:"status" validate:"required"`
+}
+
+type ReviewResponse struct {
+	ID               int       `json:"id"`
+	ProductID        int       `json:"product_id"`
+	CustomerName     string    `json:"customer_name"`
+	Rating           int       `json:"rating"`
+	Title            string    `json:"title"`
+	Body             string    `json:"body"`
+	VerifiedPurchase bool      `json:"verified_purchase"`
+	Status           string    `json:"status"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (r *ReviewEntity) ToResponse() *ReviewResponse {
+	return &ReviewResponse{
+		ID:               r.ID,
+		ProductID:        r.ProductID,
+		CustomerName:     r.CustomerName,
+		Rating:           r.Rating,
+		Title:            r.Title,
+		Body:             r.Body,
+		VerifiedPurchase: r.VerifiedPurchase,
+		Status:           r.Status.Enum(),
+		CreatedAt:        r.CreatedAt,
+	}
+}
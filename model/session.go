@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// SessionEntity is one logged in device, stored in Redis until it expires or is revoked.
+type SessionEntity struct {
+	ID               string    `json:"id"`
+	CustomerID       int       `json:"customer_id"`
+	Username         string    `json:"username"`
+	Device           string    `json:"device"`
+	IPAddress        string    `json:"ip_address"`
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	CreatedAt        time.Time `json:"created_at"`
+	LastUsedAt       time.Time `json:"last_used_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func (s *SessionEntity) ToResponse(currentSessionID string) *SessionResponse {
+	return &SessionResponse{
+		ID:         s.ID,
+		Device:     s.Device,
+		IPAddress:  s.IPAddress,
+		Current:    s.ID == currentSessionID,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	Device       string `json:"-"`
+	IPAddress    string `json:"-"`
+}
+
+type SessionRequest struct {
+	CustomerID int
+	SessionID  string
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// LoginChallengeEntity is a login that got the password right and waits for the
+// two-factor code, stored in Redis under the hash of the challenge token.
+type LoginChallengeEntity struct {
+	ID         string    `json:"id"`
+	CustomerID int       `json:"customer_id"`
+	Username   string    `json:"username"`
+	CartToken  string    `json:"cart_token"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -22,14 +22,16 @@ type TransactionEntity struct {
 }
 
 type TransactionDetailEntity struct {
-	ID            int             `db:"id"`
-	TransactionID int             `db:"transaction_id"`
-	ProductID     int             `db:"product_id"`
-	ProductName   string          `db:"product_name"`
-	Quantity      int             `db:"quantity"`
-	Price         decimal.Decimal `db:"price"`
-	CreatedAt     time.Time       `db:"created_at"`
-	UpdatedAt     time.Time       `db:"updated_at"`
+	ID               int             `db:"id"`
+	TransactionID    int             `db:"transaction_id"`
+	ProductID        int             `db:"product_id"`
+	ProductVariantID int             `db:"product_variant_id"`
+	ProductName      string          `db:"product_name"`
+	SKU              string          `db:"sku"`
+	Quantity         int             `db:"quantity"`
+	Price            decimal.Decimal `db:"price"`
+	CreatedAt        time.Time       `db:"created_at"`
+	UpdatedAt        time.Time       `db:"updated_at"`
 }
 
 type TransactionRequest struct {
@@ -38,6 +40,11 @@ type TransactionRequest struct {
 	PaymentMethod transactionEnum.Method `json:"payment_method" validate:"required"`
 }
 
+type UpdateTransactionStatusRequest struct {
+	ID     int                    `json:"id" validate:"required"`
+	Status transactionEnum.Status `json:"status" validate:"required"`
+}
+
 type TransactionResponse struct {
 	ID             int                          `json:"id"`
 	IdempotencyKey string                       `json:"idempotency_key"`
@@ -50,11 +57,13 @@ type TransactionResponse struct {
 }
 
 type TransactionDetailResponse struct {
-	ID          int     `json:"id"`
-	ProductID   int     `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
+	ID               int     `json:"id"`
+	ProductID        int     `json:"product_id"`
+	ProductVariantID int     `json:"product_variant_id,omitempty"`
+	SKU              string  `json:"sku,omitempty"`
+	ProductName      string  `json:"product_name"`
+	Quantity         int     `json:"quantity"`
+	Price            float64 `json:"price"`
 }
 
 func (c *CartEntity) ToTransactionEntity() *TransactionEntity {
@@ -66,9 +75,10 @@ func (c *CartEntity) ToTransactionEntity() *TransactionEntity {
 	details := make([]*TransactionDetailEntity, len(c.Items))
 	for i, item := range c.Items {
 		details[i] = &TransactionDetailEntity{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			Price:     item.Price,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+			Price:            item.Price,
 		}
 
 		totalAmout += item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))).InexactFloat64()
@@ -92,11 +102,13 @@ func (t *TransactionEntity) ToResponse() *TransactionResponse {
 	details := make([]*TransactionDetailResponse, len(t.Details))
 	for i, detail := range t.Details {
 		details[i] = &TransactionDetailResponse{
-			ID:          detail.ID,
-			ProductID:   detail.ProductID,
-			ProductName: detail.ProductName,
-			Quantity:    detail.Quantity,
-			Price:       detail.Price.InexactFloat64(),
+			ID:               detail.ID,
+			ProductID:        detail.ProductID,
+			ProductVariantID: detail.ProductVariantID,
+			SKU:              detail.SKU,
+			ProductName:      detail.ProductName,
+			Quantity:         detail.Quantity,
+			Price:            detail.Price.InexactFloat64(),
 		}
 	}
 
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	wishlistEnum "github.com/zakiyalmaya/online-store/constant/wishlist"
+)
+
+// WishlistEntity is a wishlisted product, or a variant of it when
+// ProductVariantID is not 0. Price and StockQuantity are the ones of the
+// variant when it has them.
+type WishlistEntity struct {
+	ID               int             `db:"id"`
+	CustomerID       int             `db:"customer_id"`
+	ProductID        int             `db:"product_id"`
+	ProductVariantID int             `db:"product_variant_id"`
+	Notify           bool            `db:"notify"`
+	LastPrice        decimal.Decimal `db:"last_price"`
+	LastStock        int             `db:"last_stock"`
+	CreatedAt        time.Time       `db:"created_at"`
+	UpdatedAt        time.Time       `db:"updated_at"`
+	ProductName      string          `db:"product_name"`
+	Price            decimal.Decimal `db:"price"`
+	StockQuantity    int             `db:"stock_quantity"`
+}
+
+type WishlistNotificationEntity struct {
+	ID          int                           `db:"id"`
+	CustomerID  int                           `db:"customer_id"`
+	ProductID   int                           `db:"product_id"`
+	Type        wishlistEnum.NotificationType `db:"type"`
+	Price       decimal.Decimal               `db:"price"`
+	CreatedAt   time.Time                     `db:"created_at"`
+	ProductName string                        `db:"product_name"`
+}
+
+type WishlistRequest struct {
+	CustomerID       int  `json:"customer_id" validate:"required"`
+	ProductID        int  `json:"product_id" validate:"required"`
+	ProductVariantID int  `json:"product_variant_id,omitempty"`
+	Notify           bool `json:"notify"`
+}
+
+type MoveToCartRequest struct {
+	CustomerID       int `json:"customer_id" validate:"required"`
+	ProductID        int `json:"product_id" validate:"required"`
+	ProductVariantID int `json:"product_variant_id,omitempty"`
+	Quantity         int `json:"quantity"`
+}
+
+type SaveForLaterRequest struct {
+	CustomerID int  `json:"customer_id" validate:"required"`
+	CartItemID int  `json:"cart_item_id" validate:"required"`
+	Notify     bool `json:"notify"`
+}
+
+type WishlistResponse struct {
+	ID               int     `json:"id"`
+	ProductID        int     `json:"product_id"`
+	ProductVariantID int     `json:"product_variant_id,omitempty"`
+	ProductName      string  `json:"product_name"`
+	Price            float64 `json:"price"`
+	StockQuantity    int     `json:"stock_quantity"`
+	Notify           bool    `json:"notify"`
+}
+
+type WishlistNotificationResponse struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Type        string    `json:"type"`
+	Price       float64   `json:"price"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (w *WishlistEntity) ToResponse() *WishlistResponse {
+	return &WishlistResponse{
+		ID:               w.ID,
+		ProductID:        w.ProductID,
+		ProductVariantID: w.ProductVariantID,
+		ProductName:      w.ProductName,
+		Price:            w.Price.InexactFloat64(),
+		StockQuantity:    w.StockQuantity,
+		Notify:           w.Notify,
+	}
+}
+
+// Notifications compares the current product price and stock with the last seen
+// values and returns the notifications the customer should receive.
+func (w *WishlistEntity) Notifications() []*WishlistNotificationEntity {
+	notifications := make([]*WishlistNotificationEntity, 0)
+	if w.Price.LessThan(w.LastPrice) {
+		notifications = append(notifications, &WishlistNotificationEntity{
+			CustomerID: w.CustomerID,
+			ProductID:  w.ProductID,
+			Type:       wishlistEnum.NotificationTypePriceDrop,
+			Price:      w.Price,
+		})
+	}
+
+	if w.LastStock <= 0 && w.StockQuantity > 0 {
+		notifications = append(notifications, &WishlistNotificationEntity{
+			CustomerID: w.CustomerID,
+			ProductID:  w.ProductID,
+			Type:       wishlistEnum.NotificationTypeBackInStock,
+			Price:      w.Price,
+		})
+	}
+
+	return notifications
+}
+
+func (w *WishlistNotificationEntity) ToResponse() *WishlistNotificationResponse {
+	return &WishlistNotificationResponse{
+		ID:          w.ID,
+		ProductID:   w.ProductID,
+		ProductName: w.ProductName,
+		Type:        w.Type.Enum(),
+		Price:       w.Price.InexactFloat64(),
+		CreatedAt:   w.CreatedAt,
+	}
+}
@@ -0,0 +1,64 @@
+package apikey
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/application/apikey"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+type Controller struct {
+	apiKeySvc apikey.Service
+}
+
+func NewAPIKeyController(apiKeySvc apikey.Service) *Controller {
+	return &Controller{apiKeySvc: apiKeySvc}
+}
+
+func (a *Controller) Create(ctx *fiber.Ctx) error {
+	createRequest := &model.CreateAPIKeyRequest{}
+	if err := ctx.BodyParser(createRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+	createRequest.CreatedBy = customerID
+
+	if err := utils.Validator(createRequest); err != nil {
+		return err
+	}
+
+	apiKey, err := a.apiKeySvc.Create(ctx.UserContext(), createRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(apiKey))
+}
+
+func (a *Controller) GetAll(ctx *fiber.Ctx) error {
+	apiKeys, err := a.apiKeySvc.GetAll(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(apiKeys))
+}
+
+func (a *Controller) Revoke(ctx *fiber.Ctx) error {
+	apiKeyID, err := strconv.Atoi(ctx.Params("api_key_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_api_key_id", "invalid api key id")
+	}
+
+	if err := a.apiKeySvc.Revoke(ctx.UserContext(), apiKeyID); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zakiyalmaya/online-store/application/cart"
+	"github.com/zakiyalmaya/online-store/constant"
 	"github.com/zakiyalmaya/online-store/model"
 	"github.com/zakiyalmaya/online-store/utils"
 )
@@ -20,22 +21,22 @@ func NewCartController(cartSvc cart.Service) *Controller {
 func (c *Controller) Create(ctx *fiber.Ctx) error {
 	createRequest := &model.CreateCartRequest{}
 	if err := ctx.BodyParser(createRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.ErrInvalidRequestBody.Wrap(err)
 	}
 
 	customerID := ctx.Locals("user_id").(int)
 	if customerID == 0 {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid customer id"))
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
 	}
 	createRequest.CustomerID = customerID
 
 	if err := utils.Validator(createRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
-	cart, err := c.cartSvc.Create(createRequest)
+	cart, err := c.cartSvc.Create(ctx.UserContext(), createRequest)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(cart))
@@ -44,7 +45,7 @@ func (c *Controller) Create(ctx *fiber.Ctx) error {
 func (c *Controller) GetAll(ctx *fiber.Ctx) error {
 	customerID := ctx.Locals("user_id").(int)
 	if customerID == 0 {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid customer id"))
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
 	}
 
 	status := ctx.Query("status")
@@ -52,7 +53,7 @@ func (c *Controller) GetAll(ctx *fiber.Ctx) error {
 	if status != "" {
 		statusParsed, err := strconv.Atoi(status)
 		if err != nil {
-			return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid status"))
+			return model.NewValidationError("invalid_status", "invalid status")
 		}
 		statusInt = &statusParsed
 	}
@@ -63,12 +64,12 @@ func (c *Controller) GetAll(ctx *fiber.Ctx) error {
 	}
 
 	if err := utils.Validator(getRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
-	cart, err := c.cartSvc.GetByParams(getRequest)
+	cart, err := c.cartSvc.GetByParams(ctx.UserContext(), getRequest)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(cart))
@@ -77,20 +78,85 @@ func (c *Controller) GetAll(ctx *fiber.Ctx) error {
 func (c *Controller) Delete(ctx *fiber.Ctx) error {
 	customerID := ctx.Locals("user_id").(int)
 	if customerID == 0 {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid customer id"))
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
 	}
 
 	cartItemID := ctx.Params("cart_item_id")
 	cartItemIDInt, err := strconv.Atoi(cartItemID)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid cart item id"))
+		return model.NewValidationError("invalid_cart_item_id", "invalid cart item id")
 	}
 
-	if err := c.cartSvc.Delete(&model.DeleteCartRequest{
+	if err := c.cartSvc.Delete(ctx.UserContext(), &model.DeleteCartRequest{
 		CustomerID: customerID,
 		CartItemID: cartItemIDInt,
 	}); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) CreateGuest(ctx *fiber.Ctx) error {
+	createRequest := &model.GuestCartRequest{}
+	if err := ctx.BodyParser(createRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+	createRequest.Token = ctx.Get(constant.GuestCartHeader)
+
+	if err := utils.Validator(createRequest); err != nil {
+		return err
+	}
+
+	cart, err := c.cartSvc.CreateGuest(ctx.UserContext(), createRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(cart))
+}
+
+func (c *Controller) GetGuest(ctx *fiber.Ctx) error {
+	token := ctx.Get(constant.GuestCartHeader)
+	if token == "" {
+		return model.NewValidationError("missing_cart_token", "missing cart token")
+	}
+
+	cart, err := c.cartSvc.GetGuest(ctx.UserContext(), token)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(cart))
+}
+
+func (c *Controller) DeleteGuest(ctx *fiber.Ctx) error {
+	token := ctx.Get(constant.GuestCartHeader)
+	if token == "" {
+		return model.NewValidationError("missing_cart_token", "missing cart token")
+	}
+
+	productID := ctx.Params("product_id")
+	productIDInt, err := strconv.Atoi(productID)
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	// product_variant_id is only given for products sold in variants
+	variantID := 0
+	if variant := ctx.Query("product_variant_id"); variant != "" {
+		variantID, err = strconv.Atoi(variant)
+		if err != nil {
+			return model.NewValidationError("invalid_product_variant_id", "invalid product variant id")
+		}
+	}
+
+	if err := c.cartSvc.DeleteGuest(ctx.UserContext(), &model.DeleteGuestCartRequest{
+		Token:            token,
+		ProductID:        productIDInt,
+		ProductVariantID: variantID,
+	}); err != nil {
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
@@ -18,25 +18,25 @@ func NewCategoryController(categorySvc category.Service) *Controller {
 func (c *Controller) Create(ctx *fiber.Ctx) error {
 	categoryRequest := &model.CategoryRequest{}
 	if err := ctx.BodyParser(categoryRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.ErrInvalidRequestBody.Wrap(err)
 	}
 
 	if err := utils.Validator(categoryRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
-	err := c.categorySvc.Create(categoryRequest.Name)
+	err := c.categorySvc.Create(ctx.UserContext(), categoryRequest.Name)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
 }
 
 func (c *Controller) GetAll(ctx *fiber.Ctx) error {
-	categories, err := c.categorySvc.GetAll()
+	categories, err := c.categorySvc.GetAll(ctx.UserContext())
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(categories))
@@ -2,11 +2,18 @@ package controller
 
 import (
 	"github.com/zakiyalmaya/online-store/application"
+	"github.com/zakiyalmaya/online-store/config"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
+	"github.com/zakiyalmaya/online-store/transport/controller/apikey"
 	"github.com/zakiyalmaya/online-store/transport/controller/cart"
 	"github.com/zakiyalmaya/online-store/transport/controller/category"
 	"github.com/zakiyalmaya/online-store/transport/controller/customer"
+	"github.com/zakiyalmaya/online-store/transport/controller/health"
+	"github.com/zakiyalmaya/online-store/transport/controller/jwks"
 	"github.com/zakiyalmaya/online-store/transport/controller/product"
+	"github.com/zakiyalmaya/online-store/transport/controller/review"
 	"github.com/zakiyalmaya/online-store/transport/controller/transaction"
+	"github.com/zakiyalmaya/online-store/transport/controller/wishlist"
 )
 
 type Controller struct {
@@ -15,14 +22,24 @@ type Controller struct {
 	Product     *product.Controller
 	Cart        *cart.Controller
 	Transaction *transaction.Controller
+	Wishlist    *wishlist.Controller
+	Review      *review.Controller
+	JWKS        *jwks.Controller
+	APIKey      *apikey.Controller
+	Health      *health.Controller
 }
 
-func NewController(application *application.Application) *Controller {
+func NewController(application *application.Application, tokens token.Manager, cfg *config.Config) *Controller {
 	return &Controller{
 		Category:    category.NewCategoryController(application.CategorySvc),
 		Customer:    customer.NewCategoryController(application.CustomerSvc),
-		Product:     product.NewProductController(application.ProductSvc),
+		Product:     product.NewProductController(application.ProductSvc, cfg.Pagination, cfg.Media),
 		Cart:        cart.NewCartController(application.CartSvc),
 		Transaction: transaction.NewTransactionController(application.TransactionSvc),
+		Wishlist:    wishlist.NewWishlistController(application.WishlistSvc),
+		Review:      review.NewReviewController(application.ReviewSvc),
+		JWKS:        jwks.NewJWKSController(tokens),
+		APIKey:      apikey.NewAPIKeyController(application.APIKeySvc),
+		Health:      health.NewHealthController(application.HealthSvc),
 	}
 }
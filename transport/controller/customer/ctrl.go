@@ -18,17 +18,17 @@ func NewCategoryController(customerSvc customer.Service) *Controller {
 func (c *Controller) Register(ctx *fiber.Ctx) error {
 	customerRequest := &model.CustomerRequest{}
 	if err := ctx.BodyParser(customerRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.ErrInvalidRequestBody.Wrap(err)
 	}
 
 	if err := utils.Validator(customerRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
-	err := c.customerSvc.Register(customerRequest)
+	err := c.customerSvc.Register(ctx.UserContext(), customerRequest)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
-	}	
+		return err
+	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
 }
@@ -36,26 +36,265 @@ func (c *Controller) Register(ctx *fiber.Ctx) error {
 func (c *Controller) Login(ctx *fiber.Ctx) error {
 	authRequest := &model.AuthRequest{}
 	if err := ctx.BodyParser(authRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.ErrInvalidRequestBody.Wrap(err)
 	}
 
 	if err := utils.Validator(authRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
+	}
+	authRequest.Device = ctx.Get(fiber.HeaderUserAgent)
+	authRequest.IPAddress = ctx.IP()
+
+	response, err := c.customerSvc.Login(ctx.UserContext(), authRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
+}
+
+func (c *Controller) LoginTwoFactor(ctx *fiber.Ctx) error {
+	twoFactorRequest := &model.TwoFactorLoginRequest{}
+	if err := ctx.BodyParser(twoFactorRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	if err := utils.Validator(twoFactorRequest); err != nil {
+		return err
+	}
+	twoFactorRequest.Device = ctx.Get(fiber.HeaderUserAgent)
+	twoFactorRequest.IPAddress = ctx.IP()
+
+	response, err := c.customerSvc.LoginTwoFactor(ctx.UserContext(), twoFactorRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
+}
+
+func (c *Controller) Refresh(ctx *fiber.Ctx) error {
+	refreshRequest := &model.RefreshTokenRequest{}
+	if err := ctx.BodyParser(refreshRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	if err := utils.Validator(refreshRequest); err != nil {
+		return err
 	}
+	refreshRequest.Device = ctx.Get(fiber.HeaderUserAgent)
+	refreshRequest.IPAddress = ctx.IP()
 
-	response, err := c.customerSvc.Login(authRequest)
+	response, err := c.customerSvc.Refresh(ctx.UserContext(), refreshRequest)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
 }
 
 func (c *Controller) Logout(ctx *fiber.Ctx) error {
-	username := ctx.Locals("username").(string)
-	if err := c.customerSvc.Logout(username); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+	if err := c.customerSvc.Logout(ctx.UserContext(), currentSession(ctx)); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) GetSessions(ctx *fiber.Ctx) error {
+	sessions, err := c.customerSvc.GetSessions(ctx.UserContext(), currentSession(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(sessions))
+}
+
+func (c *Controller) RevokeSession(ctx *fiber.Ctx) error {
+	request := &model.SessionRequest{
+		CustomerID: ctx.Locals("user_id").(int),
+		SessionID:  ctx.Params("session_id"),
+	}
+
+	if err := c.customerSvc.RevokeSession(ctx.UserContext(), request); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) GetProfile(ctx *fiber.Ctx) error {
+	response, err := c.customerSvc.GetProfile(ctx.UserContext(), ctx.Locals("user_id").(int))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
+}
+
+func (c *Controller) UpdateProfile(ctx *fiber.Ctx) error {
+	updateRequest := &model.UpdateCustomerRequest{}
+	if err := ctx.BodyParser(updateRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+	updateRequest.CustomerID = ctx.Locals("user_id").(int)
+
+	if err := utils.Validator(updateRequest); err != nil {
+		return err
+	}
+
+	response, err := c.customerSvc.UpdateProfile(ctx.UserContext(), updateRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
+}
+
+func (c *Controller) ChangePassword(ctx *fiber.Ctx) error {
+	passwordRequest := &model.ChangePasswordRequest{}
+	if err := ctx.BodyParser(passwordRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+	passwordRequest.CustomerID = ctx.Locals("user_id").(int)
+
+	if err := utils.Validator(passwordRequest); err != nil {
+		return err
+	}
+
+	if err := c.customerSvc.ChangePassword(ctx.UserContext(), passwordRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) Delete(ctx *fiber.Ctx) error {
+	deleteRequest := &model.DeleteCustomerRequest{}
+	if err := ctx.BodyParser(deleteRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+	deleteRequest.CustomerID = ctx.Locals("user_id").(int)
+
+	if err := utils.Validator(deleteRequest); err != nil {
+		return err
+	}
+
+	if err := c.customerSvc.Delete(ctx.UserContext(), deleteRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) SendVerificationEmail(ctx *fiber.Ctx) error {
+	if err := c.customerSvc.SendVerificationEmail(ctx.UserContext(), ctx.Locals("user_id").(int)); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) VerifyEmail(ctx *fiber.Ctx) error {
+	verifyRequest := &model.VerifyEmailRequest{}
+	if err := ctx.BodyParser(verifyRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	if err := utils.Validator(verifyRequest); err != nil {
+		return err
+	}
+
+	if err := c.customerSvc.VerifyEmail(ctx.UserContext(), verifyRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) ForgotPassword(ctx *fiber.Ctx) error {
+	forgotRequest := &model.ForgotPasswordRequest{}
+	if err := ctx.BodyParser(forgotRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	if err := utils.Validator(forgotRequest); err != nil {
+		return err
+	}
+
+	if err := c.customerSvc.ForgotPassword(ctx.UserContext(), forgotRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) ResetPassword(ctx *fiber.Ctx) error {
+	resetRequest := &model.ResetPasswordRequest{}
+	if err := ctx.BodyParser(resetRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	if err := utils.Validator(resetRequest); err != nil {
+		return err
+	}
+
+	if err := c.customerSvc.ResetPassword(ctx.UserContext(), resetRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) EnrollTwoFactor(ctx *fiber.Ctx) error {
+	response, err := c.customerSvc.EnrollTwoFactor(ctx.UserContext(), ctx.Locals("user_id").(int))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
+}
+
+func (c *Controller) ConfirmTwoFactor(ctx *fiber.Ctx) error {
+	confirmRequest := &model.ConfirmTwoFactorRequest{}
+	if err := ctx.BodyParser(confirmRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+	confirmRequest.CustomerID = ctx.Locals("user_id").(int)
+
+	if err := utils.Validator(confirmRequest); err != nil {
+		return err
+	}
+
+	response, err := c.customerSvc.ConfirmTwoFactor(ctx.UserContext(), confirmRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(response))
+}
+
+func (c *Controller) DisableTwoFactor(ctx *fiber.Ctx) error {
+	disableRequest := &model.DisableTwoFactorRequest{}
+	if err := ctx.BodyParser(disableRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+	disableRequest.CustomerID = ctx.Locals("user_id").(int)
+
+	if err := utils.Validator(disableRequest); err != nil {
+		return err
+	}
+
+	if err := c.customerSvc.DisableTwoFactor(ctx.UserContext(), disableRequest); err != nil {
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
-}
\ No newline at end of file
+}
+
+func currentSession(ctx *fiber.Ctx) *model.SessionRequest {
+	return &model.SessionRequest{
+		CustomerID: ctx.Locals("user_id").(int),
+		SessionID:  ctx.Locals("session_id").(string),
+	}
+}
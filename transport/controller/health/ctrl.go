@@ -0,0 +1,34 @@
+package health
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/application/health"
+	healthEnum "github.com/zakiyalmaya/online-store/constant/health"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+type Controller struct {
+	healthSvc health.Service
+}
+
+func NewHealthController(healthSvc health.Service) *Controller {
+	return &Controller{healthSvc: healthSvc}
+}
+
+// Live answers 200 as long as the app runs, without checking the database or
+// Redis: restarting the app does not bring them back.
+func (c *Controller) Live(ctx *fiber.Ctx) error {
+	health := &model.HealthEntity{Status: healthEnum.HealthStatusOK}
+	return ctx.Status(fiber.StatusOK).JSON(health.ToResponse())
+}
+
+// Ready answers 503 when the app can not serve requests, without its database,
+// so it gets no traffic until the database is back. Degraded still is ready.
+func (c *Controller) Ready(ctx *fiber.Ctx) error {
+	health := c.healthSvc.Check(ctx.UserContext())
+	if health.Status == healthEnum.HealthStatusUnavailable {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(health.ToResponse())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(health.ToResponse())
+}
@@ -0,0 +1,24 @@
+package jwks
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
+)
+
+// cacheControl lets the services verifying our tokens cache the keys, a new key
+// has to be published this long before it becomes the active key.
+const cacheControl = "public, max-age=300"
+
+type Controller struct {
+	tokens token.Manager
+}
+
+func NewJWKSController(tokens token.Manager) *Controller {
+	return &Controller{tokens: tokens}
+}
+
+// Get returns the standard JWKS document, not wrapped in the usual response body.
+func (c *Controller) Get(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, cacheControl)
+	return ctx.Status(fiber.StatusOK).JSON(c.tokens.JWKS())
+}
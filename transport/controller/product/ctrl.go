@@ -1,29 +1,34 @@
 package product
 
 import (
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/zakiyalmaya/online-store/application/product"
+	"github.com/zakiyalmaya/online-store/config"
 	"github.com/zakiyalmaya/online-store/model"
 	"github.com/zakiyalmaya/online-store/utils"
 )
 
 type Controller struct {
 	productSvc product.Service
+	pagination config.PaginationConfig
+	media      config.MediaConfig
 }
 
-func NewProductController(productSvc product.Service) *Controller {
-	return &Controller{productSvc: productSvc}
+func NewProductController(productSvc product.Service, pagination config.PaginationConfig, media config.MediaConfig) *Controller {
+	return &Controller{productSvc: productSvc, pagination: pagination, media: media}
 }
 
 func (c *Controller) GetAll(ctx *fiber.Ctx) error {
-	getRequest, err := getProductParam(ctx)
+	getRequest, err := getProductParam(ctx, c.pagination.DefaultLimit)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
-	products, err := c.productSvc.GetAll(getRequest)
+	products, err := c.productSvc.GetAll(ctx.UserContext(), getRequest)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(products))
@@ -32,17 +37,158 @@ func (c *Controller) GetAll(ctx *fiber.Ctx) error {
 func (c *Controller) Create(ctx *fiber.Ctx) error {
 	createRequest := &model.CreateProductRequest{}
 	if err := ctx.BodyParser(createRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	if err := utils.Validator(createRequest); err != nil {
+		return err
+	}
+
+	err := c.productSvc.Create(ctx.UserContext(), createRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) CreateOption(ctx *fiber.Ctx) error {
+	createRequest := &model.CreateProductOptionRequest{}
+	if err := ctx.BodyParser(createRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
 	}
+	createRequest.ProductID = productID
 
 	if err := utils.Validator(createRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
+	}
+
+	if err := c.productSvc.CreateOption(ctx.UserContext(), createRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) CreateVariant(ctx *fiber.Ctx) error {
+	createRequest := &model.CreateProductVariantRequest{}
+	if err := ctx.BodyParser(createRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
 	}
 
-	err := c.productSvc.Create(createRequest)
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+	createRequest.ProductID = productID
+
+	if err := utils.Validator(createRequest); err != nil {
+		return err
+	}
+
+	if err := c.productSvc.CreateVariant(ctx.UserContext(), createRequest); err != nil {
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
 }
+
+func (c *Controller) GetVariants(ctx *fiber.Ctx) error {
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	variants, err := c.productSvc.GetVariants(ctx.UserContext(), productID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(variants))
+}
+
+func (c *Controller) UploadImage(ctx *fiber.Ctx) error {
+	uploadRequest, err := getUploadImageRequest(ctx, c.media.MaxImageSize)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.Validator(uploadRequest); err != nil {
+		return err
+	}
+
+	image, err := c.productSvc.UploadImage(ctx.UserContext(), uploadRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(image))
+}
+
+func (c *Controller) GetImages(ctx *fiber.Ctx) error {
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	images, err := c.productSvc.GetImages(ctx.UserContext(), productID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(images))
+}
+
+func (c *Controller) DeleteImage(ctx *fiber.Ctx) error {
+	imageRequest, err := getImageParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.productSvc.DeleteImage(ctx.UserContext(), imageRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) SetPrimaryImage(ctx *fiber.Ctx) error {
+	imageRequest, err := getImageParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.productSvc.SetPrimaryImage(ctx.UserContext(), imageRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (c *Controller) ReorderImages(ctx *fiber.Ctx) error {
+	reorderRequest := &model.ReorderProductImagesRequest{}
+	if err := ctx.BodyParser(reorderRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+	reorderRequest.ProductID = productID
+
+	if err := utils.Validator(reorderRequest); err != nil {
+		return err
+	}
+
+	if err := c.productSvc.ReorderImages(ctx.UserContext(), reorderRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
@@ -0,0 +1,50 @@
+package product
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/constant"
+	"github.com/zakiyalmaya/online-store/model"
+)
+
+// getUploadImageRequest reads the multipart image. At most one byte more than
+// maxSize is read, the service rejects the image as too large from there.
+func getUploadImageRequest(ctx *fiber.Ctx, maxSize int) (*model.UploadProductImageRequest, error) {
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return nil, model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	fileHeader, err := ctx.FormFile(constant.ProductImageFormField)
+	if err != nil {
+		return nil, model.NewValidationError("image_required", fmt.Sprintf("%s file is required", constant.ProductImageFormField))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, model.NewValidationError("invalid_image", "invalid image file")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxSize)+1))
+	if err != nil {
+		return nil, model.NewValidationError("invalid_image", "invalid image file")
+	}
+
+	var isPrimary bool
+	if value := ctx.FormValue("is_primary"); value != "" {
+		isPrimary, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, model.NewValidationError("invalid_is_primary", "invalid is_primary value")
+		}
+	}
+
+	return &model.UploadProductImageRequest{
+		ProductID: productID,
+		Data:      data,
+		IsPrimary: isPrimary,
+	}, nil
+}
@@ -1,25 +1,27 @@
 package product
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zakiyalmaya/online-store/constant"
+	productEnum "github.com/zakiyalmaya/online-store/constant/product"
 	"github.com/zakiyalmaya/online-store/model"
 )
 
-func getProductParam(ctx *fiber.Ctx) (*model.GetProductRequest, error) {
+func getProductParam(ctx *fiber.Ctx, defaultLimit int) (*model.GetProductRequest, error) {
 	categoryID := ctx.Query("category_id")
 	limit := ctx.Query("limit")
 	page := ctx.Query("page")
+	sortBy := productEnum.SortBy(ctx.Query("sort_by"))
+	order := ctx.Query("order")
 
 	var categoryIDInt *int
 	var limitInt, pageInt int
 	if categoryID != "" {
 		categoryIDParsed, err := strconv.Atoi(categoryID)
 		if err != nil {
-			return nil, fmt.Errorf("invalid category id")
+			return nil, model.NewValidationError("invalid_category_id", "invalid category id")
 		}
 
 		categoryIDInt = &categoryIDParsed
@@ -28,7 +30,7 @@ func getProductParam(ctx *fiber.Ctx) (*model.GetProductRequest, error) {
 	if limit != "" {
 		limitParsed, err := strconv.Atoi(limit)
 		if err != nil {
-			return nil, fmt.Errorf("invalid limit")
+			return nil, model.NewValidationError("invalid_limit", "invalid limit")
 		}
 
 		limitInt = limitParsed
@@ -37,15 +39,23 @@ func getProductParam(ctx *fiber.Ctx) (*model.GetProductRequest, error) {
 	if page != "" {
 		pageParsed, err := strconv.Atoi(page)
 		if err != nil {
-			return nil, fmt.Errorf("invalid page")
+			return nil, model.NewValidationError("invalid_page", "invalid page")
 		}
 
 		pageInt = pageParsed
 	}
 
+	if sortBy != "" && !sortBy.IsValid() {
+		return nil, model.NewValidationError("invalid_sort_by", "invalid sort by")
+	}
+
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, model.NewValidationError("invalid_order", "invalid order")
+	}
+
 	// set default value
 	if limit == "" {
-		limitInt = constant.DefaultLimit
+		limitInt = defaultLimit
 	}
 	
 	if page == "" {
@@ -56,5 +66,24 @@ func getProductParam(ctx *fiber.Ctx) (*model.GetProductRequest, error) {
 		CategoryID: categoryIDInt,
 		Limit:      limitInt,
 		Page:       pageInt,
+		SortBy:     sortBy,
+		Descending: order == "desc",
+	}, nil
+}
+
+func getImageParam(ctx *fiber.Ctx) (*model.ProductImageRequest, error) {
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return nil, model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	imageID, err := strconv.Atoi(ctx.Params("image_id"))
+	if err != nil {
+		return nil, model.NewValidationError("invalid_image_id", "invalid image id")
+	}
+
+	return &model.ProductImageRequest{
+		ProductID: productID,
+		ImageID:   imageID,
 	}, nil
 }
@@ -0,0 +1,116 @@
+package review
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/application/review"
+	reviewEnum "github.com/zakiyalmaya/online-store/constant/review"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+type Controller struct {
+	reviewSvc review.Service
+}
+
+func NewReviewController(reviewSvc review.Service) *Controller {
+	return &Controller{reviewSvc: reviewSvc}
+}
+
+func (r *Controller) Create(ctx *fiber.Ctx) error {
+	createRequest := &model.CreateReviewRequest{}
+	if err := ctx.BodyParser(createRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+	createRequest.CustomerID = customerID
+
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+	createRequest.ProductID = productID
+
+	if err := utils.Validator(createRequest); err != nil {
+		return err
+	}
+
+	if err := r.reviewSvc.Create(ctx.UserContext(), createRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (r *Controller) GetByProduct(ctx *fiber.Ctx) error {
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	// customers only see approved reviews
+	status := int(reviewEnum.ReviewStatusApproved)
+	reviews, err := r.reviewSvc.GetByParams(ctx.UserContext(), &model.GetReviewRequest{
+		ProductID: &productID,
+		Status:    &status,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(reviews))
+}
+
+func (r *Controller) GetAll(ctx *fiber.Ctx) error {
+	getRequest := &model.GetReviewRequest{}
+	if productID := ctx.Query("product_id"); productID != "" {
+		productIDParsed, err := strconv.Atoi(productID)
+		if err != nil {
+			return model.NewValidationError("invalid_product_id", "invalid product id")
+		}
+		getRequest.ProductID = &productIDParsed
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		statusParsed, err := strconv.Atoi(status)
+		if err != nil {
+			return model.NewValidationError("invalid_status", "invalid status")
+		}
+		getRequest.Status = &statusParsed
+	}
+
+	reviews, err := r.reviewSvc.GetByParams(ctx.UserContext(), getRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(reviews))
+}
+
+func (r *Controller) UpdateStatus(ctx *fiber.Ctx) error {
+	updateRequest := &model.UpdateReviewStatusRequest{}
+	if err := ctx.BodyParser(updateRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	reviewID, err := strconv.Atoi(ctx.Params("review_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_review_id", "invalid review id")
+	}
+	updateRequest.ID = reviewID
+
+	if err := utils.Validator(updateRequest); err != nil {
+		return err
+	}
+
+	if err := r.reviewSvc.UpdateStatus(ctx.UserContext(), updateRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
@@ -20,22 +20,22 @@ func NewTransactionController(transactionSvc transaction.Service) *Controller {
 func (t *Controller) Checkout(ctx *fiber.Ctx) error {
 	transactionRequest := &model.TransactionRequest{}
 	if err := ctx.BodyParser(transactionRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return model.ErrInvalidRequestBody.Wrap(err)
 	}
 
 	customerID := ctx.Locals("user_id").(int)
 	if customerID == 0 {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid customer id"))
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
 	}
 
 	transactionRequest.CustomerID = customerID
 	if err := utils.Validator(transactionRequest); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
-	transaction, err := t.transactionSvc.Checkout(transactionRequest)
+	transaction, err := t.transactionSvc.Checkout(ctx.UserContext(), transactionRequest)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(transaction))
@@ -45,13 +45,38 @@ func (t *Controller) GetByID(ctx *fiber.Ctx) error {
 	transactionID := ctx.Query("id")
 	transactionIDInt, err := strconv.Atoi(transactionID)
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPErrorResponse("invalid transaction id"))
+		return model.NewValidationError("invalid_transaction_id", "invalid transaction id")
 	}
 
-	transaction, err := t.transactionSvc.GetByID(transactionIDInt)
+	// a customer reads their own transactions, an API key has no user
+	customerID, _ := ctx.Locals("user_id").(int)
+	transaction, err := t.transactionSvc.GetByID(ctx.UserContext(), transactionIDInt, customerID)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPErrorResponse(err.Error()))
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(transaction))
-}
\ No newline at end of file
+}
+
+func (t *Controller) UpdateStatus(ctx *fiber.Ctx) error {
+	updateRequest := &model.UpdateTransactionStatusRequest{}
+	if err := ctx.BodyParser(updateRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	transactionID, err := strconv.Atoi(ctx.Params("transaction_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_transaction_id", "invalid transaction id")
+	}
+	updateRequest.ID = transactionID
+
+	if err := utils.Validator(updateRequest); err != nil {
+		return err
+	}
+
+	if err := t.transactionSvc.UpdateStatus(ctx.UserContext(), updateRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
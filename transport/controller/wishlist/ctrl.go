@@ -0,0 +1,159 @@
+package wishlist
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zakiyalmaya/online-store/application/wishlist"
+	"github.com/zakiyalmaya/online-store/model"
+	"github.com/zakiyalmaya/online-store/utils"
+)
+
+type Controller struct {
+	wishlistSvc wishlist.Service
+}
+
+func NewWishlistController(wishlistSvc wishlist.Service) *Controller {
+	return &Controller{wishlistSvc: wishlistSvc}
+}
+
+func (w *Controller) Create(ctx *fiber.Ctx) error {
+	createRequest := &model.WishlistRequest{}
+	if err := ctx.BodyParser(createRequest); err != nil {
+		return model.ErrInvalidRequestBody.Wrap(err)
+	}
+
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+	createRequest.CustomerID = customerID
+
+	if err := utils.Validator(createRequest); err != nil {
+		return err
+	}
+
+	if err := w.wishlistSvc.Create(ctx.UserContext(), createRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (w *Controller) GetAll(ctx *fiber.Ctx) error {
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+
+	wishlists, err := w.wishlistSvc.GetByCustomerID(ctx.UserContext(), customerID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(wishlists))
+}
+
+func (w *Controller) Delete(ctx *fiber.Ctx) error {
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+
+	// product_variant_id is only given for a variant saved to the wishlist
+	variantID := 0
+	if variant := ctx.Query("product_variant_id"); variant != "" {
+		variantID, err = strconv.Atoi(variant)
+		if err != nil {
+			return model.NewValidationError("invalid_product_variant_id", "invalid product variant id")
+		}
+	}
+
+	if err := w.wishlistSvc.Delete(ctx.UserContext(), customerID, productID, variantID); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (w *Controller) MoveToCart(ctx *fiber.Ctx) error {
+	moveRequest := &model.MoveToCartRequest{}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(moveRequest); err != nil {
+			return model.ErrInvalidRequestBody.Wrap(err)
+		}
+	}
+
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+	moveRequest.CustomerID = customerID
+
+	productID, err := strconv.Atoi(ctx.Params("product_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_product_id", "invalid product id")
+	}
+	moveRequest.ProductID = productID
+
+	if err := utils.Validator(moveRequest); err != nil {
+		return err
+	}
+
+	cart, err := w.wishlistSvc.MoveToCart(ctx.UserContext(), moveRequest)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.HTTPSuccessResponse(cart))
+}
+
+func (w *Controller) SaveForLater(ctx *fiber.Ctx) error {
+	saveRequest := &model.SaveForLaterRequest{}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(saveRequest); err != nil {
+			return model.ErrInvalidRequestBody.Wrap(err)
+		}
+	}
+
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+	saveRequest.CustomerID = customerID
+
+	cartItemID, err := strconv.Atoi(ctx.Params("cart_item_id"))
+	if err != nil {
+		return model.NewValidationError("invalid_cart_item_id", "invalid cart item id")
+	}
+	saveRequest.CartItemID = cartItemID
+
+	if err := utils.Validator(saveRequest); err != nil {
+		return err
+	}
+
+	if err := w.wishlistSvc.SaveForLater(ctx.UserContext(), saveRequest); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(nil))
+}
+
+func (w *Controller) GetNotifications(ctx *fiber.Ctx) error {
+	customerID := ctx.Locals("user_id").(int)
+	if customerID == 0 {
+		return model.NewValidationError("invalid_customer_id", "invalid customer id")
+	}
+
+	notifications, err := w.wishlistSvc.GetNotifications(ctx.UserContext(), customerID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(model.HTTPSuccessResponse(notifications))
+}
@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/zakiyalmaya/online-store/model"
+	validation "github.com/zakiyalmaya/online-store/utils"
+)
+
+// errorStatus is the status answered to each kind of model.Error.
+var errorStatus = map[model.ErrorKind]int{
+	model.ErrorKindValidation:           fiber.StatusBadRequest,
+	model.ErrorKindUnauthorized:         fiber.StatusUnauthorized,
+	model.ErrorKindForbidden:            fiber.StatusForbidden,
+	model.ErrorKindNotFound:             fiber.StatusNotFound,
+	model.ErrorKindConflict:             fiber.StatusConflict,
+	model.ErrorKindUnprocessable:        fiber.StatusUnprocessableEntity,
+	model.ErrorKindTooLarge:             fiber.StatusRequestEntityTooLarge,
+	model.ErrorKindUnsupportedMediaType: fiber.StatusUnsupportedMediaType,
+}
+
+// ErrorHandler answers every error returned by a handler with the same JSON
+// schema. Errors the client can act on keep their message, any other error
+// is logged and answered with 500 so its details do not leak.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	var (
+		domainErr       *model.Error
+		fields          validation.FieldErrors
+		tooManyAttempts *model.TooManyAttemptsError
+		rateLimited     *model.RateLimitedError
+		fiberErr        *fiber.Error
+	)
+
+	switch {
+	case errors.As(err, &domainErr):
+		if domainErr.Err != nil {
+			slog.InfoContext(ctx.UserContext(), "request error", "code", domainErr.Code, "error", err)
+		}
+		return ctx.Status(errorStatus[domainErr.Kind]).JSON(model.HTTPCodeErrorResponse(domainErr.Code, domainErr.Message, domainErr.Fields))
+	case errors.As(err, &fields):
+		return ctx.Status(fiber.StatusBadRequest).JSON(model.HTTPCodeErrorResponse("validation_failed", fields.Error(), fields))
+	case errors.As(err, &tooManyAttempts):
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(tooManyAttempts.RetryAfterSeconds()))
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(model.HTTPCodeErrorResponse("too_many_attempts", tooManyAttempts.Error(), nil))
+	case errors.As(err, &rateLimited):
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(rateLimited.RetryAfterSeconds()))
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(model.HTTPCodeErrorResponse("rate_limited", rateLimited.Error(), nil))
+	case errors.As(err, &fiberErr):
+		return ctx.Status(fiberErr.Code).JSON(model.HTTPCodeErrorResponse(statusCode(fiberErr.Code), fiberErr.Message, nil))
+	case errors.Is(err, context.DeadlineExceeded):
+		slog.ErrorContext(ctx.UserContext(), "request timed out", "error", err)
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(model.HTTPCodeErrorResponse("timeout", "request timed out", nil))
+	default:
+		slog.ErrorContext(ctx.UserContext(), "request failed", "error", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(model.HTTPCodeErrorResponse("internal_error", "internal server error", nil))
+	}
+}
+
+// statusCode turns a status into an error code, 404 into not_found.
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
@@ -3,28 +3,106 @@ package transport
 import (
 	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/zakiyalmaya/online-store/application"
+	"github.com/zakiyalmaya/online-store/config"
+	apikeyEnum "github.com/zakiyalmaya/online-store/constant/apikey"
+	"github.com/zakiyalmaya/online-store/infrastructure/metrics"
+	"github.com/zakiyalmaya/online-store/infrastructure/token"
 	"github.com/zakiyalmaya/online-store/middleware"
 	"github.com/zakiyalmaya/online-store/transport/controller"
 )
 
-func Handler(application *application.Application, redcl *redis.Client, r *fiber.App) {
-	ctrl := controller.NewController(application)
+func Handler(application *application.Application, redcl *redis.Client, tokens token.Manager, r *fiber.App, cfg *config.Config) {
+	ctrl := controller.NewController(application, tokens, cfg)
+	auth := middleware.AuthMiddleware(redcl, tokens, application.APIKeySvc)
+	// these routes also accept an API key holding the scope
+	catalogRead := middleware.AuthMiddleware(redcl, tokens, application.APIKeySvc, apikeyEnum.ScopeCatalogRead)
+	catalogWrite := middleware.AuthMiddleware(redcl, tokens, application.APIKeySvc, apikeyEnum.ScopeCatalogWrite)
+	ordersRead := middleware.AuthMiddleware(redcl, tokens, application.APIKeySvc, apikeyEnum.ScopeOrdersRead)
+	ordersWrite := middleware.AuthMiddleware(redcl, tokens, application.APIKeySvc, apikeyEnum.ScopeOrdersWrite)
+	// before the auth middleware of the route, counting by IP address the
+	// requests with credentials missing or wrong too
+	preAuthLimit := middleware.PreAuthRateLimitMiddleware(application.RateLimitSvc, cfg.RateLimit)
+	// after the auth middleware of the route, counting by customer or API key
+	limit := middleware.RateLimitMiddleware(application.RateLimitSvc, cfg.RateLimit)
 
-	r.Post("/customer", ctrl.Customer.Register)
-	r.Post("/customer/login", ctrl.Customer.Login)
-	r.Post("/customer/logout", middleware.AuthMiddleware(redcl), ctrl.Customer.Logout)
+	// the probes come before the middlewares, they are not logged, measured nor traced
+	r.Get("/healthz", ctrl.Health.Live)
+	r.Get("/readyz", ctrl.Health.Ready)
+
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.MetricsMiddleware())
+	r.Use(middleware.AccessLogMiddleware())
+	r.Use(middleware.TracingMiddleware())
+	r.Use(middleware.TimeoutMiddleware(cfg.App.RequestTimeout))
+
+	r.Get("/.well-known/jwks.json", limit, ctrl.JWKS.Get)
+
+	r.Post("/customer", limit, ctrl.Customer.Register)
+	r.Post("/customer/login", limit, ctrl.Customer.Login)
+	r.Post("/customer/login/2fa", limit, ctrl.Customer.LoginTwoFactor)
+	r.Post("/customer/refresh", limit, ctrl.Customer.Refresh)
+	r.Post("/customer/logout", preAuthLimit, auth, limit, ctrl.Customer.Logout)
+	r.Get("/customer/sessions", preAuthLimit, auth, limit, ctrl.Customer.GetSessions)
+	r.Delete("/customer/session/:session_id", preAuthLimit, auth, limit, ctrl.Customer.RevokeSession)
+	r.Get("/customer/me", preAuthLimit, auth, limit, ctrl.Customer.GetProfile)
+	r.Put("/customer/me", preAuthLimit, auth, limit, ctrl.Customer.UpdateProfile)
+	r.Put("/customer/me/password", preAuthLimit, auth, limit, ctrl.Customer.ChangePassword)
+	r.Delete("/customer/me", preAuthLimit, auth, limit, ctrl.Customer.Delete)
+	r.Post("/customer/me/2fa", preAuthLimit, auth, limit, ctrl.Customer.EnrollTwoFactor)
+	r.Post("/customer/me/2fa/confirm", preAuthLimit, auth, limit, ctrl.Customer.ConfirmTwoFactor)
+	r.Delete("/customer/me/2fa", preAuthLimit, auth, limit, ctrl.Customer.DisableTwoFactor)
+	r.Post("/customer/email/verification", preAuthLimit, auth, limit, ctrl.Customer.SendVerificationEmail)
+	r.Post("/customer/email/verify", limit, ctrl.Customer.VerifyEmail)
+	r.Post("/customer/password/forgot", limit, ctrl.Customer.ForgotPassword)
+	r.Post("/customer/password/reset", limit, ctrl.Customer.ResetPassword)
 	
-	r.Get("/categories", middleware.AuthMiddleware(redcl), ctrl.Category.GetAll)
-	r.Post("/category", middleware.AuthMiddleware(redcl), ctrl.Category.Create)
+	r.Get("/categories", preAuthLimit, catalogRead, limit, ctrl.Category.GetAll)
+	r.Post("/category", preAuthLimit, catalogWrite, limit, ctrl.Category.Create)
+
+	r.Get("/products", preAuthLimit, catalogRead, limit, ctrl.Product.GetAll)
+	r.Post("/product", preAuthLimit, catalogWrite, limit, ctrl.Product.Create)
+	r.Get("/product/:product_id/variants", preAuthLimit, catalogRead, limit, ctrl.Product.GetVariants)
+	r.Post("/product/:product_id/option", preAuthLimit, catalogWrite, limit, ctrl.Product.CreateOption)
+	r.Post("/product/:product_id/variant", preAuthLimit, catalogWrite, limit, ctrl.Product.CreateVariant)
+	r.Get("/product/:product_id/images", preAuthLimit, catalogRead, limit, ctrl.Product.GetImages)
+	r.Post("/product/:product_id/image", preAuthLimit, catalogWrite, limit, middleware.AdminMiddleware(apikeyEnum.ScopeCatalogWrite), ctrl.Product.UploadImage)
+	r.Put("/product/:product_id/images/order", preAuthLimit, catalogWrite, limit, middleware.AdminMiddleware(apikeyEnum.ScopeCatalogWrite), ctrl.Product.ReorderImages)
+	r.Put("/product/:product_id/image/:image_id/primary", preAuthLimit, catalogWrite, limit, middleware.AdminMiddleware(apikeyEnum.ScopeCatalogWrite), ctrl.Product.SetPrimaryImage)
+	r.Delete("/product/:product_id/image/:image_id", preAuthLimit, catalogWrite, limit, middleware.AdminMiddleware(apikeyEnum.ScopeCatalogWrite), ctrl.Product.DeleteImage)
+	r.Get("/product/:product_id/reviews", preAuthLimit, catalogRead, limit, ctrl.Review.GetByProduct)
+	r.Post("/product/:product_id/review", preAuthLimit, auth, limit, ctrl.Review.Create)
+
+	r.Get("/admin/reviews", preAuthLimit, auth, limit, middleware.AdminMiddleware(), ctrl.Review.GetAll)
+	r.Put("/admin/review/:review_id/status", preAuthLimit, auth, limit, middleware.AdminMiddleware(), ctrl.Review.UpdateStatus)
+	r.Put("/admin/transaction/:transaction_id/status", preAuthLimit, ordersWrite, limit, middleware.AdminMiddleware(apikeyEnum.ScopeOrdersWrite), ctrl.Transaction.UpdateStatus)
+
+	r.Post("/admin/api-key", preAuthLimit, auth, limit, middleware.AdminMiddleware(), ctrl.APIKey.Create)
+	r.Get("/admin/api-keys", preAuthLimit, auth, limit, middleware.AdminMiddleware(), ctrl.APIKey.GetAll)
+	r.Delete("/admin/api-key/:api_key_id", preAuthLimit, auth, limit, middleware.AdminMiddleware(), ctrl.APIKey.Revoke)
+
+	r.Get("/carts", preAuthLimit, auth, limit, ctrl.Cart.GetAll)
+	r.Post("/cart", preAuthLimit, auth, limit, ctrl.Cart.Create)
+	r.Delete("/cart/:cart_item_id", preAuthLimit, auth, limit, ctrl.Cart.Delete)
+
+	r.Post("/cart/:cart_item_id/wishlist", preAuthLimit, auth, limit, ctrl.Wishlist.SaveForLater)
 
-	r.Get("/products", middleware.AuthMiddleware(redcl), ctrl.Product.GetAll)
-	r.Post("/product", middleware.AuthMiddleware(redcl), ctrl.Product.Create)
+	r.Get("/guest/cart", limit, ctrl.Cart.GetGuest)
+	r.Post("/guest/cart", limit, ctrl.Cart.CreateGuest)
+	r.Delete("/guest/cart/:product_id", limit, ctrl.Cart.DeleteGuest)
 
-	r.Get("/carts", middleware.AuthMiddleware(redcl), ctrl.Cart.GetAll)
-	r.Post("/cart", middleware.AuthMiddleware(redcl), ctrl.Cart.Create)
-	r.Delete("/cart/:cart_item_id", middleware.AuthMiddleware(redcl), ctrl.Cart.Delete)
+	r.Get("/wishlist", preAuthLimit, auth, limit, ctrl.Wishlist.GetAll)
+	r.Post("/wishlist", preAuthLimit, auth, limit, ctrl.Wishlist.Create)
+	r.Delete("/wishlist/:product_id", preAuthLimit, auth, limit, ctrl.Wishlist.Delete)
+	r.Post("/wishlist/:product_id/cart", preAuthLimit, auth, limit, ctrl.Wishlist.MoveToCart)
+	r.Get("/wishlist/notifications", preAuthLimit, auth, limit, ctrl.Wishlist.GetNotifications)
 
-	r.Post("/transaction", middleware.AuthMiddleware(redcl), ctrl.Transaction.Checkout)
-	r.Get("/transaction", middleware.AuthMiddleware(redcl), ctrl.Transaction.GetByID)
-}
\ No newline at end of file
+	r.Post("/transaction", preAuthLimit, auth, limit, ctrl.Transaction.Checkout)
+	r.Get("/transaction", preAuthLimit, ordersRead, limit, ctrl.Transaction.GetByID)
+}
+// MetricsHandler serves /metrics on the internal listener, apart from the
+// public routes since it needs no login.
+func MetricsHandler(r *fiber.App) {
+	r.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+}
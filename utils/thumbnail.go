@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrImageTooLarge is returned for an image with more pixels than allowed.
+var ErrImageTooLarge = errors.New("image has too many pixels")
+
+// GenerateThumbnail scales the image down to fit in a size x size box. JPEG
+// images stay JPEG, everything else becomes PNG to keep the transparency.
+// The header is read first, a small file may declare huge dimensions and
+// decoding it would allocate width x height pixels.
+func GenerateThumbnail(data []byte, size, maxPixels int) ([]byte, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if int64(config.Width)*int64(config.Height) > int64(maxPixels) {
+		return nil, "", ErrImageTooLarge
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			height = max(1, height*size/width)
+			width = size
+		} else {
+			width = max(1, width*size/height)
+			height = size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	buf := &bytes.Buffer{}
+	if format == "jpeg" {
+		if err := jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	if err := png.Encode(buf, dst); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), "image/png", nil
+}
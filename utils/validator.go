@@ -1,12 +1,129 @@
 package utils
 
-import "github.com/go-playground/validator/v10"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	phoneRegex    = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+	validate = newValidator()
+)
+
+// FieldErrors maps the json name of a request field to what is wrong with it.
+type FieldErrors map[string]string
+
+func (f FieldErrors) Error() string {
+	fields := make([]string, 0, len(f))
+	for field := range f {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + " " + f[field]
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonName)
+
+	// phone numbers are stored as typed, spaces and dashes are not stripped
+	v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return phoneRegex.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return usernameRegex.MatchString(fl.Field().String())
+	})
+
+	return v
+}
+
+// Validator checks the validate tags of the input and returns FieldErrors
+// naming the fields the way the client sent them.
 func Validator(input interface{}) error {
-	validate := validator.New()
-	if err := validate.Struct(input); err != nil {
+	err := validate.Struct(input)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
 		return err
 	}
 
-	return nil
-}
\ No newline at end of file
+	fields := FieldErrors{}
+	for _, fieldErr := range validationErrors {
+		fields[fieldErr.Field()] = message(input, fieldErr)
+	}
+
+	return fields
+}
+
+func message(input interface{}, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a phone number of 8 to 15 digits"
+	case "username":
+		return "may only contain letters, digits, dots, dashes and underscores"
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fieldErr.Param())
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fieldErr.Param())
+		}
+		return "must be at most " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "oneof":
+		return "must be one of " + fieldErr.Param()
+	case "nefield":
+		return "must be different from " + structFieldName(input, fieldErr.Param())
+	default:
+		return "is invalid"
+	}
+}
+
+// structFieldName returns the json name of the named field of the input.
+func structFieldName(input interface{}, name string) string {
+	t := reflect.TypeOf(input)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct {
+		if field, ok := t.FieldByName(name); ok {
+			return jsonName(field)
+		}
+	}
+
+	return name
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}